@@ -2,6 +2,7 @@ package main
 
 import (
 	"flag"
+	"github.com/hanc00l/nemo_go/pkg/cert"
 	"github.com/hanc00l/nemo_go/pkg/comm"
 	"github.com/hanc00l/nemo_go/pkg/conf"
 	"github.com/hanc00l/nemo_go/pkg/filesync"
@@ -20,7 +21,13 @@ type WorkerDaemonOption struct {
 	ManualSyncPort    string
 	ManualSyncAuth    string
 	TaskWorkspaceGUID string
+	WorkerTags        string
+	WorkerTagOnly     bool
 	TLSEnabled        bool
+	IdentityFile      string
+	HTTPServerURL     string
+	ReleasePublicKey  string
+	ReleaseInsecure   bool
 }
 
 func parseDaemonWorkerOption() *WorkerDaemonOption {
@@ -32,11 +39,17 @@ func parseDaemonWorkerOption() *WorkerDaemonOption {
 	flag.IntVar(&option.WorkerPerformance, "p", 0, "worker performance,default is autodetect (0:autodetect, 1:high, 2:normal)")
 	flag.StringVar(&option.WorkerRunTaskMode, "m", "0", "worker run task mode; 0: all, 1:active, 2:finger, 3:passive, 4:pocscan, 5:custom; run multiple mode separated by \",\"")
 	flag.StringVar(&option.TaskWorkspaceGUID, "w", "", "workspace guid for custom task; multiple workspace separated by \",\"")
+	flag.StringVar(&option.WorkerTags, "tags", "", "worker tags for tagged task; multiple tags separated by \",\"")
+	flag.BoolVar(&option.WorkerTagOnly, "tagonly", false, "only run tagged task")
 	flag.StringVar(&option.ManualSyncHost, "mh", "", "manual file sync host address")
 	flag.StringVar(&option.ManualSyncPort, "mp", "", "manual file sync port,default is 5002")
 	flag.StringVar(&option.ManualSyncAuth, "ma", "", "manual file sync auth key")
 	flag.BoolVar(&option.NoFilesync, "nf", option.NoFilesync, "disable file sync")
 	flag.BoolVar(&option.TLSEnabled, "tls", false, "use TLS for RPC and filesync")
+	flag.StringVar(&option.IdentityFile, "identity", comm.WorkerIdentityFile, "worker identity file issued by server")
+	flag.StringVar(&option.ReleasePublicKey, "release-pub", cert.ReleasePublicKeyFile, "public key file to verify the signed release of synced files")
+	flag.BoolVar(&option.ReleaseInsecure, "release-insecure", false, "sync files without release public key, the release signature is not verified (insecure)")
+	flag.StringVar(&option.HTTPServerURL, "http", "", "server web url for http transport, such as https://nemo.example.com:5000; RPC, filesync and task queue are accessed through the web server")
 	flag.Parse()
 
 	return option
@@ -60,12 +73,35 @@ func main() {
 		return
 	}
 	comm.TLSEnabled = option.TLSEnabled
+	comm.WorkerTags = option.WorkerTags
+	comm.WorkerTagOnly = option.WorkerTagOnly
+	comm.WorkerIdentityFileName = option.IdentityFile
 	filesync.TLSEnabled = option.TLSEnabled
+	if err := comm.LoadWorkerIdentity(option.IdentityFile); err != nil {
+		logging.CLILog.Errorf("load worker identity fail:%v", err)
+		logging.RuntimeLog.Errorf("load worker identity fail:%v", err)
+		return
+	}
+	// 只有同步文件时需要公钥
+	if !option.NoFilesync || option.ManualSyncHost != "" {
+		if err := comm.LoadReleasePublicKey(option.ReleasePublicKey, option.ReleaseInsecure); err != nil {
+			logging.CLILog.Errorf("load release public key fail:%v", err)
+			logging.RuntimeLog.Errorf("load release public key fail:%v", err)
+			return
+		}
+	}
+	if option.HTTPServerURL != "" {
+		if err := comm.EnableHTTPTransport(option.HTTPServerURL); err != nil {
+			logging.CLILog.Errorf("enable http transport fail:%v", err)
+			logging.RuntimeLog.Errorf("enable http transport fail:%v", err)
+			return
+		}
+	}
 
 	if option.ManualSyncHost != "" && option.ManualSyncPort != "" && option.ManualSyncAuth != "" {
 		logging.RuntimeLog.Info("start onetime file sync...")
 		logging.CLILog.Info("start onetime file sync...")
-		filesync.WorkerStartupSync(option.ManualSyncHost, option.ManualSyncPort, option.ManualSyncAuth)
+		filesync.WorkerStartupSync(option.ManualSyncHost, option.ManualSyncPort, option.ManualSyncAuth, "")
 		return
 	}
 	go comm.StartSaveRuntimeLog(comm.GetWorkerNameBySelf())
@@ -2,34 +2,35 @@ package main
 
 import (
 	"flag"
+	"fmt"
 	"github.com/beego/beego/v2/core/logs"
 	"github.com/beego/beego/v2/server/web"
-	beegoContext "github.com/beego/beego/v2/server/web/context"
 	"github.com/hanc00l/nemo_go/pkg/cert"
 	"github.com/hanc00l/nemo_go/pkg/comm"
 	"github.com/hanc00l/nemo_go/pkg/conf"
+	"github.com/hanc00l/nemo_go/pkg/db"
 	"github.com/hanc00l/nemo_go/pkg/filesync"
 	"github.com/hanc00l/nemo_go/pkg/logging"
 	"github.com/hanc00l/nemo_go/pkg/task/ampq"
 	"github.com/hanc00l/nemo_go/pkg/task/custom"
 	"github.com/hanc00l/nemo_go/pkg/task/runner"
 	"github.com/hanc00l/nemo_go/pkg/utils"
+	ctrl "github.com/hanc00l/nemo_go/pkg/web/controllers"
 	_ "github.com/hanc00l/nemo_go/pkg/web/routers"
-	"net/http"
 	"path/filepath"
 	"time"
 )
 
 type ServerOption struct {
-	NoFilesync  bool
-	NoRPC       bool
-	TLSEnabled  bool
-	TLSCertFile string
-	TLSKeyFile  string
+	NoFilesync    bool
+	NoRPC         bool
+	TLSEnabled    bool
+	TLSCertFile   string
+	TLSKeyFile    string
+	MigrateOnly   bool
+	MigrateDryRun bool
 }
 
-var UrlFilterWhiteList = []string{"/"}
-
 func parseServerOption() *ServerOption {
 	option := &ServerOption{}
 	if conf.RunMode == conf.Debug {
@@ -40,11 +41,39 @@ func parseServerOption() *ServerOption {
 	flag.BoolVar(&option.TLSEnabled, "tls", false, "use TLS for web、RPC and filesync")
 	flag.StringVar(&option.TLSKeyFile, "key", "server.key", "TLS private key file")
 	flag.StringVar(&option.TLSCertFile, "cert", "server.crt", "TLS cert file")
+	flag.BoolVar(&option.MigrateOnly, "migrate-only", false, "apply database migrations and exit")
+	flag.BoolVar(&option.MigrateDryRun, "dry-run", false, "show pending database migrations without applying them")
 	flag.Parse()
 
 	return option
 }
 
+// ShowPendingMigrations 显示尚未执行的数据库迁移
+func ShowPendingMigrations() {
+	pending, err := db.GetPendingMigrations()
+	if err != nil {
+		logging.CLILog.Error(err)
+		return
+	}
+	if len(pending) == 0 {
+		logging.CLILog.Info("database schema is up to date")
+		return
+	}
+	for _, m := range pending {
+		logging.CLILog.Infof("pending migration version %d:%s", m.Version, m.Name)
+	}
+}
+
+// MigrateDatabase 执行数据库迁移
+func MigrateDatabase() bool {
+	if err := db.Migrate(); err != nil {
+		logging.CLILog.Errorf("migrate database fail:%v", err)
+		logging.RuntimeLog.Errorf("migrate database fail:%v", err)
+		return false
+	}
+	return true
+}
+
 // StartCronTask 启动定时任务
 func StartCronTask() {
 	num := runner.StartCronTask()
@@ -66,8 +95,10 @@ func StartWebServer(option *ServerOption) {
 		return
 	}
 	if conf.RunMode == conf.Release {
-		web.InsertFilter("/*", web.BeforeRouter, filterLoginCheck)
+		web.InsertFilter("/*", web.BeforeRouter, ctrl.FilterLoginCheck)
 	}
+	// HTTP方式的worker通过web端口进行RPC调用、读取任务及文件同步
+	web.Handler(comm.HTTPTransportPath, comm.NewHTTPTransportHandler(!option.NoFilesync), true)
 
 	logging.RuntimeLog.Info("nemo server started...")
 	logging.CLILog.Info("nemo server started...")
@@ -78,6 +109,7 @@ func StartWebServer(option *ServerOption) {
 		web.BConfig.Listen.HTTPSKeyFile = option.TLSKeyFile
 		web.BConfig.Listen.HTTPSAddr = conf.GlobalServerConfig().Web.Host
 		web.BConfig.Listen.HTTPSPort = conf.GlobalServerConfig().Web.Port
+		web.BeeApp.Server.TLSConfig = comm.HTTPTransportTLSConfig()
 	} else {
 		web.BConfig.Listen.EnableHTTP = true
 		web.BConfig.Listen.EnableHTTPS = false
@@ -87,24 +119,27 @@ func StartWebServer(option *ServerOption) {
 	web.Run()
 }
 
-// filterLoginCheck 全局的登录验证
-func filterLoginCheck(ctx *beegoContext.Context) {
-	for _, url := range UrlFilterWhiteList {
-		if ctx.Request.RequestURI == url {
-			return
-		}
-	}
-	// 检查用户是否登录（检查登录成功后的session:User、UserRole、Workspace
-	if user, ok := ctx.Input.Session("User").(string); !ok || len(user) == 0 {
-		ctx.Redirect(http.StatusFound, "/")
-	}
-	userRole, ok := ctx.Input.Session("UserRole").(string)
-	if !ok || len(userRole) == 0 {
-		ctx.Redirect(http.StatusFound, "/")
+// LoadServerCert 加载CA，并使用CA签发server证书；worker使用CA验证server证书，server使用CA验证worker的客户端证书
+func LoadServerCert(option *ServerOption) bool {
+	ca, err := cert.LoadOrGenerateCA(cert.CACertFile, cert.CAKeyFile)
+	if err != nil {
+		logging.CLILog.Errorf("load ca fail:%v", err)
+		logging.RuntimeLog.Errorf("load ca fail:%v", err)
+		return false
 	}
-	if workspaceId, ok := ctx.Input.Session("Workspace").(int); !ok || (userRole != "superadmin" && workspaceId <= 0) {
-		ctx.Redirect(http.StatusFound, "/")
+	comm.SetServerCA(ca)
+	if !utils.CheckFileExist(filepath.Join(conf.GetRootPath(), option.TLSCertFile)) || !utils.CheckFileExist(filepath.Join(conf.GetRootPath(), option.TLSKeyFile)) {
+		if err = ca.GenerateServerCert(option.TLSCertFile, option.TLSKeyFile); err != nil {
+			logging.CLILog.Error(err)
+			return false
+		}
+		logging.CLILog.Info("generate server cert signed by ca...")
+	} else if !ca.IsSignedServerCert(option.TLSCertFile) {
+		msg := fmt.Sprintf("%s is not signed by %s,registered worker will fail to verify server cert; remove %s and %s to regenerate", option.TLSCertFile, cert.CACertFile, option.TLSCertFile, option.TLSKeyFile)
+		logging.CLILog.Warning(msg)
+		logging.RuntimeLog.Warning(msg)
 	}
+	return true
 }
 
 func loadCustomTaskWorkspace() {
@@ -117,14 +152,15 @@ func main() {
 		return
 	}
 
-	if option.TLSEnabled {
-		if !utils.CheckFileExist(filepath.Join(conf.GetRootPath(), option.TLSCertFile)) || !utils.CheckFileExist(filepath.Join(conf.GetRootPath(), option.TLSKeyFile)) {
-			if err := cert.GenerateSelfSignedCert(option.TLSCertFile, option.TLSKeyFile); err != nil {
-				logging.CLILog.Error(err)
-				return
-			}
-			logging.CLILog.Info("generate selfsigned cert...")
-		}
+	if option.MigrateDryRun {
+		ShowPendingMigrations()
+		return
+	}
+	if !MigrateDatabase() || option.MigrateOnly {
+		return
+	}
+	if option.TLSEnabled && !LoadServerCert(option) {
+		return
 	}
 	if !option.NoFilesync {
 		filesync.TLSEnabled = option.TLSEnabled
@@ -135,6 +171,7 @@ func main() {
 		time.Sleep(time.Second * 1)
 	}
 	if !option.NoRPC {
+		comm.EnableServerAuthKey()
 		comm.TLSEnabled = option.TLSEnabled
 		comm.TLSCertFile = option.TLSCertFile
 		comm.TLSKeyFile = option.TLSKeyFile
@@ -5,22 +5,15 @@ import (
 	"fmt"
 	"github.com/beego/beego/v2/core/logs"
 	"github.com/beego/beego/v2/server/web"
-	beegoContext "github.com/beego/beego/v2/server/web/context"
 	"github.com/hanc00l/nemo_go/pkg/comm"
 	"github.com/hanc00l/nemo_go/pkg/conf"
 	"github.com/hanc00l/nemo_go/pkg/logging"
 	"github.com/hanc00l/nemo_go/pkg/task/runner"
 	ctrl "github.com/hanc00l/nemo_go/pkg/web/controllers"
 	_ "github.com/hanc00l/nemo_go/pkg/webapi/routers"
-	"net/http"
 	"time"
 )
 
-var UrlFilterWhiteList = []string{
-	"/v1/login/captcha",
-	"/v1/login/login",
-}
-
 // StartCronTask 启动定时任务
 func StartCronTask() {
 	num := runner.StartCronTask()
@@ -46,7 +39,7 @@ func StartWebAPIServer() {
 		web.BConfig.WebConfig.StaticDir["/swagger"] = "swagger"
 	}
 	if conf.RunMode == conf.Release {
-		web.InsertFilter("/*", web.BeforeRouter, filterLoginCheck)
+		web.InsertFilter("/*", web.BeforeRouter, ctrl.FilterAPITokenCheck)
 	}
 	logging.RuntimeLog.Info("nemo API server started...")
 	logging.CLILog.Info("nemo API server started...")
@@ -54,23 +47,6 @@ func StartWebAPIServer() {
 	web.Run(addr)
 }
 
-// filterLoginCheck 全局的token验证
-func filterLoginCheck(ctx *beegoContext.Context) {
-	for _, url := range UrlFilterWhiteList {
-		if ctx.Request.RequestURI == url {
-			return
-		}
-	}
-	// 检查token
-	tokenString := ctx.Input.Header("Authorization")
-	if len(tokenString) == 0 {
-		ctx.Redirect(http.StatusFound, "/")
-	}
-	if jwtData := ctrl.ValidToken(ctrl.GetTokenValueFromHeader(tokenString)); jwtData == nil {
-		ctx.Redirect(http.StatusFound, "/")
-	}
-}
-
 func main() {
 	var noFilesync, noRPC bool
 	flag.BoolVar(&noFilesync, "nf", false, "disable file sync")
@@ -84,6 +60,7 @@ func main() {
 		time.Sleep(time.Second * 1)
 	}
 	if noRPC == false {
+		comm.EnableServerAuthKey()
 		go comm.StartRPCServer()
 		time.Sleep(time.Second * 1)
 	}
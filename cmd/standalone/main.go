@@ -0,0 +1,149 @@
+package main
+
+import (
+	"flag"
+	"github.com/beego/beego/v2/core/logs"
+	"github.com/beego/beego/v2/server/web"
+	beegoContext "github.com/beego/beego/v2/server/web/context"
+	"github.com/hanc00l/nemo_go/pkg/comm"
+	"github.com/hanc00l/nemo_go/pkg/conf"
+	"github.com/hanc00l/nemo_go/pkg/db"
+	"github.com/hanc00l/nemo_go/pkg/logging"
+	"github.com/hanc00l/nemo_go/pkg/task/ampq"
+	"github.com/hanc00l/nemo_go/pkg/task/custom"
+	"github.com/hanc00l/nemo_go/pkg/task/runner"
+	"github.com/hanc00l/nemo_go/pkg/task/workerapi"
+	ctrl "github.com/hanc00l/nemo_go/pkg/web/controllers"
+	_ "github.com/hanc00l/nemo_go/pkg/web/routers"
+	_ "github.com/hanc00l/nemo_go/pkg/webapi/routers"
+	"strings"
+	"time"
+)
+
+type StandaloneOption struct {
+	Dbname            string
+	Concurrency       int
+	WorkerPerformance int
+}
+
+// apiPrefix API接口的路径前缀，单机模式下与web使用同一端口
+const apiPrefix = "/v1/"
+
+// workerTopics 内嵌的worker执行的任务队列
+var workerTopics = []string{ampq.TopicActive, ampq.TopicFinger, ampq.TopicPassive, ampq.TopicPocscan, ampq.TopicCustom}
+
+func parseStandaloneOption() *StandaloneOption {
+	option := &StandaloneOption{}
+	flag.StringVar(&option.Dbname, "db", conf.StandaloneDbname, "sqlite database file")
+	flag.IntVar(&option.Concurrency, "c", 2, "concurrent number of tasks")
+	flag.IntVar(&option.WorkerPerformance, "p", 0, "worker performance,default is autodetect (0:autodetect, 1:high, 2:normal)")
+	flag.Parse()
+
+	return option
+}
+
+// MigrateDatabase 执行数据库迁移
+func MigrateDatabase() bool {
+	if err := db.Migrate(); err != nil {
+		logging.CLILog.Errorf("migrate database fail:%v", err)
+		logging.RuntimeLog.Errorf("migrate database fail:%v", err)
+		return false
+	}
+	return true
+}
+
+// StartCronTask 启动定时任务
+func StartCronTask() {
+	num := runner.StartCronTask()
+	logging.CLILog.Infof("cron task total:%d", num)
+	logging.RuntimeLog.Infof("cron task total:%d", num)
+}
+
+// StartMainTaskDemon 启动任务监控，生成运行任务，分发到队列由worker执行
+func StartMainTaskDemon() {
+	go runner.StartMainTaskDamon()
+}
+
+// StartWebServer 启动web server，同时提供API接口
+func StartWebServer() {
+	err := logs.SetLogger("file", `{"filename":"log/access.log"}`)
+	if err != nil {
+		logging.RuntimeLog.Error(err)
+		logging.CLILog.Error(err)
+		return
+	}
+	if conf.RunMode == conf.Release {
+		web.InsertFilter("/*", web.BeforeRouter, filterLoginCheck)
+	}
+
+	logging.RuntimeLog.Info("nemo standalone started...")
+	logging.CLILog.Info("nemo standalone started...")
+	web.BConfig.Listen.EnableHTTP = true
+	web.BConfig.Listen.EnableHTTPS = false
+	web.BConfig.Listen.HTTPAddr = conf.GlobalServerConfig().Web.Host
+	web.BConfig.Listen.HTTPPort = conf.GlobalServerConfig().Web.Port
+	web.Run()
+}
+
+// filterLoginCheck 全局的登录验证：API接口验证token，web验证session
+func filterLoginCheck(ctx *beegoContext.Context) {
+	if strings.HasPrefix(ctx.Request.RequestURI, apiPrefix) {
+		ctrl.FilterAPITokenCheck(ctx)
+		return
+	}
+	ctrl.FilterLoginCheck(ctx)
+}
+
+func loadCustomTaskWorkspace() {
+	ampq.CustomTaskWorkspaceMap = custom.LoadCustomTaskWorkspace()
+}
+
+// startWorker 启动内嵌的worker
+func startWorker(option *StandaloneOption) {
+	workerapi.CheckWorkerPerformance(option.WorkerPerformance)
+	workerapi.WStatus.WorkerName = comm.GetWorkerNameBySelf()
+	workerapi.WStatus.CreateTime = time.Now()
+	workerapi.WStatus.UpdateTime = time.Now()
+	workerapi.WStatus.WorkerTopics = strings.Join(workerTopics, ",")
+	for _, topic := range workerTopics {
+		go func(topicName string, concurrency int) {
+			err := workerapi.StartWorker(topicName, concurrency)
+			if err != nil {
+				logging.CLILog.Error(err.Error())
+				logging.RuntimeLog.Fatal(err.Error())
+			}
+		}(topic, option.Concurrency)
+	}
+	go workerapi.KeepAlive()
+	go comm.StartSpoolReplay()
+}
+
+func main() {
+	option := parseStandaloneOption()
+	if option == nil {
+		return
+	}
+	// 单机模式：sqlite数据库、进程内的消息队列，不启用文件同步
+	conf.EnableStandalone(option.Dbname)
+
+	if !MigrateDatabase() {
+		return
+	}
+	comm.EnableServerAuthKey()
+	go comm.StartRPCServer()
+	time.Sleep(time.Second * 1)
+	go comm.StartSaveRuntimeLog("standalone@nemo")
+	loadCustomTaskWorkspace()
+	StartCronTask()
+	StartMainTaskDemon()
+	startWorker(option)
+	time.Sleep(time.Second * 1)
+
+	err := comm.GenerateRSAKey()
+	if err != nil {
+		logging.CLILog.Error(err)
+		logging.RuntimeLog.Error(err)
+		return
+	}
+	StartWebServer()
+}
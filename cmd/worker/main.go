@@ -4,13 +4,10 @@ import (
 	"flag"
 	"fmt"
 	"github.com/hanc00l/nemo_go/pkg/comm"
-	"github.com/hanc00l/nemo_go/pkg/conf"
 	"github.com/hanc00l/nemo_go/pkg/logging"
 	"github.com/hanc00l/nemo_go/pkg/task/ampq"
 	"github.com/hanc00l/nemo_go/pkg/task/workerapi"
 	"github.com/hanc00l/nemo_go/pkg/utils"
-	"github.com/shirou/gopsutil/v3/cpu"
-	"github.com/shirou/gopsutil/v3/mem"
 	"os"
 	"os/signal"
 	"strconv"
@@ -26,6 +23,8 @@ type WorkerOption struct {
 	WorkerPerformance int
 	WorkerTopic       map[string]struct{}
 	TLSEnabled        bool
+	IdentityFile      string
+	HTTPServerURL     string
 }
 
 func parseWorkerOptions() *WorkerOption {
@@ -35,11 +34,17 @@ func parseWorkerOptions() *WorkerOption {
 
 	var workerRunTaskMode string
 	var taskWorkspaceGUID string
+	var workerTags string
+	var workerTagOnly bool
 	flag.IntVar(&option.Concurrency, "c", 3, "concurrent number of tasks")
 	flag.IntVar(&option.WorkerPerformance, "p", 0, "worker performance,default is autodetect (0:autodetect, 1:high, 2:normal)")
 	flag.StringVar(&workerRunTaskMode, "m", "0", "worker run task mode; 0: all, 1:active, 2:finger, 3:passive, 4:pocscan, 5:custom; run multiple mode separated by \",\"")
 	flag.StringVar(&taskWorkspaceGUID, "w", "", "workspace guid for custom task; multiple workspace separated by \",\"")
+	flag.StringVar(&workerTags, "tags", "", "worker tags for tagged task; multiple tags separated by \",\"")
+	flag.BoolVar(&workerTagOnly, "tagonly", false, "only run tagged task")
 	flag.BoolVar(&option.TLSEnabled, "tls", false, "use TLS for RPC and filesync")
+	flag.StringVar(&option.IdentityFile, "identity", comm.WorkerIdentityFile, "worker identity file issued by server")
+	flag.StringVar(&option.HTTPServerURL, "http", "", "server web url for http transport, such as https://nemo.example.com:5000; RPC, filesync and task queue are accessed through the web server")
 	flag.Parse()
 
 	if workerRunTaskMode == "0" {
@@ -86,38 +91,47 @@ func parseWorkerOptions() *WorkerOption {
 		logging.CLILog.Error("error worker run task mode...")
 		return nil
 	}
+	if workerTags != "" || workerTagOnly {
+		if !addWorkerTagTopic(option, workerTags, workerTagOnly) {
+			logging.CLILog.Error("error worker tags...")
+			return nil
+		}
+	}
 	return option
 }
 
-// keepAlive worker与server的心跳与同步
-func keepAlive() {
-	time.Sleep(10 * time.Second)
-	for {
-		workerapi.WStatus.Lock()
-		if !comm.DoKeepAlive(workerapi.WStatus) {
-			logging.RuntimeLog.Errorf("keep alive fail")
-			logging.CLILog.Error("keep alive fail")
+// addWorkerTagTopic 为worker的每个任务队列增加带标签的队列（如active.dmz），以执行指定了标签的任务；
+// tagOnly时不再执行未指定标签的任务。自定义任务按工作空间分配，不使用标签
+func addWorkerTagTopic(option *WorkerOption, workerTags string, tagOnly bool) bool {
+	var tags []string
+	for _, t := range strings.Split(workerTags, ",") {
+		tag := strings.TrimSpace(t)
+		if tag == "" {
+			continue
+		}
+		if !ampq.CheckWorkerTag(tag) {
+			return false
 		}
-		workerapi.WStatus.Unlock()
-		time.Sleep(60 * time.Second)
+		tags = append(tags, tag)
 	}
-}
-
-func checkWorkerPerformance(workerPerformance int) {
-	switch workerPerformance {
-	case 0:
-		cpuNumber, err1 := cpu.Counts(true)
-		memInfo, err2 := mem.VirtualMemory()
-		if err1 != nil || err2 != nil {
-			break
+	if len(tags) == 0 {
+		return false
+	}
+	topics := make(map[string]struct{})
+	for topic := range option.WorkerTopic {
+		if strings.HasPrefix(topic, ampq.TopicCustom) {
+			topics[topic] = struct{}{}
+			continue
 		}
-		if cpuNumber >= 4 && memInfo.Total >= 4*1024*1024*1024 {
-			conf.WorkerPerformanceMode = conf.HighPerformance
+		if !tagOnly {
+			topics[topic] = struct{}{}
+		}
+		for _, tag := range tags {
+			topics[ampq.GetTaggedTopic(topic, tag)] = struct{}{}
 		}
-	case 1:
-		conf.WorkerPerformanceMode = conf.HighPerformance
 	}
-	return
+	option.WorkerTopic = topics
+	return true
 }
 
 func setupCloseHandler() {
@@ -162,9 +176,22 @@ func main() {
 	}
 
 	comm.TLSEnabled = option.TLSEnabled
-	go keepAlive()
+	if err := comm.LoadWorkerIdentity(option.IdentityFile); err != nil {
+		logging.CLILog.Errorf("load worker identity fail:%v", err)
+		logging.RuntimeLog.Errorf("load worker identity fail:%v", err)
+		return
+	}
+	if option.HTTPServerURL != "" {
+		if err := comm.EnableHTTPTransport(option.HTTPServerURL); err != nil {
+			logging.CLILog.Errorf("enable http transport fail:%v", err)
+			logging.RuntimeLog.Errorf("enable http transport fail:%v", err)
+			return
+		}
+	}
+	go workerapi.KeepAlive()
 	go comm.StartSaveRuntimeLog(comm.GetWorkerNameBySelf())
-	checkWorkerPerformance(option.WorkerPerformance)
+	go comm.StartSpoolReplay()
+	workerapi.CheckWorkerPerformance(option.WorkerPerformance)
 	initWorkerStatus(option)
 	startWorker(option)
 	setupCloseHandler()
@@ -147,6 +147,7 @@ CREATE TABLE `ip` (
   `id` int(10) unsigned NOT NULL AUTO_INCREMENT,
   `ip` varchar(128) NOT NULL,
   `ip_int` bigint(20) NOT NULL,
+  `ip_sort` char(32) DEFAULT NULL,
   `org_id` int(10) unsigned DEFAULT NULL,
   `location` varchar(200) DEFAULT NULL,
   `status` varchar(20) DEFAULT NULL,
@@ -156,6 +157,7 @@ CREATE TABLE `ip` (
   `update_datetime` datetime NOT NULL,
   PRIMARY KEY (`id`),
   KEY `index_ip_org_id` (`org_id`),
+  KEY `index_ip_ip_sort` (`ip_sort`),
   KEY `fk_ip_workspace_id` (`workspace_id`),
   CONSTRAINT `fk_ip_org_id` FOREIGN KEY (`org_id`) REFERENCES `organization` (`id`) ON DELETE CASCADE ON UPDATE CASCADE,
   CONSTRAINT `fk_ip_workspace_id` FOREIGN KEY (`workspace_id`) REFERENCES `workspace` (`id`) ON DELETE CASCADE
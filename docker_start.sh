@@ -5,7 +5,7 @@ cd /opt/nemo
 if [ $# -eq 0 ]
     then
         nohup ./server_linux_amd64 &
-        nohup ./daemon_worker_linux_amd64 &
+        nohup ./daemon_worker_linux_amd64 -nf &
         nohup ./thirdparty/goby/goby-cmd-linux -mode api -bind 127.0.0.1:8361 -apiauth goby:goby &
 else
     if [ "$1" = "server" ]
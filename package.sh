@@ -9,12 +9,15 @@ echo > log/access.log
 CGO_ENABLED=0 GOOS=darwin GOARCH=amd64 go build -ldflags "-s -w" -trimpath -o server_darwin_amd64 cmd/server/main.go
 CGO_ENABLED=0 GOOS=darwin GOARCH=amd64 go build -ldflags "-s -w" -trimpath -o worker_darwin_amd64 cmd/worker/main.go
 CGO_ENABLED=0 GOOS=darwin GOARCH=amd64 go build -ldflags "-s -w" -trimpath -o daemon_worker_darwin_amd64 cmd/daemon_worker/main.go
+CGO_ENABLED=0 GOOS=darwin GOARCH=amd64 go build -ldflags "-s -w" -trimpath -o standalone_darwin_amd64 cmd/standalone/main.go
 CGO_ENABLED=0 GOOS=linux GOARCH=amd64 go build -ldflags "-s -w" -trimpath -o server_linux_amd64 cmd/server/main.go
 CGO_ENABLED=0 GOOS=linux GOARCH=amd64 go build -ldflags "-s -w" -trimpath -o worker_linux_amd64 cmd/worker/main.go
 CGO_ENABLED=0 GOOS=linux GOARCH=amd64 go build -ldflags "-s -w" -trimpath -o daemon_worker_linux_amd64 cmd/daemon_worker/main.go
+CGO_ENABLED=0 GOOS=linux GOARCH=amd64 go build -ldflags "-s -w" -trimpath -o standalone_linux_amd64 cmd/standalone/main.go
 CGO_ENABLED=0 GOOS=windows GOARCH=amd64 go build -ldflags "-s -w" -trimpath -o server_windows_amd64.exe cmd/server/main.go
 CGO_ENABLED=0 GOOS=windows GOARCH=amd64 go build -ldflags "-s -w" -trimpath -o worker_windows_amd64.exe cmd/worker/main.go
 CGO_ENABLED=0 GOOS=windows GOARCH=amd64 go build -ldflags "-s -w" -trimpath -o daemon_worker_windows_amd64.exe cmd/daemon_worker/main.go
+CGO_ENABLED=0 GOOS=windows GOARCH=amd64 go build -ldflags "-s -w" -trimpath -o standalone_windows_amd64.exe cmd/standalone/main.go
 
 tar -cvzf release/nemo_darwin_amd64.tar \
   --exclude=thirdparty/xray/xray_linux_amd64 \
@@ -32,7 +35,7 @@ tar -cvzf release/nemo_darwin_amd64.tar \
   --exclude=thirdparty/massdns/massdns_windows_amd64.exe \
   --exclude=thirdparty/massdns/cygwin1.dll \
   --exclude=thirdparty/massdns/massdns_linux_amd64 \
-  server_darwin_amd64 worker_darwin_amd64 daemon_worker_darwin_amd64 version.txt \
+  server_darwin_amd64 worker_darwin_amd64 daemon_worker_darwin_amd64 standalone_darwin_amd64 version.txt \
   conf log thirdparty web
 
 tar -cvzf release/nemo_linux_amd64.tar \
@@ -51,7 +54,7 @@ tar -cvzf release/nemo_linux_amd64.tar \
   --exclude=thirdparty/massdns/massdns_windows_amd64.exe \
   --exclude=thirdparty/massdns/cygwin1.dll \
   --exclude=thirdparty/massdns/massdns_darwin_amd64 \
-  server_linux_amd64 worker_linux_amd64 daemon_worker_linux_amd64 version.txt \
+  server_linux_amd64 worker_linux_amd64 daemon_worker_linux_amd64 standalone_linux_amd64 version.txt \
   conf log thirdparty web docker* Dockerfile*
 
 tar -cvzf release/nemo_windows_amd64.tar \
@@ -69,7 +72,7 @@ tar -cvzf release/nemo_windows_amd64.tar \
   --exclude=thirdparty/goby/goby-cmd-linux \
   --exclude=thirdparty/massdns/massdns_darwin_amd64 \
   --exclude=thirdparty/massdns/massdns_linux_amd64 \
-  server_windows_amd64.exe worker_windows_amd64.exe daemon_worker_windows_amd64.exe version.txt \
+  server_windows_amd64.exe worker_windows_amd64.exe daemon_worker_windows_amd64.exe standalone_windows_amd64.exe version.txt \
   conf log thirdparty web
 
 tar -cvzf release/worker_linux_amd64.tar \
@@ -128,8 +131,8 @@ tar -cvzf release/worker_windows_amd64.tar \
   --exclude=thirdparty/massdns/massdns_linux_amd64 \
   worker_windows_amd64.exe daemon_worker_windows_amd64.exe conf log thirdparty version.txt
 
-rm -f server_darwin_amd64 worker_darwin_amd64 daemon_worker_darwin_amd64 \
-  server_linux_amd64 worker_linux_amd64 daemon_worker_linux_amd64 \
-  server_windows_amd64.exe worker_windows_amd64.exe daemon_worker_windows_amd64.exe \
+rm -f server_darwin_amd64 worker_darwin_amd64 daemon_worker_darwin_amd64 standalone_darwin_amd64 \
+  server_linux_amd64 worker_linux_amd64 daemon_worker_linux_amd64 standalone_linux_amd64 \
+  server_windows_amd64.exe worker_windows_amd64.exe daemon_worker_windows_amd64.exe standalone_windows_amd64.exe \
 
 echo "package done..."
@@ -0,0 +1,192 @@
+package cert
+
+import (
+	"crypto/rsa"
+	"crypto/tls"
+	"crypto/x509"
+	"errors"
+	"fmt"
+	"github.com/hanc00l/nemo_go/pkg/conf"
+	"github.com/hanc00l/nemo_go/pkg/utils"
+	"k8s.io/client-go/util/cert"
+	"k8s.io/client-go/util/keyutil"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+const (
+	// CACertFile server签发证书使用的CA证书
+	CACertFile = "ca.crt"
+	// CAKeyFile CA的私钥，只保存在server
+	CAKeyFile = "ca.key"
+	// caCommonName CA证书的名称
+	caCommonName = "nemo-ca"
+)
+
+// CA 签发server证书和worker客户端证书的CA
+type CA struct {
+	Cert    *x509.Certificate
+	Key     *rsa.PrivateKey
+	CertPEM []byte
+}
+
+// LoadOrGenerateCA 加载CA证书及私钥，不存在时生成新的CA并保存
+func LoadOrGenerateCA(certFileName, keyFileName string) (*CA, error) {
+	rootPath := conf.GetRootPath()
+	certFile := filepath.Join(rootPath, certFileName)
+	keyFile := filepath.Join(rootPath, keyFileName)
+	if utils.CheckFileExist(certFile) && utils.CheckFileExist(keyFile) {
+		certPEM, err := os.ReadFile(certFile)
+		if err != nil {
+			return nil, err
+		}
+		keyPEM, err := os.ReadFile(keyFile)
+		if err != nil {
+			return nil, err
+		}
+		return ParseCA(certPEM, keyPEM)
+	}
+	key, err := NewPrivateKey()
+	if err != nil {
+		return nil, fmt.Errorf("failed to create the CA private key: %v", err)
+	}
+	caCert, err := cert.NewSelfSignedCACert(cert.Config{CommonName: caCommonName}, key)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create the CA cert: %v", err)
+	}
+	ca := &CA{Cert: caCert, Key: key, CertPEM: EncodeCertPEM(caCert)}
+	if err = os.WriteFile(certFile, ca.CertPEM, 0644); err != nil {
+		return nil, err
+	}
+	if err = os.WriteFile(keyFile, EncodePrivateKeyPEM(key), 0600); err != nil {
+		return nil, err
+	}
+	return ca, nil
+}
+
+// ParseCA 解析PEM格式的CA证书及私钥
+func ParseCA(certPEM, keyPEM []byte) (*CA, error) {
+	certs, err := cert.ParseCertsPEM(certPEM)
+	if err != nil {
+		return nil, err
+	}
+	if len(certs) != 1 || !certs[0].IsCA {
+		return nil, errors.New("invalid CA cert")
+	}
+	key, err := keyutil.ParsePrivateKeyPEM(keyPEM)
+	if err != nil {
+		return nil, err
+	}
+	privateKey, ok := key.(*rsa.PrivateKey)
+	if !ok {
+		return nil, errors.New("invalid CA private key")
+	}
+	return &CA{Cert: certs[0], Key: privateKey, CertPEM: certPEM}, nil
+}
+
+// GenerateServerCert 使用CA签发server证书并保存
+func (ca *CA) GenerateServerCert(certFileName, keyFileName string) error {
+	key, err := NewPrivateKey()
+	if err != nil {
+		return err
+	}
+	signedCert, err := NewSignedCert(
+		cert.Config{
+			CommonName: "127.0.0.1",
+			Usages:     []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth},
+			AltNames:   cert.AltNames{DNSNames: []string{"localhost"}},
+		},
+		key, ca.Cert, ca.Key,
+	)
+	if err != nil {
+		return err
+	}
+	rootPath := conf.GetRootPath()
+	if err = os.WriteFile(filepath.Join(rootPath, certFileName), EncodeCertPEM(signedCert), 0644); err != nil {
+		return err
+	}
+	return os.WriteFile(filepath.Join(rootPath, keyFileName), EncodePrivateKeyPEM(key), 0600)
+}
+
+// IsSignedServerCert 检查server证书是否由CA签发
+func (ca *CA) IsSignedServerCert(certFileName string) bool {
+	certPEM, err := os.ReadFile(filepath.Join(conf.GetRootPath(), certFileName))
+	if err != nil {
+		return false
+	}
+	certs, err := cert.ParseCertsPEM(certPEM)
+	if err != nil || len(certs) == 0 {
+		return false
+	}
+	return VerifyCert(certs[0], ca.Cert, x509.ExtKeyUsageServerAuth) == nil
+}
+
+// IssueClientCert 使用CA签发worker的客户端证书，返回PEM格式的证书、私钥及证书序列号
+func (ca *CA) IssueClientCert(commonName string) (certPEM, keyPEM []byte, serial string, err error) {
+	key, err := NewPrivateKey()
+	if err != nil {
+		return
+	}
+	signedCert, err := NewSignedCert(
+		cert.Config{
+			CommonName: commonName,
+			Usages:     []x509.ExtKeyUsage{x509.ExtKeyUsageClientAuth},
+		},
+		key, ca.Cert, ca.Key,
+	)
+	if err != nil {
+		return
+	}
+	return EncodeCertPEM(signedCert), EncodePrivateKeyPEM(key), signedCert.SerialNumber.String(), nil
+}
+
+// CertPool 只包含CA证书的证书池
+func (ca *CA) CertPool() *x509.CertPool {
+	pool := x509.NewCertPool()
+	pool.AddCert(ca.Cert)
+	return pool
+}
+
+// VerifyCert 检查证书是否由CA签发、在有效期内并且可用于指定的用途；不检查证书的主机名
+func VerifyCert(c, caCert *x509.Certificate, usage x509.ExtKeyUsage) error {
+	pool := x509.NewCertPool()
+	pool.AddCert(caCert)
+	_, err := c.Verify(x509.VerifyOptions{
+		Roots:       pool,
+		CurrentTime: time.Now(),
+		KeyUsages:   []x509.ExtKeyUsage{usage},
+	})
+	return err
+}
+
+// NewClientTLSConfig 使用客户端证书连接server的TLS配置：验证server证书由CA签发；
+// server证书不包含worker连接时使用的地址，因此不检查主机名
+func NewClientTLSConfig(certPEM, keyPEM, caCertPEM []byte) (*tls.Config, error) {
+	clientCert, err := tls.X509KeyPair(certPEM, keyPEM)
+	if err != nil {
+		return nil, err
+	}
+	caCerts, err := cert.ParseCertsPEM(caCertPEM)
+	if err != nil {
+		return nil, err
+	}
+	if len(caCerts) != 1 {
+		return nil, errors.New("invalid CA cert")
+	}
+	caCert := caCerts[0]
+	return &tls.Config{
+		Certificates:       []tls.Certificate{clientCert},
+		InsecureSkipVerify: true,
+		VerifyPeerCertificate: func(rawCerts [][]byte, _ [][]*x509.Certificate) error {
+			if len(rawCerts) == 0 {
+				return errors.New("no server certificate")
+			}
+			serverCert, err := x509.ParseCertificate(rawCerts[0])
+			if err != nil {
+				return err
+			}
+			return VerifyCert(serverCert, caCert, x509.ExtKeyUsageServerAuth)
+		},
+	}, nil
+}
@@ -0,0 +1,40 @@
+package cert
+
+import (
+	"crypto/x509"
+	"k8s.io/client-go/util/cert"
+	"testing"
+)
+
+func TestCA_IssueClientCert(t *testing.T) {
+	key, err := NewPrivateKey()
+	if err != nil {
+		t.Fatal(err)
+	}
+	caCert, err := cert.NewSelfSignedCACert(cert.Config{CommonName: caCommonName}, key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	ca, err := ParseCA(EncodeCertPEM(caCert), EncodePrivateKeyPEM(key))
+	if err != nil {
+		t.Fatal(err)
+	}
+	certPEM, _, serial, err := ca.IssueClientCert("worker1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	certs, err := cert.ParseCertsPEM(certPEM)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if certs[0].Subject.CommonName != "worker1" || certs[0].SerialNumber.String() != serial {
+		t.Errorf("invalid client cert:%s,%s", certs[0].Subject.CommonName, serial)
+	}
+	if err = VerifyCert(certs[0], ca.Cert, x509.ExtKeyUsageClientAuth); err != nil {
+		t.Error(err)
+	}
+	// worker的客户端证书不能用作server证书
+	if err = VerifyCert(certs[0], ca.Cert, x509.ExtKeyUsageServerAuth); err == nil {
+		t.Error("client cert should not be used as server cert")
+	}
+}
@@ -0,0 +1,102 @@
+package cert
+
+import (
+	"crypto/ed25519"
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"github.com/hanc00l/nemo_go/pkg/conf"
+	"github.com/hanc00l/nemo_go/pkg/utils"
+	"os"
+	"path/filepath"
+)
+
+const (
+	// ReleaseKeyFile server签名发布版本使用的私钥，只保存在server
+	ReleaseKeyFile = "release.key"
+	// ReleasePublicKeyFile worker验证发布版本签名使用的公钥
+	ReleasePublicKeyFile = "release.pub"
+)
+
+// LoadOrGenerateSigningKey 加载签名的私钥，不存在时生成新的私钥并保存私钥及公钥
+func LoadOrGenerateSigningKey(keyFileName, publicKeyFileName string) (ed25519.PrivateKey, error) {
+	rootPath := conf.GetRootPath()
+	keyFile := filepath.Join(rootPath, keyFileName)
+	if utils.CheckFileExist(keyFile) {
+		keyPEM, err := os.ReadFile(keyFile)
+		if err != nil {
+			return nil, err
+		}
+		return ParseSigningKey(keyPEM)
+	}
+	publicKey, key, err := ed25519.GenerateKey(rand.Reader)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create the signing key: %v", err)
+	}
+	keyDER, err := x509.MarshalPKCS8PrivateKey(key)
+	if err != nil {
+		return nil, err
+	}
+	publicKeyPEM, err := EncodeSigningPublicKeyPEM(publicKey)
+	if err != nil {
+		return nil, err
+	}
+	if err = os.WriteFile(keyFile, pem.EncodeToMemory(&pem.Block{Type: "PRIVATE KEY", Bytes: keyDER}), 0600); err != nil {
+		return nil, err
+	}
+	if err = os.WriteFile(filepath.Join(rootPath, publicKeyFileName), publicKeyPEM, 0644); err != nil {
+		return nil, err
+	}
+	return key, nil
+}
+
+// ParseSigningKey 解析PEM格式的签名私钥
+func ParseSigningKey(keyPEM []byte) (ed25519.PrivateKey, error) {
+	block, _ := pem.Decode(keyPEM)
+	if block == nil {
+		return nil, errors.New("invalid signing key")
+	}
+	key, err := x509.ParsePKCS8PrivateKey(block.Bytes)
+	if err != nil {
+		return nil, err
+	}
+	privateKey, ok := key.(ed25519.PrivateKey)
+	if !ok {
+		return nil, errors.New("invalid signing key")
+	}
+	return privateKey, nil
+}
+
+// ParseSigningPublicKey 解析PEM格式的签名公钥
+func ParseSigningPublicKey(publicKeyPEM []byte) (ed25519.PublicKey, error) {
+	block, _ := pem.Decode(publicKeyPEM)
+	if block == nil {
+		return nil, errors.New("invalid signing public key")
+	}
+	key, err := x509.ParsePKIXPublicKey(block.Bytes)
+	if err != nil {
+		return nil, err
+	}
+	publicKey, ok := key.(ed25519.PublicKey)
+	if !ok {
+		return nil, errors.New("invalid signing public key")
+	}
+	return publicKey, nil
+}
+
+// EncodeSigningPublicKeyPEM 将签名公钥编码为PEM格式
+func EncodeSigningPublicKeyPEM(publicKey ed25519.PublicKey) ([]byte, error) {
+	der, err := x509.MarshalPKIXPublicKey(publicKey)
+	if err != nil {
+		return nil, err
+	}
+	return pem.EncodeToMemory(&pem.Block{Type: "PUBLIC KEY", Bytes: der}), nil
+}
+
+// SigningKeyFingerprint 签名公钥的指纹，用于在界面中核对worker使用的公钥
+func SigningKeyFingerprint(publicKey ed25519.PublicKey) string {
+	return fmt.Sprintf("%x", sha256.Sum256(publicKey))[:16]
+}
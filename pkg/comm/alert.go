@@ -0,0 +1,197 @@
+package comm
+
+import (
+	"fmt"
+	"github.com/hanc00l/nemo_go/pkg/conf"
+	"github.com/hanc00l/nemo_go/pkg/db"
+	"github.com/hanc00l/nemo_go/pkg/logging"
+	"github.com/hanc00l/nemo_go/pkg/notify"
+	"github.com/hanc00l/nemo_go/pkg/task/domainscan"
+	"github.com/hanc00l/nemo_go/pkg/task/pocscan"
+	"github.com/hanc00l/nemo_go/pkg/task/portscan"
+	"github.com/hanc00l/nemo_go/pkg/utils"
+	"path"
+	"regexp"
+	"strings"
+)
+
+// severityRegexp 从漏洞结果的附加信息中提取漏洞等级（如nuclei的"severity": "high"）
+var severityRegexp = regexp.MustCompile(`(?i)"severity"\s*:\s*"?(\w+)"?`)
+
+// alertItem 一条匹配告警规则的结果
+type alertItem struct {
+	Target  string
+	Content string
+}
+
+// checkScanResultAlert 对保存的IP与域名结果中新发现的资产（保存结果时设置IsNew）匹配工作空间的告警规则
+func checkScanResultAlert(workspaceId int, ipResult map[string]*portscan.IPResult, domainResult map[string]*domainscan.DomainResult) {
+	rule := db.AlertRule{WorkspaceId: workspaceId}
+	for _, r := range rule.GetsEnabledByWorkspace() {
+		var items []alertItem
+		switch r.RuleType {
+		case db.AlertRulePort:
+			items = matchPortRule(r, ipResult)
+		case db.AlertRuleDomain:
+			items = matchDomainRule(r, domainResult)
+		case db.AlertRuleTitle:
+			items = matchTitleRule(r, ipResult, domainResult)
+		}
+		sendAlert(r, items)
+	}
+}
+
+// checkVulnerabilityAlert 对保存的漏洞结果中新发现的漏洞匹配工作空间的告警规则
+func checkVulnerabilityAlert(vulResult []pocscan.Result) {
+	workspaceVul := make(map[int][]pocscan.Result)
+	for _, v := range vulResult {
+		workspaceVul[v.WorkspaceId] = append(workspaceVul[v.WorkspaceId], v)
+	}
+	for workspaceId, vuls := range workspaceVul {
+		rule := db.AlertRule{WorkspaceId: workspaceId}
+		for _, r := range rule.GetsEnabledByWorkspace() {
+			if r.RuleType == db.AlertRuleVulnerability {
+				sendAlert(r, matchVulnerabilityRule(r, vuls))
+			}
+		}
+	}
+}
+
+// matchPortRule 新开放了指定端口，规则内容为端口列表，如3389,22,6379
+func matchPortRule(r db.AlertRule, ipResult map[string]*portscan.IPResult) (items []alertItem) {
+	ports := utils.ParsePort(r.RuleContent)
+	for ipName, ip := range ipResult {
+		for port, portResult := range ip.Ports {
+			if !portResult.IsNew {
+				continue
+			}
+			if _, ok := ports[port]; ok {
+				items = append(items, alertItem{Target: utils.FormatHostPort(ipName, port)})
+			}
+		}
+	}
+	return
+}
+
+// matchDomainRule 新发现的域名匹配通配符，规则内容为逗号分隔的通配符，如*.admin.*
+func matchDomainRule(r db.AlertRule, domainResult map[string]*domainscan.DomainResult) (items []alertItem) {
+	patterns := splitRuleContent(r.RuleContent)
+	for domain, result := range domainResult {
+		if !result.IsNew {
+			continue
+		}
+		for _, pattern := range patterns {
+			if matched, _ := path.Match(pattern, domain); matched {
+				items = append(items, alertItem{Target: domain})
+				break
+			}
+		}
+	}
+	return
+}
+
+// matchTitleRule 新的网页标题包含关键词（不区分大小写），规则内容为逗号分隔的关键词
+func matchTitleRule(r db.AlertRule, ipResult map[string]*portscan.IPResult, domainResult map[string]*domainscan.DomainResult) (items []alertItem) {
+	keywords := splitRuleContent(strings.ToLower(r.RuleContent))
+	containsKeyword := func(title string) bool {
+		title = strings.ToLower(title)
+		for _, k := range keywords {
+			if strings.Contains(title, k) {
+				return true
+			}
+		}
+		return false
+	}
+	for ipName, ip := range ipResult {
+		for port, portResult := range ip.Ports {
+			for _, attr := range portResult.PortAttrs {
+				if attr.IsNew && attr.Tag == "title" && containsKeyword(attr.Content) {
+					items = append(items, alertItem{Target: utils.FormatHostPort(ipName, port), Content: attr.Content})
+				}
+			}
+		}
+	}
+	for domain, domainResult := range domainResult {
+		for _, attr := range domainResult.DomainAttrs {
+			if attr.IsNew && attr.Tag == "title" && containsKeyword(attr.Content) {
+				items = append(items, alertItem{Target: domain, Content: attr.Content})
+			}
+		}
+	}
+	return
+}
+
+// matchVulnerabilityRule 新发现的漏洞等级匹配，规则内容为逗号分隔的等级，如high,critical；为空时匹配全部漏洞
+func matchVulnerabilityRule(r db.AlertRule, vulResult []pocscan.Result) (items []alertItem) {
+	severities := make(map[string]struct{})
+	for _, v := range splitRuleContent(strings.ToLower(r.RuleContent)) {
+		severities[v] = struct{}{}
+	}
+	for _, v := range vulResult {
+		if !v.IsNew {
+			continue
+		}
+		if len(severities) > 0 {
+			m := severityRegexp.FindStringSubmatch(v.Extra)
+			if len(m) < 2 {
+				continue
+			}
+			if _, ok := severities[strings.ToLower(m[1])]; !ok {
+				continue
+			}
+		}
+		items = append(items, alertItem{Target: utils.HostStrip(v.Target), Content: v.PocFile})
+	}
+	return
+}
+
+// sendAlert 对匹配规则的结果去重后发送告警通知；同一规则对同一结果只告警一次
+func sendAlert(r db.AlertRule, items []alertItem) {
+	var messages []string
+	for _, item := range items {
+		record := db.AlertRecord{
+			RuleId:  r.Id,
+			Target:  item.Target,
+			Content: item.Content,
+			Hash:    utils.MD5(fmt.Sprintf("%d%s%s", r.Id, item.Target, item.Content)),
+		}
+		if record.GetByHash() {
+			continue
+		}
+		if !record.Add() {
+			logging.RuntimeLog.Errorf("save alert record fail:%s %s", r.RuleName, item.Target)
+			continue
+		}
+		if item.Content != "" {
+			messages = append(messages, fmt.Sprintf("%s %s", item.Target, item.Content))
+		} else {
+			messages = append(messages, item.Target)
+		}
+	}
+	if len(messages) == 0 {
+		return
+	}
+	logging.RuntimeLog.Infof("alert rule %s matched:%d", r.RuleName, len(messages))
+	event := notify.NewEvent(notify.EventTypeAlert, fmt.Sprintf("告警规则：%s\n%s", r.RuleName, strings.Join(messages, "\n")))
+	event.WorkspaceId = r.WorkspaceId
+	workspace := db.Workspace{Id: r.WorkspaceId}
+	if workspace.Get() {
+		event.Workspace = workspace.WorkspaceName
+	}
+	event.RuleName = r.RuleName
+	event.RuleType = r.RuleType
+	event.Items = messages
+	if webURL := strings.TrimSuffix(conf.GlobalServerConfig().Web.URL, "/"); webURL != "" {
+		event.URL = webURL + "/alert-rule-list"
+	}
+	go notify.SendTo(strings.Split(r.Notify, ","), event)
+}
+
+func splitRuleContent(content string) (result []string) {
+	for _, v := range strings.Split(content, ",") {
+		if v = strings.TrimSpace(v); v != "" {
+			result = append(result, v)
+		}
+	}
+	return
+}
@@ -0,0 +1,103 @@
+package comm
+
+import (
+	"github.com/hanc00l/nemo_go/pkg/conf"
+	"github.com/hanc00l/nemo_go/pkg/db"
+	"github.com/hanc00l/nemo_go/pkg/task/domainscan"
+	"github.com/hanc00l/nemo_go/pkg/task/pocscan"
+	"github.com/hanc00l/nemo_go/pkg/task/portscan"
+	"os"
+	"testing"
+)
+
+func TestMatchAlertRule(t *testing.T) {
+	ipResult := map[string]*portscan.IPResult{
+		"192.168.1.1": {Ports: map[int]*portscan.PortResult{
+			22:   {IsNew: true},
+			3389: {},
+			80:   {PortAttrs: []portscan.PortAttrResult{{Tag: "title", Content: "Admin Login", IsNew: true}, {Tag: "server", Content: "admin"}}},
+		}},
+		"2001:db8::1": {Ports: map[int]*portscan.PortResult{
+			6379: {IsNew: true},
+			443:  {PortAttrs: []portscan.PortAttrResult{{Tag: "title", Content: "admin console"}}},
+		}},
+	}
+	domainResult := map[string]*domainscan.DomainResult{
+		"test.admin.example.com": {IsNew: true, DomainAttrs: []domainscan.DomainAttrResult{{Tag: "title", Content: "Welcome", IsNew: true}}},
+		"www.admin.example.com":  {DomainAttrs: []domainscan.DomainAttrResult{{Tag: "title", Content: "ADMIN", IsNew: true}}},
+	}
+	vulResult := []pocscan.Result{
+		{Target: "192.168.1.1:80", PocFile: "a.yaml", Extra: `"severity": "high"`, IsNew: true},
+		{Target: "192.168.1.1:80", PocFile: "b.yaml", Extra: `"severity": "low"`, IsNew: true},
+		{Target: "192.168.1.2:80", PocFile: "c.yaml", Extra: `"severity": "critical"`},
+	}
+	tests := []struct {
+		rule    db.AlertRule
+		targets map[string]struct{}
+	}{
+		{db.AlertRule{RuleType: db.AlertRulePort, RuleContent: "22,3389,6379"}, map[string]struct{}{"192.168.1.1:22": {}, "[2001:db8::1]:6379": {}}},
+		{db.AlertRule{RuleType: db.AlertRuleDomain, RuleContent: "*.admin.*"}, map[string]struct{}{"test.admin.example.com": {}}},
+		{db.AlertRule{RuleType: db.AlertRuleTitle, RuleContent: "admin"}, map[string]struct{}{"192.168.1.1:80": {}, "www.admin.example.com": {}}},
+		{db.AlertRule{RuleType: db.AlertRuleVulnerability, RuleContent: "high,critical"}, map[string]struct{}{"192.168.1.1": {}}},
+		{db.AlertRule{RuleType: db.AlertRuleVulnerability}, map[string]struct{}{"192.168.1.1": {}}},
+	}
+	for _, tt := range tests {
+		var items []alertItem
+		switch tt.rule.RuleType {
+		case db.AlertRulePort:
+			items = matchPortRule(tt.rule, ipResult)
+		case db.AlertRuleDomain:
+			items = matchDomainRule(tt.rule, domainResult)
+		case db.AlertRuleTitle:
+			items = matchTitleRule(tt.rule, ipResult, domainResult)
+		case db.AlertRuleVulnerability:
+			items = matchVulnerabilityRule(tt.rule, vulResult)
+		}
+		targets := make(map[string]struct{})
+		for _, item := range items {
+			targets[item.Target] = struct{}{}
+		}
+		if len(targets) != len(tt.targets) {
+			t.Errorf("%s rule %s matched:%v", tt.rule.RuleType, tt.rule.RuleContent, items)
+			continue
+		}
+		for target := range tt.targets {
+			if _, ok := targets[target]; !ok {
+				t.Errorf("%s rule %s not matched:%s", tt.rule.RuleType, tt.rule.RuleContent, target)
+			}
+		}
+	}
+}
+
+func TestMatchAlertRuleNewAsset(t *testing.T) {
+	if err := os.Chdir("../.."); err != nil {
+		t.Fatal(err)
+	}
+	defer os.Chdir("pkg/comm")
+	conf.EnableStandalone(db.SQLiteMemory)
+	if err := db.Migrate(); err != nil {
+		t.Fatal(err)
+	}
+	workspace := db.Workspace{WorkspaceName: "test", State: "enable"}
+	if !workspace.Add() {
+		t.Fatal("add workspace fail")
+	}
+	rule := db.AlertRule{RuleType: db.AlertRulePort, RuleContent: "22"}
+	newResult := func() *portscan.Result {
+		r := &portscan.Result{IPResult: make(map[string]*portscan.IPResult)}
+		r.SetIP("192.168.1.1")
+		r.SetPort("192.168.1.1", 22)
+		return r
+	}
+	// 第一次保存的端口为新发现的资产，已保存的端口再次扫描时不再匹配
+	r := newResult()
+	r.SaveResult(portscan.Config{WorkspaceId: workspace.Id})
+	if items := matchPortRule(rule, r.IPResult); len(items) != 1 {
+		t.Errorf("new port not matched:%v", items)
+	}
+	r = newResult()
+	r.SaveResult(portscan.Config{WorkspaceId: workspace.Id})
+	if items := matchPortRule(rule, r.IPResult); len(items) != 0 {
+		t.Errorf("saved port matched:%v", items)
+	}
+}
@@ -3,7 +3,6 @@ package comm
 import (
 	"fmt"
 	"github.com/hanc00l/nemo_go/pkg/conf"
-	"github.com/hanc00l/nemo_go/pkg/filesync"
 	"github.com/hanc00l/nemo_go/pkg/logging"
 	"github.com/hanc00l/nemo_go/pkg/utils"
 	"os"
@@ -15,12 +14,20 @@ import (
 var cmd *exec.Cmd
 var WorkerName string
 
+var (
+	// WorkerIdentityFileName 启动的worker使用的身份文件
+	WorkerIdentityFileName = WorkerIdentityFile
+	// WorkerTags 启动的worker的标签，多个标签以“,”分隔
+	WorkerTags string
+	// WorkerTagOnly 启动的worker是否只执行指定了标签的任务
+	WorkerTagOnly bool
+)
+
 // StartWorkerDaemon 启动worker的daemon
 func StartWorkerDaemon(workerRunTaskMode, taskWorkspaceGUID string, concurrency, workerPerformance int, noFilesync bool) {
-	fileSyncServer := conf.GlobalWorkerConfig().FileSync
 	if !noFilesync {
 		logging.CLILog.Info("start file sync...")
-		filesync.WorkerStartupSync(fileSyncServer.Host, fmt.Sprintf("%d", fileSyncServer.Port), fileSyncServer.AuthKey)
+		WorkerFileSync(workerRunTaskMode)
 	}
 	if success := StartWorker(workerRunTaskMode, taskWorkspaceGUID, concurrency, workerPerformance); success == false {
 		return
@@ -39,7 +46,7 @@ func StartWorkerDaemon(workerRunTaskMode, taskWorkspaceGUID string, concurrency,
 				if !noFilesync {
 					logging.CLILog.Info("manual reload to start file sync...")
 					logging.RuntimeLog.Info("manual reload to start file sync...")
-					filesync.WorkerStartupSync(fileSyncServer.Host, fmt.Sprintf("%d", fileSyncServer.Port), fileSyncServer.AuthKey)
+					WorkerFileSync(workerRunTaskMode)
 				}
 				StartWorker(workerRunTaskMode, taskWorkspaceGUID, concurrency, workerPerformance)
 			}
@@ -49,7 +56,7 @@ func StartWorkerDaemon(workerRunTaskMode, taskWorkspaceGUID string, concurrency,
 		if !noFilesync && replay.ManualFileSyncFlag {
 			logging.CLILog.Info("manual start file sync...")
 			logging.RuntimeLog.Info("manual start file sync...")
-			filesync.WorkerStartupSync(fileSyncServer.Host, fmt.Sprintf("%d", fileSyncServer.Port), fileSyncServer.AuthKey)
+			WorkerFileSync(workerRunTaskMode)
 		}
 	}
 }
@@ -95,9 +102,21 @@ func StartWorker(workerRunTaskMode, taskWorkspaceGUID string, concurrency, worke
 		"-m", workerRunTaskMode,
 		"-w", taskWorkspaceGUID,
 	}
+	if WorkerTags != "" {
+		cmdArgs = append(cmdArgs, "-tags", WorkerTags)
+	}
+	if WorkerTagOnly {
+		cmdArgs = append(cmdArgs, "-tagonly")
+	}
 	if TLSEnabled {
 		cmdArgs = append(cmdArgs, "-tls")
 	}
+	if WorkerIdentityFileName != WorkerIdentityFile {
+		cmdArgs = append(cmdArgs, "-identity", WorkerIdentityFileName)
+	}
+	if serverURL := GetHTTPTransportURL(); serverURL != "" {
+		cmdArgs = append(cmdArgs, "-http", serverURL)
+	}
 	cmd = exec.Command(workerPathName, cmdArgs...)
 	cmd.Stdout = os.Stdout
 	cmd.Stderr = os.Stderr
@@ -0,0 +1,330 @@
+package comm
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/subtle"
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"github.com/hanc00l/nemo_go/pkg/cert"
+	"github.com/hanc00l/nemo_go/pkg/conf"
+	"github.com/hanc00l/nemo_go/pkg/db"
+	"github.com/hanc00l/nemo_go/pkg/filesync"
+	"github.com/hanc00l/nemo_go/pkg/logging"
+	"github.com/hanc00l/nemo_go/pkg/utils"
+	"github.com/smallnest/rpcx/protocol"
+	"github.com/smallnest/rpcx/server"
+	"github.com/smallnest/rpcx/share"
+	"github.com/soheilhy/cmux"
+	"gopkg.in/yaml.v2"
+	"net"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	// WorkerIdentityFile worker身份文件的默认位置；不在文件同步的目录中，不会被server的文件覆盖
+	WorkerIdentityFile = "worker_identity.yml"
+	// identityCacheExpire worker身份缓存的有效时间，web与RPC不在同一进程时吊销在缓存过期后生效
+	identityCacheExpire = time.Minute
+)
+
+// WorkerIdentity 注册worker时生成的身份文件
+type WorkerIdentity struct {
+	WorkerName string `yaml:"workerName"`
+	AuthKey    string `yaml:"authKey"`
+	CACert     string `yaml:"caCert,omitempty"`
+	Cert       string `yaml:"cert,omitempty"`
+	Key        string `yaml:"key,omitempty"`
+	// ReleasePublicKey server签名发布版本的公钥，worker同步文件时验证签名
+	ReleasePublicKey string `yaml:"releasePublicKey,omitempty"`
+}
+
+// identityContextKey RPC认证通过后，在请求的context中保存worker身份名称
+type identityContextKey struct{}
+
+var (
+	// workerIdentity worker加载的身份，为空时使用worker.yml中共用的authKey
+	workerIdentity *WorkerIdentity
+	// workerTLSConfig worker使用客户端证书并验证server证书的TLS配置
+	workerTLSConfig *tls.Config
+	// serverAuthKey server进程内部的RPC调用（如保存运行日志）使用的认证key，每次启动时随机生成
+	serverAuthKey string
+	// serverCA 签发worker客户端证书的CA，启用TLS时由server加载
+	serverCA *cert.CA
+
+	identityCache         map[string]db.WorkerIdentity
+	identityCacheLoadTime time.Time
+	identityCacheMutex    sync.Mutex
+
+	workerNamePattern = regexp.MustCompile(`^[\w.@\-]{1,100}$`)
+)
+
+// LoadWorkerIdentity worker加载身份文件，文件不存在时使用worker.yml中共用的authKey
+func LoadWorkerIdentity(fileName string) error {
+	identityFile := filepath.Join(conf.GetRootPath(), fileName)
+	if !utils.CheckFileExist(identityFile) {
+		logging.CLILog.Infof("identity file:%s not exist,use shared auth key", fileName)
+		return nil
+	}
+	content, err := os.ReadFile(identityFile)
+	if err != nil {
+		return err
+	}
+	identity := &WorkerIdentity{}
+	if err = yaml.Unmarshal(content, identity); err != nil {
+		return err
+	}
+	if identity.WorkerName == "" || identity.AuthKey == "" {
+		return fmt.Errorf("invalid identity file:%s", fileName)
+	}
+	if identity.Cert != "" {
+		workerTLSConfig, err = cert.NewClientTLSConfig([]byte(identity.Cert), []byte(identity.Key), []byte(identity.CACert))
+		if err != nil {
+			return err
+		}
+		filesync.ClientTLSConfig = workerTLSConfig
+	}
+	workerIdentity = identity
+	logging.CLILog.Infof("load worker identity:%s", identity.WorkerName)
+	return nil
+}
+
+// rpcAuthKey RPC调用使用的认证key
+func rpcAuthKey() string {
+	if serverAuthKey != "" {
+		return serverAuthKey
+	}
+	if workerIdentity != nil {
+		return workerIdentity.WorkerName + ":" + workerIdentity.AuthKey
+	}
+	return conf.GlobalWorkerConfig().Rpc.AuthKey
+}
+
+// FileSyncAuthKey 文件同步使用的认证key
+func FileSyncAuthKey() string {
+	if workerIdentity != nil {
+		return workerIdentity.WorkerName + ":" + workerIdentity.AuthKey
+	}
+	return conf.GlobalWorkerConfig().FileSync.AuthKey
+}
+
+// rpcClientTLSConfig RPC客户端的TLS配置：已注册的worker验证server证书，否则不验证
+func rpcClientTLSConfig() *tls.Config {
+	if workerTLSConfig != nil && serverAuthKey == "" {
+		return workerTLSConfig
+	}
+	return &tls.Config{InsecureSkipVerify: true}
+}
+
+// EnableServerAuthKey 生成server进程内部RPC调用使用的认证key，在启动RPC server前调用
+func EnableServerAuthKey() {
+	b := make([]byte, 32)
+	if _, err := rand.Read(b); err != nil {
+		logging.RuntimeLog.Error(err)
+		return
+	}
+	serverAuthKey = hex.EncodeToString(b)
+}
+
+// SetServerCA 设置签发worker客户端证书的CA，RPC与文件同步服务同时验证worker的客户端证书
+func SetServerCA(ca *cert.CA) {
+	serverCA = ca
+}
+
+// serverTLSConfig RPC与文件同步服务的TLS配置：有CA时验证worker提供的客户端证书
+func serverTLSConfig(certificate tls.Certificate) *tls.Config {
+	config := &tls.Config{Certificates: []tls.Certificate{certificate}}
+	if serverCA != nil {
+		config.ClientCAs = serverCA.CertPool()
+		config.ClientAuth = tls.VerifyClientCertIfGiven
+	}
+	return config
+}
+
+// auth RPC调用认证：server内部调用、已注册的worker或共用的authKey；提供了客户端证书的连接必须是有效的worker身份
+func auth(ctx context.Context, req *protocol.Message, token string) error {
+	if serverAuthKey != "" && subtle.ConstantTimeCompare([]byte(token), []byte(serverAuthKey)) == 1 {
+		return nil
+	}
+	conn, _ := ctx.Value(server.RemoteConnContextKey).(net.Conn)
+	if workerName, ok := authWorker(token, getPeerCertificates(conn), conf.GlobalServerConfig().Rpc.AuthKey); ok {
+		if c, ok := ctx.(*share.Context); ok && workerName != "" {
+			c.SetValue(identityContextKey{}, workerName)
+		}
+		return nil
+	}
+	logging.RuntimeLog.Warningf("rpc auth fail from %s", getRemoteAddr(conn))
+	return errors.New("invalid token")
+}
+
+// checkFileSyncAuth 文件同步的认证，与RPC调用的认证相同
+func checkFileSyncAuth(conn net.Conn, authKey string) bool {
+	_, ok := authWorker(authKey, getPeerCertificates(conn), conf.GlobalServerConfig().FileSync.AuthKey)
+	return ok
+}
+
+// authWorker 检查已注册的worker身份或共用的authKey，返回worker身份名称（使用共用的authKey时为空）；
+// 共用的authKey只在server配置了allowSharedAuthKey时允许；提供了客户端证书的连接必须是有效的worker身份
+func authWorker(token string, peerCerts []*x509.Certificate, sharedAuthKey string) (workerName string, ok bool) {
+	if workerName, ok = checkWorkerIdentity(token, peerCerts); ok {
+		return
+	}
+	if len(peerCerts) > 0 || !checkSharedAuthKey(token, sharedAuthKey) {
+		return "", false
+	}
+	if !conf.GlobalServerConfig().AllowSharedAuthKey {
+		logging.RuntimeLog.Warning("shared authKey is disabled,register worker identity or set allowSharedAuthKey in server.yml")
+		return "", false
+	}
+	return "", true
+}
+
+// checkSharedAuthKey 检查共用的authKey；server配置的authKey为空时只允许已注册的worker
+func checkSharedAuthKey(token, authKey string) bool {
+	return authKey != "" && subtle.ConstantTimeCompare([]byte(token), []byte(authKey)) == 1
+}
+
+// checkWorkerIdentity 检查worker身份：token为“worker名称:key”；启用了CA时，worker必须提供与身份一致的客户端证书
+func checkWorkerIdentity(token string, peerCerts []*x509.Certificate) (workerName string, ok bool) {
+	i := strings.LastIndex(token, ":")
+	if i <= 0 {
+		return
+	}
+	identity, exist := getWorkerIdentity(token[:i])
+	if !exist || identity.IsRevoked {
+		return
+	}
+	if subtle.ConstantTimeCompare([]byte(hashAuthKey(token[i+1:])), []byte(identity.AuthKeyHash)) != 1 {
+		return
+	}
+	if serverCA != nil || len(peerCerts) > 0 {
+		// 证书链已在TLS握手时验证，这里检查证书是否为该worker当前的证书
+		if len(peerCerts) == 0 || peerCerts[0].Subject.CommonName != identity.WorkerName || peerCerts[0].SerialNumber.String() != identity.CertSerial {
+			return
+		}
+	}
+	return identity.WorkerName, true
+}
+
+// getWorkerIdentity 从缓存中获取worker身份
+func getWorkerIdentity(workerName string) (identity db.WorkerIdentity, ok bool) {
+	identityCacheMutex.Lock()
+	defer identityCacheMutex.Unlock()
+
+	if identityCache == nil || time.Since(identityCacheLoadTime) > identityCacheExpire {
+		identityCache = make(map[string]db.WorkerIdentity)
+		w := db.WorkerIdentity{}
+		for _, row := range w.Gets() {
+			identityCache[row.WorkerName] = row
+		}
+		identityCacheLoadTime = time.Now()
+	}
+	identity, ok = identityCache[workerName]
+	return
+}
+
+// resetWorkerIdentityCache 注册、吊销或删除worker身份后清空缓存
+func resetWorkerIdentityCache() {
+	identityCacheMutex.Lock()
+	defer identityCacheMutex.Unlock()
+
+	identityCache = nil
+}
+
+// getPeerCertificates 获取TLS连接中客户端提供的证书；rpcx的连接由cmux封装
+func getPeerCertificates(conn net.Conn) []*x509.Certificate {
+	if muxConn, ok := conn.(*cmux.MuxConn); ok {
+		conn = muxConn.Conn
+	}
+	if tlsConn, ok := conn.(*tls.Conn); ok {
+		return tlsConn.ConnectionState().PeerCertificates
+	}
+	return nil
+}
+
+func getRemoteAddr(conn net.Conn) string {
+	if conn == nil {
+		return ""
+	}
+	return conn.RemoteAddr().String()
+}
+
+// getIdentityFromContext 获取RPC请求的worker身份名称，未注册的worker为空
+func getIdentityFromContext(ctx context.Context) string {
+	workerName, _ := ctx.Value(identityContextKey{}).(string)
+	return workerName
+}
+
+func hashAuthKey(authKey string) string {
+	h := sha256.Sum256([]byte(authKey))
+	return hex.EncodeToString(h[:])
+}
+
+// EnrollWorker 注册worker身份，返回worker身份文件的内容；key不保存在server，只能在注册时获取
+func EnrollWorker(workerName, description string) (content []byte, err error) {
+	if !workerNamePattern.MatchString(workerName) {
+		return nil, errors.New("worker名称只能包含字母、数字及_.@-")
+	}
+	identity := db.WorkerIdentity{WorkerName: workerName}
+	if identity.GetByName() {
+		return nil, errors.New("worker名称已存在")
+	}
+	b := make([]byte, 32)
+	if _, err = rand.Read(b); err != nil {
+		return
+	}
+	wi := WorkerIdentity{WorkerName: workerName, AuthKey: hex.EncodeToString(b)}
+	if serverCA != nil {
+		var certPEM, keyPEM []byte
+		if certPEM, keyPEM, identity.CertSerial, err = serverCA.IssueClientCert(workerName); err != nil {
+			return
+		}
+		wi.CACert = string(serverCA.CertPEM)
+		wi.Cert = string(certPEM)
+		wi.Key = string(keyPEM)
+	}
+	if publicKeyPEM, _, errKey := ReleasePublicKey(); errKey == nil {
+		wi.ReleasePublicKey = string(publicKeyPEM)
+	} else {
+		logging.RuntimeLog.Errorf("load release public key fail:%v", errKey)
+	}
+	if content, err = yaml.Marshal(wi); err != nil {
+		return
+	}
+	identity.Description = description
+	identity.AuthKeyHash = hashAuthKey(wi.AuthKey)
+	if !identity.Add() {
+		return nil, errors.New("save to db fail")
+	}
+	resetWorkerIdentityCache()
+	return
+}
+
+// RevokeWorker 吊销worker身份，吊销后worker的RPC调用和文件同步立即失败
+func RevokeWorker(id int) bool {
+	identity := db.WorkerIdentity{Id: id}
+	if !identity.Revoke() {
+		return false
+	}
+	resetWorkerIdentityCache()
+	return true
+}
+
+// DeleteWorkerIdentity 删除worker身份
+func DeleteWorkerIdentity(id int) bool {
+	identity := db.WorkerIdentity{Id: id}
+	if !identity.Delete() {
+		return false
+	}
+	resetWorkerIdentityCache()
+	return true
+}
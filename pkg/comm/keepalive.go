@@ -31,6 +31,8 @@ func DoKeepAlive(ws ampq.WorkerStatus) bool {
 		logging.CLILog.Errorf("keep alive fail:%v", err)
 		return false
 	}
+	// 连接恢复后补传暂存的结果
+	TriggerSpoolReplay()
 	return true
 }
 
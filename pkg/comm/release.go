@@ -0,0 +1,268 @@
+package comm
+
+import (
+	"crypto/ed25519"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"github.com/hanc00l/nemo_go/pkg/cert"
+	"github.com/hanc00l/nemo_go/pkg/conf"
+	"github.com/hanc00l/nemo_go/pkg/db"
+	"github.com/hanc00l/nemo_go/pkg/filesync"
+	"github.com/hanc00l/nemo_go/pkg/logging"
+	"github.com/hanc00l/nemo_go/pkg/task/ampq"
+	"github.com/hanc00l/nemo_go/pkg/utils"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+)
+
+var (
+	// releaseSigningKey server签名发布版本的私钥
+	releaseSigningKey ed25519.PrivateKey
+	releaseMutex      sync.Mutex
+)
+
+// loadReleaseSigningKey server加载签名发布版本的私钥，不存在时生成新的私钥及公钥
+func loadReleaseSigningKey() (ed25519.PrivateKey, error) {
+	if releaseSigningKey == nil {
+		key, err := cert.LoadOrGenerateSigningKey(cert.ReleaseKeyFile, cert.ReleasePublicKeyFile)
+		if err != nil {
+			return nil, err
+		}
+		releaseSigningKey = key
+	}
+	return releaseSigningKey, nil
+}
+
+// ReleasePublicKey server签名发布版本的公钥（PEM格式）及指纹
+func ReleasePublicKey() (publicKeyPEM []byte, fingerprint string, err error) {
+	releaseMutex.Lock()
+	defer releaseMutex.Unlock()
+
+	key, err := loadReleaseSigningKey()
+	if err != nil {
+		return
+	}
+	publicKey := key.Public().(ed25519.PublicKey)
+	if publicKeyPEM, err = cert.EncodeSigningPublicKeyPEM(publicKey); err != nil {
+		return
+	}
+	fingerprint = cert.SigningKeyFingerprint(publicKey)
+	return
+}
+
+// LoadReleasePublicKey worker加载验证发布版本签名的公钥：优先使用运行目录下的公钥文件，其次为worker身份文件中的公钥；
+// 没有公钥时返回错误，除非指定了insecure（不验证发布版本的签名）
+func LoadReleasePublicKey(fileName string, insecure bool) error {
+	var publicKeyPEM []byte
+	publicKeyFile := filepath.Join(conf.GetRootPath(), fileName)
+	if utils.CheckFileExist(publicKeyFile) {
+		content, err := os.ReadFile(publicKeyFile)
+		if err != nil {
+			return err
+		}
+		publicKeyPEM = content
+	} else if workerIdentity != nil && workerIdentity.ReleasePublicKey != "" {
+		publicKeyPEM = []byte(workerIdentity.ReleasePublicKey)
+	} else if insecure {
+		filesync.ReleaseInsecure = true
+		logging.CLILog.Warningf("release public key:%s not exist,release signature will not be verified", fileName)
+		logging.RuntimeLog.Warningf("release public key:%s not exist,release signature will not be verified", fileName)
+		return nil
+	} else {
+		return fmt.Errorf("release public key:%s not exist,copy release.pub from server or use -release-insecure", fileName)
+	}
+	publicKey, err := cert.ParseSigningPublicKey(publicKeyPEM)
+	if err != nil {
+		return err
+	}
+	filesync.ReleasePublicKey = publicKey
+	logging.CLILog.Infof("load release public key:%s", cert.SigningKeyFingerprint(publicKey))
+	return nil
+}
+
+// PublishRelease 将server当前需要同步的文件发布为新的版本，并使用server的私钥签名
+func PublishRelease(description string) (version int, err error) {
+	releaseMutex.Lock()
+	defer releaseMutex.Unlock()
+
+	key, err := loadReleaseSigningKey()
+	if err != nil {
+		return
+	}
+	latest := db.WorkerRelease{}
+	version = 1
+	if latest.GetLatest() {
+		version = latest.Version + 1
+	}
+	manifest, err := filesync.BuildReleaseManifest(version, description)
+	if err != nil {
+		return
+	}
+	release, err := filesync.SignRelease(key, manifest)
+	if err != nil {
+		return
+	}
+	r := db.WorkerRelease{
+		Version:     version,
+		Description: description,
+		FileCount:   len(manifest.Files),
+		Manifest:    string(release.Manifest),
+		Signature:   base64.StdEncoding.EncodeToString(release.Signature),
+	}
+	if !r.Add() {
+		return 0, errors.New("save to db fail")
+	}
+	logging.RuntimeLog.Infof("publish worker release:%d,files:%d", version, len(manifest.Files))
+	return
+}
+
+// DeleteRelease 删除发布版本，同时删除不再使用的文件内容；worker组正在使用的版本不能删除
+func DeleteRelease(version int) error {
+	releaseMutex.Lock()
+	defer releaseMutex.Unlock()
+
+	pin := db.WorkerReleasePin{}
+	for _, p := range pin.Gets() {
+		if p.Version == version {
+			return fmt.Errorf("版本正在被worker组%s使用", workerGroupName(p.WorkerTag))
+		}
+	}
+	r := db.WorkerRelease{Version: version}
+	if !r.GetByVersion() || !r.Delete() {
+		return errors.New("删除版本失败")
+	}
+	var manifests []*filesync.ReleaseManifest
+	for _, row := range r.Gets() {
+		manifest, err := filesync.ParseReleaseManifest([]byte(row.Manifest))
+		if err != nil {
+			return err
+		}
+		manifests = append(manifests, manifest)
+	}
+	count, err := filesync.CleanReleaseBlobs(manifests)
+	if err != nil {
+		return err
+	}
+	logging.RuntimeLog.Infof("delete worker release:%d,clean files:%d", version, count)
+	return nil
+}
+
+// SetWorkerReleasePin 指定worker组（worker标签，为空时为全部worker）使用的发布版本，并通知该组在线的worker同步文件
+func SetWorkerReleasePin(workerTag string, version int) error {
+	if workerTag != "" && !ampq.CheckWorkerTag(workerTag) {
+		return errors.New("无效的worker标签")
+	}
+	r := db.WorkerRelease{Version: version}
+	if !r.GetByVersion() {
+		return errors.New("版本不存在")
+	}
+	pin := db.WorkerReleasePin{WorkerTag: workerTag, Version: version}
+	if !pin.Save() {
+		return errors.New("save to db fail")
+	}
+	logging.RuntimeLog.Infof("set worker group:%s release:%d", workerGroupName(workerTag), version)
+	setWorkerGroupFileSync(workerTag)
+	return nil
+}
+
+// DeleteWorkerReleasePin 取消worker组指定的发布版本，恢复使用最新版本
+func DeleteWorkerReleasePin(workerTag string) bool {
+	pin := db.WorkerReleasePin{WorkerTag: workerTag}
+	if !pin.DeleteByTag() {
+		return false
+	}
+	setWorkerGroupFileSync(workerTag)
+	return true
+}
+
+// getWorkerRelease server按worker的标签获取同步的发布版本，没有发布版本时为空
+func getWorkerRelease(workerTags []string) *filesync.SignedRelease {
+	r := db.WorkerRelease{Version: workerReleaseVersion(getWorkerReleasePins(), workerTags)}
+	if r.Version > 0 {
+		if !r.GetByVersion() {
+			logging.RuntimeLog.Errorf("worker release:%d not exist", r.Version)
+			return nil
+		}
+	} else if !r.GetLatest() {
+		return nil
+	}
+	signature, err := base64.StdEncoding.DecodeString(r.Signature)
+	if err != nil {
+		logging.RuntimeLog.Error(err)
+		return nil
+	}
+	return &filesync.SignedRelease{Manifest: []byte(r.Manifest), Signature: signature}
+}
+
+// getWorkerReleasePins 获取worker组指定的发布版本：worker标签 -> 版本
+func getWorkerReleasePins() map[string]int {
+	pins := make(map[string]int)
+	pin := db.WorkerReleasePin{}
+	for _, p := range pin.Gets() {
+		pins[p.WorkerTag] = p.Version
+	}
+	return pins
+}
+
+// workerReleaseVersion 根据worker的标签确定使用的发布版本：有多个标签时使用第一个指定了版本的标签，
+// 其次为全部worker指定的版本；为0时使用最新版本
+func workerReleaseVersion(pins map[string]int, workerTags []string) int {
+	tags := make([]string, len(workerTags))
+	copy(tags, workerTags)
+	sort.Strings(tags)
+	for _, tag := range tags {
+		if version, ok := pins[tag]; ok && tag != "" {
+			return version
+		}
+	}
+	return pins[""]
+}
+
+// workerTagsFromTopics 从worker执行任务的队列名称中获取worker的标签，如active.dmz
+func workerTagsFromTopics(topics string) (tags []string) {
+	tagMap := make(map[string]struct{})
+	for _, topic := range strings.Split(topics, ",") {
+		if strings.HasPrefix(topic, ampq.TopicCustom) {
+			continue
+		}
+		if i := strings.Index(topic, "."); i > 0 {
+			tagMap[topic[i+1:]] = struct{}{}
+		}
+	}
+	for tag := range tagMap {
+		tags = append(tags, tag)
+	}
+	sort.Strings(tags)
+	return
+}
+
+// setWorkerGroupFileSync 设置worker组中在线的worker的同步标志
+func setWorkerGroupFileSync(workerTag string) {
+	WorkerStatusMutex.Lock()
+	defer WorkerStatusMutex.Unlock()
+
+	for _, ws := range WorkerStatus {
+		if workerTag == "" {
+			ws.ManualFileSyncFlag = true
+			continue
+		}
+		for _, tag := range workerTagsFromTopics(ws.WorkerTopics) {
+			if tag == workerTag {
+				ws.ManualFileSyncFlag = true
+				break
+			}
+		}
+	}
+}
+
+// workerGroupName worker组的名称
+func workerGroupName(workerTag string) string {
+	if workerTag == "" {
+		return "全部worker"
+	}
+	return workerTag
+}
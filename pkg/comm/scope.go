@@ -0,0 +1,66 @@
+package comm
+
+import (
+	"github.com/hanc00l/nemo_go/pkg/db"
+	"github.com/hanc00l/nemo_go/pkg/logging"
+	"github.com/hanc00l/nemo_go/pkg/task/domainscan"
+	"github.com/hanc00l/nemo_go/pkg/task/portscan"
+	"github.com/hanc00l/nemo_go/pkg/task/scope"
+)
+
+// outOfScopeColorTag 授权范围外结果的颜色标记（GRAY）
+const outOfScopeColorTag = "badge-secondary"
+
+// getWorkspaceScope 获取工作空间的授权范围；未配置时返回nil，表示不限制
+func getWorkspaceScope(workspaceId int) (s *scope.Scope, err error) {
+	workspace := db.Workspace{Id: workspaceId}
+	if workspaceId <= 0 || !workspace.Get() {
+		return nil, nil
+	}
+	if s, err = scope.Parse(workspace.ScopeConfig); err != nil {
+		logging.RuntimeLog.Errorf("parse workspace:%d scope config fail:%v", workspaceId, err)
+	}
+	return
+}
+
+// tagOutOfScopeResult 对保存的授权范围外的IP与域名结果进行颜色标记（已有颜色标记的保持不变），返回授权范围外结果的数量
+func tagOutOfScopeResult(workspaceId int, ipResult map[string]*portscan.IPResult, domainResult map[string]*domainscan.DomainResult) (count int) {
+	s, _ := getWorkspaceScope(workspaceId)
+	if !s.IsTargetLimited() {
+		return
+	}
+	for ip := range ipResult {
+		if s.ContainsIP(ip) {
+			continue
+		}
+		count++
+		ipDb := db.Ip{IpName: ip, WorkspaceId: workspaceId}
+		if !ipDb.GetByIp() {
+			continue
+		}
+		colorTag := db.IpColorTag{RelatedId: ipDb.Id}
+		if !colorTag.GetByRelatedId() {
+			colorTag.Color = outOfScopeColorTag
+			colorTag.Add()
+		}
+	}
+	for domain := range domainResult {
+		if s.ContainsDomain(domain) {
+			continue
+		}
+		count++
+		domainDb := db.Domain{DomainName: domain, WorkspaceId: workspaceId}
+		if !domainDb.GetByDomain() {
+			continue
+		}
+		colorTag := db.DomainColorTag{RelatedId: domainDb.Id}
+		if !colorTag.GetByRelatedId() {
+			colorTag.Color = outOfScopeColorTag
+			colorTag.Add()
+		}
+	}
+	if count > 0 {
+		logging.RuntimeLog.Warningf("workspace:%d,%d results out of scope", workspaceId, count)
+	}
+	return
+}
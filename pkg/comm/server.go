@@ -2,15 +2,12 @@ package comm
 
 import (
 	"bytes"
-	"context"
 	"crypto/tls"
-	"errors"
 	"fmt"
 	"github.com/hanc00l/nemo_go/pkg/conf"
 	"github.com/hanc00l/nemo_go/pkg/filesync"
 	"github.com/hanc00l/nemo_go/pkg/logging"
 	"github.com/hanc00l/nemo_go/pkg/utils"
-	"github.com/smallnest/rpcx/protocol"
 	"github.com/smallnest/rpcx/server"
 	"os"
 	"path/filepath"
@@ -27,6 +24,10 @@ func StartRPCServer() {
 	rpc := conf.GlobalServerConfig().Rpc
 	logging.RuntimeLog.Infof("start rpc server running on tcp@%s:%d...", rpc.Host, rpc.Port)
 	logging.CLILog.Infof("start rpc server running on tcp@%s:%d...", rpc.Host, rpc.Port)
+	if conf.GlobalServerConfig().AllowSharedAuthKey {
+		logging.RuntimeLog.Warning("allowSharedAuthKey is enabled,worker without identity can connect with shared authKey")
+		logging.CLILog.Warning("allowSharedAuthKey is enabled,worker without identity can connect with shared authKey")
+	}
 
 	var s *server.Server
 	if TLSEnabled {
@@ -36,8 +37,7 @@ func StartRPCServer() {
 			logging.CLILog.Infof("load tls cert fail:%s", err)
 			return
 		}
-		configs := &tls.Config{Certificates: []tls.Certificate{cert}}
-		s = server.NewServer(server.WithTLSConfig(configs))
+		s = server.NewServer(server.WithTLSConfig(serverTLSConfig(cert)))
 	} else {
 		s = server.NewServer()
 	}
@@ -58,20 +58,14 @@ func StartRPCServer() {
 	}
 }
 
-// auth RPC调用认证
-func auth(ctx context.Context, req *protocol.Message, token string) error {
-	if token == conf.GlobalServerConfig().Rpc.AuthKey {
-		return nil
-	}
-
-	return errors.New("invalid token")
-}
-
 // StartFileSyncServer 启动文件同步服务
 func StartFileSyncServer() {
 	fileSyncServer := conf.GlobalServerConfig().FileSync
 	logging.RuntimeLog.Infof("start filesync server running on tcp@%s:%d...", fileSyncServer.Host, fileSyncServer.Port)
 	logging.CLILog.Infof("start filesync server running on tcp@%s:%d...", fileSyncServer.Host, fileSyncServer.Port)
+	filesync.ServerTLSConfig = serverTLSConfig
+	filesync.CheckAuthKey = checkFileSyncAuth
+	filesync.ReleaseProvider = getWorkerRelease
 
 	filesync.StartFileSyncServer(fileSyncServer.Host, fmt.Sprintf("%d", fileSyncServer.Port), fileSyncServer.AuthKey)
 }
@@ -3,7 +3,6 @@ package comm
 import (
 	"bytes"
 	"context"
-	"crypto/tls"
 	"encoding/json"
 	"errors"
 	"fmt"
@@ -62,6 +61,7 @@ type TaskStatusArgs struct {
 	TaskID    string
 	IsExist   bool
 	IsRevoked bool
+	IsPaused  bool
 	State     string
 	Worker    string
 	Result    string
@@ -73,6 +73,8 @@ type NewTaskArgs struct {
 	ConfigJSON    string
 	MainTaskID    string
 	LastRunTaskId string
+	WorkflowStep  string //工作流任务的步骤，非工作流任务为空
+	WorkerTag     string //执行任务的worker标签，为空时使用主任务的worker标签
 }
 
 type LoadIPOpenedPortArgs struct {
@@ -85,17 +87,6 @@ type LoadDomainOpenedPortArgs struct {
 	Target      map[string]struct{}
 }
 
-type MainTaskResultMap struct {
-	IPResult         map[string]map[int]interface{}
-	DomainResult     map[string]interface{}
-	VulResult        map[string]map[string]interface{}
-	ScreenShotResult int
-	IPNew            int
-	PortNew          int
-	DomainNew        int
-	VulnerabilityNew int
-}
-
 type RuntimeLogArgs struct {
 	Source     string
 	LogMessage []byte
@@ -108,9 +99,8 @@ var (
 	// 数据库操作的同步锁
 	saveIPMutex     sync.RWMutex
 	saveDomainMutex sync.RWMutex
-	// MainTaskResult 缓存汇总各个子任务、保存任务的结果
-	MainTaskResult      map[string]MainTaskResultMap
-	MainTaskResultMutex sync.Mutex
+	// RunTaskStateHandler 子任务状态变化后的处理，由runner设置，用于更新主任务的进度及完成状态
+	RunTaskStateHandler func(mainTaskId string)
 	// TLSEnabled 是否启用TLS加密
 	TLSEnabled  bool
 	TLSCertFile string
@@ -119,6 +109,9 @@ var (
 
 // CallXClient RPC远程调用
 func CallXClient(serviceMethod string, args interface{}, reply interface{}) error {
+	if workerHTTPTransport != nil {
+		return workerHTTPTransport.call(serviceMethod, args, reply)
+	}
 	globalXClientMutex.Lock()
 	defer globalXClientMutex.Unlock()
 
@@ -129,11 +122,11 @@ func CallXClient(serviceMethod string, args interface{}, reply interface{}) erro
 		}
 		option := client.DefaultOption
 		if TLSEnabled {
-			option.TLSConfig = &tls.Config{InsecureSkipVerify: true}
+			option.TLSConfig = rpcClientTLSConfig()
 		}
 		d, _ := client.NewPeer2PeerDiscovery(fmt.Sprintf("tcp@%s:%d", host, conf.GlobalWorkerConfig().Rpc.Port), "")
 		globalXClient = client.NewXClient("Service", client.Failtry, client.RandomSelect, d, option)
-		globalXClient.Auth(conf.GlobalWorkerConfig().Rpc.AuthKey)
+		globalXClient.Auth(rpcAuthKey())
 	}
 
 	return globalXClient.Call(context.Background(), serviceMethod, args, reply)
@@ -147,8 +140,10 @@ func (s *Service) SaveScanResult(ctx context.Context, args *ScanResultArgs, repl
 			IPResult: args.IPResult,
 		}
 
+		config := *args.IPConfig
+		config.MainTaskId = args.MainTaskId
 		saveIPMutex.Lock()
-		msg = append(msg, r.SaveResult(*args.IPConfig))
+		msg = append(msg, r.SaveResult(config))
 		saveIPMutex.Unlock()
 
 		if len(args.IPResult) > 0 {
@@ -160,17 +155,36 @@ func (s *Service) SaveScanResult(ctx context.Context, args *ScanResultArgs, repl
 			DomainResult: args.DomainResult,
 		}
 
+		config := *args.DomainConfig
+		config.MainTaskId = args.MainTaskId
 		saveDomainMutex.Lock()
-		msg = append(msg, r.SaveResult(*args.DomainConfig))
+		msg = append(msg, r.SaveResult(config))
 		saveDomainMutex.Unlock()
 
 		if len(args.DomainResult) > 0 {
 			saveTaskResult(args.TaskID, args.DomainResult)
 		}
 	}
+	// 授权范围外的结果（如在线资产平台返回的结果）进行标记
+	var outOfScope int
+	if args.IPConfig != nil && args.IPResult != nil {
+		outOfScope += tagOutOfScopeResult(args.IPConfig.WorkspaceId, args.IPResult, nil)
+	}
+	if args.DomainConfig != nil && args.DomainResult != nil {
+		outOfScope += tagOutOfScopeResult(args.DomainConfig.WorkspaceId, nil, args.DomainResult)
+	}
+	if outOfScope > 0 {
+		msg = append(msg, fmt.Sprintf("outOfScope:%d", outOfScope))
+	}
 	saveMainTaskResult(args.MainTaskId, args.IPResult, args.DomainResult, args.VulnerabilityResult, 0)
 	*replay = strings.Join(msg, ",")
 	saveMainTaskNewResult(args.MainTaskId, *replay)
+	if args.IPConfig != nil && args.IPResult != nil {
+		checkScanResultAlert(args.IPConfig.WorkspaceId, args.IPResult, nil)
+	}
+	if args.DomainConfig != nil && args.DomainResult != nil {
+		checkScanResultAlert(args.DomainConfig.WorkspaceId, nil, args.DomainResult)
+	}
 
 	return nil
 }
@@ -219,6 +233,7 @@ func (s *Service) SaveVulnerabilityResult(ctx context.Context, args *ScanResultA
 		saveTaskResult(args.TaskID, args.VulnerabilityResult)
 		saveMainTaskResult(args.MainTaskId, nil, nil, args.VulnerabilityResult, 0)
 		saveMainTaskNewResult(args.MainTaskId, *replay)
+		checkVulnerabilityAlert(args.VulnerabilityResult)
 	}
 	return nil
 }
@@ -277,6 +292,9 @@ func (s *Service) CheckTask(ctx context.Context, args *string, replay *TaskStatu
 	if taskRun.State == ampq.REVOKED || taskMain.State == ampq.REVOKED {
 		replay.IsRevoked = true
 	}
+	if taskMain.State == ampq.PAUSED || taskRun.State == ampq.PAUSED {
+		replay.IsPaused = true
+	}
 	replay.TaskID = taskRun.TaskId
 	replay.State = taskRun.State
 	replay.Worker = taskRun.Worker
@@ -287,10 +305,18 @@ func (s *Service) CheckTask(ctx context.Context, args *string, replay *TaskStatu
 
 // UpdateTask 更新任务状态到数据库中
 func (s *Service) UpdateTask(ctx context.Context, args *TaskStatusArgs, replay *bool) error {
+	// 任务执行完成后确认HTTP方式的worker读取的任务
+	if args.State == ampq.SUCCESS || args.State == ampq.FAILURE || args.State == ampq.REVOKED {
+		ampq.AckTask(args.TaskID)
+	}
 	taskCheck := &db.TaskRun{TaskId: args.TaskID}
 	if !taskCheck.GetByTaskId() {
 		return nil
 	}
+	// 暂停的子任务由server保存，恢复时重新发送，忽略worker更新的状态（如强制暂停时仍在执行的任务）
+	if taskCheck.State == ampq.PAUSED && args.State != "" {
+		return nil
+	}
 	dt := time.Now()
 	task := &db.TaskRun{
 		TaskId: args.TaskID,
@@ -303,6 +329,11 @@ func (s *Service) UpdateTask(ctx context.Context, args *TaskStatusArgs, replay *
 		task.SucceededTime = &dt
 	case ampq.FAILURE:
 		task.FailedTime = &dt
+		// 按重试策略重新执行失败的任务
+		if taskCheck.State != ampq.RETRY && serverapi.RetryFailedTask(taskCheck) != "" {
+			task.State = ampq.RETRY
+			task.RetriedTime = &dt
+		}
 	case ampq.REVOKED:
 		task.RevokedTime = &dt
 	case ampq.STARTED:
@@ -314,6 +345,10 @@ func (s *Service) UpdateTask(ctx context.Context, args *TaskStatusArgs, replay *
 	}
 	if task.SaveOrUpdate() {
 		*replay = true
+		// 子任务状态变化后，更新主任务的进度及完成状态
+		if args.State != "" && RunTaskStateHandler != nil {
+			RunTaskStateHandler(taskCheck.MainTaskId)
+		}
 	} else {
 		logging.RuntimeLog.Errorf("update task:%s,state:%s fail !", args.TaskID, args.State)
 	}
@@ -326,6 +361,8 @@ func (s *Service) KeepAlive(ctx context.Context, args *KeepAliveInfo, replay *st
 		logging.RuntimeLog.Error("no worker name")
 		return nil
 	}
+	// worker身份由server根据认证结果设置，不使用worker上报的值
+	args.WorkerStatus.Identity = getIdentityFromContext(ctx)
 	WorkerStatusMutex.Lock()
 	WorkerStatus[args.WorkerStatus.WorkerName] = &args.WorkerStatus
 	WorkerStatus[args.WorkerStatus.WorkerName].UpdateTime = time.Now()
@@ -366,7 +403,7 @@ func (s *Service) NewTask(ctx context.Context, args *NewTaskArgs, replay *string
 		replay = &msg
 		return errors.New(msg)
 	}
-	taskId, err := serverapi.NewRunTask(args.TaskName, args.ConfigJSON, args.MainTaskID, args.LastRunTaskId)
+	taskId, err := serverapi.NewWorkflowRunTask(args.TaskName, args.ConfigJSON, args.MainTaskID, args.LastRunTaskId, args.WorkflowStep, args.WorkerTag)
 	if err != nil {
 		logging.RuntimeLog.Error(err)
 		return err
@@ -383,7 +420,7 @@ func (s *Service) LoadOpenedPort(ctx context.Context, args *LoadIPOpenedPortArgs
 	ips := strings.Split(args.Target, ",")
 	for _, ip := range ips {
 		// 如果不是有效的IP（可能是域名）
-		if utils.CheckIPV4(ip) == false && utils.CheckIPV4Subnet(ip) == false {
+		if utils.CheckIP(ip) == false && utils.CheckIPSubnet(ip) == false {
 			continue
 		}
 		//解析原始输入，可能是ip，也可能是ip/掩码
@@ -403,7 +440,7 @@ func (s *Service) LoadOpenedPort(ctx context.Context, args *LoadIPOpenedPortArgs
 				continue
 			}
 			for _, port := range ports {
-				resultIPAndPort = append(resultIPAndPort, fmt.Sprintf("%s:%d", ipOneByOne, port.PortNum))
+				resultIPAndPort = append(resultIPAndPort, utils.FormatHostPort(ipOneByOne, port.PortNum))
 			}
 		}
 	}
@@ -469,7 +506,7 @@ func (s *Service) LoadDomainOpenedPort(ctx context.Context, args *LoadDomainOpen
 		domainAttr := db.DomainAttr{RelatedId: domain.Id}
 		domainAttrData := domainAttr.GetsByRelatedId()
 		for _, da := range domainAttrData {
-			if da.Tag == "A" {
+			if da.Tag == "A" || da.Tag == "AAAA" {
 				domainIP[domainName][da.Content] = struct{}{}
 			}
 		}
@@ -493,6 +530,19 @@ func (s *Service) LoadDomainOpenedPort(ctx context.Context, args *LoadDomainOpen
 	return nil
 }
 
+// LoadWorkspaceScope 读取工作空间的授权范围配置（yaml格式，为空时不限制）
+func (s *Service) LoadWorkspaceScope(ctx context.Context, args *int, replay *string) error {
+	if args == nil || *args <= 0 {
+		return nil
+	}
+	workspace := db.Workspace{Id: *args}
+	if !workspace.Get() {
+		return errors.New("workspace not exist")
+	}
+	*replay = workspace.ScopeConfig
+	return nil
+}
+
 // SaveRuntimeLog 保存RuntimeLog
 func (s *Service) SaveRuntimeLog(ctx context.Context, args *RuntimeLogArgs, replay *string) error {
 	if len(args.Source) == 0 || len(args.LogMessage) == 0 {
@@ -583,78 +633,50 @@ func saveTaskResult(taskID string, result interface{}) {
 	}
 }
 
-// saveMainTaskResult 保存runtask的任务结果到maintask的缓存中
+// saveMainTaskResult 保存runtask的任务结果到数据库中maintask的结果汇总
 func saveMainTaskResult(taskId string, ipResult map[string]*portscan.IPResult, domainResult map[string]*domainscan.DomainResult, vulResult []pocscan.Result, screenshotResult int) {
-	MainTaskResultMutex.Lock()
-	defer MainTaskResultMutex.Unlock()
-
-	if _, ok := MainTaskResult[taskId]; !ok {
+	if taskId == "" {
 		return
 	}
-	taskObj := MainTaskResult[taskId]
-	if ipResult != nil {
-		for ip, ipr := range ipResult {
-			if _, ok := taskObj.IPResult[ip]; !ok {
-				taskObj.IPResult[ip] = make(map[int]interface{})
-			}
-			for port := range ipr.Ports {
-				taskObj.IPResult[ip][port] = struct{}{}
-			}
+	taskResult := db.TaskMainResult{MainTaskId: taskId}
+	var ips, ports, domains, vuls []string
+	for ip, ipr := range ipResult {
+		ips = append(ips, ip)
+		for port := range ipr.Ports {
+			ports = append(ports, fmt.Sprintf("%s:%d", ip, port))
 		}
 	}
-	if domainResult != nil {
-		for domain := range domainResult {
-			if _, ok := taskObj.DomainResult[domain]; !ok {
-				taskObj.DomainResult[domain] = struct{}{}
-			}
-		}
+	for domain := range domainResult {
+		domains = append(domains, domain)
 	}
-	if vulResult != nil {
-		for _, poc := range vulResult {
-			if _, ok := taskObj.VulResult[poc.Target]; !ok {
-				taskObj.VulResult[poc.Target] = make(map[string]interface{})
-			}
-			taskObj.VulResult[poc.Target][poc.PocFile] = struct{}{}
-		}
+	for _, poc := range vulResult {
+		vuls = append(vuls, fmt.Sprintf("%s %s", poc.Target, poc.PocFile))
+	}
+	taskResult.AddContents(db.TaskResultItemIP, ips)
+	taskResult.AddContents(db.TaskResultItemPort, ports)
+	taskResult.AddContents(db.TaskResultItemDomain, domains)
+	taskResult.AddContents(db.TaskResultItemVulnerability, vuls)
+	if !taskResult.IncreaseCount(db.TaskResultItemScreenshot, screenshotResult) {
+		logging.RuntimeLog.Errorf("save maintask:%s screenshot result fail", taskId)
 	}
-	taskObj.ScreenShotResult += screenshotResult
-
-	MainTaskResult[taskId] = taskObj
-	return
 }
 
 // saveMainTaskNewResult 解析并保存任务结果中新增的资产数量
 func saveMainTaskNewResult(mainTaskId, msg string) {
-	MainTaskResultMutex.Lock()
-	defer MainTaskResultMutex.Unlock()
-
-	if _, ok := MainTaskResult[mainTaskId]; !ok {
+	if mainTaskId == "" {
 		return
 	}
-	taskObj := MainTaskResult[mainTaskId]
+	taskResult := db.TaskMainResult{MainTaskId: mainTaskId}
 	allResult := strings.Split(msg, ",")
 	for _, result := range allResult {
 		kv := strings.Split(result, ":")
 		switch kv[0] {
-		case "ipNew":
-			if v, err := strconv.Atoi(kv[1]); err == nil {
-				taskObj.IPNew += v
-			}
-		case "portNew":
-			if v, err := strconv.Atoi(kv[1]); err == nil {
-				taskObj.PortNew += v
-			}
-		case "domainNew":
-			if v, err := strconv.Atoi(kv[1]); err == nil {
-				taskObj.DomainNew += v
-			}
-		case "vulnerabilityNew":
+		case db.TaskResultItemIPNew, db.TaskResultItemPortNew, db.TaskResultItemDomainNew, db.TaskResultItemVulnerabilityNew:
 			if v, err := strconv.Atoi(kv[1]); err == nil {
-				taskObj.VulnerabilityNew += v
+				if !taskResult.IncreaseCount(kv[0], v) {
+					logging.RuntimeLog.Errorf("save maintask:%s %s result fail", mainTaskId, kv[0])
+				}
 			}
 		}
 	}
-
-	MainTaskResult[mainTaskId] = taskObj
-	return
 }
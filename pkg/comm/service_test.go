@@ -0,0 +1,50 @@
+package comm
+
+import (
+	"context"
+	"github.com/google/uuid"
+	"github.com/hanc00l/nemo_go/pkg/conf"
+	"github.com/hanc00l/nemo_go/pkg/db"
+	"github.com/hanc00l/nemo_go/pkg/task/ampq"
+	"os"
+	"testing"
+)
+
+func TestServicePausedTask(t *testing.T) {
+	if err := os.Chdir("../.."); err != nil {
+		t.Fatal(err)
+	}
+	defer os.Chdir("pkg/comm")
+	conf.EnableStandalone(db.SQLiteMemory)
+	if err := db.Migrate(); err != nil {
+		t.Fatal(err)
+	}
+	workspace := db.Workspace{WorkspaceName: "test", State: "enable"}
+	if !workspace.Add() {
+		t.Fatal("add workspace fail")
+	}
+	mainTask := db.TaskMain{TaskId: uuid.New().String(), TaskName: "xportscan", State: ampq.STARTED, WorkspaceId: workspace.Id}
+	if !mainTask.Add() {
+		t.Fatal("add maintask fail")
+	}
+	s := &Service{}
+	for _, state := range []string{ampq.CREATED, ampq.PAUSED} {
+		task := db.TaskRun{TaskId: uuid.New().String(), TaskName: "portscan", State: state, MainTaskId: mainTask.TaskId, WorkspaceId: workspace.Id}
+		if !task.Add() {
+			t.Fatal("add runtask fail")
+		}
+		var status TaskStatusArgs
+		_ = s.CheckTask(context.Background(), &task.TaskId, &status)
+		if !status.IsExist || status.IsPaused != (state == ampq.PAUSED) || status.State != state {
+			t.Errorf("%s: invalid task status:%+v", state, status)
+		}
+		// 暂停的子任务由server保存，忽略worker更新的状态
+		var updated bool
+		_ = s.UpdateTask(context.Background(), &TaskStatusArgs{TaskID: task.TaskId, State: ampq.SUCCESS}, &updated)
+		if current := (db.TaskRun{TaskId: task.TaskId}); !current.GetByTaskId() || updated != (state == ampq.CREATED) {
+			t.Errorf("%s: update task:%v", state, updated)
+		} else if (state == ampq.PAUSED) != (current.State == ampq.PAUSED) {
+			t.Errorf("%s: task state after update:%s", state, current.State)
+		}
+	}
+}
@@ -0,0 +1,369 @@
+package comm
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"github.com/google/uuid"
+	"github.com/hanc00l/nemo_go/pkg/conf"
+	"github.com/hanc00l/nemo_go/pkg/db"
+	"github.com/hanc00l/nemo_go/pkg/logging"
+	"github.com/hanc00l/nemo_go/pkg/task/onlineapi"
+	whoisparser "github.com/likexian/whois-parser"
+	"github.com/smallnest/rpcx/client"
+	"net/http"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+const (
+	// spoolDir worker暂存未能发送到server的RPC调用的目录
+	spoolDir = "log/spool"
+	// spoolFailedDir 补传时被server拒绝的调用，保留以便人工检查
+	spoolFailedDir = "log/spool/failed"
+	// spoolFileExt 暂存文件的扩展名
+	spoolFileExt = ".json"
+	// spoolReplayInterval 定时补传的间隔
+	spoolReplayInterval = 30 * time.Second
+	// spoolReplayTTL server保留已处理调用ID的时间：重复的补传只发生在响应丢失后的下一次补传，超过该时间的记录可以删除
+	spoolReplayTTL = 7 * 24 * time.Hour
+	// SpoolResultMessage 调用已暂存时的返回结果
+	SpoolResultMessage = "result spooled"
+)
+
+// SpoolArgs 带唯一ID的RPC调用，实时调用及补传时作为SpoolCall的请求参数
+type SpoolArgs struct {
+	Id      string //唯一ID，用于server丢弃重复的调用
+	Worker  string
+	Method  string
+	Payload []byte //JSON格式的请求参数
+}
+
+// SpoolReply SpoolCall的返回结果
+type SpoolReply struct {
+	Duplicated bool   //调用已经处理过
+	Reply      []byte //JSON格式的调用结果
+}
+
+var (
+	// spoolSeq 同一时间暂存的调用的序号，保证文件名的顺序与调用顺序一致
+	spoolSeq uint64
+	// spoolDepth 暂存等待补传的调用数量，首次使用时从暂存目录加载
+	spoolDepth     int64
+	spoolDepthOnce sync.Once
+	// spoolReplayTrigger 触发立即补传
+	spoolReplayTrigger = make(chan struct{}, 1)
+)
+
+// spoolMethods 支持暂存和补传的RPC方法，及server收到补传后的处理
+var spoolMethods = map[string]func(s *Service, ctx context.Context, payload []byte) (interface{}, error){
+	"SaveScanResult": func(s *Service, ctx context.Context, payload []byte) (interface{}, error) {
+		var args ScanResultArgs
+		if err := json.Unmarshal(payload, &args); err != nil {
+			return nil, err
+		}
+		var replay string
+		err := s.SaveScanResult(ctx, &args, &replay)
+		return replay, err
+	},
+	"SaveVulnerabilityResult": func(s *Service, ctx context.Context, payload []byte) (interface{}, error) {
+		var args ScanResultArgs
+		if err := json.Unmarshal(payload, &args); err != nil {
+			return nil, err
+		}
+		var replay string
+		err := s.SaveVulnerabilityResult(ctx, &args, &replay)
+		return replay, err
+	},
+	"SaveScreenshotResult": func(s *Service, ctx context.Context, payload []byte) (interface{}, error) {
+		var args ScreenshotResultArgs
+		if err := json.Unmarshal(payload, &args); err != nil {
+			return nil, err
+		}
+		var replay string
+		err := s.SaveScreenshotResult(ctx, &args, &replay)
+		return replay, err
+	},
+	"SaveIconImageResult": func(s *Service, ctx context.Context, payload []byte) (interface{}, error) {
+		var args IconHashResultArgs
+		if err := json.Unmarshal(payload, &args); err != nil {
+			return nil, err
+		}
+		var replay string
+		err := s.SaveIconImageResult(ctx, &args, &replay)
+		return replay, err
+	},
+	"SaveICPResult": func(s *Service, ctx context.Context, payload []byte) (interface{}, error) {
+		var args map[string]*onlineapi.ICPInfo
+		if err := json.Unmarshal(payload, &args); err != nil {
+			return nil, err
+		}
+		var replay string
+		err := s.SaveICPResult(ctx, &args, &replay)
+		return replay, err
+	},
+	"SaveWhoisResult": func(s *Service, ctx context.Context, payload []byte) (interface{}, error) {
+		var args map[string]*whoisparser.WhoisInfo
+		if err := json.Unmarshal(payload, &args); err != nil {
+			return nil, err
+		}
+		var replay string
+		err := s.SaveWhoisResult(ctx, &args, &replay)
+		return replay, err
+	},
+	"UpdateTask": func(s *Service, ctx context.Context, payload []byte) (interface{}, error) {
+		var args TaskStatusArgs
+		if err := json.Unmarshal(payload, &args); err != nil {
+			return nil, err
+		}
+		var replay bool
+		err := s.UpdateTask(ctx, &args, &replay)
+		return replay, err
+	},
+}
+
+// CallXClientWithSpool 发送结果的RPC调用：server不可达时将调用暂存到本地，连接恢复后按顺序补传；
+// 调用在第一次发送前生成唯一ID，server对实时调用及补传均记录该ID，以丢弃结果已保存但响应丢失后的重复补传
+func CallXClientWithSpool(serviceMethod string, args interface{}, reply interface{}) error {
+	if _, ok := spoolMethods[serviceMethod]; !ok {
+		return CallXClient(serviceMethod, args, reply)
+	}
+	spoolArgs, err := newSpoolArgs(serviceMethod, args)
+	if err != nil {
+		return err
+	}
+	// 已有暂存的调用时，新的调用也暂存，以保证补传的顺序
+	if SpoolDepth() == 0 {
+		var spoolReply SpoolReply
+		err = CallXClient("SpoolCall", spoolArgs, &spoolReply)
+		if err == nil {
+			if spoolReply.Duplicated {
+				setSpoolReply(reply, "duplicated")
+				return nil
+			}
+			return json.Unmarshal(spoolReply.Reply, reply)
+		}
+		if !isSpoolError(err) {
+			return err
+		}
+		logging.RuntimeLog.Warningf("rpc call %s fail:%v,save to spool", serviceMethod, err)
+		logging.CLILog.Warningf("rpc call %s fail:%v,save to spool", serviceMethod, err)
+	}
+	if err = saveSpool(spoolArgs); err != nil {
+		logging.RuntimeLog.Errorf("save spool fail:%v", err)
+		logging.CLILog.Errorf("save spool fail:%v", err)
+		return err
+	}
+	setSpoolReply(reply, SpoolResultMessage)
+	return nil
+}
+
+// setSpoolReply 调用已暂存或已处理过时，设置调用的返回结果
+func setSpoolReply(reply interface{}, message string) {
+	switch r := reply.(type) {
+	case *string:
+		*r = message
+	case *bool:
+		*r = true
+	}
+}
+
+// SpoolDepth 暂存等待补传的调用数量
+func SpoolDepth() int {
+	spoolDepthOnce.Do(func() {
+		atomic.StoreInt64(&spoolDepth, int64(len(listSpoolFiles())))
+	})
+	return int(atomic.LoadInt64(&spoolDepth))
+}
+
+// TriggerSpoolReplay 有暂存的调用时触发立即补传，用于连接恢复后
+func TriggerSpoolReplay() {
+	if SpoolDepth() == 0 {
+		return
+	}
+	select {
+	case spoolReplayTrigger <- struct{}{}:
+	default:
+	}
+}
+
+// StartSpoolReplay worker定时补传暂存的调用
+func StartSpoolReplay() {
+	ticker := time.NewTicker(spoolReplayInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ticker.C:
+		case <-spoolReplayTrigger:
+		}
+		replaySpool()
+	}
+}
+
+// replaySpool 按暂存的顺序补传，连接失败时停止，等待下一次补传
+func replaySpool() {
+	files := listSpoolFiles()
+	if len(files) == 0 {
+		return
+	}
+	var count int
+	for _, file := range files {
+		content, err := os.ReadFile(file)
+		if err != nil {
+			logging.RuntimeLog.Errorf("read spool file:%s fail:%v", file, err)
+			continue
+		}
+		var args SpoolArgs
+		if err = json.Unmarshal(content, &args); err != nil {
+			logging.RuntimeLog.Errorf("invalid spool file:%s,%v", file, err)
+			moveSpoolFailed(file)
+			continue
+		}
+		var spoolReply SpoolReply
+		if err = CallXClient("SpoolCall", &args, &spoolReply); err != nil {
+			if isSpoolError(err) {
+				logging.RuntimeLog.Warningf("replay spool fail:%v,remain:%d", err, len(files)-count)
+				return
+			}
+			logging.RuntimeLog.Errorf("replay spool %s:%s rejected:%v", args.Method, args.Id, err)
+			moveSpoolFailed(file)
+			continue
+		}
+		if err = os.Remove(file); err != nil {
+			logging.RuntimeLog.Errorf("remove spool file:%s fail:%v", file, err)
+		} else {
+			decreaseSpoolDepth()
+		}
+		count++
+	}
+	logging.RuntimeLog.Infof("replay spool finished,total:%d", count)
+	logging.CLILog.Infof("replay spool finished,total:%d", count)
+}
+
+// newSpoolArgs 生成带唯一ID的RPC调用
+func newSpoolArgs(serviceMethod string, args interface{}) (*SpoolArgs, error) {
+	payload, err := json.Marshal(args)
+	if err != nil {
+		return nil, err
+	}
+	return &SpoolArgs{
+		Id:      uuid.New().String(),
+		Worker:  GetWorkerNameBySelf(),
+		Method:  serviceMethod,
+		Payload: payload,
+	}, nil
+}
+
+// saveSpool 将RPC调用保存到暂存目录
+func saveSpool(args *SpoolArgs) error {
+	content, err := json.Marshal(args)
+	if err != nil {
+		return err
+	}
+	dir := filepath.Join(conf.GetRootPath(), spoolDir)
+	if err = os.MkdirAll(dir, 0755); err != nil {
+		return err
+	}
+	// 文件名按时间和序号排序；先写入临时文件再改名，避免补传时读取到不完整的文件
+	name := fmt.Sprintf("%020d-%010d", time.Now().UnixNano(), atomic.AddUint64(&spoolSeq, 1))
+	tmpFile := filepath.Join(dir, name+".tmp")
+	if err = os.WriteFile(tmpFile, content, 0644); err != nil {
+		return err
+	}
+	if err = os.Rename(tmpFile, filepath.Join(dir, name+spoolFileExt)); err != nil {
+		return err
+	}
+	SpoolDepth()
+	atomic.AddInt64(&spoolDepth, 1)
+	return nil
+}
+
+// decreaseSpoolDepth 暂存的调用补传或移到失败目录后减少等待补传的数量
+func decreaseSpoolDepth() {
+	SpoolDepth()
+	if atomic.AddInt64(&spoolDepth, -1) < 0 {
+		atomic.StoreInt64(&spoolDepth, 0)
+	}
+}
+
+// listSpoolFiles 按暂存顺序获取全部暂存文件
+func listSpoolFiles() (files []string) {
+	dir := filepath.Join(conf.GetRootPath(), spoolDir)
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return
+	}
+	for _, entry := range entries {
+		if !entry.IsDir() && strings.HasSuffix(entry.Name(), spoolFileExt) {
+			files = append(files, filepath.Join(dir, entry.Name()))
+		}
+	}
+	sort.Strings(files)
+	return
+}
+
+// moveSpoolFailed 将无法补传的暂存文件移到失败目录
+func moveSpoolFailed(file string) {
+	dir := filepath.Join(conf.GetRootPath(), spoolFailedDir)
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		logging.RuntimeLog.Error(err)
+		return
+	}
+	if err := os.Rename(file, filepath.Join(dir, filepath.Base(file))); err != nil {
+		logging.RuntimeLog.Error(err)
+		return
+	}
+	decreaseSpoolDepth()
+}
+
+// isSpoolError 是否为可以暂存后补传的错误：只有连接失败时暂存；server返回的错误、认证失败及TLS证书错误补传也不会成功，直接返回
+func isSpoolError(err error) bool {
+	var serviceError client.ServiceError
+	if errors.As(err, &serviceError) {
+		return false
+	}
+	var statusError *httpTransportStatusError
+	if errors.As(err, &statusError) && (statusError.StatusCode == http.StatusUnauthorized || statusError.StatusCode == http.StatusForbidden) {
+		return false
+	}
+	msg := err.Error()
+	return !strings.Contains(msg, "x509: ") && !strings.Contains(msg, "tls: ")
+}
+
+// SpoolCall 接收worker带唯一ID的调用（实时调用及补传）：先记录调用的ID，ID已存在（已处理过）的调用直接丢弃
+func (s *Service) SpoolCall(ctx context.Context, args *SpoolArgs, reply *SpoolReply) error {
+	handler, ok := spoolMethods[args.Method]
+	if !ok {
+		return fmt.Errorf("invalid spool method:%s", args.Method)
+	}
+	spoolReplay := &db.SpoolReplay{SpoolId: args.Id, Worker: args.Worker, Method: args.Method}
+	if !spoolReplay.Add() {
+		logging.RuntimeLog.Infof("spool %s:%s from %s already saved,discard", args.Method, args.Id, args.Worker)
+		reply.Duplicated = true
+		return nil
+	}
+	result, err := handler(s, ctx, args.Payload)
+	if err != nil {
+		// 处理失败的调用删除记录，允许重新补传
+		spoolReplay.DeleteBySpoolId()
+		return err
+	}
+	if reply.Reply, err = json.Marshal(result); err != nil {
+		return err
+	}
+	return nil
+}
+
+// PruneSpoolReplay 删除超过保留时间的已处理调用ID，由server的后台任务定期执行
+func PruneSpoolReplay() {
+	spoolReplay := &db.SpoolReplay{}
+	if count := spoolReplay.DeleteBefore(time.Now().Add(-spoolReplayTTL)); count > 0 {
+		logging.RuntimeLog.Infof("prune spool replay:%d", count)
+	}
+}
@@ -0,0 +1,149 @@
+package comm
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"github.com/hanc00l/nemo_go/pkg/conf"
+	"github.com/hanc00l/nemo_go/pkg/db"
+	"github.com/smallnest/rpcx/client"
+	"os"
+	"path/filepath"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestSpoolFiles(t *testing.T) {
+	cwd, _ := os.Getwd()
+	if err := os.Chdir(t.TempDir()); err != nil {
+		t.Fatal(err)
+	}
+	defer os.Chdir(cwd)
+
+	depth := SpoolDepth()
+	var ids []string
+	for i := 0; i < 3; i++ {
+		args := &SpoolArgs{Id: fmt.Sprintf("spool-%d", i), Worker: "test", Method: "UpdateTask", Payload: []byte(`{}`)}
+		if err := saveSpool(args); err != nil {
+			t.Fatal(err)
+		}
+		ids = append(ids, args.Id)
+	}
+	files := listSpoolFiles()
+	if len(files) != 3 || SpoolDepth() != depth+3 {
+		t.Fatalf("spool files:%d,depth:%d", len(files), SpoolDepth())
+	}
+	// 补传按暂存的顺序进行
+	for i, file := range files {
+		var args SpoolArgs
+		content, _ := os.ReadFile(file)
+		if err := json.Unmarshal(content, &args); err != nil || args.Id != ids[i] {
+			t.Errorf("spool file %d:%s,%v", i, args.Id, err)
+		}
+	}
+	moveSpoolFailed(files[0])
+	if _, err := os.Stat(filepath.Join(spoolFailedDir, filepath.Base(files[0]))); err != nil {
+		t.Error(err)
+	}
+	if len(listSpoolFiles()) != 2 || SpoolDepth() != depth+2 {
+		t.Errorf("spool files after move failed:%d,depth:%d", len(listSpoolFiles()), SpoolDepth())
+	}
+}
+
+func TestIsSpoolError(t *testing.T) {
+	tests := []struct {
+		err   error
+		spool bool
+	}{
+		{errors.New("dial tcp 127.0.0.1:5001: connect: connection refused"), true},
+		{client.ErrXClientShutdown, true},
+		{client.NewServiceError("invalid spool method"), false},
+		{fmt.Errorf("wrap:%w", client.NewServiceError("save scan result fail")), false},
+		{&httpTransportStatusError{StatusCode: 401}, false},
+		{&httpTransportStatusError{StatusCode: 502}, true},
+		{errors.New("x509: certificate signed by unknown authority"), false},
+		{errors.New("remote error: tls: bad certificate"), false},
+	}
+	for _, tt := range tests {
+		if isSpoolError(tt.err) != tt.spool {
+			t.Errorf("%v: spool should be %v", tt.err, tt.spool)
+		}
+	}
+}
+
+func TestSpoolCall(t *testing.T) {
+	if err := os.Chdir("../.."); err != nil {
+		t.Fatal(err)
+	}
+	defer os.Chdir("pkg/comm")
+	conf.EnableStandalone(db.SQLiteMemory)
+	if err := db.Migrate(); err != nil {
+		t.Fatal(err)
+	}
+	var calls int64
+	spoolMethods["test"] = func(s *Service, ctx context.Context, payload []byte) (interface{}, error) {
+		atomic.AddInt64(&calls, 1)
+		var result string
+		err := json.Unmarshal(payload, &result)
+		return result, err
+	}
+	spoolMethods["testFail"] = func(s *Service, ctx context.Context, payload []byte) (interface{}, error) {
+		atomic.AddInt64(&calls, 1)
+		return nil, errors.New("save fail")
+	}
+	defer delete(spoolMethods, "test")
+	defer delete(spoolMethods, "testFail")
+	s := &Service{}
+
+	// 同一调用并发补传（如响应丢失后的重复补传）时只处理一次
+	args := &SpoolArgs{Id: "test-spool-call", Worker: "test", Method: "test", Payload: []byte(`"ok"`)}
+	var wg sync.WaitGroup
+	var duplicated int64
+	for i := 0; i < 5; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			var reply SpoolReply
+			if err := s.SpoolCall(context.Background(), args, &reply); err != nil {
+				t.Error(err)
+			}
+			if reply.Duplicated {
+				atomic.AddInt64(&duplicated, 1)
+			} else if string(reply.Reply) != `"ok"` {
+				t.Errorf("spool call reply:%s", reply.Reply)
+			}
+		}()
+	}
+	wg.Wait()
+	if calls != 1 || duplicated != 4 {
+		t.Errorf("spool call:%d,duplicated:%d", calls, duplicated)
+	}
+	// 处理失败的调用可以重新补传
+	failArgs := &SpoolArgs{Id: "test-spool-call-fail", Worker: "test", Method: "testFail"}
+	for i := 0; i < 2; i++ {
+		var reply SpoolReply
+		if err := s.SpoolCall(context.Background(), failArgs, &reply); err == nil || reply.Duplicated {
+			t.Errorf("failed spool call:%v,%v", err, reply.Duplicated)
+		}
+	}
+	if calls != 3 {
+		t.Errorf("failed spool call:%d", calls)
+	}
+	var reply SpoolReply
+	if err := s.SpoolCall(context.Background(), &SpoolArgs{Id: "test-spool-call-invalid", Method: "invalid"}, &reply); err == nil {
+		t.Error("invalid spool method")
+	}
+
+	// 超过保留时间的记录被删除
+	dbConn := db.GetDB()
+	dbConn.Model(&db.SpoolReplay{}).Where("spool_id", args.Id).Update("create_datetime", time.Now().Add(-spoolReplayTTL-time.Hour))
+	db.CloseDB(dbConn)
+	PruneSpoolReplay()
+	reply = SpoolReply{}
+	if err := s.SpoolCall(context.Background(), args, &reply); err != nil || reply.Duplicated || calls != 4 {
+		t.Errorf("spool call after prune:%v,%v,%d", err, reply.Duplicated, calls)
+	}
+}
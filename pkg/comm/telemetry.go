@@ -0,0 +1,63 @@
+package comm
+
+import (
+	"fmt"
+	"github.com/hanc00l/nemo_go/pkg/db"
+	"github.com/hanc00l/nemo_go/pkg/filesync"
+	"github.com/hanc00l/nemo_go/pkg/task/ampq"
+	"github.com/hanc00l/nemo_go/pkg/utils"
+	"sort"
+)
+
+// CheckWorkerTelemetry 检查worker缺少或版本低于其它worker的第三方工具，以及没有完成最新文件同步的worker，返回每个worker的提示；
+// 调用时需持有WorkerStatusMutex
+func CheckWorkerTelemetry() map[string][]string {
+	// 在线worker中每个工具的最高版本
+	latestVersions := make(map[string]string)
+	for _, ws := range WorkerStatus {
+		for tool, version := range ws.ToolVersions {
+			if version == "" || version == ampq.ToolVersionUnknown {
+				continue
+			}
+			if latest, ok := latestVersions[tool]; !ok || utils.CompareVersion(version, latest) > 0 {
+				latestVersions[tool] = version
+			}
+		}
+	}
+	releasePins := getWorkerReleasePins()
+	latestRelease := db.WorkerRelease{}
+	latestRelease.GetLatest()
+	warnings := make(map[string][]string)
+	for name, ws := range WorkerStatus {
+		var tools []string
+		for tool := range ws.ToolVersions {
+			tools = append(tools, tool)
+		}
+		sort.Strings(tools)
+		for _, tool := range tools {
+			version := ws.ToolVersions[tool]
+			if version == "" {
+				warnings[name] = append(warnings[name], fmt.Sprintf("缺少%s", tool))
+			} else if latest, ok := latestVersions[tool]; ok && version != ampq.ToolVersionUnknown && utils.CompareVersion(version, latest) < 0 {
+				warnings[name] = append(warnings[name], fmt.Sprintf("%s版本较低(%s<%s)", tool, version, latest))
+			}
+		}
+		if ws.FileSyncRelease > 0 {
+			// 同步发布版本的worker，检查是否为worker组使用的版本
+			if releaseVersion := workerReleaseVersion(releasePins, workerTagsFromTopics(ws.WorkerTopics)); releaseVersion == 0 {
+				if latestRelease.Version > 0 && ws.FileSyncRelease != latestRelease.Version {
+					warnings[name] = append(warnings[name], fmt.Sprintf("文件未同步到最新发布版本(%d)", latestRelease.Version))
+				}
+			} else if ws.FileSyncRelease != releaseVersion {
+				warnings[name] = append(warnings[name], fmt.Sprintf("文件未同步到指定的发布版本(%d)", releaseVersion))
+			}
+		} else if serverRevision := filesync.ServerRevision(ws.FileSyncProfile); serverRevision != "" && ws.FileSyncRevision != serverRevision {
+			// server的同步版本在有worker同步后才能确定；worker只同步其profile需要的文件
+			warnings[name] = append(warnings[name], "文件未同步到最新版本")
+		}
+		if ws.SpoolDepth > 0 {
+			warnings[name] = append(warnings[name], fmt.Sprintf("%d个结果等待补传", ws.SpoolDepth))
+		}
+	}
+	return warnings
+}
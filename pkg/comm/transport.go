@@ -0,0 +1,450 @@
+package comm
+
+import (
+	"bytes"
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/gob"
+	"encoding/json"
+	"fmt"
+	"github.com/hanc00l/nemo_go/pkg/conf"
+	"github.com/hanc00l/nemo_go/pkg/filesync"
+	"github.com/hanc00l/nemo_go/pkg/logging"
+	"github.com/hanc00l/nemo_go/pkg/task/ampq"
+	"github.com/smallnest/rpcx/client"
+	"io"
+	"net/http"
+	"net/http/cookiejar"
+	"net/url"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	// HTTPTransportPath server为HTTP方式的worker提供的接口路径，与web使用同一个端口
+	HTTPTransportPath = "/worker-transport"
+	// httpTransportAuthHeader worker认证使用的请求头，值与RPC的认证key相同
+	httpTransportAuthHeader = "X-Nemo-Auth"
+	// httpTransportLeaseWait server等待队列中有任务的最长时间
+	httpTransportLeaseWait = 10 * time.Second
+	// httpTransportTimeout worker请求的超时时间，需大于等待任务的时间，并能上传较大的结果
+	httpTransportTimeout = 5 * time.Minute
+	// httpTransportMaxBodySize server接收的请求的最大长度
+	httpTransportMaxBodySize = 512 << 20
+)
+
+// httpCallArgs HTTP方式的RPC调用请求，参数为JSON格式
+type httpCallArgs struct {
+	Method string          `json:"method"`
+	Args   json.RawMessage `json:"args"`
+}
+
+// httpCallReply HTTP方式的RPC调用结果，Error为server处理调用时返回的错误
+type httpCallReply struct {
+	Reply json.RawMessage `json:"reply,omitempty"`
+	Error string          `json:"error,omitempty"`
+}
+
+// httpLeaseArgs 读取任务的请求
+type httpLeaseArgs struct {
+	RoutingKey string `json:"routing_key"`
+}
+
+// httpRequeueArgs 将任务放回队列的请求
+type httpRequeueArgs struct {
+	RoutingKey string    `json:"routing_key"`
+	Msg        []byte    `json:"msg"`
+	ETA        time.Time `json:"eta"`
+}
+
+// httpTaskArgs 确认或延长任务的请求
+type httpTaskArgs struct {
+	TaskIds []string `json:"task_ids"`
+}
+
+// httpTransport worker通过server的web端口进行RPC调用、读取任务及文件同步
+type httpTransport struct {
+	serverURL string
+	client    *http.Client
+}
+
+// workerHTTPTransport worker使用HTTP方式连接server时的连接，为空时使用RPC、文件同步服务及消息队列
+var workerHTTPTransport *httpTransport
+
+// serviceValue 处理HTTP方式RPC调用的服务
+var serviceValue = reflect.ValueOf(new(Service))
+
+// EnableHTTPTransport worker使用HTTP方式连接server，只需要访问server的web端口；在加载worker身份后调用
+func EnableHTTPTransport(serverURL string) error {
+	u, err := url.Parse(serverURL)
+	if err != nil {
+		return err
+	}
+	if (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		return fmt.Errorf("invalid server url:%s", serverURL)
+	}
+	// 保持web的session，避免每个请求在server生成新的session
+	jar, err := cookiejar.New(nil)
+	if err != nil {
+		return err
+	}
+	workerHTTPTransport = &httpTransport{
+		serverURL: strings.TrimSuffix(serverURL, "/"),
+		client: &http.Client{
+			Transport: &http.Transport{
+				Proxy:           http.ProxyFromEnvironment,
+				TLSClientConfig: rpcClientTLSConfig(),
+			},
+			Jar:     jar,
+			Timeout: httpTransportTimeout,
+		},
+	}
+	ampq.HTTPTaskTransport = workerHTTPTransport
+	logging.CLILog.Infof("use http transport:%s", workerHTTPTransport.serverURL)
+	return nil
+}
+
+// GetHTTPTransportURL worker使用HTTP方式连接server时的地址，否则为空
+func GetHTTPTransportURL() string {
+	if workerHTTPTransport == nil {
+		return ""
+	}
+	return workerHTTPTransport.serverURL
+}
+
+// post 发送请求，返回状态为200或204的响应
+func (t *httpTransport) post(path string, body []byte) (*http.Response, error) {
+	req, err := http.NewRequest(http.MethodPost, t.serverURL+HTTPTransportPath+path, bytes.NewReader(body))
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set(httpTransportAuthHeader, rpcAuthKey())
+	resp, err := t.client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	if resp.StatusCode != http.StatusOK && resp.StatusCode != http.StatusNoContent {
+		msg, _ := io.ReadAll(io.LimitReader(resp.Body, 1024))
+		resp.Body.Close()
+		return nil, &httpTransportStatusError{StatusCode: resp.StatusCode, Status: resp.Status, Message: strings.TrimSpace(string(msg))}
+	}
+	return resp, nil
+}
+
+// httpTransportStatusError server返回的错误状态
+type httpTransportStatusError struct {
+	StatusCode int
+	Status     string
+	Message    string
+}
+
+func (e *httpTransportStatusError) Error() string {
+	return fmt.Sprintf("http transport %s:%s", e.Status, e.Message)
+}
+
+// call HTTP方式的RPC调用；server处理调用时返回的错误为ServiceError，与RPC调用一致
+func (t *httpTransport) call(serviceMethod string, args interface{}, reply interface{}) error {
+	argsJSON, err := json.Marshal(args)
+	if err != nil {
+		return err
+	}
+	body, err := json.Marshal(httpCallArgs{Method: serviceMethod, Args: argsJSON})
+	if err != nil {
+		return err
+	}
+	resp, err := t.post("/call", body)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	var r httpCallReply
+	if err = json.NewDecoder(resp.Body).Decode(&r); err != nil {
+		return err
+	}
+	if r.Error != "" {
+		return client.NewServiceError(r.Error)
+	}
+	return json.Unmarshal(r.Reply, reply)
+}
+
+// LeaseTask 从server读取一个任务，没有任务时返回nil
+func (t *httpTransport) LeaseTask(routingKey string) ([]byte, error) {
+	body, err := json.Marshal(httpLeaseArgs{RoutingKey: routingKey})
+	if err != nil {
+		return nil, err
+	}
+	resp, err := t.post("/lease", body)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode == http.StatusNoContent {
+		return nil, nil
+	}
+	return io.ReadAll(resp.Body)
+}
+
+// RequeueTask 将任务放回server的队列
+func (t *httpTransport) RequeueTask(routingKey string, msg []byte, eta time.Time) error {
+	body, err := json.Marshal(httpRequeueArgs{RoutingKey: routingKey, Msg: msg, ETA: eta})
+	if err != nil {
+		return err
+	}
+	resp, err := t.post("/requeue", body)
+	if err != nil {
+		return err
+	}
+	return resp.Body.Close()
+}
+
+// AckTask 确认任务已执行完成
+func (t *httpTransport) AckTask(taskIds []string) error {
+	return t.postTasks("/ack", taskIds)
+}
+
+// ExtendTask 延长正在执行的任务
+func (t *httpTransport) ExtendTask(taskIds []string) error {
+	return t.postTasks("/extend", taskIds)
+}
+
+// postTasks 发送确认或延长任务的请求
+func (t *httpTransport) postTasks(path string, taskIds []string) error {
+	body, err := json.Marshal(httpTaskArgs{TaskIds: taskIds})
+	if err != nil {
+		return err
+	}
+	resp, err := t.post(path, body)
+	if err != nil {
+		return err
+	}
+	return resp.Body.Close()
+}
+
+// fileSync 发送文件同步的消息，并返回server响应的消息
+func (t *httpTransport) fileSync(mg filesync.Message) (reply filesync.Message, err error) {
+	var body bytes.Buffer
+	if err = gob.NewEncoder(&body).Encode(mg); err != nil {
+		return
+	}
+	resp, err := t.post("/filesync", body.Bytes())
+	if err != nil {
+		return
+	}
+	defer resp.Body.Close()
+
+	err = gob.NewDecoder(resp.Body).Decode(&reply)
+	return
+}
+
+// WorkerFileSync worker从server同步文件，只同步worker执行的任务类型需要的文件
+func WorkerFileSync(workerRunTaskMode string) {
+	profile := fileSyncProfile(workerRunTaskMode)
+	filesync.WorkerTags = nil
+	for _, tag := range strings.Split(WorkerTags, ",") {
+		if tag = strings.TrimSpace(tag); tag != "" {
+			filesync.WorkerTags = append(filesync.WorkerTags, tag)
+		}
+	}
+	if workerHTTPTransport != nil {
+		filesync.WorkerSyncByExchange(workerHTTPTransport.fileSync, profile)
+		return
+	}
+	fileSyncServer := conf.GlobalWorkerConfig().FileSync
+	filesync.WorkerStartupSync(fileSyncServer.Host, fmt.Sprintf("%d", fileSyncServer.Port), FileSyncAuthKey(), profile)
+}
+
+// fileSyncProfile 根据worker执行的任务模式生成文件同步的profile；执行全部任务或自定义任务时同步全部文件
+func fileSyncProfile(workerRunTaskMode string) string {
+	var taskTypes []string
+	for _, mode := range strings.Split(workerRunTaskMode, ",") {
+		m, err := strconv.Atoi(strings.TrimSpace(mode))
+		if err != nil {
+			return ""
+		}
+		switch ampq.WorkerRunTaskMode(m) {
+		case ampq.TaskModeActive:
+			taskTypes = append(taskTypes, ampq.TopicActive)
+		case ampq.TaskModeFinger:
+			taskTypes = append(taskTypes, ampq.TopicFinger)
+		case ampq.TaskModePassive:
+			taskTypes = append(taskTypes, ampq.TopicPassive)
+		case ampq.TaskModePocscan:
+			taskTypes = append(taskTypes, ampq.TopicPocscan)
+		default:
+			return ""
+		}
+	}
+	return filesync.SyncProfile(taskTypes)
+}
+
+// HTTPTransportTLSConfig web的TLS配置：有CA时验证HTTP方式的worker提供的客户端证书；浏览器不需要提供证书
+func HTTPTransportTLSConfig() *tls.Config {
+	if serverCA == nil {
+		return nil
+	}
+	return &tls.Config{
+		ClientCAs:  serverCA.CertPool(),
+		ClientAuth: tls.VerifyClientCertIfGiven,
+	}
+}
+
+// NewHTTPTransportHandler server为HTTP方式的worker提供RPC调用、读取任务及文件同步（enableFileSync）的接口
+func NewHTTPTransportHandler(enableFileSync bool) http.Handler {
+	mux := http.NewServeMux()
+	mux.HandleFunc(HTTPTransportPath+"/call", handleHTTPCall)
+	mux.HandleFunc(HTTPTransportPath+"/lease", handleHTTPLease)
+	mux.HandleFunc(HTTPTransportPath+"/requeue", handleHTTPRequeue)
+	mux.HandleFunc(HTTPTransportPath+"/ack", handleHTTPAck)
+	mux.HandleFunc(HTTPTransportPath+"/extend", handleHTTPExtend)
+	if enableFileSync {
+		filesync.ReleaseProvider = getWorkerRelease
+		mux.HandleFunc(HTTPTransportPath+"/filesync", handleHTTPFileSync)
+	}
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPost {
+			http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+			return
+		}
+		var peerCerts []*x509.Certificate
+		if r.TLS != nil {
+			peerCerts = r.TLS.PeerCertificates
+		}
+		workerName, ok := authWorker(r.Header.Get(httpTransportAuthHeader), peerCerts, conf.GlobalServerConfig().Rpc.AuthKey)
+		if !ok {
+			logging.RuntimeLog.Warningf("http transport auth fail from %s", r.RemoteAddr)
+			http.Error(w, "invalid token", http.StatusUnauthorized)
+			return
+		}
+		ctx := r.Context()
+		if workerName != "" {
+			ctx = context.WithValue(ctx, identityContextKey{}, workerName)
+		}
+		r.Body = http.MaxBytesReader(w, r.Body, httpTransportMaxBodySize)
+		mux.ServeHTTP(w, r.WithContext(ctx))
+	})
+}
+
+// handleHTTPCall 处理HTTP方式的RPC调用，调用Service中与RPC同名的方法
+func handleHTTPCall(w http.ResponseWriter, r *http.Request) {
+	var args httpCallArgs
+	if err := json.NewDecoder(r.Body).Decode(&args); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	method := serviceValue.MethodByName(args.Method)
+	if !method.IsValid() || method.Type().NumIn() != 3 || method.Type().NumOut() != 1 ||
+		method.Type().In(1).Kind() != reflect.Ptr || method.Type().In(2).Kind() != reflect.Ptr {
+		writeHTTPCallReply(w, nil, fmt.Errorf("invalid method:%s", args.Method))
+		return
+	}
+	argv := reflect.New(method.Type().In(1).Elem())
+	if err := json.Unmarshal(args.Args, argv.Interface()); err != nil {
+		writeHTTPCallReply(w, nil, err)
+		return
+	}
+	replyv := reflect.New(method.Type().In(2).Elem())
+	out := method.Call([]reflect.Value{reflect.ValueOf(r.Context()), argv, replyv})
+	err, _ := out[0].Interface().(error)
+	writeHTTPCallReply(w, replyv.Interface(), err)
+}
+
+// writeHTTPCallReply 返回RPC调用的结果
+func writeHTTPCallReply(w http.ResponseWriter, reply interface{}, err error) {
+	var r httpCallReply
+	if err != nil {
+		r.Error = err.Error()
+	} else if r.Reply, err = json.Marshal(reply); err != nil {
+		r.Error = err.Error()
+	}
+	w.Header().Set("Content-Type", "application/json")
+	if err = json.NewEncoder(w).Encode(r); err != nil {
+		logging.RuntimeLog.Error(err)
+	}
+}
+
+// handleHTTPLease 为worker读取一个任务，队列中没有任务时等待一段时间
+func handleHTTPLease(w http.ResponseWriter, r *http.Request) {
+	var args httpLeaseArgs
+	if err := json.NewDecoder(r.Body).Decode(&args); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	msg, err := ampq.LeaseTask(r.Context(), args.RoutingKey, httpTransportLeaseWait)
+	if err != nil {
+		logging.RuntimeLog.Errorf("lease task from %s fail:%v", args.RoutingKey, err)
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	if len(msg) == 0 {
+		w.WriteHeader(http.StatusNoContent)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	if _, err = w.Write(msg); err != nil {
+		logging.RuntimeLog.Errorf("send task to %s fail:%v,requeue", r.RemoteAddr, err)
+		ampq.RequeueLeasedTask(msg)
+	}
+}
+
+// handleHTTPRequeue 将worker未执行或需要重试的任务放回队列
+func handleHTTPRequeue(w http.ResponseWriter, r *http.Request) {
+	var args httpRequeueArgs
+	if err := json.NewDecoder(r.Body).Decode(&args); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	if err := ampq.RequeueTask(args.RoutingKey, args.Msg, args.ETA); err != nil {
+		logging.RuntimeLog.Errorf("requeue task to %s fail:%v", args.RoutingKey, err)
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	w.WriteHeader(http.StatusOK)
+}
+
+// handleHTTPAck 确认worker已执行完成的任务
+func handleHTTPAck(w http.ResponseWriter, r *http.Request) {
+	var args httpTaskArgs
+	if err := json.NewDecoder(r.Body).Decode(&args); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	for _, taskId := range args.TaskIds {
+		ampq.AckTask(taskId)
+	}
+	w.WriteHeader(http.StatusOK)
+}
+
+// handleHTTPExtend 延长worker正在执行的任务
+func handleHTTPExtend(w http.ResponseWriter, r *http.Request) {
+	var args httpTaskArgs
+	if err := json.NewDecoder(r.Body).Decode(&args); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	for _, taskId := range args.TaskIds {
+		ampq.ExtendTask(taskId)
+	}
+	w.WriteHeader(http.StatusOK)
+}
+
+// handleHTTPFileSync 处理worker的文件同步消息
+func handleHTTPFileSync(w http.ResponseWriter, r *http.Request) {
+	var mg filesync.Message
+	if err := gob.NewDecoder(r.Body).Decode(&mg); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	if mg.MgType == filesync.MsgSync {
+		logging.RuntimeLog.Infof("file sync from %s", r.RemoteAddr)
+		logging.CLILog.Infof("file sync from %s", r.RemoteAddr)
+	}
+	reply := filesync.HandleMessage(&mg)
+	if err := gob.NewEncoder(w).Encode(reply); err != nil {
+		logging.RuntimeLog.Error(err)
+	}
+}
@@ -5,6 +5,7 @@ import (
 	"gopkg.in/yaml.v2"
 	"os"
 	"path/filepath"
+	"time"
 )
 
 const (
@@ -25,6 +26,12 @@ var RunMode = Release
 
 //var RunMode = Debug
 
+// Standalone 单机模式：web、API、RPC与worker在同一进程中运行，使用sqlite数据库与进程内的消息队列
+var Standalone bool
+
+// StandaloneDbname 单机模式使用的sqlite数据库文件
+var StandaloneDbname = "nemo.db"
+
 // Nemo 系统运行全局配置参数
 var serverConfig *Server
 var workerConfig *Worker
@@ -59,15 +66,21 @@ type Server struct {
 	FileSync RPC               `yaml:"fileSync"`
 	WebAPI   WebAPI            `yaml:"api"`
 	Database Database          `yaml:"database"`
+	Broker   string            `yaml:"broker"` //消息队列类型：amqp(默认)、redis、memory
 	Rabbitmq Rabbitmq          `yaml:"rabbitmq"`
+	Redis    Redis             `yaml:"redis"`
 	Task     Task              `yaml:"task"`
 	Notify   map[string]Notify `yaml:"notify"`
+	// AllowSharedAuthKey 是否允许未注册身份的worker使用rpc及fileSync共用的authKey连接，默认只允许已注册身份的worker
+	AllowSharedAuthKey bool `yaml:"allowSharedAuthKey"`
 }
 
 type Worker struct {
 	Rpc         RPC         `yaml:"rpc"`
 	FileSync    RPC         `yaml:"fileSync"`
+	Broker      string      `yaml:"broker"` //消息队列类型，与server保持一致
 	Rabbitmq    Rabbitmq    `yaml:"rabbitmq"`
+	Redis       Redis       `yaml:"redis"`
 	API         API         `yaml:"api"`
 	Portscan    Portscan    `yaml:"portscan"`
 	Fingerprint Fingerprint `yaml:"fingerprint"`
@@ -80,6 +93,7 @@ type Web struct {
 	Host     string `yaml:"host"`
 	Port     int    `yaml:"port"`
 	WebFiles string `yaml:"webfiles"`
+	URL      string `yaml:"url,omitempty"` //web访问的地址，用于消息通知中的链接，如https://nemo.example.com:5000
 }
 
 type WebAPI struct {
@@ -93,6 +107,7 @@ type RPC struct {
 }
 
 type Database struct {
+	Driver   string `yaml:"driver"` //数据库类型：mysql(默认)、sqlite、postgres
 	Host     string `yaml:"host"`
 	Port     int    `yaml:"port"`
 	Dbname   string `yaml:"name"`
@@ -107,9 +122,45 @@ type Rabbitmq struct {
 	Password string `yaml:"password"`
 }
 
+type Redis struct {
+	Host     string `yaml:"host"`
+	Port     int    `yaml:"port"`
+	Password string `yaml:"password"`
+	DB       int    `yaml:"db"`
+}
+
 type Task struct {
-	IpSliceNumber   int `yaml:"ipSliceNumber"`
-	PortSliceNumber int `yaml:"portSliceNumber"`
+	IpSliceNumber   int                    `yaml:"ipSliceNumber"`
+	PortSliceNumber int                    `yaml:"portSliceNumber"`
+	Retry           map[string]RetryPolicy `yaml:"retry,omitempty"` //按任务名称的失败重试策略，default为未单独配置的任务的策略
+}
+
+// RetryPolicy 任务失败后的重试策略
+type RetryPolicy struct {
+	MaxAttempts int `yaml:"maxAttempts"`          //最多执行的次数（包括第一次执行），小于等于1时不重试
+	Backoff     int `yaml:"backoff"`              //第一次重试的延迟秒数，之后每次重试延迟加倍
+	MaxBackoff  int `yaml:"maxBackoff,omitempty"` //重试延迟的最大秒数，0为不限制
+}
+
+// GetRetryPolicy 获取任务的重试策略
+func (t *Task) GetRetryPolicy(taskName string) (policy RetryPolicy, ok bool) {
+	if policy, ok = t.Retry[taskName]; ok {
+		return
+	}
+	policy, ok = t.Retry["default"]
+	return
+}
+
+// Delay 第attempt次执行失败后，重试的延迟时间
+func (p RetryPolicy) Delay(attempt int) time.Duration {
+	delay := p.Backoff
+	for i := 1; i < attempt && (p.MaxBackoff <= 0 || delay < p.MaxBackoff); i++ {
+		delay *= 2
+	}
+	if p.MaxBackoff > 0 && delay > p.MaxBackoff {
+		delay = p.MaxBackoff
+	}
+	return time.Duration(delay) * time.Second
 }
 
 type API struct {
@@ -175,12 +226,26 @@ type OnlineAPI struct {
 }
 
 type Notify struct {
+	Type  string `yaml:"type,omitempty"` //通知类型：dingtalk、feishu、serverchan、webhook、smtp，为空时与配置的名称相同
 	Token string `yaml:"token"`
+	//webhook
+	URL      string            `yaml:"url,omitempty"`
+	Headers  map[string]string `yaml:"headers,omitempty"`
+	Secret   string            `yaml:"secret,omitempty"`   //HMAC-SHA256签名的密钥
+	Template string            `yaml:"template,omitempty"` //消息内容的模板，webhook为请求body的模板，为空时发送JSON格式的事件
+	//smtp
+	Host     string   `yaml:"host,omitempty"`
+	Port     int      `yaml:"port,omitempty"`
+	Username string   `yaml:"username,omitempty"`
+	Password string   `yaml:"password,omitempty"`
+	From     string   `yaml:"from,omitempty"`
+	To       []string `yaml:"to,omitempty"`
+	SSL      bool     `yaml:"ssl,omitempty"`
 }
 
 // WriteConfig 写配置到yaml文件中
 func (config *Server) WriteConfig() error {
-	content, err := yaml.Marshal(config)
+	content, err := yaml.Marshal(config.fileConfig())
 	if err != nil {
 		fmt.Println(err)
 		return err
@@ -192,6 +257,13 @@ func (config *Server) WriteConfig() error {
 	return err
 }
 
+// ParseNotifyConfig 解析yaml格式的消息通知配置（与server.yml中notify的格式相同）
+func ParseNotifyConfig(content string) (notify map[string]Notify, err error) {
+	notify = make(map[string]Notify)
+	err = yaml.Unmarshal([]byte(content), &notify)
+	return
+}
+
 // ReloadConfig 从yaml文件中加载配置
 func (config *Server) ReloadConfig() error {
 	fileContent, err := os.ReadFile(filepath.Join(GetRootPath(), "conf/server.yml"))
@@ -203,6 +275,9 @@ func (config *Server) ReloadConfig() error {
 	if err != nil {
 		fmt.Println(err)
 	}
+	if Standalone {
+		config.applyStandalone()
+	}
 	return err
 }
 
@@ -217,12 +292,15 @@ func (config *Worker) ReloadConfig() error {
 	if err != nil {
 		fmt.Println(err)
 	}
+	if Standalone {
+		config.applyStandalone()
+	}
 	return err
 }
 
 // WriteConfig 写配置到yaml文件中
 func (config *Worker) WriteConfig() error {
-	content, err := yaml.Marshal(config)
+	content, err := yaml.Marshal(config.fileConfig())
 	if err != nil {
 		fmt.Println(err)
 		return err
@@ -234,6 +312,60 @@ func (config *Worker) WriteConfig() error {
 	return err
 }
 
+// EnableStandalone 启用单机模式，已加载的配置同时使用单机模式的配置
+func EnableStandalone(dbname string) {
+	Standalone = true
+	if dbname != "" {
+		StandaloneDbname = dbname
+	}
+	if serverConfig != nil {
+		serverConfig.applyStandalone()
+	}
+	if workerConfig != nil {
+		workerConfig.applyStandalone()
+	}
+}
+
+// applyStandalone 单机模式使用sqlite数据库与进程内的消息队列（不写入配置文件）
+func (config *Server) applyStandalone() {
+	config.Database = Database{Driver: "sqlite", Dbname: StandaloneDbname}
+	config.Broker = "memory"
+}
+
+// applyStandalone 单机模式的worker使用进程内的消息队列，并连接本进程的RPC（不写入配置文件）
+func (config *Worker) applyStandalone() {
+	config.Broker = "memory"
+	config.Rpc = GlobalServerConfig().Rpc
+}
+
+// fileConfig 写入配置文件的配置：单机模式覆盖的配置保持配置文件中原有的值
+func (config *Server) fileConfig() *Server {
+	if !Standalone {
+		return config
+	}
+	c := *config
+	var fc Server
+	if fileContent, err := os.ReadFile(filepath.Join(GetRootPath(), "conf/server.yml")); err == nil && yaml.Unmarshal(fileContent, &fc) == nil {
+		c.Database = fc.Database
+		c.Broker = fc.Broker
+	}
+	return &c
+}
+
+// fileConfig 写入配置文件的配置：单机模式覆盖的配置保持配置文件中原有的值
+func (config *Worker) fileConfig() *Worker {
+	if !Standalone {
+		return config
+	}
+	c := *config
+	var fc Worker
+	if fileContent, err := os.ReadFile(filepath.Join(GetRootPath(), "conf/worker.yml")); err == nil && yaml.Unmarshal(fileContent, &fc) == nil {
+		c.Broker = fc.Broker
+		c.Rpc = fc.Rpc
+	}
+	return &c
+}
+
 // GetRootPath 获取运行时系统的root位置，解决调试时无法使用相对位置的困扰
 func GetRootPath() string {
 	if RunMode == Debug {
@@ -0,0 +1,90 @@
+package conf
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+// useTempConfig 在临时目录中使用配置文件的副本进行测试，结束后恢复全局配置
+func useTempConfig(t *testing.T) {
+	dir := t.TempDir()
+	if err := os.Mkdir(filepath.Join(dir, "conf"), 0755); err != nil {
+		t.Fatal(err)
+	}
+	for _, name := range []string{"server.yml", "worker.yml"} {
+		content, err := os.ReadFile(filepath.Join("../../conf", name))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if err = os.WriteFile(filepath.Join(dir, "conf", name), content, 0666); err != nil {
+			t.Fatal(err)
+		}
+	}
+	cwd, _ := os.Getwd()
+	if err := os.Chdir(dir); err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() {
+		_ = os.Chdir(cwd)
+		serverConfig, workerConfig = nil, nil
+		Standalone, StandaloneDbname = false, "nemo.db"
+	})
+}
+
+func TestEnableStandalone(t *testing.T) {
+	useTempConfig(t)
+	server, worker := GlobalServerConfig(), GlobalWorkerConfig()
+	database, serverBroker := server.Database, server.Broker
+	rpc, workerBroker := worker.Rpc, worker.Broker
+	if database.Driver == "sqlite" || serverBroker == "memory" || workerBroker == "memory" {
+		t.Fatalf("config files should not use standalone config:%s,%s,%s", database.Driver, serverBroker, workerBroker)
+	}
+
+	// 已加载的配置切换为单机模式
+	EnableStandalone("test.db")
+	checkStandalone := func(server *Server, worker *Worker) {
+		if server.Database.Driver != "sqlite" || server.Database.Dbname != "test.db" || server.Broker != "memory" {
+			t.Errorf("server standalone config:%+v,%s", server.Database, server.Broker)
+		}
+		if worker.Broker != "memory" || worker.Rpc != server.Rpc {
+			t.Errorf("worker standalone config:%+v,%s", worker.Rpc, worker.Broker)
+		}
+	}
+	checkStandalone(server, worker)
+
+	// 写回配置文件时保持单机模式覆盖的配置项不变，其它配置正常保存
+	server.Task.IpSliceNumber = 32
+	worker.Portscan.Rate = 100
+	if err := server.WriteConfig(); err != nil {
+		t.Fatal(err)
+	}
+	if err := worker.WriteConfig(); err != nil {
+		t.Fatal(err)
+	}
+	checkStandalone(server, worker)
+	Standalone = false
+	fileServer, fileWorker := new(Server), new(Worker)
+	if err := fileServer.ReloadConfig(); err != nil {
+		t.Fatal(err)
+	}
+	if err := fileWorker.ReloadConfig(); err != nil {
+		t.Fatal(err)
+	}
+	if fileServer.Database != database || fileServer.Broker != serverBroker || fileServer.Task.IpSliceNumber != 32 {
+		t.Errorf("server config file:%+v,%s,%d", fileServer.Database, fileServer.Broker, fileServer.Task.IpSliceNumber)
+	}
+	if fileWorker.Rpc != rpc || fileWorker.Broker != workerBroker || fileWorker.Portscan.Rate != 100 {
+		t.Errorf("worker config file:%+v,%s,%d", fileWorker.Rpc, fileWorker.Broker, fileWorker.Portscan.Rate)
+	}
+
+	// 单机模式下重新加载的配置同样使用单机模式的配置
+	Standalone = true
+	if err := fileServer.ReloadConfig(); err != nil {
+		t.Fatal(err)
+	}
+	if err := fileWorker.ReloadConfig(); err != nil {
+		t.Fatal(err)
+	}
+	checkStandalone(fileServer, fileWorker)
+}
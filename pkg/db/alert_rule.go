@@ -0,0 +1,157 @@
+package db
+
+import (
+	"time"
+)
+
+const (
+	AlertRulePort          = "port"
+	AlertRuleVulnerability = "vulnerability"
+	AlertRuleDomain        = "domain"
+	AlertRuleTitle         = "title"
+)
+
+// AlertRule 工作空间的告警规则：扫描结果保存时按规则匹配新发现的资产和漏洞并发送通知
+type AlertRule struct {
+	Id             int       `gorm:"primaryKey"`
+	WorkspaceId    int       `gorm:"column:workspace_id;not null;index:fk_alert_rule_workspace_id"`
+	RuleName       string    `gorm:"column:rule_name;size:100;not null"`
+	RuleType       string    `gorm:"column:rule_type;size:20;not null"`
+	RuleContent    string    `gorm:"column:rule_content;size:500"`
+	Notify         string    `gorm:"column:notify;size:200"`
+	State          string    `gorm:"column:state;size:20;not null"`
+	CreateDatetime time.Time `gorm:"column:create_datetime;not null"`
+	UpdateDatetime time.Time `gorm:"column:update_datetime;not null"`
+}
+
+// TableName 设置数据库关联的表名
+func (*AlertRule) TableName() string {
+	return "alert_rule"
+}
+
+// Add 插入一条新的记录，返回主键ID及成功标志
+func (r *AlertRule) Add() (success bool) {
+	r.CreateDatetime = time.Now()
+	r.UpdateDatetime = time.Now()
+
+	db := GetDB()
+	defer CloseDB(db)
+	if result := db.Create(r); result.RowsAffected > 0 {
+		return true
+	} else {
+		return false
+	}
+}
+
+// Get 根据ID查询记录
+func (r *AlertRule) Get() (success bool) {
+	db := GetDB()
+	defer CloseDB(db)
+
+	if result := db.First(r, r.Id); result.RowsAffected > 0 {
+		return true
+	} else {
+		return false
+	}
+}
+
+// Update 更新指定ID的一条记录，列名和内容位于map中
+func (r *AlertRule) Update(updateMap map[string]interface{}) (success bool) {
+	updateMap["update_datetime"] = time.Now()
+
+	db := GetDB()
+	defer CloseDB(db)
+	if result := db.Model(r).Updates(updateMap); result.RowsAffected > 0 {
+		return true
+	} else {
+		return false
+	}
+}
+
+// Delete 删除指定主键ID的一条记录
+func (r *AlertRule) Delete() (success bool) {
+	db := GetDB()
+	defer CloseDB(db)
+	if result := db.Delete(r, r.Id); result.RowsAffected > 0 {
+		return true
+	} else {
+		return false
+	}
+}
+
+// Gets 根据指定的条件，查询满足要求的记录
+func (r *AlertRule) Gets(searchMap map[string]interface{}, page, rowsPerPage int) (results []AlertRule, count int) {
+	orderBy := "update_datetime desc"
+
+	db := GetDB()
+	defer CloseDB(db)
+	for column, value := range searchMap {
+		switch column {
+		case "rule_name":
+			db = makeLike(value, column, db)
+		default:
+			db = db.Where(column, value)
+		}
+	}
+	db = db.Model(r)
+	//统计满足条件的总记录数
+	var total int64
+	db.Count(&total)
+	//获取分页查询结果
+	if rowsPerPage > 0 && page > 0 {
+		db = db.Offset((page - 1) * rowsPerPage).Limit(rowsPerPage)
+	}
+	db.Order(orderBy).Find(&results)
+
+	return results, int(total)
+}
+
+// GetsEnabledByWorkspace 获取工作空间中启用的告警规则
+func (r *AlertRule) GetsEnabledByWorkspace() (results []AlertRule) {
+	db := GetDB()
+	defer CloseDB(db)
+
+	db.Where("workspace_id", r.WorkspaceId).Where("state", "enable").Order("id").Find(&results)
+	return
+}
+
+// AlertRecord 告警规则已触发的记录，用于告警去重
+type AlertRecord struct {
+	Id             int       `gorm:"primaryKey"`
+	RuleId         int       `gorm:"column:rule_id;not null;index:fk_alert_record_rule_id"`
+	Target         string    `gorm:"column:target;size:200;not null"`
+	Content        string    `gorm:"column:content;size:4000"`
+	Hash           string    `gorm:"column:hash;size:32;not null;uniqueIndex:index_alert_record_hash"`
+	CreateDatetime time.Time `gorm:"column:create_datetime;not null"`
+}
+
+// TableName 设置数据库关联的表名
+func (*AlertRecord) TableName() string {
+	return "alert_record"
+}
+
+// Add 插入一条新的记录
+func (r *AlertRecord) Add() (success bool) {
+	r.CreateDatetime = time.Now()
+	r.Content = truncateContent(r.Content, AttrContentSize)
+
+	db := GetDB()
+	defer CloseDB(db)
+	if result := db.Create(r); result.RowsAffected > 0 {
+		return true
+	} else {
+		return false
+	}
+}
+
+// GetByHash 根据hash查询记录
+func (r *AlertRecord) GetByHash() (success bool) {
+	db := GetDB()
+	defer CloseDB(db)
+
+	if result := db.Where("hash", r.Hash).First(r); result.RowsAffected > 0 {
+		return true
+	} else {
+		return false
+	}
+}
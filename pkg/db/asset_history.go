@@ -0,0 +1,123 @@
+package db
+
+import (
+	"time"
+)
+
+const (
+	AssetTypeIP     = "ip"
+	AssetTypeDomain = "domain"
+)
+
+const (
+	HistoryItemIP         = "ip"
+	HistoryItemPort       = "port"
+	HistoryItemPortAttr   = "port_attr"
+	HistoryItemDomain     = "domain"
+	HistoryItemDomainAttr = "domain_attr"
+)
+
+const (
+	HistoryActionAdd    = "add"
+	HistoryActionChange = "change"
+	HistoryActionRemove = "remove"
+)
+
+// AssetHistory 资产变更记录，只追加不修改
+type AssetHistory struct {
+	Id             int       `gorm:"primaryKey"`
+	WorkspaceId    int       `gorm:"column:workspace_id;not null;index:index_asset_history_asset"`
+	AssetType      string    `gorm:"column:asset_type;size:20;not null;index:index_asset_history_asset"`
+	AssetName      string    `gorm:"column:asset_name;size:128;not null;index:index_asset_history_asset"`
+	Item           string    `gorm:"column:item;size:20;not null"`
+	Port           int       `gorm:"column:port"`
+	Action         string    `gorm:"column:action;size:20;not null"`
+	Field          string    `gorm:"column:field;size:40"`
+	OldValue       string    `gorm:"column:old_value;size:4000"`
+	NewValue       string    `gorm:"column:new_value;size:4000"`
+	MainTaskId     string    `gorm:"column:main_id;size:36"`
+	CreateDatetime time.Time `gorm:"column:create_datetime;not null;index:index_asset_history_create_datetime"`
+}
+
+// TableName 设置数据库关联的表名
+func (*AssetHistory) TableName() string {
+	return "asset_history"
+}
+
+// Add 插入一条新的记录
+func (h *AssetHistory) Add() (success bool) {
+	h.CreateDatetime = time.Now()
+	h.OldValue = truncateContent(h.OldValue, AttrContentSize)
+	h.NewValue = truncateContent(h.NewValue, AttrContentSize)
+
+	db := GetDB()
+	defer CloseDB(db)
+	if result := db.Create(h); result.RowsAffected > 0 {
+		return true
+	} else {
+		return false
+	}
+}
+
+// GetsByAsset 获取一个资产的变更记录，按时间倒序排列
+func (h *AssetHistory) GetsByAsset(limit int) (results []AssetHistory) {
+	db := GetDB()
+	defer CloseDB(db)
+
+	db = db.Where("workspace_id", h.WorkspaceId).Where("asset_type", h.AssetType).Where("asset_name", h.AssetName)
+	if limit > 0 {
+		db = db.Limit(limit)
+	}
+	db.Order("create_datetime desc,id desc").Find(&results)
+	return
+}
+
+// GetLatestByPort 获取一个端口最近的一条端口变更记录
+func (h *AssetHistory) GetLatestByPort() (success bool) {
+	db := GetDB()
+	defer CloseDB(db)
+
+	if result := db.Where("workspace_id", h.WorkspaceId).Where("asset_type", h.AssetType).Where("asset_name", h.AssetName).
+		Where("item", HistoryItemPort).Where("port", h.Port).Order("create_datetime desc,id desc").First(h); result.RowsAffected > 0 {
+		return true
+	} else {
+		return false
+	}
+}
+
+// GetLatestByDomain 获取一个域名最近的一条域名变更记录
+func (h *AssetHistory) GetLatestByDomain() (success bool) {
+	db := GetDB()
+	defer CloseDB(db)
+
+	if result := db.Where("workspace_id", h.WorkspaceId).Where("asset_type", AssetTypeDomain).Where("asset_name", h.AssetName).
+		Where("item", HistoryItemDomain).Order("create_datetime desc,id desc").First(h); result.RowsAffected > 0 {
+		return true
+	} else {
+		return false
+	}
+}
+
+// GetsByMainTaskAndDatetime 获取任务在指定时间范围内(start,end]记录的变更
+func (h *AssetHistory) GetsByMainTaskAndDatetime(start, end time.Time) (results []AssetHistory) {
+	db := GetDB()
+	defer CloseDB(db)
+
+	db.Where("workspace_id", h.WorkspaceId).Where("main_id", h.MainTaskId).Where("create_datetime > ? and create_datetime <= ?", start, end).
+		Order("asset_type,asset_name,create_datetime,id").Find(&results)
+	return
+}
+
+// GetsNewByMainTask 获取任务新增资产（IP、端口、域名）的变更记录
+func (h *AssetHistory) GetsNewByMainTask(limit int) (results []AssetHistory) {
+	db := GetDB()
+	defer CloseDB(db)
+
+	db = db.Where("main_id", h.MainTaskId).Where("action", HistoryActionAdd).
+		Where("item in ?", []string{HistoryItemIP, HistoryItemPort, HistoryItemDomain})
+	if limit > 0 {
+		db = db.Limit(limit)
+	}
+	db.Order("id").Find(&results)
+	return
+}
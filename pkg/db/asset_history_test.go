@@ -0,0 +1,19 @@
+package db
+
+import (
+	"strings"
+	"testing"
+	"unicode/utf8"
+)
+
+func TestAssetHistory_AddTruncate(t *testing.T) {
+	useSQLiteMemoryDB(t)
+	h := AssetHistory{WorkspaceId: 1, AssetType: AssetTypeIP, AssetName: "192.168.1.1", Item: HistoryItemPortAttr, Action: HistoryActionAdd,
+		NewValue: strings.Repeat("标题", AttrContentSize)}
+	if !h.Add() {
+		t.Fatal("add asset history fail")
+	}
+	if !utf8.ValidString(h.NewValue) || utf8.RuneCountInString(h.NewValue) != AttrContentSize {
+		t.Errorf("truncate new value:%d", utf8.RuneCountInString(h.NewValue))
+	}
+}
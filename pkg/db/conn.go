@@ -1,10 +1,8 @@
 package db
 
 import (
-	"fmt"
 	"github.com/hanc00l/nemo_go/pkg/conf"
 	"github.com/hanc00l/nemo_go/pkg/logging"
-	"gorm.io/driver/mysql"
 	"gorm.io/gorm"
 	"gorm.io/gorm/logger"
 	"time"
@@ -40,10 +38,7 @@ func GetDB() *gorm.DB {
 
 func getDB() *gorm.DB {
 	database := conf.GlobalServerConfig().Database
-	dsn := fmt.Sprintf("%s:%s@tcp(%s:%d)/%s?charset=utf8mb4&parseTime=True&loc=Local",
-		database.Username, database.Password, database.Host, database.Port, database.Dbname)
-
-	db, err := gorm.Open(mysql.Open(dsn), &gorm.Config{})
+	db, err := gorm.Open(getDialector(database), &gorm.Config{})
 	if err != nil {
 		logging.RuntimeLog.Error(err.Error())
 		return nil
@@ -58,6 +53,10 @@ func getDB() *gorm.DB {
 	sqlDB.SetMaxIdleConns(10)
 	// SetMaxOpenConns sets the maximum number of open connections to the database.
 	sqlDB.SetMaxOpenConns(100)
+	//sqlite只支持单个写入连接，多连接并发写入时会出现database is locked
+	if GetDriver() == DriverSQLite {
+		sqlDB.SetMaxOpenConns(1)
+	}
 	// SetConnMaxLifetime sets the maximum amount of time a connection may be reused.
 	sqlDB.SetConnMaxLifetime(time.Hour)
 
@@ -0,0 +1,81 @@
+package db
+
+import (
+	"fmt"
+	"github.com/glebarez/sqlite"
+	"github.com/hanc00l/nemo_go/pkg/conf"
+	"gorm.io/driver/mysql"
+	"gorm.io/driver/postgres"
+	"gorm.io/gorm"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+const (
+	DriverMySQL    = "mysql"
+	DriverSQLite   = "sqlite"
+	DriverPostgres = "postgres"
+
+	SQLiteMemory = ":memory:"
+)
+
+// GetDriver 获取配置的数据库类型，未配置时默认为mysql
+func GetDriver() string {
+	switch strings.ToLower(conf.GlobalServerConfig().Database.Driver) {
+	case DriverSQLite, "sqlite3":
+		return DriverSQLite
+	case DriverPostgres, "postgresql", "pgsql":
+		return DriverPostgres
+	default:
+		return DriverMySQL
+	}
+}
+
+// getDialector 根据数据库类型生成gorm的连接驱动
+func getDialector(database conf.Database) gorm.Dialector {
+	switch GetDriver() {
+	case DriverSQLite:
+		//sqlite使用name作为数据库文件，相对路径以nemo的根目录为基准；:memory:为内存数据库（用于测试）
+		dbFile := database.Dbname
+		if dbFile == SQLiteMemory {
+			dbFile = "file::memory:"
+		} else if !filepath.IsAbs(dbFile) {
+			dbFile = filepath.Join(conf.GetRootPath(), dbFile)
+		}
+		dsn := fmt.Sprintf("%s?_pragma=foreign_keys(1)&_pragma=busy_timeout(10000)&_pragma=journal_mode(WAL)", dbFile)
+		return sqlite.Open(dsn)
+	case DriverPostgres:
+		dsn := fmt.Sprintf("host=%s port=%d user=%s password=%s dbname=%s sslmode=disable TimeZone=Local",
+			database.Host, database.Port, database.Username, database.Password, database.Dbname)
+		return postgres.Open(dsn)
+	default:
+		dsn := fmt.Sprintf("%s:%s@tcp(%s:%d)/%s?charset=utf8mb4&parseTime=True&loc=Local",
+			database.Username, database.Password, database.Host, database.Port, database.Dbname)
+		return mysql.Open(dsn)
+	}
+}
+
+// likeOperator 模糊查询的操作符：mysql与sqlite的like默认不区分大小写，postgres需使用ilike保持一致
+func likeOperator() string {
+	if GetDriver() == DriverPostgres {
+		return "ilike"
+	}
+	return "like"
+}
+
+// makeDateDelta 查询指定天数内的记录
+func makeDateDelta(days int, columnName string, db *gorm.DB) *gorm.DB {
+	daysToHour := 24 * days
+	dayDelta, err := time.ParseDuration(fmt.Sprintf("-%dh", daysToHour))
+	if err == nil {
+		now := time.Now()
+		return db.Where(fmt.Sprintf("%s between ? and ?", columnName), now.Add(dayDelta), now)
+	}
+	return db
+}
+
+// makeLike 模糊查询包含指定内容的记录
+func makeLike(value interface{}, columnName string, db *gorm.DB) *gorm.DB {
+	return db.Where(fmt.Sprintf("%s %s ?", columnName, likeOperator()), fmt.Sprintf("%%%s%%", value))
+}
@@ -0,0 +1,48 @@
+package db
+
+import (
+	"github.com/hanc00l/nemo_go/pkg/conf"
+	"os"
+	"testing"
+)
+
+// useSQLiteMemoryDB 使用sqlite内存数据库并完成数据库迁移（测试在nemo的根目录下读取配置文件）
+func useSQLiteMemoryDB(t *testing.T) {
+	if err := os.Chdir("../.."); err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() {
+		os.Chdir("pkg/db")
+		globalDB = nil
+	})
+	conf.EnableStandalone(SQLiteMemory)
+	globalDB = nil
+	if err := Migrate(); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestSQLiteMigrateAndLike(t *testing.T) {
+	useSQLiteMemoryDB(t)
+	if GetDriver() != DriverSQLite || likeOperator() != "like" {
+		t.Fatalf("driver:%s,like:%s", GetDriver(), likeOperator())
+	}
+	if pending, err := GetPendingMigrations(); err != nil || len(pending) > 0 {
+		t.Fatalf("pending migrations:%v,err:%v", pending, err)
+	}
+	workspace := Workspace{WorkspaceName: "test", State: "enable"}
+	if !workspace.Add() {
+		t.Fatal("add workspace fail")
+	}
+	for _, name := range []string{"www.example.com", "mail.example.com", "www.example.org"} {
+		domain := Domain{DomainName: name, WorkspaceId: workspace.Id}
+		if !domain.Add() {
+			t.Fatalf("add domain %s fail", name)
+		}
+	}
+	// sqlite的like与mysql一样不区分大小写
+	domain := Domain{}
+	if count := domain.Count(map[string]interface{}{"domain": "EXAMPLE.COM"}); count != 2 {
+		t.Errorf("like query count:%d", count)
+	}
+}
@@ -2,6 +2,7 @@ package db
 
 import (
 	"fmt"
+	"github.com/hanc00l/nemo_go/pkg/utils"
 	"gorm.io/gorm"
 	"strings"
 	"time"
@@ -9,12 +10,12 @@ import (
 
 type Domain struct {
 	Id             int       `gorm:"primaryKey"`
-	DomainName     string    `gorm:"column:domain"`
-	OrgId          *int      `gorm:"column:org_id"` //使用指针可以处理数据库的NULL（go中传递nil）
-	WorkspaceId    int       `gorm:"column:workspace_id"`
-	PinIndex       int       `gorm:"column:pin_index"`
-	CreateDatetime time.Time `gorm:"column:create_datetime"`
-	UpdateDatetime time.Time `gorm:"column:update_datetime"`
+	DomainName     string    `gorm:"column:domain;size:100;not null"`
+	OrgId          *int      `gorm:"column:org_id;index:fk_domain_org_id"` //使用指针可以处理数据库的NULL（go中传递nil）
+	WorkspaceId    int       `gorm:"column:workspace_id;not null;index:fk_domain_workspace_id"`
+	PinIndex       int       `gorm:"column:pin_index;not null"`
+	CreateDatetime time.Time `gorm:"column:create_datetime;not null"`
+	UpdateDatetime time.Time `gorm:"column:update_datetime;not null"`
 }
 
 // TableName 设置数据库关联的表名
@@ -104,7 +105,7 @@ func (domain *Domain) makeWhere(searchMap map[string]interface{}) *gorm.DB {
 		case "domain":
 			db = makeLike(value, column, db)
 		case "ip":
-			domainAttr := GetDB().Model(&DomainAttr{}).Select("r_id").Distinct("r_id").Where("tag", "A").Where("content", value)
+			domainAttr := GetDB().Model(&DomainAttr{}).Select("r_id").Distinct("r_id").Where("tag in ?", []string{"A", "AAAA"}).Where("content", utils.FormatIPV6(fmt.Sprintf("%v", value)))
 			db = db.Where("id in (?)", domainAttr)
 			CloseDB(domainAttr)
 		case "color_tag":
@@ -112,7 +113,7 @@ func (domain *Domain) makeWhere(searchMap map[string]interface{}) *gorm.DB {
 			db = db.Where("id in (?)", colorTag)
 			CloseDB(colorTag)
 		case "memo_content":
-			memoContent := GetDB().Model(&DomainMemo{}).Select("r_id").Where("content like ?", fmt.Sprintf("%%%s%%", value))
+			memoContent := makeLike(value, "content", GetDB().Model(&DomainMemo{}).Select("r_id"))
 			db = db.Where("id in (?)", memoContent)
 			CloseDB(memoContent)
 		case "date_delta":
@@ -120,11 +121,11 @@ func (domain *Domain) makeWhere(searchMap map[string]interface{}) *gorm.DB {
 		case "create_date_delta":
 			db = makeDateDelta(value.(int), "create_datetime", db)
 		case "content":
-			domainAttr := GetDB().Model(&DomainAttr{}).Select("r_id").Where("content like ?", fmt.Sprintf("%%%s%%", value))
+			domainAttr := makeLike(value, "content", GetDB().Model(&DomainAttr{}).Select("r_id"))
 			db = db.Where("id in (?)", domainAttr)
 			CloseDB(domainAttr)
 		case "domain_http":
-			http := GetDB().Model(&DomainHttp{}).Select("r_id").Where("content like ?", fmt.Sprintf("%%%s%%", value))
+			http := makeLike(value, "content", GetDB().Model(&DomainHttp{}).Select("r_id"))
 			db = db.Where("id in (?)", http)
 			CloseDB(http)
 		default:
@@ -178,19 +179,6 @@ func (domain *Domain) GetsForBlackListDomain(blackDomain string, workspaceId int
 	db := GetDB()
 	defer CloseDB(db)
 	//sql语句为 select * from domain where domain like "%.qq.com" or domain="qq.com"，只匹配子域名
-	db.Where("workspace_id", workspaceId).Where("domain like ? or domain = ?", fmt.Sprintf("%%%s", blackDomain), strings.TrimLeft(blackDomain, ".")).Model(domain).Find(&results)
+	db.Where("workspace_id", workspaceId).Where(fmt.Sprintf("domain %s ? or domain = ?", likeOperator()), fmt.Sprintf("%%%s", blackDomain), strings.TrimLeft(blackDomain, ".")).Model(domain).Find(&results)
 	return
 }
-
-func makeDateDelta(days int, columnName string, db *gorm.DB) *gorm.DB {
-	daysToHour := 24 * days
-	dayDelta, err := time.ParseDuration(fmt.Sprintf("-%dh", daysToHour))
-	if err == nil {
-		return db.Where(fmt.Sprintf("%s between ? and ?", columnName), time.Now().Add(dayDelta), time.Now())
-	}
-	return db
-}
-
-func makeLike(value interface{}, columnName string, db *gorm.DB) *gorm.DB {
-	return db.Where(fmt.Sprintf("%s like ?", columnName), fmt.Sprintf("%%%s%%", value))
-}
@@ -10,13 +10,13 @@ const AttrContentSize = 4000
 
 type DomainAttr struct {
 	Id             int       `gorm:"primaryKey"`
-	RelatedId      int       `gorm:"column:r_id"`
-	Source         string    `gorm:"column:source"`
-	Tag            string    `gorm:"column:tag"`
-	Content        string    `gorm:"column:content"`
-	Hash           string    `gorm:"column:hash"`
-	CreateDatetime time.Time `gorm:"column:create_datetime"`
-	UpdateDatetime time.Time `gorm:"column:update_datetime"`
+	RelatedId      int       `gorm:"column:r_id;not null;index:index_domain_attr_ip_id"`
+	Source         string    `gorm:"column:source;size:40"`
+	Tag            string    `gorm:"column:tag;size:40;not null"`
+	Content        string    `gorm:"column:content;size:4000"`
+	Hash           string    `gorm:"column:hash;size:32;uniqueIndex:index_domain_attr_hash"`
+	CreateDatetime time.Time `gorm:"column:create_datetime;not null"`
+	UpdateDatetime time.Time `gorm:"column:update_datetime;not null"`
 }
 
 func (*DomainAttr) TableName() string {
@@ -51,6 +51,18 @@ func (domainAttr *DomainAttr) GetByDomainAttr() (success bool) {
 	}
 }
 
+// GetLatestBySourceAndTag 获取域名指定来源和属性的最近一条记录
+func (domainAttr *DomainAttr) GetLatestBySourceAndTag() (success bool) {
+	db := GetDB()
+	defer CloseDB(db)
+
+	if result := db.Where("r_id", domainAttr.RelatedId).Where("source", domainAttr.Source).Where("tag", domainAttr.Tag).Order("update_datetime desc").First(domainAttr); result.RowsAffected > 0 {
+		return true
+	} else {
+		return false
+	}
+}
+
 // GetsByRelatedId 根据查询条件执行数据库查询操作，返回查询结果数组
 func (domainAttr *DomainAttr) GetsByRelatedId() (results []DomainAttr) {
 	db := GetDB()
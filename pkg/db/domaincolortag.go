@@ -4,8 +4,8 @@ import "time"
 
 type DomainColorTag struct {
 	Id             int       `gorm:"primaryKey"`
-	RelatedId      int       `gorm:"column:r_id"`
-	Color          string    `gorm:"column:color"`
+	RelatedId      int       `gorm:"column:r_id;not null;uniqueIndex:fk_domain_color_tag_rid_unique"`
+	Color          string    `gorm:"column:color;size:20;not null"`
 	CreateDatetime time.Time `gorm:"column:create_datetime"`
 	UpdateDatetime time.Time `gorm:"column:update_datetime"`
 }
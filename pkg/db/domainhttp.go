@@ -8,13 +8,13 @@ const HttpBodyContentSize = 16000 // 由于utf-8-mb4的mysql每行sizew不能超
 
 type DomainHttp struct {
 	Id             int       `gorm:"primaryKey"`
-	RelatedId      int       `gorm:"column:r_id"`
-	Port           int       `gorm:"column:port"`
-	Source         string    `gorm:"column:source"`
-	Tag            string    `gorm:"column:tag"`
-	Content        string    `gorm:"column:content"`
-	CreateDatetime time.Time `gorm:"column:create_datetime"`
-	UpdateDatetime time.Time `gorm:"column:update_datetime"`
+	RelatedId      int       `gorm:"column:r_id;not null;index:fk_domain_http_rid"`
+	Port           int       `gorm:"column:port;not null"`
+	Source         string    `gorm:"column:source;size:40;not null"`
+	Tag            string    `gorm:"column:tag;size:40;not null"`
+	Content        string    `gorm:"column:content;size:16000;not null"`
+	CreateDatetime time.Time `gorm:"column:create_datetime;not null"`
+	UpdateDatetime time.Time `gorm:"column:update_datetime;not null"`
 }
 
 func (*DomainHttp) TableName() string {
@@ -4,8 +4,8 @@ import "time"
 
 type DomainMemo struct {
 	Id             int       `gorm:"primaryKey"`
-	RelatedId      int       `gorm:"column:r_id"`
-	Content        string    `gorm:"column:content"`
+	RelatedId      int       `gorm:"column:r_id;not null;uniqueIndex:fk_domain_memo_rid_unique"`
+	Content        string    `gorm:"column:content;size:10000"`
 	CreateDatetime time.Time `gorm:"column:create_datetime"`
 	UpdateDatetime time.Time `gorm:"column:update_datetime"`
 }
@@ -1,7 +1,6 @@
 package db
 
 import (
-	"fmt"
 	"github.com/hanc00l/nemo_go/pkg/utils"
 	"gorm.io/gorm"
 	"net"
@@ -11,15 +10,16 @@ import (
 
 type Ip struct {
 	Id             int       `gorm:"primaryKey"`
-	IpName         string    `gorm:"column:ip"`
-	IpInt          int       `gorm:"column:ip_int"`
-	OrgId          *int      `gorm:"column:org_id"` //使用指针可以处理数据库的NULL（go中传递nil）
-	Location       string    `gorm:"column:location"`
-	Status         string    `gorm:"column:status"`
-	WorkspaceId    int       `gorm:"column:workspace_id"`
-	PinIndex       int       `gorm:"column:pin_index"`
-	CreateDatetime time.Time `gorm:"column:create_datetime"`
-	UpdateDatetime time.Time `gorm:"column:update_datetime"`
+	IpName         string    `gorm:"column:ip;size:128;not null"`
+	IpInt          int       `gorm:"column:ip_int;not null"`
+	IpSort         string    `gorm:"column:ip_sort;size:32;index:index_ip_ip_sort"`
+	OrgId          *int      `gorm:"column:org_id;index:index_ip_org_id"` //使用指针可以处理数据库的NULL（go中传递nil）
+	Location       string    `gorm:"column:location;size:200"`
+	Status         string    `gorm:"column:status;size:20"`
+	WorkspaceId    int       `gorm:"column:workspace_id;not null;index:fk_ip_workspace_id"`
+	PinIndex       int       `gorm:"column:pin_index;not null"`
+	CreateDatetime time.Time `gorm:"column:create_datetime;not null"`
+	UpdateDatetime time.Time `gorm:"column:update_datetime;not null"`
 }
 
 // TableName 设置数据库关联的表名
@@ -43,7 +43,12 @@ func (ip *Ip) Get() (success bool) {
 func (ip *Ip) Add() (success bool) {
 	ip.CreateDatetime = time.Now()
 	ip.UpdateDatetime = time.Now()
-	ip.IpInt = int(utils.IPToUInt32(ip.IpName))
+	if utils.CheckIPV6(ip.IpName) {
+		ip.IpName = utils.FormatIPV6(ip.IpName)
+	} else {
+		ip.IpInt = int(utils.IPToUInt32(ip.IpName))
+	}
+	ip.IpSort = utils.IPToSortString(ip.IpName)
 
 	db := GetDB()
 	defer CloseDB(db)
@@ -62,13 +67,22 @@ func (ip *Ip) GetByIp() (success bool) {
 	if ip.WorkspaceId > 0 {
 		db = db.Where("workspace_id", ip.WorkspaceId)
 	}
-	if result := db.Where("ip", ip.IpName).First(ip); result.RowsAffected > 0 {
+	if result := db.Where("ip", utils.FormatIPV6(ip.IpName)).First(ip); result.RowsAffected > 0 {
 		return true
 	} else {
 		return false
 	}
 }
 
+// GetsBySortRange 获取工作空间中ip_sort在[start,end]范围内的IP
+func (ip *Ip) GetsBySortRange(start, end string) (results []Ip) {
+	db := GetDB()
+	defer CloseDB(db)
+
+	db.Where("workspace_id", ip.WorkspaceId).Where("ip_sort between ? and ?", start, end).Find(&results)
+	return
+}
+
 // Update 更新指定ID的一条记录，列名和内容位于map中
 func (ip *Ip) Update(updateMap map[string]interface{}) (success bool) {
 	updateMap["update_datetime"] = time.Now()
@@ -104,7 +118,7 @@ func (ip *Ip) Count(searchMap map[string]interface{}) (count int) {
 
 // Gets 根据指定的条件，查询满足要求的记录
 func (ip *Ip) Gets(searchMap map[string]interface{}, page, rowsPerPage int, orderByDate bool) (results []Ip, count int) {
-	orderByField := "ip_int"
+	orderByField := "ip_sort,ip_int"
 	if orderByDate {
 		orderByField = "update_datetime desc"
 	}
@@ -155,15 +169,19 @@ func (ip *Ip) makeWhere(searchMap map[string]interface{}) *gorm.DB {
 		case "location":
 			db = makeLike(value, column, db)
 		case "domain":
-			dbDomains := GetDB().Model(&Domain{}).Select("id").Where("domain like ?", fmt.Sprintf("%%%s%%", value))
-			dbContent := GetDB().Model(&DomainAttr{}).Select("content").Where("tag", "A").Where("r_id in (?)", dbDomains)
+			dbDomains := makeLike(value, "domain", GetDB().Model(&Domain{}).Select("id"))
+			dbContent := GetDB().Model(&DomainAttr{}).Select("content").Where("tag in ?", []string{"A", "AAAA"}).Where("r_id in (?)", dbDomains)
 			db = db.Where("ip in (?)", dbContent)
 			CloseDB(dbDomains)
 			CloseDB(dbContent)
 		case "ip":
 			_ip, _ipNet, err := net.ParseCIDR(value.(string))
 			if err != nil {
-				db = db.Where("ip", value)
+				db = db.Where("ip", utils.FormatIPV6(value.(string)))
+			} else if _ip.To4() == nil {
+				// IPv6子网使用ip_sort进行范围查询
+				_ipStart, _ipEnd, _ := utils.IPSubnetToSortRange(_ipNet.String())
+				db = db.Where("ip_sort between ? and ?", _ipStart, _ipEnd)
 			} else {
 				ones, bits := _ipNet.Mask.Size()
 				_ipStart := utils.IPToUInt32(_ip.String())
@@ -183,7 +201,7 @@ func (ip *Ip) makeWhere(searchMap map[string]interface{}) *gorm.DB {
 			db = db.Where("id in (?)", portStatus)
 			CloseDB(portStatus)
 		case "content":
-			portAttr := GetDB().Model(&PortAttr{}).Select("r_id").Where("content like ?", fmt.Sprintf("%%%s%%", value))
+			portAttr := makeLike(value, "content", GetDB().Model(&PortAttr{}).Select("r_id"))
 			port := GetDB().Model(&Port{}).Select("ip_id").Where("id in (?)", portAttr)
 			db = db.Where("id in (?)", port)
 			CloseDB(portAttr)
@@ -193,21 +211,17 @@ func (ip *Ip) makeWhere(searchMap map[string]interface{}) *gorm.DB {
 			db = db.Where("id in (?)", colorTag)
 			CloseDB(colorTag)
 		case "memo_content":
-			memoContent := GetDB().Model(&IpMemo{}).Select("r_id").Where("content like ?", fmt.Sprintf("%%%s%%", value))
+			memoContent := makeLike(value, "content", GetDB().Model(&IpMemo{}).Select("r_id"))
 			db = db.Where("id in (?)", memoContent)
 			CloseDB(memoContent)
 		case "date_delta":
 			db = makeDateDelta(value.(int), "update_datetime", db)
 		case "create_date_delta":
-			daysToHour := 24 * value.(int)
-			dayDelta, err := time.ParseDuration(fmt.Sprintf("-%dh", daysToHour))
-			if err == nil {
-				dbPorts := GetDB().Model(&Port{}).Select("ip_id").Distinct("ip_id").Where("create_datetime between ? and ?", time.Now().Add(dayDelta), time.Now())
-				db = db.Where("id in (?)", dbPorts)
-				CloseDB(dbPorts)
-			}
+			dbPorts := makeDateDelta(value.(int), "create_datetime", GetDB().Model(&Port{}).Select("ip_id").Distinct("ip_id"))
+			db = db.Where("id in (?)", dbPorts)
+			CloseDB(dbPorts)
 		case "ip_http":
-			http := GetDB().Model(&IpHttp{}).Select("r_id").Where("content like ?", fmt.Sprintf("%%%s%%", value))
+			http := makeLike(value, "content", GetDB().Model(&IpHttp{}).Select("r_id"))
 			port := GetDB().Model(&Port{}).Select("ip_id").Where("id in (?)", http)
 			db = db.Where("id in (?)", port)
 			CloseDB(http)
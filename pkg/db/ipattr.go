@@ -8,13 +8,13 @@ import (
 
 type IpAttr struct {
 	Id             int       `gorm:"primaryKey"`
-	RelatedId      int       `gorm:"column:r_id"`
-	Source         string    `gorm:"column:source"`
-	Tag            string    `gorm:"column:tag"`
-	Content        string    `gorm:"column:content"`
-	Hash           string    `gorm:"column:hash"`
-	CreateDatetime time.Time `gorm:"column:create_datetime"`
-	UpdateDatetime time.Time `gorm:"column:update_datetime"`
+	RelatedId      int       `gorm:"column:r_id;not null;index:index_ip_attr_ip_id"`
+	Source         string    `gorm:"column:source;size:40"`
+	Tag            string    `gorm:"column:tag;size:40;not null"`
+	Content        string    `gorm:"column:content;size:4000"`
+	Hash           string    `gorm:"column:hash;size:32;uniqueIndex:index_ip_attr_hash"`
+	CreateDatetime time.Time `gorm:"column:create_datetime;not null"`
+	UpdateDatetime time.Time `gorm:"column:update_datetime;not null"`
 }
 
 func (*IpAttr) TableName() string {
@@ -6,8 +6,8 @@ import (
 
 type IpColorTag struct {
 	Id             int       `gorm:"primaryKey"`
-	RelatedId      int       `gorm:"column:r_id"`
-	Color          string    `gorm:"column:color"`
+	RelatedId      int       `gorm:"column:r_id;not null;uniqueIndex:fk_ip_color_tag_rid_unique"`
+	Color          string    `gorm:"column:color;size:20;not null"`
 	CreateDatetime time.Time `gorm:"column:create_datetime"`
 	UpdateDatetime time.Time `gorm:"column:update_datetime"`
 }
@@ -6,12 +6,12 @@ import (
 
 type IpHttp struct {
 	Id             int       `gorm:"primaryKey"`
-	RelatedId      int       `gorm:"column:r_id"`
-	Source         string    `gorm:"column:source"`
-	Tag            string    `gorm:"column:tag"`
-	Content        string    `gorm:"column:content"`
-	CreateDatetime time.Time `gorm:"column:create_datetime"`
-	UpdateDatetime time.Time `gorm:"column:update_datetime"`
+	RelatedId      int       `gorm:"column:r_id;not null;index:fk_ip_http_rid"`
+	Source         string    `gorm:"column:source;size:40;not null"`
+	Tag            string    `gorm:"column:tag;size:40;not null"`
+	Content        string    `gorm:"column:content;size:16000;not null"`
+	CreateDatetime time.Time `gorm:"column:create_datetime;not null"`
+	UpdateDatetime time.Time `gorm:"column:update_datetime;not null"`
 }
 
 func (*IpHttp) TableName() string {
@@ -4,8 +4,8 @@ import "time"
 
 type IpMemo struct {
 	Id             int       `gorm:"primaryKey"`
-	RelatedId      int       `gorm:"column:r_id"`
-	Content        string    `gorm:"column:content"`
+	RelatedId      int       `gorm:"column:r_id;not null;uniqueIndex:fk_ip_memo_rid_unqie"`
+	Content        string    `gorm:"column:content;size:10000"`
 	CreateDatetime time.Time `gorm:"column:create_datetime"`
 	UpdateDatetime time.Time `gorm:"column:update_datetime"`
 }
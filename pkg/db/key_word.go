@@ -7,15 +7,15 @@ import (
 
 type KeyWord struct {
 	Id             int       `gorm:"primaryKey"`
-	OrgId          int       `gorm:"column:org_id"`
-	KeyWord        string    `gorm:"column:key_word"`
-	Engine         string    `gorm:"column:engine"`
-	SearchTime     string    `gorm:"column:search_time"`
-	ExcludeWords   string    `gorm:"column:exclude_words"`
-	CheckMod       string    `gorm:"column:check_mod"`
-	IsDelete       bool      `gorm:"column:is_delete"`
+	OrgId          int       `gorm:"column:org_id;not null"`
+	KeyWord        string    `gorm:"column:key_word;size:511;not null"`
+	Engine         string    `gorm:"column:engine;size:40;not null"`
+	SearchTime     string    `gorm:"column:search_time;size:63"`
+	ExcludeWords   string    `gorm:"column:exclude_words;size:2047"`
+	CheckMod       string    `gorm:"column:check_mod;size:255"`
+	IsDelete       bool      `gorm:"column:is_delete;not null"`
 	Count          int       `gorm:"column:count"`
-	WorkspaceId    int       `gorm:"column:workspace_id"`
+	WorkspaceId    int       `gorm:"column:workspace_id;not null;index:fk_key_word_workspace_id"`
 	CreateDatetime time.Time `gorm:"column:create_datetime"`
 	UpdateDatetime time.Time `gorm:"column:update_datetime"`
 }
@@ -0,0 +1,447 @@
+package db
+
+import (
+	"fmt"
+	"github.com/google/uuid"
+	"github.com/hanc00l/nemo_go/pkg/logging"
+	"github.com/hanc00l/nemo_go/pkg/utils"
+	"gorm.io/gorm"
+	"time"
+)
+
+// SchemaMigration 已执行的数据库迁移版本记录
+type SchemaMigration struct {
+	Version        int       `gorm:"primaryKey;autoIncrement:false"`
+	Name           string    `gorm:"column:name;size:200;not null"`
+	CreateDatetime time.Time `gorm:"column:create_datetime;not null"`
+}
+
+// TableName 设置数据库关联的表名
+func (*SchemaMigration) TableName() string {
+	return "schema_migration"
+}
+
+// Migration 一个数据库迁移版本，每个迁移必须是可重复执行的
+type Migration struct {
+	Version int
+	Name    string
+	migrate func(db *gorm.DB) error
+}
+
+// migrations 按版本号顺序执行的数据库迁移；发布后的版本不能修改，新的表结构变更只能追加新的版本
+var migrations = []Migration{
+	{Version: 1, Name: "create tables from models", migrate: func(db *gorm.DB) error {
+		return migrateModels(db, getMigrateModels()...)
+	}},
+	{Version: 2, Name: "init default workspace and user", migrate: initDefaultData},
+	{Version: 3, Name: "set default engine of key_word", migrate: migrateKeyWordEngine},
+	{Version: 4, Name: "fill ip_sort of ip", migrate: migrateIpSort},
+	{Version: 5, Name: "create asset_history", migrate: func(db *gorm.DB) error {
+		return migrateModels(db, &migrateAssetHistory{})
+	}},
+	{Version: 6, Name: "create alert_rule and alert_record", migrate: func(db *gorm.DB) error {
+		return migrateModels(db, &migrateAlertRule{}, &migrateAlertRecord{})
+	}},
+	{Version: 7, Name: "create notify_template", migrate: func(db *gorm.DB) error {
+		return migrateModels(db, &migrateNotifyTemplate{})
+	}},
+	{Version: 8, Name: "add notify_config to workspace", migrate: func(db *gorm.DB) error {
+		return migrateModels(db, &migrateWorkspace{})
+	}},
+	{Version: 9, Name: "create workflow and add workflow_step to task_run", migrate: func(db *gorm.DB) error {
+		return migrateModels(db, &migrateWorkflow{}, &migrateTaskRun{})
+	}},
+	{Version: 10, Name: "add attempt to task_run", migrate: func(db *gorm.DB) error {
+		return migrateModels(db, &migrateTaskRun{})
+	}},
+	{Version: 11, Name: "create task_main_result", migrate: func(db *gorm.DB) error {
+		return migrateModels(db, &TaskMainResult{})
+	}},
+	{Version: 12, Name: "add main_id index to task_run", migrate: func(db *gorm.DB) error {
+		return migrateModels(db, &migrateTaskRun{})
+	}},
+	{Version: 13, Name: "create target_set and target_set_entry", migrate: func(db *gorm.DB) error {
+		return migrateModels(db, &migrateTargetSet{}, &migrateTargetSetEntry{})
+	}},
+	{Version: 14, Name: "add scope_config to workspace", migrate: func(db *gorm.DB) error {
+		return migrateModels(db, &migrateWorkspace{})
+	}},
+	{Version: 15, Name: "add worker_tag to task_main and task_run", migrate: func(db *gorm.DB) error {
+		return migrateModels(db, &migrateTaskMain{}, &migrateTaskRun{})
+	}},
+	{Version: 16, Name: "create spool_replay", migrate: func(db *gorm.DB) error {
+		return migrateModels(db, &SpoolReplay{})
+	}},
+	{Version: 17, Name: "create worker_identity", migrate: func(db *gorm.DB) error {
+		return migrateModels(db, &WorkerIdentity{})
+	}},
+	{Version: 18, Name: "create worker_release and worker_release_pin", migrate: func(db *gorm.DB) error {
+		return migrateModels(db, &WorkerRelease{}, &WorkerReleasePin{})
+	}},
+	{Version: 19, Name: "add content_hash to task_main_result", migrate: migrateTaskMainResultHash},
+}
+
+// 以下结构体只用于生成数据库表结构：在数据模型的基础上声明外键，建表时生成与nemo.sql一致的级联删除约束
+
+type migrateWorkspace struct {
+	Workspace
+}
+
+type migrateUser struct {
+	User
+}
+
+type migrateUserWorkspace struct {
+	UserWorkspace
+	User      *migrateUser      `gorm:"foreignKey:UserId;constraint:OnDelete:CASCADE"`
+	Workspace *migrateWorkspace `gorm:"foreignKey:WorkspaceId;constraint:OnDelete:CASCADE"`
+}
+
+type migrateOrganization struct {
+	Organization
+	Workspace *migrateWorkspace `gorm:"foreignKey:WorkspaceId;constraint:OnDelete:CASCADE"`
+}
+
+type migrateIp struct {
+	Ip
+	Organization *migrateOrganization `gorm:"foreignKey:OrgId;constraint:OnUpdate:CASCADE,OnDelete:CASCADE"`
+	Workspace    *migrateWorkspace    `gorm:"foreignKey:WorkspaceId;constraint:OnDelete:CASCADE"`
+}
+
+type migrateIpAttr struct {
+	IpAttr
+	Ip *migrateIp `gorm:"foreignKey:RelatedId;constraint:OnUpdate:CASCADE,OnDelete:CASCADE"`
+}
+
+type migrateIpColorTag struct {
+	IpColorTag
+	Ip *migrateIp `gorm:"foreignKey:RelatedId;constraint:OnDelete:CASCADE"`
+}
+
+type migrateIpMemo struct {
+	IpMemo
+	Ip *migrateIp `gorm:"foreignKey:RelatedId;constraint:OnDelete:CASCADE"`
+}
+
+type migratePort struct {
+	Port
+	Ip *migrateIp `gorm:"foreignKey:IpId;constraint:OnUpdate:CASCADE,OnDelete:CASCADE"`
+}
+
+type migratePortAttr struct {
+	PortAttr
+	Port *migratePort `gorm:"foreignKey:RelatedId;constraint:OnUpdate:CASCADE,OnDelete:CASCADE"`
+}
+
+type migrateIpHttp struct {
+	IpHttp
+	Port *migratePort `gorm:"foreignKey:RelatedId;constraint:OnDelete:CASCADE"`
+}
+
+type migrateDomain struct {
+	Domain
+	Organization *migrateOrganization `gorm:"foreignKey:OrgId;constraint:OnUpdate:CASCADE,OnDelete:CASCADE"`
+	Workspace    *migrateWorkspace    `gorm:"foreignKey:WorkspaceId;constraint:OnDelete:CASCADE"`
+}
+
+type migrateDomainAttr struct {
+	DomainAttr
+	Domain *migrateDomain `gorm:"foreignKey:RelatedId;constraint:OnUpdate:CASCADE,OnDelete:CASCADE"`
+}
+
+type migrateDomainColorTag struct {
+	DomainColorTag
+	Domain *migrateDomain `gorm:"foreignKey:RelatedId;constraint:OnDelete:CASCADE"`
+}
+
+type migrateDomainMemo struct {
+	DomainMemo
+	Domain *migrateDomain `gorm:"foreignKey:RelatedId;constraint:OnDelete:CASCADE"`
+}
+
+type migrateDomainHttp struct {
+	DomainHttp
+	Domain *migrateDomain `gorm:"foreignKey:RelatedId;constraint:OnDelete:CASCADE"`
+}
+
+type migrateVulnerability struct {
+	Vulnerability
+	Workspace *migrateWorkspace `gorm:"foreignKey:WorkspaceId;constraint:OnDelete:CASCADE"`
+}
+
+type migrateKeyWord struct {
+	KeyWord
+	Workspace *migrateWorkspace `gorm:"foreignKey:WorkspaceId;constraint:OnDelete:CASCADE"`
+}
+
+type migrateTaskMain struct {
+	TaskMain
+	Workspace *migrateWorkspace `gorm:"foreignKey:WorkspaceId;constraint:OnDelete:CASCADE"`
+}
+
+type migrateTaskRun struct {
+	TaskRun
+	Workspace *migrateWorkspace `gorm:"foreignKey:WorkspaceId;constraint:OnDelete:CASCADE"`
+}
+
+type migrateTaskCron struct {
+	TaskCron
+	Workspace *migrateWorkspace `gorm:"foreignKey:WorkspaceId;constraint:OnDelete:CASCADE"`
+}
+
+type migrateRuntimeLog struct {
+	RuntimeLog
+}
+
+type migrateAssetHistory struct {
+	AssetHistory
+	Workspace *migrateWorkspace `gorm:"foreignKey:WorkspaceId;constraint:OnDelete:CASCADE"`
+}
+
+type migrateAlertRule struct {
+	AlertRule
+	Workspace *migrateWorkspace `gorm:"foreignKey:WorkspaceId;constraint:OnDelete:CASCADE"`
+}
+
+type migrateAlertRecord struct {
+	AlertRecord
+	AlertRule *migrateAlertRule `gorm:"foreignKey:RuleId;constraint:OnDelete:CASCADE"`
+}
+
+type migrateNotifyTemplate struct {
+	NotifyTemplate
+	Workspace *migrateWorkspace `gorm:"foreignKey:WorkspaceId;constraint:OnDelete:CASCADE"`
+}
+
+type migrateWorkflow struct {
+	Workflow
+	Workspace *migrateWorkspace `gorm:"foreignKey:WorkspaceId;constraint:OnDelete:CASCADE"`
+}
+
+type migrateTargetSet struct {
+	TargetSet
+	Workspace *migrateWorkspace `gorm:"foreignKey:WorkspaceId;constraint:OnDelete:CASCADE"`
+}
+
+type migrateTargetSetEntry struct {
+	TargetSetEntry
+	TargetSet *migrateTargetSet `gorm:"foreignKey:TargetSetId;constraint:OnDelete:CASCADE"`
+}
+
+// getMigrateModels 需要迁移的数据模型，被外键引用的表需要排在前面
+func getMigrateModels() []interface{} {
+	return []interface{}{
+		&migrateWorkspace{},
+		&migrateUser{},
+		&migrateUserWorkspace{},
+		&migrateOrganization{},
+		&migrateIp{},
+		&migrateIpAttr{},
+		&migrateIpColorTag{},
+		&migrateIpMemo{},
+		&migratePort{},
+		&migratePortAttr{},
+		&migrateIpHttp{},
+		&migrateDomain{},
+		&migrateDomainAttr{},
+		&migrateDomainColorTag{},
+		&migrateDomainMemo{},
+		&migrateDomainHttp{},
+		&migrateVulnerability{},
+		&migrateKeyWord{},
+		&migrateTaskMain{},
+		&migrateTaskRun{},
+		&migrateTaskCron{},
+		&migrateRuntimeLog{},
+	}
+}
+
+// GetPendingMigrations 获取尚未执行的数据库迁移
+func GetPendingMigrations() (pending []Migration, err error) {
+	db := GetDB()
+	defer CloseDB(db)
+
+	applied := make(map[int]struct{})
+	if db.Migrator().HasTable(&SchemaMigration{}) {
+		var results []SchemaMigration
+		if err = db.Find(&results).Error; err != nil {
+			return
+		}
+		for _, r := range results {
+			applied[r.Version] = struct{}{}
+		}
+	}
+	for _, m := range migrations {
+		if _, ok := applied[m.Version]; !ok {
+			pending = append(pending, m)
+		}
+	}
+	return
+}
+
+// Migrate 按版本顺序执行尚未执行的数据库迁移，并记录已执行的版本
+func Migrate() error {
+	db := GetDB()
+	defer CloseDB(db)
+
+	if err := migrateModels(db, &SchemaMigration{}); err != nil {
+		return err
+	}
+	pending, err := GetPendingMigrations()
+	if err != nil {
+		return err
+	}
+	for _, m := range pending {
+		logging.CLILog.Infof("migrate database to version %d:%s", m.Version, m.Name)
+		logging.RuntimeLog.Infof("migrate database to version %d:%s", m.Version, m.Name)
+		if err = runMigration(db, m); err != nil {
+			return fmt.Errorf("migrate version %d fail:%v", m.Version, err)
+		}
+	}
+	return nil
+}
+
+// runMigration 执行一个迁移并记录版本：postgres与sqlite支持事务中的DDL，迁移与版本记录在同一事务中完成；
+// mysql的DDL会隐式提交事务，只能依次执行（因此每个迁移必须是可重复执行的）
+func runMigration(db *gorm.DB, m Migration) error {
+	apply := func(tx *gorm.DB) error {
+		if err := m.migrate(tx); err != nil {
+			return err
+		}
+		return tx.Create(&SchemaMigration{Version: m.Version, Name: m.Name, CreateDatetime: time.Now()}).Error
+	}
+	if GetDriver() == DriverMySQL {
+		return apply(db)
+	}
+	return db.Transaction(apply)
+}
+
+// migrateModels 根据数据模型生成数据库表结构：
+// 不存在的表直接创建（包括索引和外键）；已存在的表只补充缺失的字段和索引，不修改已有的字段，
+// 以兼容由nemo.sql初始化的mysql数据库
+func migrateModels(db *gorm.DB, models ...interface{}) error {
+	migrator := db.Migrator()
+	for _, model := range models {
+		if !migrator.HasTable(model) {
+			if err := migrator.CreateTable(model); err != nil {
+				return err
+			}
+			continue
+		}
+		stmt := &gorm.Statement{DB: db}
+		if err := stmt.Parse(model); err != nil {
+			return err
+		}
+		for _, field := range stmt.Schema.Fields {
+			if field.DBName == "" || migrator.HasColumn(model, field.DBName) {
+				continue
+			}
+			if err := migrator.AddColumn(model, field.DBName); err != nil {
+				return err
+			}
+		}
+		for _, index := range stmt.Schema.ParseIndexes() {
+			if migrator.HasIndex(model, index.Name) {
+				continue
+			}
+			if err := migrator.CreateIndex(model, index.Name); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// initDefaultData 初始化默认的工作空间和超级管理员（与nemo.sql中的初始数据一致）
+func initDefaultData(db *gorm.DB) error {
+	var count int64
+	if err := db.Model(&User{}).Count(&count).Error; err != nil || count > 0 {
+		return err
+	}
+	//迁移可能在事务中执行，使用db插入数据
+	now := time.Now()
+	workspace := &Workspace{
+		WorkspaceName:        "默认",
+		WorkspaceGUID:        uuid.New().String(),
+		WorkspaceDescription: "默认工作空间",
+		State:                "enable",
+		SortOrder:            100,
+		CreateDatetime:       now,
+		UpdateDatetime:       now,
+	}
+	if err := db.Create(workspace).Error; err != nil {
+		return fmt.Errorf("add default workspace fail:%v", err)
+	}
+	//默认密码为nemo
+	user := &User{
+		UserName:        "nemo",
+		UserPassword:    "648ce596dba3b408b523d3d1189b15070123456789abcdef",
+		UserDescription: "默认超级管理员",
+		UserRole:        "superadmin",
+		State:           "enable",
+		SortOrder:       100,
+		CreateDatetime:  now,
+		UpdateDatetime:  now,
+	}
+	if err := db.Create(user).Error; err != nil {
+		return fmt.Errorf("add default user fail:%v", err)
+	}
+	userWorkspace := &UserWorkspace{UserId: user.Id, WorkspaceId: workspace.Id, CreateDatetime: now, UpdateDatetime: now}
+	if err := db.Create(userWorkspace).Error; err != nil {
+		return fmt.Errorf("add default user workspace fail:%v", err)
+	}
+	return nil
+}
+
+// migrateKeyWordEngine 原key_word_update.sql：新增的engine字段默认为xfofa
+func migrateKeyWordEngine(db *gorm.DB) error {
+	return db.Model(&KeyWord{}).Where("engine = ? or engine is null", "").Update("engine", "xfofa").Error
+}
+
+// migrateTaskMainResultHash 使用完整内容的hash作为结果去重的唯一索引，替换原有的content唯一索引
+func migrateTaskMainResultHash(db *gorm.DB) error {
+	m := db.Migrator()
+	if !m.HasColumn(&TaskMainResult{}, "ContentHash") {
+		if err := m.AddColumn(&TaskMainResult{}, "ContentHash"); err != nil {
+			return err
+		}
+	}
+	var results []TaskMainResult
+	err := db.Select("id", "content").Where("content_hash = ? or content_hash is null", "").FindInBatches(&results, 1000, func(tx *gorm.DB, batch int) error {
+		for _, r := range results {
+			if err := db.Model(&TaskMainResult{}).Where("id", r.Id).Update("content_hash", taskMainResultContentHash(r.Content)).Error; err != nil {
+				return err
+			}
+		}
+		return nil
+	}).Error
+	if err != nil {
+		return err
+	}
+	if m.HasIndex(&TaskMainResult{}, "index_task_main_result_item") {
+		if err = m.DropIndex(&TaskMainResult{}, "index_task_main_result_item"); err != nil {
+			return err
+		}
+	}
+	if !m.HasIndex(&TaskMainResult{}, "index_task_main_result_hash") {
+		return m.CreateIndex(&TaskMainResult{}, "index_task_main_result_hash")
+	}
+	return nil
+}
+
+// migrateIpSort 原ipv6_update.sql：为已有的IP生成用于IPv4/IPv6统一排序的ip_sort
+func migrateIpSort(db *gorm.DB) error {
+	var ips []Ip
+	return db.Select("id", "ip").Where("ip_sort = ? or ip_sort is null", "").FindInBatches(&ips, 1000, func(tx *gorm.DB, batch int) error {
+		for _, ip := range ips {
+			ipSort := utils.IPToSortString(ip.IpName)
+			if ipSort == "" {
+				continue
+			}
+			if err := db.Model(&Ip{}).Where("id", ip.Id).Update("ip_sort", ipSort).Error; err != nil {
+				return err
+			}
+		}
+		return nil
+	}).Error
+}
@@ -0,0 +1,48 @@
+package db
+
+import (
+	"errors"
+	"gorm.io/gorm"
+	"testing"
+)
+
+func TestMigrateVersion(t *testing.T) {
+	useSQLiteMemoryDB(t)
+	var count int64
+	GetDB().Model(&SchemaMigration{}).Count(&count)
+	if int(count) != len(migrations) {
+		t.Fatalf("schema migration count:%d", count)
+	}
+	// 已执行的迁移不再重复执行
+	if err := Migrate(); err != nil {
+		t.Fatal(err)
+	}
+	GetDB().Model(&User{}).Count(&count)
+	if count != 1 {
+		t.Errorf("default user count:%d", count)
+	}
+
+	// 失败的迁移回滚表结构的变更，且不记录版本
+	type migrateTestTable struct {
+		Id int `gorm:"primaryKey"`
+	}
+	version := len(migrations) + 1
+	migrations = append(migrations, Migration{Version: version, Name: "test rollback", migrate: func(db *gorm.DB) error {
+		if err := db.Migrator().CreateTable(&migrateTestTable{}); err != nil {
+			return err
+		}
+		return errors.New("migrate fail")
+	}})
+	defer func() {
+		migrations = migrations[:len(migrations)-1]
+	}()
+	if err := Migrate(); err == nil {
+		t.Fatal("failed migration should return error")
+	}
+	if GetDB().Migrator().HasTable(&migrateTestTable{}) {
+		t.Error("failed migration not rolled back")
+	}
+	if pending, _ := GetPendingMigrations(); len(pending) != 1 || pending[0].Version != version {
+		t.Errorf("pending migrations:%v", pending)
+	}
+}
@@ -0,0 +1,91 @@
+package db
+
+import (
+	"time"
+)
+
+// NotifyTemplate 工作空间的消息通知模板：按通知名称覆盖server.yml中配置的模板
+type NotifyTemplate struct {
+	Id             int       `gorm:"primaryKey"`
+	WorkspaceId    int       `gorm:"column:workspace_id;not null;uniqueIndex:index_notify_template_workspace_notify"`
+	NotifyName     string    `gorm:"column:notify_name;size:100;not null;uniqueIndex:index_notify_template_workspace_notify"`
+	Template       string    `gorm:"column:template;size:8000;not null"`
+	CreateDatetime time.Time `gorm:"column:create_datetime;not null"`
+	UpdateDatetime time.Time `gorm:"column:update_datetime;not null"`
+}
+
+// TableName 设置数据库关联的表名
+func (*NotifyTemplate) TableName() string {
+	return "notify_template"
+}
+
+// Add 插入一条新的记录，返回主键ID及成功标志
+func (t *NotifyTemplate) Add() (success bool) {
+	t.CreateDatetime = time.Now()
+	t.UpdateDatetime = time.Now()
+
+	db := GetDB()
+	defer CloseDB(db)
+	if result := db.Create(t); result.RowsAffected > 0 {
+		return true
+	} else {
+		return false
+	}
+}
+
+// Get 根据ID查询记录
+func (t *NotifyTemplate) Get() (success bool) {
+	db := GetDB()
+	defer CloseDB(db)
+
+	if result := db.First(t, t.Id); result.RowsAffected > 0 {
+		return true
+	} else {
+		return false
+	}
+}
+
+// GetByNotifyName 根据工作空间和通知名称查询记录
+func (t *NotifyTemplate) GetByNotifyName() (success bool) {
+	db := GetDB()
+	defer CloseDB(db)
+
+	if result := db.Where("workspace_id", t.WorkspaceId).Where("notify_name", t.NotifyName).First(t); result.RowsAffected > 0 {
+		return true
+	} else {
+		return false
+	}
+}
+
+// Update 更新指定ID的一条记录，列名和内容位于map中
+func (t *NotifyTemplate) Update(updateMap map[string]interface{}) (success bool) {
+	updateMap["update_datetime"] = time.Now()
+
+	db := GetDB()
+	defer CloseDB(db)
+	if result := db.Model(t).Updates(updateMap); result.RowsAffected > 0 {
+		return true
+	} else {
+		return false
+	}
+}
+
+// Delete 删除指定主键ID的一条记录
+func (t *NotifyTemplate) Delete() (success bool) {
+	db := GetDB()
+	defer CloseDB(db)
+	if result := db.Delete(t, t.Id); result.RowsAffected > 0 {
+		return true
+	} else {
+		return false
+	}
+}
+
+// GetsByWorkspace 获取工作空间的全部通知模板
+func (t *NotifyTemplate) GetsByWorkspace() (results []NotifyTemplate) {
+	db := GetDB()
+	defer CloseDB(db)
+
+	db.Where("workspace_id", t.WorkspaceId).Order("notify_name").Find(&results)
+	return
+}
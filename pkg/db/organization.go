@@ -6,12 +6,12 @@ import (
 
 type Organization struct {
 	Id             int       `gorm:"primaryKey"`
-	OrgName        string    `gorm:"column:org_name"`
-	Status         string    `gorm:"column:status"`
-	SortOrder      int       `gorm:"column:sort_order" `
-	WorkspaceId    int       `gorm:"column:workspace_id"`
-	CreateDatetime time.Time `gorm:"column:create_datetime"`
-	UpdateDatetime time.Time `gorm:"column:update_datetime" `
+	OrgName        string    `gorm:"column:org_name;size:200;not null"`
+	Status         string    `gorm:"column:status;size:20;not null"`
+	SortOrder      int       `gorm:"column:sort_order;not null" `
+	WorkspaceId    int       `gorm:"column:workspace_id;not null;index:fk_org_workspace_id"`
+	CreateDatetime time.Time `gorm:"column:create_datetime;not null"`
+	UpdateDatetime time.Time `gorm:"column:update_datetime;not null" `
 }
 
 // TableName 设置数据库关联的表名
@@ -4,11 +4,11 @@ import "time"
 
 type Port struct {
 	Id             int       `gorm:"primaryKey"`
-	IpId           int       `gorm:"column:ip_id"`
-	PortNum        int       `gorm:"column:port"`
-	Status         string    `gorm:"column:status"`
-	CreateDatetime time.Time `gorm:"column:create_datetime"`
-	UpdateDatetime time.Time `gorm:"column:update_datetime"`
+	IpId           int       `gorm:"column:ip_id;not null;uniqueIndex:index_port_ip_port"`
+	PortNum        int       `gorm:"column:port;not null;uniqueIndex:index_port_ip_port"`
+	Status         string    `gorm:"column:status;size:20;not null"`
+	CreateDatetime time.Time `gorm:"column:create_datetime;not null"`
+	UpdateDatetime time.Time `gorm:"column:update_datetime;not null"`
 }
 
 func (*Port) TableName() string {
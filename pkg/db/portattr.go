@@ -8,13 +8,13 @@ import (
 
 type PortAttr struct {
 	Id             int       `gorm:"primaryKey"`
-	RelatedId      int       `gorm:"column:r_id"`
-	Source         string    `gorm:"column:source"`
-	Tag            string    `gorm:"column:tag"`
-	Content        string    `gorm:"column:content"`
-	Hash           string    `gorm:"column:hash"`
-	CreateDatetime time.Time `gorm:"column:create_datetime"`
-	UpdateDatetime time.Time `gorm:"column:update_datetime"`
+	RelatedId      int       `gorm:"column:r_id;not null;index:fk_port_attr_r_id"`
+	Source         string    `gorm:"column:source;size:40"`
+	Tag            string    `gorm:"column:tag;size:40;not null"`
+	Content        string    `gorm:"column:content;size:4000"`
+	Hash           string    `gorm:"column:hash;size:32;uniqueIndex:index_port_attr_hash"`
+	CreateDatetime time.Time `gorm:"column:create_datetime;not null"`
+	UpdateDatetime time.Time `gorm:"column:update_datetime;not null"`
 }
 
 func (*PortAttr) TableName() string {
@@ -49,6 +49,18 @@ func (portAttr *PortAttr) GetByPortAttr() (success bool) {
 	}
 }
 
+// GetLatestBySourceAndTag 获取端口指定来源和属性的最近一条记录
+func (portAttr *PortAttr) GetLatestBySourceAndTag() (success bool) {
+	db := GetDB()
+	defer CloseDB(db)
+
+	if result := db.Where("r_id", portAttr.RelatedId).Where("source", portAttr.Source).Where("tag", portAttr.Tag).Order("update_datetime desc").First(portAttr); result.RowsAffected > 0 {
+		return true
+	} else {
+		return false
+	}
+}
+
 // GetsByRelatedId 根据查询条件执行数据库查询操作，返回查询结果数组
 func (portAttr *PortAttr) GetsByRelatedId() (results []PortAttr) {
 	orderBy := "tag,update_datetime desc"
@@ -9,14 +9,14 @@ import (
 
 type RuntimeLog struct {
 	Id             int       `gorm:"primaryKey"`
-	Source         string    `gorm:"column:source"`
-	File           string    `gorm:"column:file"`
-	Func           string    `gorm:"column:func"`
-	Level          string    `gorm:"column:level"`
-	LevelInt       int       `gorm:"level_int"`
-	Message        string    `gorm:"column:message"`
-	CreateDatetime time.Time `gorm:"column:create_datetime"`
-	UpdateDatetime time.Time `gorm:"column:update_datetime"`
+	Source         string    `gorm:"column:source;size:40;not null"`
+	File           string    `gorm:"column:file;size:80"`
+	Func           string    `gorm:"column:func;size:80"`
+	Level          string    `gorm:"column:level;size:20;not null"`
+	LevelInt       int       `gorm:"column:level_int;not null"`
+	Message        string    `gorm:"column:message;size:1000;not null"`
+	CreateDatetime time.Time `gorm:"column:create_datetime;not null"`
+	UpdateDatetime time.Time `gorm:"column:update_datetime;not null"`
 }
 
 func (*RuntimeLog) TableName() string {
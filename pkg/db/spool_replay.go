@@ -0,0 +1,54 @@
+package db
+
+import (
+	"gorm.io/gorm/clause"
+	"time"
+)
+
+// SpoolReplay worker补传的RPC调用记录，用于丢弃重复补传的结果
+type SpoolReplay struct {
+	Id             int       `gorm:"primaryKey"`
+	SpoolId        string    `gorm:"column:spool_id;size:36;not null;uniqueIndex:index_spool_replay_spool_id"`
+	Worker         string    `gorm:"column:worker;size:100;not null"`
+	Method         string    `gorm:"column:method;size:50;not null"`
+	CreateDatetime time.Time `gorm:"column:create_datetime;not null"`
+}
+
+// TableName 设置数据库关联的表名
+func (*SpoolReplay) TableName() string {
+	return "spool_replay"
+}
+
+// Add 记录补传调用的ID，返回是否为新增的记录（ID已存在时忽略），用于原子地判断调用是否已经处理过
+func (r *SpoolReplay) Add() (success bool) {
+	r.CreateDatetime = time.Now()
+	db := GetDB()
+	defer CloseDB(db)
+
+	if result := db.Clauses(clause.OnConflict{DoNothing: true}).Create(r); result.Error == nil && result.RowsAffected > 0 {
+		return true
+	} else {
+		return false
+	}
+}
+
+// DeleteBySpoolId 删除补传调用的记录，用于调用处理失败后允许重新补传
+func (r *SpoolReplay) DeleteBySpoolId() (success bool) {
+	db := GetDB()
+	defer CloseDB(db)
+
+	if result := db.Where("spool_id", r.SpoolId).Delete(&SpoolReplay{}); result.RowsAffected > 0 {
+		return true
+	} else {
+		return false
+	}
+}
+
+// DeleteBefore 删除指定时间之前的记录，返回删除的数量
+func (r *SpoolReplay) DeleteBefore(datetime time.Time) (count int) {
+	db := GetDB()
+	defer CloseDB(db)
+
+	result := db.Where("create_datetime < ?", datetime).Delete(&SpoolReplay{})
+	return int(result.RowsAffected)
+}
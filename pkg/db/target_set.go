@@ -0,0 +1,158 @@
+package db
+
+import (
+	"gorm.io/gorm"
+	"time"
+)
+
+// TargetSet 工作空间中命名的目标集合：包含及排除的条目（IP、CIDR、IP范围、域名）单独保存在target_set_entry中
+type TargetSet struct {
+	Id             int       `gorm:"primaryKey"`
+	WorkspaceId    int       `gorm:"column:workspace_id;not null;uniqueIndex:index_target_set_workspace_name"`
+	TargetSetName  string    `gorm:"column:target_set_name;size:100;not null;uniqueIndex:index_target_set_workspace_name"`
+	Description    string    `gorm:"column:description;size:500"`
+	IncludeCount   int       `gorm:"column:include_count;not null;default:0"`
+	ExcludeCount   int       `gorm:"column:exclude_count;not null;default:0"`
+	CreateDatetime time.Time `gorm:"column:create_datetime;not null"`
+	UpdateDatetime time.Time `gorm:"column:update_datetime;not null"`
+}
+
+// TargetSetEntry 目标集合的一个条目
+type TargetSetEntry struct {
+	Id          int    `gorm:"primaryKey"`
+	TargetSetId int    `gorm:"column:set_id;not null;index:index_target_set_entry_set_id"`
+	IsExclude   bool   `gorm:"column:is_exclude;not null;default:false"`
+	Content     string `gorm:"column:content;size:255;not null"`
+}
+
+// TableName 设置数据库关联的表名
+func (*TargetSet) TableName() string {
+	return "target_set"
+}
+
+// TableName 设置数据库关联的表名
+func (*TargetSetEntry) TableName() string {
+	return "target_set_entry"
+}
+
+// Add 插入一条新的记录，返回主键ID及成功标志
+func (t *TargetSet) Add() (success bool) {
+	t.CreateDatetime = time.Now()
+	t.UpdateDatetime = time.Now()
+
+	db := GetDB()
+	defer CloseDB(db)
+	if result := db.Create(t); result.RowsAffected > 0 {
+		return true
+	} else {
+		return false
+	}
+}
+
+// Get 根据ID查询记录
+func (t *TargetSet) Get() (success bool) {
+	db := GetDB()
+	defer CloseDB(db)
+
+	if result := db.First(t, t.Id); result.RowsAffected > 0 {
+		return true
+	} else {
+		return false
+	}
+}
+
+// GetByName 根据工作空间和名称查询记录
+func (t *TargetSet) GetByName() (success bool) {
+	db := GetDB()
+	defer CloseDB(db)
+
+	if result := db.Where("workspace_id", t.WorkspaceId).Where("target_set_name", t.TargetSetName).First(t); result.RowsAffected > 0 {
+		return true
+	} else {
+		return false
+	}
+}
+
+// Update 更新指定ID的一条记录，列名和内容位于map中
+func (t *TargetSet) Update(updateMap map[string]interface{}) (success bool) {
+	updateMap["update_datetime"] = time.Now()
+
+	db := GetDB()
+	defer CloseDB(db)
+	if result := db.Model(t).Updates(updateMap); result.RowsAffected > 0 {
+		return true
+	} else {
+		return false
+	}
+}
+
+// Delete 删除指定主键ID的一条记录及全部的条目
+func (t *TargetSet) Delete() (success bool) {
+	db := GetDB()
+	defer CloseDB(db)
+
+	err := db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("set_id", t.Id).Delete(&TargetSetEntry{}).Error; err != nil {
+			return err
+		}
+		result := tx.Delete(t, t.Id)
+		if result.Error == nil && result.RowsAffected == 0 {
+			return gorm.ErrRecordNotFound
+		}
+		return result.Error
+	})
+	return err == nil
+}
+
+// GetsByWorkspace 获取工作空间的全部目标集合
+func (t *TargetSet) GetsByWorkspace() (results []TargetSet) {
+	db := GetDB()
+	defer CloseDB(db)
+
+	db.Where("workspace_id", t.WorkspaceId).Order("target_set_name").Find(&results)
+	return
+}
+
+// SaveEntries 使用新的包含及排除条目替换目标集合的全部条目，并更新条目数量
+func (t *TargetSet) SaveEntries(include, exclude []string) (success bool) {
+	var entries []TargetSetEntry
+	for _, content := range include {
+		entries = append(entries, TargetSetEntry{TargetSetId: t.Id, Content: content})
+	}
+	for _, content := range exclude {
+		entries = append(entries, TargetSetEntry{TargetSetId: t.Id, IsExclude: true, Content: content})
+	}
+	db := GetDB()
+	defer CloseDB(db)
+
+	err := db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("set_id", t.Id).Delete(&TargetSetEntry{}).Error; err != nil {
+			return err
+		}
+		if len(entries) > 0 {
+			if err := tx.CreateInBatches(entries, 500).Error; err != nil {
+				return err
+			}
+		}
+		return tx.Model(t).Updates(map[string]interface{}{
+			"include_count":   len(include),
+			"exclude_count":   len(exclude),
+			"update_datetime": time.Now(),
+		}).Error
+	})
+	if err != nil {
+		return false
+	}
+	t.IncludeCount = len(include)
+	t.ExcludeCount = len(exclude)
+	return true
+}
+
+// GetEntries 获取目标集合的包含或排除的全部条目
+func (t *TargetSet) GetEntries(isExclude bool) (contents []string) {
+	db := GetDB()
+	defer CloseDB(db)
+
+	db.Model(&TargetSetEntry{}).Where("set_id", t.Id).Where("is_exclude", isExclude).Order("id").Pluck("content", &contents)
+	return
+}
@@ -7,17 +7,17 @@ import (
 
 type TaskCron struct {
 	Id              int       `gorm:"primaryKey"`
-	TaskId          string    `gorm:"column:task_id"`
-	TaskName        string    `gorm:"column:task_name"`
-	KwArgs          string    `gorm:"column:kwargs"`
-	CreateDatetime  time.Time `gorm:"column:create_datetime"`
-	UpdateDatetime  time.Time `gorm:"column:update_datetime"`
-	CronRule        string    `gorm:"column:cron_rule"`
+	TaskId          string    `gorm:"column:task_id;size:36;not null"`
+	TaskName        string    `gorm:"column:task_name;size:100;not null"`
+	KwArgs          string    `gorm:"column:kwargs;size:8000"`
+	CreateDatetime  time.Time `gorm:"column:create_datetime;not null"`
+	UpdateDatetime  time.Time `gorm:"column:update_datetime;not null"`
+	CronRule        string    `gorm:"column:cron_rule;size:200;not null"`
 	LastRunDatetime time.Time `gorm:"column:lastrun_datetime"`
-	Status          string    `gorm:"column:status"`
-	WorkspaceId     int       `gorm:"column:workspace_id"`
+	Status          string    `gorm:"column:status;size:10;not null"`
+	WorkspaceId     int       `gorm:"column:workspace_id;not null;index:fk_task_cron_workspace_id"`
 	RunCount        int       `gorm:"column:run_count"`
-	Comment         string    `gorm:"column:comment"`
+	Comment         string    `gorm:"column:comment;size:200"`
 }
 
 func (*TaskCron) TableName() string {
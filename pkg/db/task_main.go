@@ -7,19 +7,20 @@ import (
 
 type TaskMain struct {
 	Id              int        `gorm:"primaryKey"`
-	TaskId          string     `gorm:"column:task_id"`
-	TaskName        string     `gorm:"column:task_name"`
-	KwArgs          string     `gorm:"column:kwargs"`
-	State           string     `gorm:"column:state"`
-	Result          string     `gorm:"column:result"`
-	ReceivedTime    time.Time  `gorm:"column:received"`
+	TaskId          string     `gorm:"column:task_id;size:36;not null"`
+	TaskName        string     `gorm:"column:task_name;size:100;not null"`
+	KwArgs          string     `gorm:"column:kwargs;size:8000"`
+	State           string     `gorm:"column:state;size:40;not null"`
+	Result          string     `gorm:"column:result;size:4000"`
+	ReceivedTime    time.Time  `gorm:"column:received;not null"`
 	StartedTime     *time.Time `gorm:"column:started"`
 	SucceededTime   *time.Time `gorm:"column:succeeded"`
-	ProgressMessage string     `gorm:"column:progress_message"`
-	CronTaskId      string     `gorm:"column:cron_id"`
-	WorkspaceId     int        `gorm:"column:workspace_id"`
-	CreateDatetime  time.Time  `gorm:"column:create_datetime"`
-	UpdateDatetime  time.Time  `gorm:"column:update_datetime"`
+	ProgressMessage string     `gorm:"column:progress_message;size:100"`
+	CronTaskId      string     `gorm:"column:cron_id;size:36"`
+	WorkspaceId     int        `gorm:"column:workspace_id;not null;index:fk_task_main_workspace_id"`
+	WorkerTag       string     `gorm:"column:worker_tag;size:50"`
+	CreateDatetime  time.Time  `gorm:"column:create_datetime;not null"`
+	UpdateDatetime  time.Time  `gorm:"column:update_datetime;not null"`
 }
 
 func (*TaskMain) TableName() string {
@@ -78,6 +79,19 @@ func (t *TaskMain) Update(updateMap map[string]interface{}) (success bool) {
 	}
 }
 
+// UpdateByState 只有当前状态为state时才更新记录，用于并发时保证状态变更只执行一次
+func (t *TaskMain) UpdateByState(state string, updateMap map[string]interface{}) (success bool) {
+	updateMap["update_datetime"] = time.Now()
+
+	db := GetDB()
+	defer CloseDB(db)
+	if result := db.Model(&TaskMain{}).Where("id = ? AND state = ?", t.Id, state).Updates(updateMap); result.RowsAffected > 0 {
+		return true
+	} else {
+		return false
+	}
+}
+
 // Delete 删除指定主键ID的一条记录
 func (t *TaskMain) Delete() (success bool) {
 	db := GetDB()
@@ -0,0 +1,145 @@
+package db
+
+import (
+	"crypto/md5"
+	"fmt"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+	"time"
+	"unicode/utf8"
+)
+
+// 主任务结果汇总的项目：IP、端口、域名、漏洞为去重的结果，其它为累加的计数
+const (
+	TaskResultItemIP               = "ip"
+	TaskResultItemPort             = "port"
+	TaskResultItemDomain           = "domain"
+	TaskResultItemVulnerability    = "vulnerability"
+	TaskResultItemScreenshot       = "screenshot"
+	TaskResultItemIPNew            = "ipNew"
+	TaskResultItemPortNew          = "portNew"
+	TaskResultItemDomainNew        = "domainNew"
+	TaskResultItemVulnerabilityNew = "vulnerabilityNew"
+)
+
+// taskMainResultContentSize 结果内容显示的最大字符数
+const taskMainResultContentSize = 255
+
+// TaskMainResult 主任务的结果汇总：去重的结果每条一个记录（完整内容的hash唯一）；计数的content为空，累加count
+type TaskMainResult struct {
+	Id             int       `gorm:"primaryKey"`
+	MainTaskId     string    `gorm:"column:main_id;size:36;not null;uniqueIndex:index_task_main_result_hash"`
+	Item           string    `gorm:"column:item;size:20;not null;uniqueIndex:index_task_main_result_hash"`
+	Content        string    `gorm:"column:content;size:255;not null"`
+	ContentHash    string    `gorm:"column:content_hash;size:32;not null;default:'';uniqueIndex:index_task_main_result_hash"`
+	Count          int       `gorm:"column:count;not null;default:0"`
+	CreateDatetime time.Time `gorm:"column:create_datetime;not null"`
+	UpdateDatetime time.Time `gorm:"column:update_datetime;not null"`
+}
+
+// TableName 设置数据库关联的表名
+func (*TaskMainResult) TableName() string {
+	return "task_main_result"
+}
+
+// AddContents 保存去重的结果，已存在的结果忽略；返回新增的数量
+func (r *TaskMainResult) AddContents(item string, contents []string) (count int) {
+	if len(contents) == 0 {
+		return
+	}
+	now := time.Now()
+	var results []TaskMainResult
+	for _, content := range contents {
+		results = append(results, TaskMainResult{
+			MainTaskId:     r.MainTaskId,
+			Item:           item,
+			Content:        truncateContent(content, taskMainResultContentSize),
+			ContentHash:    taskMainResultContentHash(content),
+			Count:          1,
+			CreateDatetime: now,
+			UpdateDatetime: now,
+		})
+	}
+	db := GetDB()
+	defer CloseDB(db)
+
+	result := db.Clauses(clause.OnConflict{DoNothing: true}).CreateInBatches(results, 100)
+	return int(result.RowsAffected)
+}
+
+// IncreaseCount 原子地累加计数
+func (r *TaskMainResult) IncreaseCount(item string, count int) (success bool) {
+	if count == 0 {
+		return true
+	}
+	now := time.Now()
+	result := TaskMainResult{
+		MainTaskId:     r.MainTaskId,
+		Item:           item,
+		ContentHash:    taskMainResultContentHash(""),
+		Count:          count,
+		CreateDatetime: now,
+		UpdateDatetime: now,
+	}
+	db := GetDB()
+	defer CloseDB(db)
+
+	return db.Clauses(clause.OnConflict{
+		Columns: []clause.Column{{Name: "main_id"}, {Name: "item"}, {Name: "content_hash"}},
+		DoUpdates: clause.Assignments(map[string]interface{}{
+			"count":           gorm.Expr("count + ?", count),
+			"update_datetime": now,
+		}),
+	}).Create(&result).Error == nil
+}
+
+// GetCounts 获取主任务各个项目的数量
+func (r *TaskMainResult) GetCounts() (counts map[string]int) {
+	var results []struct {
+		Item  string
+		Total int
+	}
+	db := GetDB()
+	defer CloseDB(db)
+
+	db.Model(r).Select("item, sum(count) as total").Where("main_id", r.MainTaskId).Group("item").Scan(&results)
+	counts = make(map[string]int)
+	for _, result := range results {
+		counts[result.Item] = result.Total
+	}
+	return
+}
+
+// GetContents 获取主任务一个项目的全部结果
+func (r *TaskMainResult) GetContents(item string) (contents []string) {
+	db := GetDB()
+	defer CloseDB(db)
+
+	db.Model(r).Where("main_id", r.MainTaskId).Where("item", item).Order("content").Pluck("content", &contents)
+	return
+}
+
+// DeleteByMainTaskId 删除主任务的结果汇总
+func (r *TaskMainResult) DeleteByMainTaskId() (success bool) {
+	db := GetDB()
+	defer CloseDB(db)
+
+	if result := db.Delete(r, "main_id = ?", r.MainTaskId); result.RowsAffected > 0 {
+		return true
+	} else {
+		return false
+	}
+}
+
+// taskMainResultContentHash 结果完整内容的hash，用于结果的去重
+func taskMainResultContentHash(content string) string {
+	return fmt.Sprintf("%x", md5.Sum([]byte(content)))
+}
+
+// truncateContent 按字符截断内容，避免截断多字节字符
+func truncateContent(content string, size int) string {
+	if utf8.RuneCountInString(content) <= size {
+		return content
+	}
+	return string([]rune(content)[:size])
+}
@@ -0,0 +1,70 @@
+package db
+
+import (
+	"strings"
+	"sync"
+	"testing"
+	"unicode/utf8"
+)
+
+func TestTaskMainResult_AddContents(t *testing.T) {
+	useSQLiteMemoryDB(t)
+	r := TaskMainResult{MainTaskId: "test-add-contents"}
+	// 前255个字符相同的内容按完整内容的hash去重
+	long := strings.Repeat("域名", taskMainResultContentSize)
+	contents := []string{"192.168.1.1", "192.168.1.2", long + "a", long + "b"}
+	if count := r.AddContents(TaskResultItemIP, contents); count != len(contents) {
+		t.Errorf("add contents:%d", count)
+	}
+	if count := r.AddContents(TaskResultItemIP, []string{"192.168.1.1", "192.168.1.3", long + "a"}); count != 1 {
+		t.Errorf("add duplicate contents:%d", count)
+	}
+	if count := r.AddContents(TaskResultItemDomain, []string{"192.168.1.1"}); count != 1 {
+		t.Errorf("add contents of another item:%d", count)
+	}
+	results := r.GetContents(TaskResultItemIP)
+	if len(results) != 5 {
+		t.Errorf("get contents:%d", len(results))
+	}
+	for _, content := range results {
+		if !utf8.ValidString(content) || utf8.RuneCountInString(content) > taskMainResultContentSize {
+			t.Errorf("invalid content:%s", content)
+		}
+	}
+	if counts := r.GetCounts(); counts[TaskResultItemIP] != 5 || counts[TaskResultItemDomain] != 1 {
+		t.Errorf("get counts:%v", counts)
+	}
+}
+
+func TestTaskMainResult_IncreaseCount(t *testing.T) {
+	useSQLiteMemoryDB(t)
+	r := TaskMainResult{MainTaskId: "test-increase-count"}
+	if !r.IncreaseCount(TaskResultItemScreenshot, 0) {
+		t.Error("increase zero count fail")
+	}
+	var wg sync.WaitGroup
+	for i := 1; i <= 10; i++ {
+		wg.Add(1)
+		go func(count int) {
+			defer wg.Done()
+			if !r.IncreaseCount(TaskResultItemScreenshot, count) {
+				t.Errorf("increase count:%d fail", count)
+			}
+		}(i)
+	}
+	wg.Wait()
+	if !r.IncreaseCount(TaskResultItemIPNew, 2) {
+		t.Error("increase count fail")
+	}
+	counts := r.GetCounts()
+	if counts[TaskResultItemScreenshot] != 55 || counts[TaskResultItemIPNew] != 2 {
+		t.Errorf("get counts:%v", counts)
+	}
+	var total int64
+	db := GetDB()
+	defer CloseDB(db)
+	db.Model(&TaskMainResult{}).Where("main_id = ? AND item = ?", r.MainTaskId, TaskResultItemScreenshot).Count(&total)
+	if total != 1 {
+		t.Errorf("count records:%d", total)
+	}
+}
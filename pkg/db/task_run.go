@@ -7,24 +7,27 @@ import (
 
 type TaskRun struct {
 	Id              int        `gorm:"primaryKey"`
-	TaskId          string     `gorm:"column:task_id"`
-	TaskName        string     `gorm:"column:task_name"`
-	KwArgs          string     `gorm:"column:kwargs"`
-	Worker          string     `gorm:"column:worker"`
-	State           string     `gorm:"column:state"`
-	Result          string     `gorm:"column:result"`
+	TaskId          string     `gorm:"column:task_id;size:36;not null"`
+	TaskName        string     `gorm:"column:task_name;size:100;not null"`
+	KwArgs          string     `gorm:"column:kwargs;size:8000"`
+	Worker          string     `gorm:"column:worker;size:100"`
+	State           string     `gorm:"column:state;size:40;not null"`
+	Result          string     `gorm:"column:result;size:4000"`
 	ReceivedTime    *time.Time `gorm:"column:received"`
 	RetriedTime     *time.Time `gorm:"column:retried"`
 	RevokedTime     *time.Time `gorm:"column:revoked"`
 	StartedTime     *time.Time `gorm:"column:started"`
 	SucceededTime   *time.Time `gorm:"column:succeeded"`
 	FailedTime      *time.Time `gorm:"column:failed"`
-	ProgressMessage string     `gorm:"column:progress_message"`
-	CreateDatetime  time.Time  `gorm:"column:create_datetime"`
-	UpdateDatetime  time.Time  `gorm:"column:update_datetime"`
-	MainTaskId      string     `gorm:"column:main_id"`
-	LastRunTaskId   string     `gorm:"column:last_run_id"`
-	WorkspaceId     int        `gorm:"column:workspace_id"`
+	ProgressMessage string     `gorm:"column:progress_message;size:100"`
+	CreateDatetime  time.Time  `gorm:"column:create_datetime;not null"`
+	UpdateDatetime  time.Time  `gorm:"column:update_datetime;not null"`
+	MainTaskId      string     `gorm:"column:main_id;size:36;index:index_task_run_main_id"`
+	LastRunTaskId   string     `gorm:"column:last_run_id;size:36"`
+	WorkspaceId     int        `gorm:"column:workspace_id;not null;index:fk_task_run_workspace_id"`
+	WorkflowStep    string     `gorm:"column:workflow_step;size:100"`
+	Attempt         int        `gorm:"column:attempt;not null;default:1"`
+	WorkerTag       string     `gorm:"column:worker_tag;size:50"`
 }
 
 func (*TaskRun) TableName() string {
@@ -81,6 +84,19 @@ func (t *TaskRun) Update(updateMap map[string]interface{}) (success bool) {
 	}
 }
 
+// UpdateByState 只有当前状态为state时才更新记录，用于并发时保证状态变更只执行一次
+func (t *TaskRun) UpdateByState(state string, updateMap map[string]interface{}) (success bool) {
+	updateMap["update_datetime"] = time.Now()
+
+	db := GetDB()
+	defer CloseDB(db)
+	if result := db.Model(&TaskRun{}).Where("id = ? AND state = ?", t.Id, state).Updates(updateMap); result.RowsAffected > 0 {
+		return true
+	} else {
+		return false
+	}
+}
+
 // Delete 删除指定主键ID的一条记录
 func (t *TaskRun) Delete() (success bool) {
 	db := GetDB()
@@ -104,6 +120,15 @@ func (t *TaskRun) DeleteByMainTaskId() (success bool) {
 	}
 }
 
+// UpdateStateByMainTaskId 将主任务中指定状态的子任务更新为新的状态，返回更新的数量
+func (t *TaskRun) UpdateStateByMainTaskId(state, newState string) (count int) {
+	db := GetDB()
+	defer CloseDB(db)
+
+	result := db.Model(t).Where("main_id = ? AND state = ?", t.MainTaskId, state).Updates(map[string]interface{}{"state": newState, "update_datetime": time.Now()})
+	return int(result.RowsAffected)
+}
+
 // Count 统计指定查询条件的记录数量
 func (t *TaskRun) Count(searchMap map[string]interface{}) (count int) {
 	db := t.makeWhere(searchMap).Model(t)
@@ -194,3 +219,63 @@ func (t *TaskRun) SaveOrUpdate() (success bool) {
 		return t.Add()
 	}
 }
+
+// WorkflowStepState 工作流步骤的子任务状态统计
+type WorkflowStepState struct {
+	WorkflowStep string
+	State        string
+	Count        int
+}
+
+// CountStateByMainTaskId 按状态统计主任务的子任务数量
+func (t *TaskRun) CountStateByMainTaskId() (counts map[string]int) {
+	var results []struct {
+		State string
+		Count int
+	}
+	db := GetDB()
+	defer CloseDB(db)
+
+	db.Model(t).Select("state, count(*) as count").Where("main_id", t.MainTaskId).Group("state").Scan(&results)
+	counts = make(map[string]int)
+	for _, r := range results {
+		counts[r.State] = r.Count
+	}
+	return
+}
+
+// CountWorkflowStepState 按工作流步骤和状态统计主任务的子任务数量
+func (t *TaskRun) CountWorkflowStepState() (results []WorkflowStepState) {
+	db := GetDB()
+	defer CloseDB(db)
+
+	db.Model(t).Select("workflow_step, state, count(*) as count").Where("main_id", t.MainTaskId).Where("workflow_step <> ?", "").Group("workflow_step, state").Scan(&results)
+	return
+}
+
+// GetAttempts 获取任务的全部执行记录：失败重试生成的新任务的last_run_id指向上一次执行的任务
+func (t *TaskRun) GetAttempts() (results []TaskRun) {
+	current := *t
+	for current.Attempt > 1 && current.LastRunTaskId != "" {
+		previous := TaskRun{TaskId: current.LastRunTaskId}
+		if !previous.GetByTaskId() || previous.Attempt != current.Attempt-1 {
+			break
+		}
+		results = append([]TaskRun{previous}, results...)
+		current = previous
+	}
+	results = append(results, *t)
+
+	db := GetDB()
+	defer CloseDB(db)
+	current = *t
+	for {
+		var next TaskRun
+		if result := db.Where("last_run_id", current.TaskId).Where("attempt", current.Attempt+1).First(&next); result.RowsAffected == 0 {
+			break
+		}
+		results = append(results, next)
+		current = next
+	}
+	return
+}
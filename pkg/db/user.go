@@ -4,14 +4,14 @@ import "time"
 
 type User struct {
 	Id              int       `gorm:"primaryKey"`
-	UserName        string    `gorm:"column:user_name"`
-	UserPassword    string    `gorm:"column:user_password"`
-	UserDescription string    `gorm:"column:user_description"`
-	UserRole        string    `gorm:"column:user_role"`
-	State           string    `gorm:"column:state"`
-	SortOrder       int       `gorm:"column:sort_order"`
-	CreateDatetime  time.Time `gorm:"column:create_datetime"`
-	UpdateDatetime  time.Time `gorm:"column:update_datetime"`
+	UserName        string    `gorm:"column:user_name;size:100;not null"`
+	UserPassword    string    `gorm:"column:user_password;size:48;not null"`
+	UserDescription string    `gorm:"column:user_description;size:200"`
+	UserRole        string    `gorm:"column:user_role;size:40;not null"`
+	State           string    `gorm:"column:state;size:40;not null"`
+	SortOrder       int       `gorm:"column:sort_order;not null"`
+	CreateDatetime  time.Time `gorm:"column:create_datetime;not null"`
+	UpdateDatetime  time.Time `gorm:"column:update_datetime;not null"`
 }
 
 // TableName 设置数据库关联的表名
@@ -4,10 +4,10 @@ import "time"
 
 type UserWorkspace struct {
 	Id             int       `gorm:"primaryKey"`
-	UserId         int       `gorm:"column:user_id"`
-	WorkspaceId    int       `gorm:"column:workspace_id"`
-	CreateDatetime time.Time `gorm:"column:create_datetime"`
-	UpdateDatetime time.Time `gorm:"column:update_datetime"`
+	UserId         int       `gorm:"column:user_id;not null;index:fk_userid"`
+	WorkspaceId    int       `gorm:"column:workspace_id;not null;index:fk_workspaceid"`
+	CreateDatetime time.Time `gorm:"column:create_datetime;not null"`
+	UpdateDatetime time.Time `gorm:"column:update_datetime;not null"`
 }
 
 func (*UserWorkspace) TableName() string {
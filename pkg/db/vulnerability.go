@@ -9,15 +9,15 @@ import (
 
 type Vulnerability struct {
 	Id             int       `gorm:"primaryKey"`
-	Target         string    `gorm:"column:target"`
-	Url            string    `gorm:"column:url"`
-	PocFile        string    `gorm:"column:poc_file"`
-	Source         string    `gorm:"column:source"`
-	Extra          string    `gorm:"column:extra"`
-	Hash           string    `gorm:"column:hash"`
-	WorkspaceId    int       `gorm:"column:workspace_id"`
-	CreateDatetime time.Time `gorm:"column:create_datetime"`
-	UpdateDatetime time.Time `gorm:"column:update_datetime"`
+	Target         string    `gorm:"column:target;size:100;not null"`
+	Url            string    `gorm:"column:url;size:200;not null"`
+	PocFile        string    `gorm:"column:poc_file;size:200;not null"`
+	Source         string    `gorm:"column:source;size:40;not null"`
+	Extra          string    `gorm:"column:extra;size:4000"`
+	Hash           string    `gorm:"column:hash;size:32;not null"`
+	WorkspaceId    int       `gorm:"column:workspace_id;not null;index:fk_vul_workspace_id"`
+	CreateDatetime time.Time `gorm:"column:create_datetime;not null"`
+	UpdateDatetime time.Time `gorm:"column:update_datetime;not null"`
 }
 
 func (*Vulnerability) TableName() string {
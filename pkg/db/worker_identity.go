@@ -0,0 +1,99 @@
+package db
+
+import (
+	"time"
+)
+
+// WorkerIdentity 注册的worker身份：每个worker使用独立的认证key，启用TLS时同时使用CA签发的客户端证书
+type WorkerIdentity struct {
+	Id              int        `gorm:"primaryKey"`
+	WorkerName      string     `gorm:"column:worker_name;size:100;not null;uniqueIndex:index_worker_identity_name"`
+	Description     string     `gorm:"column:description;size:500"`
+	AuthKeyHash     string     `gorm:"column:auth_key_hash;size:64;not null"` //认证key的sha256，不保存key的明文
+	CertSerial      string     `gorm:"column:cert_serial;size:40"`
+	IsRevoked       bool       `gorm:"column:is_revoked;not null;default:false"`
+	RevokedDatetime *time.Time `gorm:"column:revoked_datetime"`
+	CreateDatetime  time.Time  `gorm:"column:create_datetime;not null"`
+	UpdateDatetime  time.Time  `gorm:"column:update_datetime;not null"`
+}
+
+// TableName 设置数据库关联的表名
+func (*WorkerIdentity) TableName() string {
+	return "worker_identity"
+}
+
+// Add 插入一条新的记录，返回主键ID及成功标志
+func (w *WorkerIdentity) Add() (success bool) {
+	w.CreateDatetime = time.Now()
+	w.UpdateDatetime = time.Now()
+
+	db := GetDB()
+	defer CloseDB(db)
+	if result := db.Create(w); result.RowsAffected > 0 {
+		return true
+	} else {
+		return false
+	}
+}
+
+// Get 根据ID查询记录
+func (w *WorkerIdentity) Get() (success bool) {
+	db := GetDB()
+	defer CloseDB(db)
+
+	if result := db.First(w, w.Id); result.RowsAffected > 0 {
+		return true
+	} else {
+		return false
+	}
+}
+
+// GetByName 根据worker名称查询记录
+func (w *WorkerIdentity) GetByName() (success bool) {
+	db := GetDB()
+	defer CloseDB(db)
+
+	if result := db.Where("worker_name", w.WorkerName).First(w); result.RowsAffected > 0 {
+		return true
+	} else {
+		return false
+	}
+}
+
+// Revoke 吊销worker身份
+func (w *WorkerIdentity) Revoke() (success bool) {
+	now := time.Now()
+	db := GetDB()
+	defer CloseDB(db)
+
+	if result := db.Model(w).Updates(map[string]interface{}{
+		"is_revoked":       true,
+		"revoked_datetime": &now,
+		"update_datetime":  now,
+	}); result.RowsAffected > 0 {
+		return true
+	} else {
+		return false
+	}
+}
+
+// Delete 删除指定ID的一条记录
+func (w *WorkerIdentity) Delete() (success bool) {
+	db := GetDB()
+	defer CloseDB(db)
+
+	if result := db.Delete(w, w.Id); result.RowsAffected > 0 {
+		return true
+	} else {
+		return false
+	}
+}
+
+// Gets 获取全部的记录
+func (w *WorkerIdentity) Gets() (results []WorkerIdentity) {
+	db := GetDB()
+	defer CloseDB(db)
+
+	db.Order("worker_name").Find(&results)
+	return
+}
@@ -0,0 +1,137 @@
+package db
+
+import (
+	"time"
+)
+
+// WorkerRelease server发布的同步到worker的文件版本：manifest包括文件及其sha256，并由server签名
+type WorkerRelease struct {
+	Id             int       `gorm:"primaryKey"`
+	Version        int       `gorm:"column:version;not null;uniqueIndex:index_worker_release_version"`
+	Description    string    `gorm:"column:description;size:500"`
+	FileCount      int       `gorm:"column:file_count;not null"`
+	Manifest       string    `gorm:"column:manifest;size:16777215;not null"` //manifest的JSON，包括全部文件，mysql中为mediumtext
+	Signature      string    `gorm:"column:signature;size:200;not null"`     //manifest签名的base64
+	CreateDatetime time.Time `gorm:"column:create_datetime;not null"`
+}
+
+// TableName 设置数据库关联的表名
+func (*WorkerRelease) TableName() string {
+	return "worker_release"
+}
+
+// Add 插入一条新的记录，返回主键ID及成功标志
+func (r *WorkerRelease) Add() (success bool) {
+	r.CreateDatetime = time.Now()
+
+	db := GetDB()
+	defer CloseDB(db)
+	if result := db.Create(r); result.RowsAffected > 0 {
+		return true
+	} else {
+		return false
+	}
+}
+
+// GetByVersion 根据版本号查询记录
+func (r *WorkerRelease) GetByVersion() (success bool) {
+	db := GetDB()
+	defer CloseDB(db)
+
+	if result := db.Where("version", r.Version).First(r); result.RowsAffected > 0 {
+		return true
+	} else {
+		return false
+	}
+}
+
+// GetLatest 查询最新的版本
+func (r *WorkerRelease) GetLatest() (success bool) {
+	db := GetDB()
+	defer CloseDB(db)
+
+	if result := db.Order("version desc").First(r); result.RowsAffected > 0 {
+		return true
+	} else {
+		return false
+	}
+}
+
+// Delete 删除指定ID的一条记录
+func (r *WorkerRelease) Delete() (success bool) {
+	db := GetDB()
+	defer CloseDB(db)
+
+	if result := db.Delete(r, r.Id); result.RowsAffected > 0 {
+		return true
+	} else {
+		return false
+	}
+}
+
+// Gets 获取全部的记录，按版本号从新到旧排序
+func (r *WorkerRelease) Gets() (results []WorkerRelease) {
+	db := GetDB()
+	defer CloseDB(db)
+
+	db.Order("version desc").Find(&results)
+	return
+}
+
+// WorkerReleasePin worker组（按worker标签，为空时为全部worker）使用的指定发布版本，用于回滚
+type WorkerReleasePin struct {
+	Id             int       `gorm:"primaryKey"`
+	WorkerTag      string    `gorm:"column:worker_tag;size:50;not null;uniqueIndex:index_worker_release_pin_tag"`
+	Version        int       `gorm:"column:version;not null"`
+	UpdateDatetime time.Time `gorm:"column:update_datetime;not null"`
+}
+
+// TableName 设置数据库关联的表名
+func (*WorkerReleasePin) TableName() string {
+	return "worker_release_pin"
+}
+
+// Save 设置worker组使用的版本，已存在时更新
+func (p *WorkerReleasePin) Save() (success bool) {
+	p.UpdateDatetime = time.Now()
+
+	db := GetDB()
+	defer CloseDB(db)
+	existed := WorkerReleasePin{}
+	if result := db.Where("worker_tag", p.WorkerTag).First(&existed); result.RowsAffected > 0 {
+		p.Id = existed.Id
+		if result = db.Model(p).Updates(map[string]interface{}{
+			"version":         p.Version,
+			"update_datetime": p.UpdateDatetime,
+		}); result.RowsAffected > 0 {
+			return true
+		}
+		return false
+	}
+	if result := db.Create(p); result.RowsAffected > 0 {
+		return true
+	} else {
+		return false
+	}
+}
+
+// DeleteByTag 删除worker组指定的版本，恢复使用最新版本
+func (p *WorkerReleasePin) DeleteByTag() (success bool) {
+	db := GetDB()
+	defer CloseDB(db)
+
+	if result := db.Where("worker_tag", p.WorkerTag).Delete(&WorkerReleasePin{}); result.RowsAffected > 0 {
+		return true
+	} else {
+		return false
+	}
+}
+
+// Gets 获取全部的记录
+func (p *WorkerReleasePin) Gets() (results []WorkerReleasePin) {
+	db := GetDB()
+	defer CloseDB(db)
+
+	db.Order("worker_tag").Find(&results)
+	return
+}
@@ -0,0 +1,92 @@
+package db
+
+import (
+	"time"
+)
+
+// Workflow 工作空间中可重复使用的工作流：定义为JSON格式的任务步骤树
+type Workflow struct {
+	Id             int       `gorm:"primaryKey"`
+	WorkspaceId    int       `gorm:"column:workspace_id;not null;uniqueIndex:index_workflow_workspace_name"`
+	WorkflowName   string    `gorm:"column:workflow_name;size:100;not null;uniqueIndex:index_workflow_workspace_name"`
+	Description    string    `gorm:"column:description;size:500"`
+	Definition     string    `gorm:"column:definition;size:8000;not null"`
+	CreateDatetime time.Time `gorm:"column:create_datetime;not null"`
+	UpdateDatetime time.Time `gorm:"column:update_datetime;not null"`
+}
+
+// TableName 设置数据库关联的表名
+func (*Workflow) TableName() string {
+	return "workflow"
+}
+
+// Add 插入一条新的记录，返回主键ID及成功标志
+func (w *Workflow) Add() (success bool) {
+	w.CreateDatetime = time.Now()
+	w.UpdateDatetime = time.Now()
+
+	db := GetDB()
+	defer CloseDB(db)
+	if result := db.Create(w); result.RowsAffected > 0 {
+		return true
+	} else {
+		return false
+	}
+}
+
+// Get 根据ID查询记录
+func (w *Workflow) Get() (success bool) {
+	db := GetDB()
+	defer CloseDB(db)
+
+	if result := db.First(w, w.Id); result.RowsAffected > 0 {
+		return true
+	} else {
+		return false
+	}
+}
+
+// GetByName 根据工作空间和名称查询记录
+func (w *Workflow) GetByName() (success bool) {
+	db := GetDB()
+	defer CloseDB(db)
+
+	if result := db.Where("workspace_id", w.WorkspaceId).Where("workflow_name", w.WorkflowName).First(w); result.RowsAffected > 0 {
+		return true
+	} else {
+		return false
+	}
+}
+
+// Update 更新指定ID的一条记录，列名和内容位于map中
+func (w *Workflow) Update(updateMap map[string]interface{}) (success bool) {
+	updateMap["update_datetime"] = time.Now()
+
+	db := GetDB()
+	defer CloseDB(db)
+	if result := db.Model(w).Updates(updateMap); result.RowsAffected > 0 {
+		return true
+	} else {
+		return false
+	}
+}
+
+// Delete 删除指定主键ID的一条记录
+func (w *Workflow) Delete() (success bool) {
+	db := GetDB()
+	defer CloseDB(db)
+	if result := db.Delete(w, w.Id); result.RowsAffected > 0 {
+		return true
+	} else {
+		return false
+	}
+}
+
+// GetsByWorkspace 获取工作空间的全部工作流
+func (w *Workflow) GetsByWorkspace() (results []Workflow) {
+	db := GetDB()
+	defer CloseDB(db)
+
+	db.Where("workspace_id", w.WorkspaceId).Order("workflow_name").Find(&results)
+	return
+}
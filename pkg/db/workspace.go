@@ -7,13 +7,15 @@ import (
 
 type Workspace struct {
 	Id                   int       `gorm:"primaryKey"`
-	WorkspaceName        string    `gorm:"column:workspace_name"`
-	WorkspaceGUID        string    `gorm:"column:workspace_guid"`
-	WorkspaceDescription string    `gorm:"column:workspace_description"`
-	State                string    `gorm:"column:state"`
-	SortOrder            int       `gorm:"column:sort_order"`
-	CreateDatetime       time.Time `gorm:"column:create_datetime"`
-	UpdateDatetime       time.Time `gorm:"column:update_datetime"`
+	WorkspaceName        string    `gorm:"column:workspace_name;size:100;not null"`
+	WorkspaceGUID        string    `gorm:"column:workspace_guid;size:36;not null;uniqueIndex:workspace_space_guid_uindex"`
+	WorkspaceDescription string    `gorm:"column:workspace_description;size:200"`
+	State                string    `gorm:"column:state;size:20;not null"`
+	SortOrder            int       `gorm:"column:sort_order;not null"`
+	NotifyConfig         string    `gorm:"column:notify_config;size:8000"`
+	ScopeConfig          string    `gorm:"column:scope_config;size:8000"`
+	CreateDatetime       time.Time `gorm:"column:create_datetime;not null"`
+	UpdateDatetime       time.Time `gorm:"column:update_datetime;not null"`
 }
 
 func (*Workspace) TableName() string {
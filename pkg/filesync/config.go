@@ -1,6 +1,9 @@
 package filesync
 
 import (
+	"crypto/ed25519"
+	"crypto/tls"
+	"net"
 	"strings"
 )
 
@@ -15,6 +18,20 @@ var (
 	TLSEnabled  bool
 	TLSCertFile string
 	TLSKeyFile  string
+	// ServerTLSConfig 生成server的TLS配置，为空时只使用server证书
+	ServerTLSConfig func(certificate tls.Certificate) *tls.Config
+	// ClientTLSConfig worker连接server的TLS配置，为空时不验证server证书
+	ClientTLSConfig *tls.Config
+	// CheckAuthKey server检查worker的认证，为空时只比较authKey
+	CheckAuthKey func(conn net.Conn, authKey string) bool
+	// ReleaseProvider server按worker的标签获取同步的发布版本，为空或没有发布版本时同步server当前的文件
+	ReleaseProvider func(workerTags []string) *SignedRelease
+	// ReleasePublicKey worker验证发布版本签名的公钥，只同步签名正确的发布版本；为空时不同步文件
+	ReleasePublicKey ed25519.PublicKey
+	// ReleaseInsecure worker没有公钥时不验证签名，同步未签名的版本或server当前的文件（-release-insecure）
+	ReleaseInsecure bool
+	// WorkerTags worker的标签
+	WorkerTags []string
 )
 
 // checkFileIsSyncWhileList 同步文件的白名单校验
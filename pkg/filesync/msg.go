@@ -6,14 +6,19 @@ import "os"
 
 // Message 文件同步交互消息
 type Message struct {
-	MgAuthKey  string
-	MgType     string //
-	MgByte     []byte //
-	MgString   string //
-	MgStrings  []string
-	MgFileMode os.FileMode
-	Del        bool // whether should the not exist files in src be deleted.
-	Overwrite  bool // whether the conflicted files be
+	MgAuthKey   string
+	MgType      string //
+	MgByte      []byte //
+	MgString    string //
+	MgStrings   []string
+	MgFileMode  os.FileMode
+	Del         bool     // whether should the not exist files in src be deleted.
+	Overwrite   bool     // whether the conflicted files be
+	MgProfile   string   // worker同步的profile，为空时同步全部文件
+	MgCompress  bool     // 请求时表示worker支持压缩，响应时表示文件内容已使用gzip压缩
+	MgTags      []string // worker的标签，server按标签选择同步的发布版本
+	MgSignature []byte   // 发布版本的manifest（MgByte）的签名
+	MgHash      string   // 请求发布版本中的文件时，文件内容的sha256
 }
 
 const (
@@ -24,3 +29,6 @@ const (
 	MsgEnd      = "END"
 	MsgError    = "ERROR"
 )
+
+// MessageExchange worker发送同步消息，并返回server响应的消息
+type MessageExchange func(mg Message) (Message, error)
@@ -0,0 +1,72 @@
+package filesync
+
+import (
+	"sort"
+	"strings"
+)
+
+// syncProfilePaths 只同步到执行指定任务类型的worker的文件或目录，不在列表中的文件同步到所有worker；
+// 任务类型与worker执行任务的队列名称一致（active、finger、passive、pocscan）
+var syncProfilePaths = map[string][]string{
+	"thirdparty/xray":                   {"pocscan"},
+	"thirdparty/nuclei":                 {"pocscan"},
+	"thirdparty/goby":                   {"pocscan"},
+	"thirdparty/dict/dicc.txt":          {"pocscan"},
+	"thirdparty/dict/400_blacklist.txt": {"pocscan"},
+	"thirdparty/dict/403_blacklist.txt": {"pocscan"},
+	"thirdparty/fingerprinthub":         {"active", "finger"},
+	"thirdparty/httpx":                  {"active", "finger"},
+	"thirdparty/massdns":                {"active", "passive"},
+	"thirdparty/subfinder":              {"active", "passive"},
+}
+
+// SyncProfile 根据worker执行的任务类型生成同步的profile；为空时同步全部文件
+func SyncProfile(taskTypes []string) string {
+	typeMap := make(map[string]struct{})
+	for _, t := range taskTypes {
+		if t = strings.TrimSpace(t); t != "" {
+			typeMap[t] = struct{}{}
+		}
+	}
+	var types []string
+	for t := range typeMap {
+		types = append(types, t)
+	}
+	sort.Strings(types)
+	return strings.Join(types, ",")
+}
+
+// CheckFileInSyncProfile 检查文件是否需要同步到指定profile的worker
+func CheckFileInSyncProfile(filePathName, profile string) bool {
+	if profile == "" {
+		return true
+	}
+	for p, taskTypes := range syncProfilePaths {
+		if filePathName != p && !strings.HasPrefix(filePathName, p+"/") {
+			continue
+		}
+		for _, t := range taskTypes {
+			for _, pt := range strings.Split(profile, ",") {
+				if t == pt {
+					return true
+				}
+			}
+		}
+		return false
+	}
+	return true
+}
+
+// filterSyncProfile 过滤出需要同步到指定profile的worker的文件md5列表
+func filterSyncProfile(md5List []string, profile string) []string {
+	if profile == "" {
+		return md5List
+	}
+	var files []string
+	for _, f := range md5List {
+		if CheckFileInSyncProfile(strings.SplitN(f, ",,", 2)[0], profile) {
+			files = append(files, f)
+		}
+	}
+	return files
+}
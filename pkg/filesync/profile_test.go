@@ -0,0 +1,47 @@
+package filesync
+
+import (
+	"bytes"
+	"testing"
+)
+
+func TestCheckFileInSyncProfile(t *testing.T) {
+	profile := SyncProfile([]string{"passive", "active", "passive"})
+	if profile != "active,passive" {
+		t.Errorf("profile:%s", profile)
+	}
+	files := map[string]bool{
+		"conf/worker.yml":                 true,
+		"thirdparty/massdns":              true,
+		"thirdparty/subfinder/subfinder":  true,
+		"thirdparty/xray":                 false,
+		"thirdparty/xray/xray":            false,
+		"thirdparty/dict/dicc.txt":        false,
+		"thirdparty/dict/subnames.txt":    true,
+		"thirdparty/xray_custom/poc.yaml": true,
+	}
+	for f, expected := range files {
+		if CheckFileInSyncProfile(f, profile) != expected {
+			t.Errorf("%s:%v", f, !expected)
+		}
+		if !CheckFileInSyncProfile(f, "") {
+			t.Errorf("%s not in full profile", f)
+		}
+	}
+}
+
+func TestGzipCompress(t *testing.T) {
+	content := bytes.Repeat([]byte("nemo filesync\n"), 1000)
+	compressed, err := gzipCompress(content)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Log(len(content), len(compressed))
+	decompressed, err := gzipDecompress(compressed)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(content, decompressed) {
+		t.Error("decompressed content not equal")
+	}
+}
@@ -0,0 +1,212 @@
+package filesync
+
+import (
+	"crypto/ed25519"
+	"crypto/md5"
+	"crypto/sha256"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"github.com/hanc00l/nemo_go/pkg/conf"
+	"os"
+	"path"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"time"
+)
+
+// ReleaseBlobPath server保存发布版本中文件内容的目录，文件名为内容的sha256（不在同步的白名单中）
+const ReleaseBlobPath = "release/blobs"
+
+var sha256Regexp = regexp.MustCompile(`^[0-9a-f]{64}$`)
+
+// ReleaseFile 发布版本中的一个文件
+type ReleaseFile struct {
+	Path   string      `json:"path"`
+	Md5    string      `json:"md5"`
+	Sha256 string      `json:"sha256"`
+	Size   int64       `json:"size"`
+	Mode   os.FileMode `json:"mode"`
+}
+
+// ReleaseManifest 发布版本的manifest：同步到worker的全部文件及其sha256
+type ReleaseManifest struct {
+	Version     int           `json:"version"`
+	Description string        `json:"description"`
+	CreateTime  time.Time     `json:"create_time"`
+	Files       []ReleaseFile `json:"files"`
+}
+
+// SignedRelease 签名的发布版本
+type SignedRelease struct {
+	Manifest  []byte
+	Signature []byte
+}
+
+// BuildReleaseManifest server根据当前需要同步的文件生成发布版本，并将文件内容保存到ReleaseBlobPath
+func BuildReleaseManifest(version int, description string) (*ReleaseManifest, error) {
+	rootPath, err := filepath.Abs(conf.GetRootPath())
+	if err != nil {
+		return nil, err
+	}
+	md5List, err := Traverse(rootPath)
+	if err != nil {
+		return nil, err
+	}
+	manifest := &ReleaseManifest{Version: version, Description: description, CreateTime: time.Now()}
+	for file := range md5ListToMap(md5List) {
+		rf, err := saveReleaseBlob(rootPath, file)
+		if err != nil {
+			return nil, err
+		}
+		manifest.Files = append(manifest.Files, rf)
+	}
+	if len(manifest.Files) == 0 {
+		return nil, errors.New("emtry file list")
+	}
+	sort.Slice(manifest.Files, func(i, j int) bool {
+		return manifest.Files[i].Path < manifest.Files[j].Path
+	})
+	return manifest, nil
+}
+
+// saveReleaseBlob 保存文件内容到ReleaseBlobPath，内容相同的文件只保存一份
+func saveReleaseBlob(rootPath, file string) (rf ReleaseFile, err error) {
+	st, err := os.Stat(filepath.Join(rootPath, file))
+	if err != nil {
+		return
+	}
+	content, err := os.ReadFile(filepath.Join(rootPath, file))
+	if err != nil {
+		return
+	}
+	rf = ReleaseFile{
+		Path:   file,
+		Md5:    fmt.Sprintf("%x", md5.Sum(content)),
+		Sha256: fmt.Sprintf("%x", sha256.Sum256(content)),
+		Size:   int64(len(content)),
+		Mode:   st.Mode().Perm(),
+	}
+	blobFile := filepath.Join(rootPath, ReleaseBlobPath, rf.Sha256)
+	if _, err = os.Stat(blobFile); err == nil {
+		return
+	}
+	if err = os.MkdirAll(filepath.Dir(blobFile), 0700); err != nil {
+		return
+	}
+	err = writeFileAtomic(blobFile, content, 0600)
+	return
+}
+
+// readReleaseBlob server读取发布版本中的文件内容
+func readReleaseBlob(fileSha256 string) ([]byte, error) {
+	if !sha256Regexp.MatchString(fileSha256) {
+		return nil, errors.New("invalid file hash")
+	}
+	return os.ReadFile(filepath.Join(conf.GetRootPath(), ReleaseBlobPath, fileSha256))
+}
+
+// CleanReleaseBlobs server删除不在保留的发布版本中的文件内容
+func CleanReleaseBlobs(manifests []*ReleaseManifest) (count int, err error) {
+	keep := make(map[string]struct{})
+	for _, m := range manifests {
+		for _, f := range m.Files {
+			keep[f.Sha256] = struct{}{}
+		}
+	}
+	blobPath := filepath.Join(conf.GetRootPath(), ReleaseBlobPath)
+	entries, err := os.ReadDir(blobPath)
+	if err != nil {
+		if os.IsNotExist(err) {
+			err = nil
+		}
+		return
+	}
+	for _, e := range entries {
+		if _, ok := keep[e.Name()]; ok || e.IsDir() {
+			continue
+		}
+		if err = os.Remove(filepath.Join(blobPath, e.Name())); err != nil {
+			return
+		}
+		count++
+	}
+	return
+}
+
+// SignRelease 使用私钥对发布版本签名
+func SignRelease(key ed25519.PrivateKey, manifest *ReleaseManifest) (*SignedRelease, error) {
+	content, err := json.Marshal(manifest)
+	if err != nil {
+		return nil, err
+	}
+	return &SignedRelease{Manifest: content, Signature: ed25519.Sign(key, content)}, nil
+}
+
+// VerifyRelease 验证发布版本的签名，并检查manifest中的文件
+func VerifyRelease(publicKey ed25519.PublicKey, manifestContent, signature []byte) (*ReleaseManifest, error) {
+	if len(publicKey) != ed25519.PublicKeySize {
+		return nil, errors.New("invalid release public key")
+	}
+	if !ed25519.Verify(publicKey, manifestContent, signature) {
+		return nil, errors.New("invalid release signature")
+	}
+	return ParseReleaseManifest(manifestContent)
+}
+
+// ParseReleaseManifest 解析发布版本的manifest，并检查其中的文件
+func ParseReleaseManifest(manifestContent []byte) (*ReleaseManifest, error) {
+	manifest := &ReleaseManifest{}
+	if err := json.Unmarshal(manifestContent, manifest); err != nil {
+		return nil, err
+	}
+	for _, f := range manifest.Files {
+		if !checkFileIsSyncWhileList(f.Path) || path.IsAbs(f.Path) || !sha256Regexp.MatchString(f.Sha256) {
+			return nil, fmt.Errorf("invalid release file:%s", f.Path)
+		}
+	}
+	return manifest, nil
+}
+
+// md5List 生成与Traverse格式一致的文件md5列表，包括文件所在的目录
+func (m *ReleaseManifest) md5List() (md5List []string) {
+	dirs := make(map[string]struct{})
+	for _, f := range m.Files {
+		for dir := path.Dir(f.Path); dir != "." && dir != "/"; dir = path.Dir(dir) {
+			dirs[dir] = struct{}{}
+		}
+		md5List = append(md5List, f.Path+",,"+f.Md5)
+	}
+	for dir := range dirs {
+		md5List = append(md5List, dir+",,Directory")
+	}
+	sort.Strings(md5List)
+	return
+}
+
+// fileMap 发布版本中的文件名与文件的对应关系
+func (m *ReleaseManifest) fileMap() map[string]ReleaseFile {
+	files := make(map[string]ReleaseFile)
+	for _, f := range m.Files {
+		files[f.Path] = f
+	}
+	return files
+}
+
+// writeFileAtomic 写入临时文件后替换原文件，避免替换正在执行的文件失败或写入不完整的文件
+func writeFileAtomic(fileName string, content []byte, mode os.FileMode) error {
+	tmpFile := fileName + ".nemo_sync_tmp"
+	if err := os.WriteFile(tmpFile, content, mode); err != nil {
+		return err
+	}
+	if err := os.Chmod(tmpFile, mode); err != nil {
+		os.Remove(tmpFile)
+		return err
+	}
+	if err := os.Rename(tmpFile, fileName); err != nil {
+		os.Remove(tmpFile)
+		return err
+	}
+	return nil
+}
@@ -0,0 +1,50 @@
+package filesync
+
+import (
+	"crypto/ed25519"
+	"crypto/rand"
+	"strings"
+	"testing"
+)
+
+func TestVerifyRelease(t *testing.T) {
+	publicKey, key, err := ed25519.GenerateKey(rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	manifest := &ReleaseManifest{
+		Version: 1,
+		Files: []ReleaseFile{
+			{Path: "thirdparty/xray/xray", Md5: "md5", Sha256: strings.Repeat("a", 64), Mode: 0755},
+		},
+	}
+	release, err := SignRelease(key, manifest)
+	if err != nil {
+		t.Fatal(err)
+	}
+	m, err := VerifyRelease(publicKey, release.Manifest, release.Signature)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if md5List := m.md5List(); len(md5List) != 3 || md5List[2] != "thirdparty/xray/xray,,md5" {
+		t.Errorf("md5 list:%v", md5List)
+	}
+	// 篡改后的manifest及其它私钥的签名都不能通过验证
+	tampered := []byte(strings.Replace(string(release.Manifest), strings.Repeat("a", 64), strings.Repeat("b", 64), 1))
+	if _, err = VerifyRelease(publicKey, tampered, release.Signature); err == nil {
+		t.Error("tampered manifest verified")
+	}
+	otherPublicKey, _, _ := ed25519.GenerateKey(rand.Reader)
+	if _, err = VerifyRelease(otherPublicKey, release.Manifest, release.Signature); err == nil {
+		t.Error("signature verified by other key")
+	}
+	if _, err = VerifyRelease(nil, release.Manifest, release.Signature); err == nil {
+		t.Error("release verified without key")
+	}
+	// 不在同步白名单中的文件
+	manifest.Files[0].Path = "conf/../../etc/passwd"
+	release, _ = SignRelease(key, manifest)
+	if _, err = VerifyRelease(publicKey, release.Manifest, release.Signature); err == nil {
+		t.Error("invalid file path verified")
+	}
+}
@@ -0,0 +1,85 @@
+package filesync
+
+import (
+	"crypto/md5"
+	"encoding/json"
+	"fmt"
+	"github.com/hanc00l/nemo_go/pkg/conf"
+	"github.com/hanc00l/nemo_go/pkg/logging"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+// syncRevisionFile worker保存最近一次完成的文件同步版本的文件（不在同步的白名单中）
+const syncRevisionFile = "log/filesync.json"
+
+// SyncRevision 文件同步的版本：由server同步到worker的全部文件的md5列表生成
+type SyncRevision struct {
+	Revision string    `json:"revision"`
+	Profile  string    `json:"profile"`
+	Release  int       `json:"release"` //同步的发布版本，没有发布版本时为0
+	SyncTime time.Time `json:"sync_time"`
+}
+
+var (
+	serverRevisionMutex sync.Mutex
+	// serverMd5List server最近一次向worker提供的全部文件的md5列表
+	serverMd5List []string
+	// serverRevisions 每个profile的同步版本
+	serverRevisions = make(map[string]string)
+)
+
+// Revision 根据文件的md5列表生成同步版本
+func Revision(md5List []string) string {
+	files := make([]string, len(md5List))
+	copy(files, md5List)
+	sort.Strings(files)
+	return fmt.Sprintf("%x", md5.Sum([]byte(strings.Join(files, "\n"))))[:12]
+}
+
+// ServerRevision server最近一次向worker提供的指定profile的同步版本，还没有worker同步时为空
+func ServerRevision(profile string) string {
+	serverRevisionMutex.Lock()
+	defer serverRevisionMutex.Unlock()
+
+	if serverMd5List == nil {
+		return ""
+	}
+	if _, ok := serverRevisions[profile]; !ok {
+		serverRevisions[profile] = Revision(filterSyncProfile(serverMd5List, profile))
+	}
+	return serverRevisions[profile]
+}
+
+// setServerMd5List 记录server向worker提供的全部文件的md5列表，各profile的同步版本在使用时重新生成
+func setServerMd5List(md5List []string) {
+	serverRevisionMutex.Lock()
+	defer serverRevisionMutex.Unlock()
+
+	serverMd5List = md5List
+	serverRevisions = make(map[string]string)
+}
+
+// LoadSyncRevision 读取worker最近一次完成的文件同步版本
+func LoadSyncRevision() (r SyncRevision) {
+	content, err := os.ReadFile(filepath.Join(conf.GetRootPath(), syncRevisionFile))
+	if err != nil {
+		return
+	}
+	if err = json.Unmarshal(content, &r); err != nil {
+		logging.RuntimeLog.Warningf("invalid file sync revision:%v", err)
+	}
+	return
+}
+
+// saveSyncRevision worker保存完成的文件同步版本
+func saveSyncRevision(revision, profile string, release int) {
+	content, _ := json.Marshal(SyncRevision{Revision: revision, Profile: profile, Release: release, SyncTime: time.Now()})
+	if err := os.WriteFile(filepath.Join(conf.GetRootPath(), syncRevisionFile), content, 0644); err != nil {
+		logging.RuntimeLog.Errorf("save file sync revision fail:%v", err)
+	}
+}
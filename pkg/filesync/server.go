@@ -4,6 +4,8 @@ package filesync
 
 import (
 	"bufio"
+	"bytes"
+	"compress/gzip"
 	"crypto/tls"
 	"encoding/gob"
 	"fmt"
@@ -66,6 +68,9 @@ func StartFileSyncServer(host, port, authKey string) {
 			return
 		}
 		configs := &tls.Config{Certificates: []tls.Certificate{cert}}
+		if ServerTLSConfig != nil {
+			configs = ServerTLSConfig(cert)
+		}
 		srv, err = tls.Listen("tcp", serverAddr, configs)
 	} else {
 		srv, err = net.Listen("tcp", serverAddr)
@@ -100,7 +105,7 @@ func handleSync(conn net.Conn, authKey string) {
 			return
 		}
 		// 检查authKey，如果不通过直接返回
-		if success := checkSyncAuthKey(authKey, mg.MgAuthKey, gbc); success == false {
+		if success := checkSyncAuthKey(conn, authKey, mg.MgAuthKey, gbc); success == false {
 			logging.RuntimeLog.Warningf("invalid auth from %s", conn.RemoteAddr().String())
 			logging.CLILog.Warningf("invalid auth from %s", conn.RemoteAddr().String())
 			return
@@ -110,64 +115,89 @@ func handleSync(conn net.Conn, authKey string) {
 		case MsgSync:
 			logging.RuntimeLog.Infof("file sync from %s", conn.RemoteAddr().String())
 			logging.CLILog.Infof("file sync from %s", conn.RemoteAddr().String())
-			hdSync(gbc)
 		// 请求文件传输
 		case MsgTran:
-			hdTranFile(&mg, gbc)
 		// 结束
 		case MsgEnd:
 			return
 		// 未知消息
 		default:
-			hdNoType(gbc)
+			writeErrorMg("error, not a recognizable message.", gbc)
 			return
 		}
+		if err = gbc.gobConnWt(HandleMessage(&mg)); err != nil {
+			logging.RuntimeLog.Error(err)
+			logging.CLILog.Error(err)
+		}
+	}
+}
+
+// HandleMessage 处理worker的同步请求（已通过认证），返回响应的消息
+func HandleMessage(mg *Message) Message {
+	switch mg.MgType {
+	// 请求同步
+	case MsgSync:
+		return hdSync(mg)
+	// 请求文件传输
+	case MsgTran:
+		return hdTranFile(mg)
 	}
+	return errorMg("error, not a recognizable message.")
 }
 
-// hdSync 处理worker的全部文件同步请求
-func hdSync(gbc *GobConn) {
+// hdSync 处理worker的全部文件同步请求，只返回worker的profile需要同步的文件
+func hdSync(mg *Message) Message {
 	srcPath, err := filepath.Abs(conf.GetRootPath())
 	if err != nil {
 		logging.RuntimeLog.Error(err)
 		logging.CLILog.Error(err)
-		writeErrorMg(err.Error(), gbc)
-		return
+		return errorMg(err.Error())
 	}
 	//srcPath := "/tmp/test/src"
 	fileMd5List, err := Traverse(srcPath)
 	if err != nil {
 		logging.RuntimeLog.Error(err)
 		logging.CLILog.Error(err)
-		writeErrorMg(err.Error(), gbc)
-		return
+		return errorMg(err.Error())
 	}
 	if len(fileMd5List) == 0 {
-		writeErrorMg("emtry file list", gbc)
-		return
+		return errorMg("emtry file list")
 	}
-	cr := Message{
-		MgStrings: fileMd5List,
+	setServerMd5List(fileMd5List)
+	// 有发布版本时同步worker对应的发布版本，worker验证签名后按manifest同步文件
+	if ReleaseProvider != nil {
+		if release := ReleaseProvider(mg.MgTags); release != nil {
+			manifest, err := ParseReleaseManifest(release.Manifest)
+			if err != nil {
+				logging.RuntimeLog.Error(err)
+				logging.CLILog.Error(err)
+				return errorMg(err.Error())
+			}
+			return Message{
+				MgStrings:   filterSyncProfile(manifest.md5List(), mg.MgProfile),
+				MgType:      MsgMd5List,
+				Overwrite:   true,
+				MgProfile:   mg.MgProfile,
+				MgByte:      release.Manifest,
+				MgSignature: release.Signature,
+			}
+		}
+	}
+	return Message{
+		MgStrings: filterSyncProfile(fileMd5List, mg.MgProfile),
 		MgType:    MsgMd5List,
 		Overwrite: true,
-	}
-	err = gbc.gobConnWt(cr)
-	if err != nil {
-		logging.RuntimeLog.Error(err)
-		logging.CLILog.Error(err)
-		return
+		MgProfile: mg.MgProfile,
 	}
 }
 
 // hdTranFile 向worker同步一个文件
-func hdTranFile(mg *Message, gbc *GobConn) {
+func hdTranFile(mg *Message) Message {
 	if len(mg.MgString) <= 0 {
-		writeErrorMg("no file to transfer", gbc)
-		return
+		return errorMg("no file to transfer")
 	}
 	if checkFileIsSyncWhileList(mg.MgString) == false {
-		writeErrorMg("invalid file or path to sync", gbc)
-		return
+		return errorMg("invalid file or path to sync")
 	}
 
 	//srcPath := "/tmp/test/src"
@@ -175,41 +205,60 @@ func hdTranFile(mg *Message, gbc *GobConn) {
 	if err != nil {
 		logging.RuntimeLog.Error(err)
 		logging.CLILog.Error(err)
-		writeErrorMg(err.Error(), gbc)
-		return
+		return errorMg(err.Error())
 	}
 	srcPathFileName := filepath.Join(srcPath, mg.MgString)
 	var cr Message
-	st, err := os.Stat(srcPathFileName)
-	if err != nil {
-		writeErrorMg(fmt.Sprintf("read sync file:%s error", err), gbc)
-		return
+	if mg.MgHash != "" {
+		// 发布版本中的文件，按内容的sha256读取
+		cr.MgFileMode = 0644
+		cr.MgByte, err = readReleaseBlob(mg.MgHash)
+		if err != nil {
+			return errorMg(fmt.Sprintf("read release file:%s error", mg.MgString))
+		}
+	} else {
+		st, err := os.Stat(srcPathFileName)
+		if err != nil {
+			return errorMg(fmt.Sprintf("read sync file:%s error", err))
+		}
+		cr.MgFileMode = st.Mode()
+		cr.MgByte, err = os.ReadFile(srcPathFileName)
+		if err != nil {
+			return errorMg(fmt.Sprintf("read sync file:%s error", srcPathFileName))
+		}
 	}
-	cr.MgFileMode = st.Mode()
-	cr.MgByte, err = os.ReadFile(srcPathFileName)
-	if err != nil {
-		writeErrorMg(fmt.Sprintf("read sync file:%s error", srcPathFileName), gbc)
-		return
+	// worker支持压缩，且压缩后更小时发送压缩的内容
+	if mg.MgCompress {
+		if compressed, err := gzipCompress(cr.MgByte); err == nil && len(compressed) < len(cr.MgByte) {
+			cr.MgByte = compressed
+			cr.MgCompress = true
+		}
 	}
 	cr.MgType = MsgTranData
-	err = gbc.gobConnWt(cr)
-	if err != nil {
-		logging.RuntimeLog.Error(err)
-		logging.CLILog.Error(err)
+	return cr
+}
+
+// gzipCompress 使用gzip压缩文件内容
+func gzipCompress(content []byte) ([]byte, error) {
+	var buf bytes.Buffer
+	gw := gzip.NewWriter(&buf)
+	if _, err := gw.Write(content); err != nil {
+		return nil, err
 	}
+	if err := gw.Close(); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
 }
 
-// hdNoType 处理消息类型错误
-func hdNoType(gbc *GobConn) {
-	writeErrorMg("error, not a recognizable message.", gbc)
+// errorMg 错误信息的消息
+func errorMg(message string) Message {
+	return Message{MgType: MsgError, MgString: message}
 }
 
 // writeErrorMg 返回错误信息的消息
 func writeErrorMg(message string, gbc *GobConn) {
-	var errMsg Message
-	errMsg.MgType = MsgError
-	errMsg.MgString = message
-	sendErr := gbc.gobConnWt(errMsg)
+	sendErr := gbc.gobConnWt(errorMg(message))
 	if sendErr != nil {
 		logging.RuntimeLog.Error(sendErr)
 		logging.CLILog.Error(sendErr)
@@ -217,8 +266,12 @@ func writeErrorMg(message string, gbc *GobConn) {
 }
 
 // checkSyncAuthKey 同步的认证检查
-func checkSyncAuthKey(authKey, workerAuthKey string, gbc *GobConn) (success bool) {
-	if workerAuthKey == authKey {
+func checkSyncAuthKey(conn net.Conn, authKey, workerAuthKey string, gbc *GobConn) (success bool) {
+	if CheckAuthKey != nil {
+		if CheckAuthKey(conn, workerAuthKey) {
+			return true
+		}
+	} else if workerAuthKey == authKey {
 		return true
 	}
 	writeErrorMg("authKey error!", gbc)
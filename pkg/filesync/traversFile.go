@@ -11,6 +11,20 @@ import (
 	"path/filepath"
 	"runtime"
 	"strings"
+	"sync"
+	"time"
+)
+
+// fileMd5 缓存的文件md5值，文件的大小和修改时间不变时不再重新计算
+type fileMd5 struct {
+	size    int64
+	modTime time.Time
+	md5     string
+}
+
+var (
+	fileMd5Cache      = make(map[string]fileMd5)
+	fileMd5CacheMutex sync.Mutex
 )
 
 // Traverse walk要同步的文件, 生成md5, 并返回列表
@@ -51,7 +65,7 @@ func Traverse(path string) ([]string, error) {
 		}
 		if info.Mode().IsRegular() {
 			// if !info.IsDir() {
-			md5Str, fErr = Md5OfAFile(path)
+			md5Str, fErr = cachedMd5OfAFile(filepath.Join(dir, path), info)
 			if fErr != nil {
 				return fErr
 			}
@@ -88,6 +102,24 @@ func Traverse(path string) ([]string, error) {
 	return md5List, nil
 }
 
+// cachedMd5OfAFile 计算文件的md5值，优先使用缓存
+func cachedMd5OfAFile(f string, info os.FileInfo) (string, error) {
+	fileMd5CacheMutex.Lock()
+	cache, ok := fileMd5Cache[f]
+	fileMd5CacheMutex.Unlock()
+	if ok && cache.size == info.Size() && cache.modTime.Equal(info.ModTime()) {
+		return cache.md5, nil
+	}
+	md5Str, err := Md5OfAFile(f)
+	if err != nil {
+		return "", err
+	}
+	fileMd5CacheMutex.Lock()
+	fileMd5Cache[f] = fileMd5{size: info.Size(), modTime: info.ModTime(), md5: md5Str}
+	fileMd5CacheMutex.Unlock()
+	return md5Str, nil
+}
+
 func Md5OfAFile(f string) (string, error) {
 	fi, fiErr := os.Open(f)
 	if fiErr != nil {
@@ -3,10 +3,16 @@
 package filesync
 
 import (
+	"bytes"
+	"compress/gzip"
+	"crypto/md5"
+	"crypto/sha256"
 	"crypto/tls"
+	"errors"
 	"fmt"
 	"github.com/hanc00l/nemo_go/pkg/conf"
 	"github.com/hanc00l/nemo_go/pkg/logging"
+	"io"
 	"net"
 	"os"
 	"path/filepath"
@@ -14,14 +20,18 @@ import (
 	"strings"
 )
 
-// WorkerStartupSync worker在启动时进行文件同步
-func WorkerStartupSync(host, port, authKey string) {
-	serverAddr := fmt.Sprintf("%s:%s", host, port)
+// WorkerStartupSync worker在启动时进行文件同步，profile为空时同步全部文件
+func WorkerStartupSync(host, port, authKey, profile string) {
+	serverAddr := net.JoinHostPort(host, port)
 	// 1 连接到server
 	var conn net.Conn
 	var err error
 	if TLSEnabled {
-		conn, err = tls.Dial("tcp", serverAddr, &tls.Config{InsecureSkipVerify: true})
+		config := ClientTLSConfig
+		if config == nil {
+			config = &tls.Config{InsecureSkipVerify: true}
+		}
+		conn, err = tls.Dial("tcp", serverAddr, config)
 	} else {
 		conn, err = net.Dial("tcp", serverAddr)
 	}
@@ -33,17 +43,31 @@ func WorkerStartupSync(host, port, authKey string) {
 	defer conn.Close()
 
 	gbc := initGobConn(conn)
-	// 2 发送SYNC请求
-	msgSync := Message{MgType: MsgSync, MgAuthKey: authKey}
-	encErr := gbc.gobConnWt(msgSync)
+	workerSync(authKey, profile, func(mg Message) (reply Message, err error) {
+		if err = gbc.gobConnWt(mg); err != nil {
+			return
+		}
+		err = gbc.Dec.Decode(&reply)
+		return
+	})
+	// 6 结束同步
+	endMsg := Message{MgType: MsgEnd, MgAuthKey: authKey}
+	encErr := gbc.gobConnWt(endMsg)
 	if encErr != nil {
 		logging.CLILog.Error(encErr)
 		logging.RuntimeLog.Error(encErr)
-		return
 	}
-	// 3 服务器返回信息
-	var hostMessage Message
-	err = gbc.Dec.Decode(&hostMessage)
+}
+
+// WorkerSyncByExchange worker通过指定的消息交换方式（如HTTP）进行文件同步，认证由exchange处理
+func WorkerSyncByExchange(exchange MessageExchange, profile string) {
+	workerSync("", profile, exchange)
+}
+
+// workerSync 请求server的文件列表，并同步有变化的文件
+func workerSync(authKey, profile string, exchange MessageExchange) {
+	// 2 发送SYNC请求，3 服务器返回信息
+	hostMessage, err := exchange(Message{MgType: MsgSync, MgAuthKey: authKey, MgProfile: profile, MgTags: WorkerTags})
 	if err != nil {
 		logging.CLILog.Error(err)
 		logging.RuntimeLog.Error(err)
@@ -54,30 +78,75 @@ func WorkerStartupSync(host, port, authKey string) {
 		logging.RuntimeLog.Errorf("get sync file fail:%s", hostMessage.MgString)
 		return
 	}
+	// 有发布版本时，验证签名后按manifest中的文件同步；没有公钥时只在-release-insecure时同步
+	if ReleasePublicKey == nil && !ReleaseInsecure {
+		logging.CLILog.Error("no release public key,file sync refused")
+		logging.RuntimeLog.Error("no release public key,file sync refused")
+		return
+	}
+	var releaseFiles map[string]ReleaseFile
+	var releaseVersion int
+	if len(hostMessage.MgByte) > 0 || ReleasePublicKey != nil {
+		manifest, err := verifyHostRelease(&hostMessage)
+		if err != nil {
+			logging.CLILog.Errorf("verify release fail:%v", err)
+			logging.RuntimeLog.Errorf("verify release fail:%v", err)
+			return
+		}
+		hostMessage.MgStrings = filterSyncProfile(manifest.md5List(), profile)
+		releaseFiles = manifest.fileMap()
+		releaseVersion = manifest.Version
+		logging.CLILog.Infof("sync release version:%d", releaseVersion)
+	}
 	// 4 获取服务器所有文件及md5值,并预处理本地的路径和文件
-	transFiles, err := doFileMd5List(&hostMessage)
-	if err == nil {
-		logging.CLILog.Infof("file needed sync: %d", len(transFiles))
-		// 5 同步文件
-		for i, file := range transFiles {
-			status := doTranFile(file, authKey, gbc)
-			logging.CLILog.Infof("%d %s %v", i+1, file, status)
-
+	transFiles, localPaths, err := doFileMd5List(&hostMessage)
+	if err != nil {
+		return
+	}
+	localFiles := make(map[string]string)
+	for file, fileMd5 := range localPaths {
+		localFiles[fileMd5] = file
+	}
+	logging.CLILog.Infof("file needed sync: %d", len(transFiles))
+	// 5 同步文件：本地已有相同内容（md5相同）的文件直接复制，不再从server传输
+	hostFilesMd5 := md5ListToMap(hostMessage.MgStrings)
+	allSynced := true
+	var copied int
+	for i, file := range transFiles {
+		var status bool
+		var releaseFile *ReleaseFile
+		if rf, ok := releaseFiles[file]; ok {
+			releaseFile = &rf
+		}
+		fileMd5 := hostFilesMd5[file]
+		if localFile, ok := localFiles[fileMd5]; ok && copyLocalFile(localFile, file, fileMd5, releaseFile) {
+			status = true
+			copied++
+		} else {
+			status = doTranFile(file, authKey, exchange, releaseFile)
 		}
-		logging.RuntimeLog.Info("finish file sync")
-		logging.CLILog.Info("finish file sync")
+		if status {
+			// 文件被覆盖后，原内容不能再作为复制的来源
+			if oldMd5, ok := localPaths[file]; ok && localFiles[oldMd5] == file {
+				delete(localFiles, oldMd5)
+			}
+			localPaths[file] = fileMd5
+			localFiles[fileMd5] = file
+		}
+		logging.CLILog.Infof("%d %s %v", i+1, file, status)
+		allSynced = allSynced && status
 	}
-	// 6 结束同步
-	endMsg := Message{MgType: MsgEnd, MgAuthKey: authKey}
-	encErr = gbc.gobConnWt(endMsg)
-	if encErr != nil {
-		logging.CLILog.Error(encErr)
-		logging.RuntimeLog.Error(encErr)
+	// 全部文件同步成功时，记录同步的版本
+	if allSynced {
+		saveSyncRevision(Revision(hostMessage.MgStrings), profile, releaseVersion)
 	}
+	logging.RuntimeLog.Infof("finish file sync, %d files synced, %d copied from local", len(transFiles), copied)
+	logging.CLILog.Infof("finish file sync, %d files synced, %d copied from local", len(transFiles), copied)
 }
 
-// doFileMd5List 读取worker本地文件列表及md5值，并与服务端进行对比，确定需要同步的文件列表
-func doFileMd5List(mg *Message) (transFiles []string, err error) {
+// doFileMd5List 读取worker本地文件列表及md5值，并与服务端进行对比，确定需要同步的文件列表；
+// localPaths为本地文件名与md5的对应关系
+func doFileMd5List(mg *Message) (transFiles []string, localPaths map[string]string, err error) {
 	//srcPath := "/tmp/test/dst"
 	var srcPath string
 	srcPath, err = filepath.Abs(conf.GetRootPath())
@@ -106,7 +175,7 @@ func doFileMd5List(mg *Message) (transFiles []string, err error) {
 	if fErr != nil {
 		logging.CLILog.Error(err)
 		logging.RuntimeLog.Error(err)
-		return nil, fErr
+		return nil, nil, fErr
 	}
 	defer os.Chdir(cwd)
 
@@ -117,12 +186,77 @@ func doFileMd5List(mg *Message) (transFiles []string, err error) {
 		return
 	}
 	sort.Strings(transFiles)
+	localPaths = md5ListToMap(localFilesMd5)
 
 	return
 }
 
-// doTranFile worker向server请求同步一个文件
-func doTranFile(filePathName, authKey string, gbc *GobConn) bool {
+// md5ListToMap 将文件md5列表转换为文件名与md5的对应关系，不包括目录和符号链接
+func md5ListToMap(md5List []string) map[string]string {
+	files := make(map[string]string)
+	for _, f := range md5List {
+		arr := strings.Split(f, ",,")
+		if len(arr) != 2 || arr[1] == "Directory" || strings.HasPrefix(arr[1], "symbolLink&&") {
+			continue
+		}
+		files[arr[0]] = arr[1]
+	}
+	return files
+}
+
+// verifyHostRelease 验证server发送的发布版本的签名；worker有公钥时只接受签名正确的发布版本
+func verifyHostRelease(hostMessage *Message) (*ReleaseManifest, error) {
+	if len(hostMessage.MgByte) == 0 {
+		return nil, errors.New("server has no signed release")
+	}
+	if ReleasePublicKey == nil && ReleaseInsecure {
+		logging.CLILog.Warning("release insecure mode,release signature not verified")
+		logging.RuntimeLog.Warning("release insecure mode,release signature not verified")
+		return ParseReleaseManifest(hostMessage.MgByte)
+	}
+	return VerifyRelease(ReleasePublicKey, hostMessage.MgByte, hostMessage.MgSignature)
+}
+
+// checkReleaseFile 检查文件内容与发布版本中的sha256是否一致
+func checkReleaseFile(content []byte, releaseFile *ReleaseFile) error {
+	if releaseFile == nil {
+		return nil
+	}
+	if fmt.Sprintf("%x", sha256.Sum256(content)) != releaseFile.Sha256 {
+		return fmt.Errorf("file:%s sha256 mismatch", releaseFile.Path)
+	}
+	return nil
+}
+
+// copyLocalFile 复制worker本地已有的相同内容的文件；复制前重新校验源文件的md5
+func copyLocalFile(srcFile, dstFile, fileMd5 string, releaseFile *ReleaseFile) bool {
+	rootPath, err := filepath.Abs(conf.GetRootPath())
+	if err != nil {
+		logging.RuntimeLog.Error(err)
+		return false
+	}
+	srcPathFileName := filepath.Join(rootPath, srcFile)
+	st, err := os.Stat(srcPathFileName)
+	if err != nil {
+		return false
+	}
+	content, err := os.ReadFile(srcPathFileName)
+	if err != nil || fmt.Sprintf("%x", md5.Sum(content)) != fileMd5 || checkReleaseFile(content, releaseFile) != nil {
+		return false
+	}
+	fileMode := st.Mode().Perm()
+	if releaseFile != nil {
+		fileMode = releaseFile.Mode
+	}
+	if err = writeFileAtomic(filepath.Join(rootPath, dstFile), content, fileMode); err != nil {
+		logging.RuntimeLog.Error(err)
+		return false
+	}
+	return true
+}
+
+// doTranFile worker向server请求同步一个文件；发布版本中的文件在验证sha256后写入
+func doTranFile(filePathName, authKey string, exchange MessageExchange, releaseFile *ReleaseFile) bool {
 	var err error
 	var srcPath string
 	//dstPath := "/tmp/test/dst"
@@ -133,26 +267,40 @@ func doTranFile(filePathName, authKey string, gbc *GobConn) bool {
 		return false
 	}
 	mg := Message{
-		MgAuthKey: authKey,
-		MgType:    MsgTran,
-		MgString:  filePathName,
+		MgAuthKey:  authKey,
+		MgType:     MsgTran,
+		MgString:   filePathName,
+		MgCompress: true,
 	}
-	err = gbc.gobConnWt(mg)
-	if err != nil {
-		logging.CLILog.Error(err)
-		logging.RuntimeLog.Error(err)
-		return false
+	if releaseFile != nil {
+		mg.MgHash = releaseFile.Sha256
 	}
-	var hostMessage Message
-	err = gbc.Dec.Decode(&hostMessage)
+	hostMessage, err := exchange(mg)
 	if err != nil {
 		logging.CLILog.Error(err)
 		logging.RuntimeLog.Error(err)
 		return false
 	}
 	if hostMessage.MgType == MsgTranData {
+		content := hostMessage.MgByte
+		if hostMessage.MgCompress {
+			if content, err = gzipDecompress(content); err != nil {
+				logging.CLILog.Error(err)
+				logging.RuntimeLog.Error(err)
+				return false
+			}
+		}
+		fileMode := hostMessage.MgFileMode
+		if releaseFile != nil {
+			if err = checkReleaseFile(content, releaseFile); err != nil {
+				logging.CLILog.Error(err)
+				logging.RuntimeLog.Error(err)
+				return false
+			}
+			fileMode = releaseFile.Mode
+		}
 		dstFilePathName := filepath.Join(srcPath, filePathName)
-		err = os.WriteFile(dstFilePathName, hostMessage.MgByte, hostMessage.MgFileMode)
+		err = writeFileAtomic(dstFilePathName, content, fileMode)
 		if err != nil {
 			logging.CLILog.Error(err)
 			logging.RuntimeLog.Error(err)
@@ -165,6 +313,16 @@ func doTranFile(filePathName, authKey string, gbc *GobConn) bool {
 	return false
 }
 
+// gzipDecompress 解压server发送的文件内容
+func gzipDecompress(content []byte) ([]byte, error) {
+	gr, err := gzip.NewReader(bytes.NewReader(content))
+	if err != nil {
+		return nil, err
+	}
+	defer gr.Close()
+	return io.ReadAll(gr)
+}
+
 // doDiff 对比需要同步的文件列表
 func doDiff(src []string, dst []string) (needCreate []string, needDelete []string, needTransfer []string) {
 	srcFileMap := make(map[string]struct{})
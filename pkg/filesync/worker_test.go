@@ -0,0 +1,32 @@
+package filesync
+
+import (
+	"crypto/md5"
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestCopyLocalFile(t *testing.T) {
+	cwd, _ := os.Getwd()
+	defer os.Chdir(cwd)
+	if err := os.Chdir(t.TempDir()); err != nil {
+		t.Fatal(err)
+	}
+	content := []byte("nemo")
+	fileMd5 := fmt.Sprintf("%x", md5.Sum(content))
+	_ = os.WriteFile("a.txt", content, 0644)
+
+	if !copyLocalFile("a.txt", "b.txt", fileMd5, nil) {
+		t.Fatal("copy local file fail")
+	}
+	if c, _ := os.ReadFile(filepath.Join(".", "b.txt")); string(c) != "nemo" {
+		t.Errorf("copied content:%s", c)
+	}
+	// 源文件已被覆盖时不能复制
+	_ = os.WriteFile("a.txt", []byte("changed"), 0644)
+	if copyLocalFile("a.txt", "c.txt", fileMd5, nil) {
+		t.Error("copy overwritten local file")
+	}
+}
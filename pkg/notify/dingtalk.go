@@ -5,6 +5,7 @@ import (
 	"encoding/json"
 	"errors"
 	"fmt"
+	"github.com/hanc00l/nemo_go/pkg/conf"
 	"io"
 	"net/http"
 )
@@ -19,14 +20,24 @@ type DingTalkResponseInfo struct {
 	Message string `json:"errmsg"`
 }
 
-func (d *DingTalk) Send(token string, message string) (err error) {
-	url := fmt.Sprintf("https://oapi.dingtalk.com/robot/send?access_token=%s", token)
+func (d *DingTalk) Send(config conf.Notify, event *Event) (err error) {
+	url := fmt.Sprintf("https://oapi.dingtalk.com/robot/send?access_token=%s", config.Token)
 	//-d '{"msgtype": "text","text": {"content":"Nemo任务通知：\n我就是我, 是不一样的烟火"}}'
-	text := make(map[string]string)
-	text["content"] = fmt.Sprintf("Nemo任务通知：\n%s", message)
 	data := make(map[string]interface{})
-	data["text"] = text
-	data["msgtype"] = "text"
+	if config.Template != "" {
+		//配置了模板时以markdown格式发送
+		var text string
+		if text, err = renderTemplate(config.Template, event); err != nil {
+			return
+		}
+		data["markdown"] = map[string]string{"title": event.Title(), "text": text}
+		data["msgtype"] = "markdown"
+	} else {
+		text := make(map[string]string)
+		text["content"] = fmt.Sprintf("%s：\n%s", event.Title(), event.Message)
+		data["text"] = text
+		data["msgtype"] = "text"
+	}
 	b, _ := json.Marshal(data)
 	var resp *http.Response
 	if resp, err = http.Post(url, "application/json", bytes.NewBuffer(b)); err != nil {
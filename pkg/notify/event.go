@@ -0,0 +1,52 @@
+package notify
+
+import (
+	"time"
+)
+
+const (
+	EventTypeTask  = "task"
+	EventTypeAlert = "alert"
+	EventTypeTest  = "test"
+)
+
+// Event 消息通知的事件，webhook等通知以结构化的数据发送，其它通知发送Message
+type Event struct {
+	Type            string         `json:"type"`
+	Time            time.Time      `json:"time"`
+	WorkspaceId     int            `json:"workspaceId,omitempty"`
+	Workspace       string         `json:"workspace,omitempty"`
+	TaskId          string         `json:"taskId,omitempty"`
+	TaskName        string         `json:"taskName,omitempty"`
+	Target          string         `json:"target,omitempty"`
+	Runtime         string         `json:"runtime,omitempty"`
+	Progress        string         `json:"progress,omitempty"`
+	Result          string         `json:"result,omitempty"`
+	Counts          map[string]int `json:"counts,omitempty"`
+	NewIPs          []string       `json:"newIPs,omitempty"`
+	NewPorts        []string       `json:"newPorts,omitempty"`
+	NewDomains      []string       `json:"newDomains,omitempty"`
+	Vulnerabilities []string       `json:"vulnerabilities,omitempty"`
+	URL             string         `json:"url,omitempty"`
+	RuleName        string         `json:"ruleName,omitempty"`
+	RuleType        string         `json:"ruleType,omitempty"`
+	Items           []string       `json:"items,omitempty"`
+	Message         string         `json:"message"`
+}
+
+// NewEvent 生成一个指定类型的事件
+func NewEvent(eventType string, message string) *Event {
+	return &Event{
+		Type:    eventType,
+		Time:    time.Now(),
+		Message: message,
+	}
+}
+
+// Title 事件的标题
+func (e *Event) Title() string {
+	if e.Type == EventTypeAlert {
+		return "Nemo告警通知"
+	}
+	return "Nemo任务通知"
+}
@@ -5,6 +5,7 @@ import (
 	"encoding/json"
 	"errors"
 	"fmt"
+	"github.com/hanc00l/nemo_go/pkg/conf"
 	"io"
 	"net/http"
 )
@@ -19,14 +20,27 @@ type FeishuResponseInfo struct {
 	Message string `json:"StatusMessage"`
 }
 
-func (f *Feishu) Send(token string, message string) (err error) {
-	url := fmt.Sprintf("https://open.feishu.cn/open-apis/bot/v2/hook/%s", token)
+func (f *Feishu) Send(config conf.Notify, event *Event) (err error) {
+	url := fmt.Sprintf("https://open.feishu.cn/open-apis/bot/v2/hook/%s", config.Token)
 	//-d '{"msg_type":"text","content":{"text":"request example"}}' \
-	content := make(map[string]string)
-	content["text"] = fmt.Sprintf("Nemo任务通知：\n%s", message)
 	data := make(map[string]interface{})
-	data["content"] = content
-	data["msg_type"] = "text"
+	if config.Template != "" {
+		//配置了模板时，模板渲染的结果为消息卡片的JSON
+		var card string
+		if card, err = renderTemplate(config.Template, event); err != nil {
+			return
+		}
+		if !json.Valid([]byte(card)) {
+			return errors.New("invalid feishu card json")
+		}
+		data["card"] = json.RawMessage(card)
+		data["msg_type"] = "interactive"
+	} else {
+		content := make(map[string]string)
+		content["text"] = fmt.Sprintf("%s：\n%s", event.Title(), event.Message)
+		data["content"] = content
+		data["msg_type"] = "text"
+	}
 	b, _ := json.Marshal(data)
 	var resp *http.Response
 	if resp, err = http.Post(url, "application/json", bytes.NewBuffer(b)); err != nil {
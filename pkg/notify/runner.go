@@ -1,47 +1,99 @@
 package notify
 
 import (
+	"fmt"
 	"github.com/hanc00l/nemo_go/pkg/conf"
+	"github.com/hanc00l/nemo_go/pkg/db"
 	"github.com/hanc00l/nemo_go/pkg/logging"
+	"strings"
 	"sync"
 )
 
 // Sender 各个API的消息通知调用handler
 type Sender interface {
-	Send(token string, message string) (err error)
+	Send(config conf.Notify, event *Event) (err error)
 }
 
 // Send 根据server的配置，调用各个接口handler发送消息通知
-func Send(message string) {
+func Send(event *Event) {
+	SendTo(nil, event)
+}
+
+// SendTo 调用指定的接口handler发送消息通知，未指定时发送到全部已配置的接口
+func SendTo(senderNames []string, event *Event) {
+	senderSet := make(map[string]struct{})
+	for _, name := range senderNames {
+		if name = strings.TrimSpace(name); name != "" {
+			senderSet[name] = struct{}{}
+		}
+	}
+	// 工作空间的通知模板优先于server.yml中的配置
+	workspaceTemplates := loadWorkspaceTemplates(event.WorkspaceId)
 	// 采用多线程同时发送模式
 	swg := sync.WaitGroup{}
-	for senderName, config := range conf.GlobalServerConfig().Notify {
-		if len(config.Token) == 0 {
+	for senderName, config := range GetNotifyConfig(event.WorkspaceId) {
+		if _, ok := senderSet[senderName]; len(senderSet) > 0 && !ok {
 			continue
 		}
-		var sender Sender
-		switch senderName {
-		case "serverchan":
-			sender = new(ServerChan)
-		case "dingtalk":
-			sender = new(DingTalk)
-		case "feishu":
-			sender = new(Feishu)
-		default:
-			msg := "invalid notify sender"
+		sender, enabled := newSender(senderName, config)
+		if sender == nil {
+			msg := fmt.Sprintf("invalid notify sender:%s", senderName)
 			logging.RuntimeLog.Error(msg)
 			logging.CLILog.Error(msg)
 			continue
 		}
+		if !enabled {
+			continue
+		}
+		if tmpl, ok := workspaceTemplates[senderName]; ok {
+			config.Template = tmpl
+		}
 		//send message
 		swg.Add(1)
-		go func(s Sender, token string) {
+		go func(s Sender, c conf.Notify) {
 			defer swg.Done()
-			if err := s.Send(token, message); err != nil {
+			if err := s.Send(c, event); err != nil {
 				logging.CLILog.Error(err)
 				logging.RuntimeLog.Error(err)
 			}
-		}(sender, config.Token)
+		}(sender, config)
 	}
 	swg.Wait()
 }
+
+// newSender 根据通知的类型（未指定时为配置的名称）生成handler，并检查必需的配置项是否已配置
+func newSender(senderName string, config conf.Notify) (sender Sender, enabled bool) {
+	senderType := config.Type
+	if senderType == "" {
+		senderType = senderName
+	}
+	switch senderType {
+	case "serverchan":
+		return new(ServerChan), config.Token != ""
+	case "dingtalk":
+		return new(DingTalk), config.Token != ""
+	case "feishu":
+		return new(Feishu), config.Token != ""
+	case "webhook":
+		return new(Webhook), config.URL != ""
+	case "smtp":
+		return new(SMTP), config.Host != "" && len(config.To) > 0
+	}
+	return nil, false
+}
+
+// GetNotifyConfig 获取工作空间的消息通知配置；工作空间未配置时使用server.yml中的全局配置
+func GetNotifyConfig(workspaceId int) map[string]conf.Notify {
+	if workspaceId > 0 {
+		workspace := db.Workspace{Id: workspaceId}
+		if workspace.Get() && strings.TrimSpace(workspace.NotifyConfig) != "" {
+			notifyConfig, err := conf.ParseNotifyConfig(workspace.NotifyConfig)
+			if err != nil {
+				logging.RuntimeLog.Errorf("parse notify config of workspace %s fail:%v", workspace.WorkspaceName, err)
+			} else if len(notifyConfig) > 0 {
+				return notifyConfig
+			}
+		}
+	}
+	return conf.GlobalServerConfig().Notify
+}
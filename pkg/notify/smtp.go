@@ -0,0 +1,106 @@
+package notify
+
+import (
+	"bytes"
+	"crypto/tls"
+	"fmt"
+	"github.com/hanc00l/nemo_go/pkg/conf"
+	"mime"
+	"net"
+	"net/smtp"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// SMTP 以邮件方式发送消息通知
+type SMTP struct {
+}
+
+func (s *SMTP) Send(config conf.Notify, event *Event) (err error) {
+	port := config.Port
+	if port == 0 {
+		if config.SSL {
+			port = 465
+		} else {
+			port = 25
+		}
+	}
+	from := config.From
+	if from == "" {
+		from = config.Username
+	}
+	//配置了模板时以HTML格式发送
+	body, contentType := event.Message, "text/plain"
+	if config.Template != "" {
+		if body, err = renderTemplate(config.Template, event); err != nil {
+			return
+		}
+		contentType = "text/html"
+	}
+	addr := net.JoinHostPort(config.Host, strconv.Itoa(port))
+	tlsConfig := &tls.Config{ServerName: config.Host}
+
+	var conn net.Conn
+	dialer := &net.Dialer{Timeout: 30 * time.Second}
+	if config.SSL {
+		conn, err = tls.DialWithDialer(dialer, "tcp", addr, tlsConfig)
+	} else {
+		conn, err = dialer.Dial("tcp", addr)
+	}
+	if err != nil {
+		return
+	}
+	var c *smtp.Client
+	if c, err = smtp.NewClient(conn, config.Host); err != nil {
+		conn.Close()
+		return
+	}
+	defer c.Close()
+	if !config.SSL {
+		if ok, _ := c.Extension("STARTTLS"); ok {
+			if err = c.StartTLS(tlsConfig); err != nil {
+				return
+			}
+		}
+	}
+	if config.Username != "" {
+		if err = c.Auth(smtp.PlainAuth("", config.Username, config.Password, config.Host)); err != nil {
+			return
+		}
+	}
+	if err = c.Mail(from); err != nil {
+		return
+	}
+	for _, to := range config.To {
+		if err = c.Rcpt(to); err != nil {
+			return
+		}
+	}
+	w, err := c.Data()
+	if err != nil {
+		return
+	}
+	if _, err = w.Write(buildMailMessage(from, config.To, event.Title(), body, contentType)); err != nil {
+		return
+	}
+	if err = w.Close(); err != nil {
+		return
+	}
+	return c.Quit()
+}
+
+// buildMailMessage 生成邮件的内容
+func buildMailMessage(from string, to []string, subject, body, contentType string) []byte {
+	var buf bytes.Buffer
+	buf.WriteString(fmt.Sprintf("From: %s\r\n", from))
+	buf.WriteString(fmt.Sprintf("To: %s\r\n", strings.Join(to, ", ")))
+	buf.WriteString(fmt.Sprintf("Subject: %s\r\n", mime.BEncoding.Encode("UTF-8", subject)))
+	buf.WriteString(fmt.Sprintf("Date: %s\r\n", time.Now().Format(time.RFC1123Z)))
+	buf.WriteString("MIME-Version: 1.0\r\n")
+	buf.WriteString(fmt.Sprintf("Content-Type: %s; charset=UTF-8\r\n", contentType))
+	buf.WriteString("\r\n")
+	buf.WriteString(strings.ReplaceAll(body, "\n", "\r\n"))
+	buf.WriteString("\r\n")
+	return buf.Bytes()
+}
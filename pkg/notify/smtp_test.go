@@ -0,0 +1,89 @@
+package notify
+
+import (
+	"bufio"
+	"github.com/hanc00l/nemo_go/pkg/conf"
+	"net"
+	"strings"
+	"testing"
+)
+
+// fakeSMTPServer 一个简单的SMTP服务端，返回收到的邮件内容
+func fakeSMTPServer(t *testing.T) (host string, port int, result chan string) {
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	result = make(chan string, 1)
+	go func() {
+		defer l.Close()
+		conn, err := l.Accept()
+		if err != nil {
+			return
+		}
+		defer conn.Close()
+		r := bufio.NewReader(conn)
+		reply := func(s string) { conn.Write([]byte(s + "\r\n")) }
+		reply("220 localhost ESMTP")
+		var data strings.Builder
+		for {
+			line, err := r.ReadString('\n')
+			if err != nil {
+				return
+			}
+			cmd := strings.ToUpper(strings.TrimSpace(line))
+			switch {
+			case strings.HasPrefix(cmd, "EHLO"), strings.HasPrefix(cmd, "HELO"):
+				reply("250 localhost")
+			case strings.HasPrefix(cmd, "MAIL"), strings.HasPrefix(cmd, "RCPT"):
+				reply("250 OK")
+			case cmd == "DATA":
+				reply("354 End data with <CR><LF>.<CR><LF>")
+				for {
+					l, err := r.ReadString('\n')
+					if err != nil || l == ".\r\n" {
+						break
+					}
+					data.WriteString(l)
+				}
+				reply("250 OK")
+			case cmd == "QUIT":
+				reply("221 Bye")
+				result <- data.String()
+				return
+			default:
+				reply("500 unknown command")
+			}
+		}
+	}()
+	addr := l.Addr().(*net.TCPAddr)
+	return addr.IP.String(), addr.Port, result
+}
+
+func TestSMTP_Send(t *testing.T) {
+	host, port, result := fakeSMTPServer(t)
+	config := conf.Notify{Host: host, Port: port, From: "nemo@example.com", To: []string{"admin@example.com"}}
+	event := NewEvent(EventTypeTask, "portscan->runtime:25s\nresult->ip:10")
+	if err := new(SMTP).Send(config, event); err != nil {
+		t.Fatal(err)
+	}
+	mail := <-result
+	if !strings.Contains(mail, "To: admin@example.com") || !strings.Contains(mail, "result->ip:10") {
+		t.Errorf("mail mismatch:%s", mail)
+	}
+}
+
+func TestSMTP_SendTemplate(t *testing.T) {
+	host, port, result := fakeSMTPServer(t)
+	config := conf.Notify{Host: host, Port: port, From: "nemo@example.com", To: []string{"admin@example.com"},
+		Template: "<h3>{{html .TaskName}}</h3>"}
+	event := NewEvent(EventTypeTask, "")
+	event.TaskName = "portscan"
+	if err := new(SMTP).Send(config, event); err != nil {
+		t.Fatal(err)
+	}
+	mail := <-result
+	if !strings.Contains(mail, "Content-Type: text/html") || !strings.Contains(mail, "<h3>portscan</h3>") {
+		t.Errorf("mail mismatch:%s", mail)
+	}
+}
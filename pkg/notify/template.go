@@ -0,0 +1,54 @@
+package notify
+
+import (
+	"bytes"
+	"encoding/json"
+	"github.com/hanc00l/nemo_go/pkg/db"
+	htmltemplate "html/template"
+	"strings"
+	"text/template"
+)
+
+// templateFuncs 消息模板中可使用的函数
+var templateFuncs = template.FuncMap{
+	// json 将值转换为JSON，用于在JSON模板中安全地输出字符串
+	"json": func(v interface{}) (string, error) {
+		b, err := json.Marshal(v)
+		return string(b), err
+	},
+	// html 对HTML进行转义，用于邮件的HTML模板
+	"html": htmltemplate.HTMLEscapeString,
+	"join": strings.Join,
+}
+
+// renderTemplate 以事件为数据渲染消息模板（Go text/template）
+func renderTemplate(tmpl string, event *Event) (string, error) {
+	t, err := template.New("notify").Funcs(templateFuncs).Parse(tmpl)
+	if err != nil {
+		return "", err
+	}
+	var buf bytes.Buffer
+	if err = t.Execute(&buf, event); err != nil {
+		return "", err
+	}
+	return buf.String(), nil
+}
+
+// loadWorkspaceTemplates 获取工作空间配置的通知模板，key为通知名称
+func loadWorkspaceTemplates(workspaceId int) map[string]string {
+	templates := make(map[string]string)
+	if workspaceId <= 0 {
+		return templates
+	}
+	t := db.NotifyTemplate{WorkspaceId: workspaceId}
+	for _, row := range t.GetsByWorkspace() {
+		templates[row.NotifyName] = row.Template
+	}
+	return templates
+}
+
+// CheckTemplate 检查消息模板的语法是否正确
+func CheckTemplate(tmpl string) error {
+	_, err := template.New("notify").Funcs(templateFuncs).Parse(tmpl)
+	return err
+}
@@ -0,0 +1,35 @@
+package notify
+
+import (
+	"testing"
+)
+
+func TestRenderTemplate(t *testing.T) {
+	event := NewEvent(EventTypeTask, "")
+	event.TaskName = "portscan"
+	event.Counts = map[string]int{"ip": 10, "ipNew": 2}
+	event.NewIPs = []string{"192.168.1.1", "192.168.1.2"}
+	event.URL = "https://nemo.example.com/task-info-main?task_id=1"
+
+	markdown, err := renderTemplate("### {{.TaskName}}\n- ip:{{.Counts.ip}}(+{{.Counts.ipNew}}) {{join .NewIPs \",\"}}\n- [查看]({{.URL}})", event)
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected := "### portscan\n- ip:10(+2) 192.168.1.1,192.168.1.2\n- [查看](https://nemo.example.com/task-info-main?task_id=1)"
+	if markdown != expected {
+		t.Errorf("markdown mismatch:%s", markdown)
+	}
+
+	event.Target = "<script>"
+	html, err := renderTemplate("<p>{{html .Target}}</p>", event)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if html != "<p>&lt;script&gt;</p>" {
+		t.Errorf("html mismatch:%s", html)
+	}
+
+	if err = CheckTemplate("{{.TaskName"); err == nil {
+		t.Error("expect template syntax error")
+	}
+}
@@ -0,0 +1,66 @@
+package notify
+
+import (
+	"bytes"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"github.com/hanc00l/nemo_go/pkg/conf"
+	"io"
+	"net/http"
+	"time"
+)
+
+// WebhookSignatureHeader 配置了secret时，请求body的HMAC-SHA256签名所在的header
+const WebhookSignatureHeader = "X-Nemo-Signature"
+
+// Webhook 以POST方式将事件发送到指定的URL
+type Webhook struct {
+}
+
+func (w *Webhook) Send(config conf.Notify, event *Event) (err error) {
+	var body []byte
+	if body, err = renderWebhookBody(config.Template, event); err != nil {
+		return
+	}
+	var req *http.Request
+	if req, err = http.NewRequest(http.MethodPost, config.URL, bytes.NewReader(body)); err != nil {
+		return
+	}
+	req.Header.Set("Content-Type", "application/json")
+	for k, v := range config.Headers {
+		req.Header.Set(k, v)
+	}
+	if config.Secret != "" {
+		req.Header.Set(WebhookSignatureHeader, "sha256="+SignWebhookBody(config.Secret, body))
+	}
+	client := &http.Client{Timeout: 30 * time.Second}
+	var resp *http.Response
+	if resp, err = client.Do(req); err != nil {
+		return
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		responseData, _ := io.ReadAll(io.LimitReader(resp.Body, 1024))
+		return fmt.Errorf("webhook response status:%d %s", resp.StatusCode, string(responseData))
+	}
+	return
+}
+
+// SignWebhookBody 计算请求body的HMAC-SHA256签名
+func SignWebhookBody(secret string, body []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write(body)
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+// renderWebhookBody 生成请求的body：未配置模板时为事件的JSON，否则为模板渲染的结果
+func renderWebhookBody(tmpl string, event *Event) ([]byte, error) {
+	if tmpl == "" {
+		return json.Marshal(event)
+	}
+	body, err := renderTemplate(tmpl, event)
+	return []byte(body), err
+}
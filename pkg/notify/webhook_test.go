@@ -0,0 +1,78 @@
+package notify
+
+import (
+	"encoding/json"
+	"github.com/hanc00l/nemo_go/pkg/conf"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestWebhook_Send(t *testing.T) {
+	var header http.Header
+	var body []byte
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		header = r.Header
+		body, _ = io.ReadAll(r.Body)
+	}))
+	defer ts.Close()
+
+	event := NewEvent(EventTypeTask, "portscan->runtime:25s")
+	event.TaskId = "c3b5c5f5-7a2e-4a4b-9b1e-2d7f0c9e8a11"
+	event.Counts = map[string]int{"ip": 10, "port": 20}
+	event.NewIPs = []string{"192.168.1.1"}
+	config := conf.Notify{URL: ts.URL, Secret: "secret", Headers: map[string]string{"X-Token": "abc"}}
+	if err := new(Webhook).Send(config, event); err != nil {
+		t.Fatal(err)
+	}
+	if header.Get("X-Token") != "abc" {
+		t.Errorf("header not set:%v", header)
+	}
+	if header.Get(WebhookSignatureHeader) != "sha256="+SignWebhookBody("secret", body) {
+		t.Errorf("signature mismatch:%s", header.Get(WebhookSignatureHeader))
+	}
+	var e Event
+	if err := json.Unmarshal(body, &e); err != nil {
+		t.Fatal(err)
+	}
+	if e.TaskId != event.TaskId || e.Counts["port"] != 20 || len(e.NewIPs) != 1 {
+		t.Errorf("event mismatch:%s", string(body))
+	}
+}
+
+func TestWebhook_SendTemplate(t *testing.T) {
+	var body []byte
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ = io.ReadAll(r.Body)
+	}))
+	defer ts.Close()
+
+	event := NewEvent(EventTypeAlert, "告警规则：rdp\n\"192.168.1.1:3389\"")
+	config := conf.Notify{URL: ts.URL, Template: `{"msgtype":"text","text":{"content":{{json .Message}}}}`}
+	if err := new(Webhook).Send(config, event); err != nil {
+		t.Fatal(err)
+	}
+	var data struct {
+		Text struct {
+			Content string `json:"content"`
+		} `json:"text"`
+	}
+	if err := json.Unmarshal(body, &data); err != nil {
+		t.Fatal(err, string(body))
+	}
+	if data.Text.Content != event.Message {
+		t.Errorf("template body mismatch:%s", string(body))
+	}
+}
+
+func TestWebhook_SendFail(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusForbidden)
+	}))
+	defer ts.Close()
+
+	if err := new(Webhook).Send(conf.Notify{URL: ts.URL}, NewEvent(EventTypeTest, "test")); err == nil {
+		t.Error("expect error for status 403")
+	}
+}
@@ -3,12 +3,9 @@ package ampq
 import (
 	"fmt"
 	"github.com/RichardKnop/machinery/v2"
-	amqpbackend "github.com/RichardKnop/machinery/v2/backends/amqp"
-	amqpbroker "github.com/RichardKnop/machinery/v2/brokers/amqp"
-	"github.com/RichardKnop/machinery/v2/config"
-	eagerlock "github.com/RichardKnop/machinery/v2/locks/eager"
 	"github.com/RichardKnop/machinery/v2/tasks"
 	"github.com/hanc00l/nemo_go/pkg/conf"
+	"regexp"
 	"strings"
 	"sync"
 	"time"
@@ -22,7 +19,8 @@ const (
 	FAILURE  string = tasks.StateFailure  //任务执行完成，结果为FAILURE
 	RECEIVED string = tasks.StateReceived //未使用
 	PENDING  string = tasks.StatePending  //未使用
-	RETRY    string = tasks.StateRetry    //未使用
+	RETRY    string = tasks.StateRetry    //任务执行失败，已按重试策略重新执行
+	PAUSED   string = "PAUSED"            //主任务被暂停，未开始执行的子任务暂不执行
 
 	TopicActive  = "active"
 	TopicFinger  = "finger"
@@ -31,6 +29,8 @@ const (
 	TopicCustom  = "custom"
 
 	TopicMQPrefix = "nemo_mq"
+
+	ToolVersionUnknown = "unknown" //worker的第三方工具存在，但无法获取版本
 )
 
 type TaskResult struct {
@@ -48,6 +48,25 @@ type WorkerStatus struct {
 	ManualReloadFlag       bool      `json:"manual_reload_flag"`
 	ManualFileSyncFlag     bool      `json:"manual_file_sync_flag"`
 	WorkerDaemonUpdateTime time.Time `json:"worker_daemon_update_time"`
+	// 以下为worker在心跳时上报的运行状态
+	CPUPercent       float64           `json:"cpu_percent"`
+	MemPercent       float64           `json:"mem_percent"`
+	DiskPercent      float64           `json:"disk_percent"`
+	RunningTasks     []RunningTask     `json:"running_tasks"`
+	ToolVersions     map[string]string `json:"tool_versions"` //第三方工具的版本，不存在的工具为空
+	FileSyncRevision string            `json:"filesync_revision"`
+	FileSyncProfile  string            `json:"filesync_profile"` //worker同步文件的profile，为空时同步全部文件
+	FileSyncRelease  int               `json:"filesync_release"` //worker同步的发布版本，没有发布版本时为0
+	FileSyncTime     time.Time         `json:"filesync_time"`
+	SpoolDepth       int               `json:"spool_depth"` //暂存等待补传到server的结果数量
+	Identity         string            `json:"identity"`    //server认证的worker身份，未注册的worker为空
+}
+
+// RunningTask worker正在执行的任务
+type RunningTask struct {
+	TaskId   string `json:"task_id"`
+	TaskName string `json:"task_name"`
+	Elapsed  int    `json:"elapsed"` //已执行的秒数
 }
 
 type WorkerRunTaskMode int
@@ -98,71 +117,80 @@ var taskTopicDefineMap = map[string]string{
 	"test": TopicCustom,
 }
 
-// taskServer 复用的全局AMQP连接
+// taskServerConn 复用的全局消息队列连接
 var taskServerConn = make(map[string]*machinery.Server)
+var taskServerConnMutex sync.Mutex
 
 // CustomTaskWorkspaceMap 自定义任务关联的工作空间GUID
 var CustomTaskWorkspaceMap = make(map[string]struct{})
 
+// workerTagRegexp worker标签作为队列名称的一部分，只允许字母、数字、“_”及“-”
+var workerTagRegexp = regexp.MustCompile(`^[a-zA-Z0-9_-]{1,50}$`)
+
 // GetServerTaskAMPQServer 根据server配置文件，获取到消息中心的连接
 func GetServerTaskAMPQServer(topicName string) *machinery.Server {
+	taskServerConnMutex.Lock()
+	defer taskServerConnMutex.Unlock()
+
 	if _, ok := taskServerConn[topicName]; !ok {
-		rabbitmq := conf.GlobalServerConfig().Rabbitmq
-		taskServerConn[topicName] = startAMQPServer(rabbitmq.Username, rabbitmq.Password, rabbitmq.Host, rabbitmq.Port, topicName, 3)
+		config := conf.GlobalServerConfig()
+		taskServerConn[topicName] = startTaskServer(config.Broker, config.Rabbitmq, config.Redis, topicName, 3)
 	}
 	return taskServerConn[topicName]
 }
 
 // GetWorkerAMPQServer 根据worker配置文件，获取到消息中心的连接
 func GetWorkerAMPQServer(topicName string, prefetchCount int) *machinery.Server {
+	taskServerConnMutex.Lock()
+	defer taskServerConnMutex.Unlock()
+
 	if _, ok := taskServerConn[topicName]; !ok {
-		rabbitmq := conf.GlobalWorkerConfig().Rabbitmq
-		taskServerConn[topicName] = startAMQPServer(rabbitmq.Username, rabbitmq.Password, rabbitmq.Host, rabbitmq.Port, topicName, prefetchCount)
+		config := conf.GlobalWorkerConfig()
+		brokerType := config.Broker
+		// 使用HTTP方式连接server时，通过server读取任务
+		if HTTPTaskTransport != nil {
+			brokerType = BrokerHTTP
+		}
+		taskServerConn[topicName] = startTaskServer(brokerType, config.Rabbitmq, config.Redis, topicName, prefetchCount)
 	}
 	return taskServerConn[topicName]
 }
 
-// startAMQPServer 连接到AMQP消息队列服务器
-func startAMQPServer(username, password, host string, port int, topicName string, prefetchCount int) *machinery.Server {
-	amqpConfig := fmt.Sprintf("amqp://%s:%s@%s:%d/", username, password, host, port)
-	routingKey := GetRoutingKeyByTopic(topicName)
-	cnf := &config.Config{
-		Broker:          amqpConfig,
-		DefaultQueue:    routingKey,
-		ResultBackend:   amqpConfig,
-		ResultsExpireIn: 300,
-		AMQP: &config.AMQPConfig{
-			Exchange:      "nemo_mq_exchange",
-			ExchangeType:  "topic",
-			BindingKey:    routingKey,
-			PrefetchCount: prefetchCount,
-		},
+// GetTopicByTaskName 获取任务对应的队列名称；指定了worker标签时，任务只分配到具有该标签的worker
+func GetTopicByTaskName(taskName string, workspaceGUID string, workerTag string) string {
+	// 单机模式的任务都由内嵌的worker执行，不按自定义任务的工作空间和worker标签分配队列
+	if conf.Standalone {
+		return taskTopicDefineMap[taskName]
 	}
-	// Create server instance
-	broker := amqpbroker.New(cnf)
-	backend := amqpbackend.New(cnf)
-	lock := eagerlock.New()
-	server := machinery.NewServer(cnf, broker, backend, lock)
-
-	return server
-}
-
-func GetTopicByTaskName(taskName string, workspaceGUID string) string {
 	if _, ok := CustomTaskWorkspaceMap[workspaceGUID]; ok {
 		// custom.1a0ca919-7960-4067-9981-9abcb4eaa735
 		return fmt.Sprintf("%s.%s", TopicCustom, workspaceGUID)
 	}
 	if queueName, ok := taskTopicDefineMap[taskName]; ok {
 		// active、finger...
-		return queueName
+		return GetTaggedTopic(queueName, workerTag)
 	}
 	return ""
 }
 
+// GetTaggedTopic 获取带worker标签的队列名称，如active.dmz；标签为空时为原队列名称
+func GetTaggedTopic(topicName string, workerTag string) string {
+	if workerTag == "" {
+		return topicName
+	}
+	return fmt.Sprintf("%s.%s", topicName, workerTag)
+}
+
+// CheckWorkerTag 检查worker标签是否合法
+func CheckWorkerTag(workerTag string) bool {
+	return workerTagRegexp.MatchString(workerTag)
+}
+
 func GetTopicByMQRoutingKey(routingKey string) string {
 	keys := strings.Split(routingKey, ".")
 	if len(keys) == 3 {
 		// nemo_mq.custom.1a0ca919-7960-4067-9981-9abcb4eaa735
+		// nemo_mq.active.dmz
 		return fmt.Sprintf("%s.%s", keys[1], keys[2])
 	} else if len(keys) == 2 {
 		// nemo_mq.active...
@@ -0,0 +1,320 @@
+package ampq
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"github.com/RichardKnop/machinery/v2"
+	amqpbackend "github.com/RichardKnop/machinery/v2/backends/amqp"
+	amqpbroker "github.com/RichardKnop/machinery/v2/brokers/amqp"
+	"github.com/RichardKnop/machinery/v2/brokers/errs"
+	"github.com/RichardKnop/machinery/v2/brokers/iface"
+	"github.com/RichardKnop/machinery/v2/common"
+	"github.com/RichardKnop/machinery/v2/config"
+	eagerlock "github.com/RichardKnop/machinery/v2/locks/eager"
+	"github.com/RichardKnop/machinery/v2/tasks"
+	"github.com/hanc00l/nemo_go/pkg/conf"
+	"github.com/hanc00l/nemo_go/pkg/logging"
+	"runtime"
+	"sync"
+	"time"
+)
+
+const (
+	BrokerAMQP   = "amqp"   //RabbitMQ（默认）
+	BrokerRedis  = "redis"  //Redis
+	BrokerMemory = "memory" //进程内的消息队列，只用于server与worker在同一进程中运行
+	BrokerHTTP   = "http"   //worker通过server的HTTP接口读取任务，不直接连接消息队列
+
+	resultsExpireIn = 300 //任务结果的保存时间（秒）
+	// queueRetryDelay 读取消息队列失败后重试的等待时间
+	queueRetryDelay = 5 * time.Second
+	// unregisteredTaskDelay worker未注册的任务重新放回消息队列的延迟时间
+	unregisteredTaskDelay = time.Second
+)
+
+// startTaskServer 根据配置的消息队列类型，连接到消息队列
+func startTaskServer(brokerType string, rabbitmq conf.Rabbitmq, redis conf.Redis, topicName string, prefetchCount int) *machinery.Server {
+	switch brokerType {
+	case BrokerRedis:
+		return startRedisServer(redis, topicName, prefetchCount)
+	case BrokerMemory:
+		return startMemoryServer(topicName, prefetchCount)
+	case BrokerHTTP:
+		return startHTTPServer(topicName, prefetchCount)
+	case "", BrokerAMQP:
+	default:
+		logging.RuntimeLog.Warningf("invalid broker type:%s,use amqp instead", brokerType)
+	}
+	return startAMQPServer(rabbitmq.Username, rabbitmq.Password, rabbitmq.Host, rabbitmq.Port, topicName, prefetchCount)
+}
+
+// startAMQPServer 连接到AMQP消息队列服务器
+func startAMQPServer(username, password, host string, port int, topicName string, prefetchCount int) *machinery.Server {
+	amqpConfig := fmt.Sprintf("amqp://%s:%s@%s:%d/", username, password, host, port)
+	routingKey := GetRoutingKeyByTopic(topicName)
+	cnf := &config.Config{
+		Broker:          amqpConfig,
+		DefaultQueue:    routingKey,
+		ResultBackend:   amqpConfig,
+		ResultsExpireIn: resultsExpireIn,
+		AMQP: &config.AMQPConfig{
+			Exchange:      "nemo_mq_exchange",
+			ExchangeType:  "topic",
+			BindingKey:    routingKey,
+			PrefetchCount: prefetchCount,
+		},
+	}
+	// Create server instance
+	broker := amqpbroker.New(cnf)
+	backend := amqpbackend.New(cnf)
+	lock := eagerlock.New()
+	server := machinery.NewServer(cnf, broker, backend, lock)
+
+	return server
+}
+
+// startRedisServer 连接到Redis消息队列服务器；任务结果只由执行任务的worker读取，因此保存在进程内
+func startRedisServer(redis conf.Redis, topicName string, prefetchCount int) *machinery.Server {
+	cnf := &config.Config{
+		Broker:          fmt.Sprintf("redis://%s:%d/%d", redis.Host, redis.Port, redis.DB),
+		DefaultQueue:    GetRoutingKeyByTopic(topicName),
+		ResultBackend:   "memory://",
+		ResultsExpireIn: resultsExpireIn,
+	}
+	broker := newQueueBroker(cnf, newRedisQueue(redis), prefetchCount)
+	server := machinery.NewServer(cnf, broker, newMemoryBackend(cnf), eagerlock.New())
+
+	return server
+}
+
+// startMemoryServer 使用进程内的消息队列
+func startMemoryServer(topicName string, prefetchCount int) *machinery.Server {
+	cnf := &config.Config{
+		Broker:          "memory://",
+		DefaultQueue:    GetRoutingKeyByTopic(topicName),
+		ResultBackend:   "memory://",
+		ResultsExpireIn: resultsExpireIn,
+	}
+	broker := newQueueBroker(cnf, memoryTaskQueue, prefetchCount)
+	server := machinery.NewServer(cnf, broker, newMemoryBackend(cnf), eagerlock.New())
+
+	return server
+}
+
+// startHTTPServer 通过server的HTTP接口读取任务；任务结果只由执行任务的worker读取，因此保存在进程内
+func startHTTPServer(topicName string, prefetchCount int) *machinery.Server {
+	cnf := &config.Config{
+		Broker:          "http://",
+		DefaultQueue:    GetRoutingKeyByTopic(topicName),
+		ResultBackend:   "memory://",
+		ResultsExpireIn: resultsExpireIn,
+	}
+	broker := newQueueBroker(cnf, &httpQueue{transport: HTTPTaskTransport}, prefetchCount)
+	server := machinery.NewServer(cnf, broker, newMemoryBackend(cnf), eagerlock.New())
+
+	return server
+}
+
+// taskQueue 按routingKey保存任务消息的队列
+type taskQueue interface {
+	// push 将任务放入队列，eta不为零时延迟到eta后才能被读取
+	push(routingKey string, msg []byte, eta time.Time) error
+	// pop 读取队列中的一个任务，没有任务时等待，超时或stop后返回nil；读取的任务在ack前不会被删除
+	pop(routingKey string, stop <-chan int) ([]byte, error)
+	// ack 确认任务已执行完成，删除pop读取的任务
+	ack(routingKey string, msg []byte) error
+	// pending 队列中等待执行的任务
+	pending(routingKey string) ([][]byte, error)
+	// delayed 还没有到执行时间的任务
+	delayed() ([][]byte, error)
+	// moveDelayed 将已到执行时间的任务放入队列，返回移动的任务数量
+	moveDelayed() (int, error)
+	// requeueExpired 将已离线的consumer读取后未确认的任务放回队列，返回放回的任务数量
+	requeueExpired() (int, error)
+}
+
+// queueBroker 基于taskQueue实现的machinery消息队列
+type queueBroker struct {
+	common.Broker
+	queue         taskQueue
+	prefetchCount int
+	processingWG  sync.WaitGroup
+	consumingWG   sync.WaitGroup
+}
+
+// newQueueBroker 创建基于taskQueue的消息队列
+func newQueueBroker(cnf *config.Config, queue taskQueue, prefetchCount int) iface.Broker {
+	return &queueBroker{Broker: common.NewBroker(cnf), queue: queue, prefetchCount: prefetchCount}
+}
+
+// StartConsuming 读取队列中的任务并交给worker执行，直到StopConsuming
+func (b *queueBroker) StartConsuming(consumerTag string, concurrency int, taskProcessor iface.TaskProcessor) (bool, error) {
+	b.consumingWG.Add(1)
+	defer b.consumingWG.Done()
+
+	if concurrency < 1 {
+		concurrency = b.prefetchCount
+	}
+	if concurrency < 1 {
+		concurrency = runtime.NumCPU() * 2
+	}
+	b.Broker.StartConsuming(consumerTag, concurrency, taskProcessor)
+
+	routingKey := taskProcessor.CustomQueue()
+	if routingKey == "" {
+		routingKey = b.GetConfig().DefaultQueue
+	}
+	stop := b.GetStopChan()
+	go b.moveDelayedTasks(stop)
+
+	pool := make(chan struct{}, concurrency)
+	for {
+		select {
+		case <-stop:
+			b.processingWG.Wait()
+			return b.GetRetry(), nil
+		case pool <- struct{}{}:
+		}
+		if !taskProcessor.PreConsumeHandler() {
+			<-pool
+			wait(stop, queueRetryDelay)
+			continue
+		}
+		msg, err := b.queue.pop(routingKey, stop)
+		if err != nil {
+			logging.RuntimeLog.Errorf("read task from queue:%s fail:%v", routingKey, err)
+			wait(stop, queueRetryDelay)
+		}
+		if len(msg) == 0 {
+			<-pool
+			continue
+		}
+		b.processingWG.Add(1)
+		go func() {
+			defer func() {
+				<-pool
+				b.processingWG.Done()
+			}()
+			if err := b.consumeOne(msg, taskProcessor); err != nil {
+				logging.RuntimeLog.Error(err)
+			}
+			if err := b.queue.ack(routingKey, msg); err != nil {
+				logging.RuntimeLog.Errorf("ack task from queue:%s fail:%v", routingKey, err)
+			}
+		}()
+	}
+}
+
+// StopConsuming 停止读取任务，并等待正在执行的任务完成
+func (b *queueBroker) StopConsuming() {
+	b.Broker.StopConsuming()
+	b.consumingWG.Wait()
+	b.processingWG.Wait()
+}
+
+// Publish 将任务放入routingKey对应的队列
+func (b *queueBroker) Publish(ctx context.Context, signature *tasks.Signature) error {
+	b.Broker.AdjustRoutingKey(signature)
+
+	msg, err := json.Marshal(signature)
+	if err != nil {
+		return fmt.Errorf("JSON marshal error: %s", err)
+	}
+	var eta time.Time
+	if signature.ETA != nil && signature.ETA.After(time.Now()) {
+		eta = *signature.ETA
+	}
+	return b.queue.push(signature.RoutingKey, msg, eta)
+}
+
+// GetPendingTasks 获取队列中等待执行的任务
+func (b *queueBroker) GetPendingTasks(queue string) ([]*tasks.Signature, error) {
+	if queue == "" {
+		queue = b.GetConfig().DefaultQueue
+	}
+	msgs, err := b.queue.pending(queue)
+	if err != nil {
+		return nil, err
+	}
+	return decodeSignatures(msgs)
+}
+
+// GetDelayedTasks 获取还没有到执行时间的任务
+func (b *queueBroker) GetDelayedTasks() ([]*tasks.Signature, error) {
+	msgs, err := b.queue.delayed()
+	if err != nil {
+		return nil, err
+	}
+	return decodeSignatures(msgs)
+}
+
+// consumeOne 执行一个任务；worker未注册的任务延迟后重新放回队列
+func (b *queueBroker) consumeOne(msg []byte, taskProcessor iface.TaskProcessor) error {
+	signature, err := decodeSignature(msg)
+	if err != nil {
+		return errs.NewErrCouldNotUnmarshalTaskSignature(msg, err)
+	}
+	if !b.IsTaskRegistered(signature.Name) {
+		if signature.IgnoreWhenTaskNotRegistered {
+			return nil
+		}
+		logging.RuntimeLog.Warningf("task not registered with this worker,requeue:%s", signature.Name)
+		return b.queue.push(signature.RoutingKey, msg, time.Now().Add(unregisteredTaskDelay))
+	}
+	return taskProcessor.Process(signature)
+}
+
+// moveDelayedTasks 定时将已到执行时间的任务、已离线的consumer未完成的任务放入队列
+func (b *queueBroker) moveDelayedTasks(stop <-chan int) {
+	ticker := time.NewTicker(time.Second)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-stop:
+			return
+		case <-ticker.C:
+			if _, err := b.queue.moveDelayed(); err != nil {
+				logging.RuntimeLog.Errorf("move delayed task fail:%v", err)
+			}
+			if count, err := b.queue.requeueExpired(); err != nil {
+				logging.RuntimeLog.Errorf("requeue expired task fail:%v", err)
+			} else if count > 0 {
+				logging.RuntimeLog.Warningf("requeue %d task of offline consumer", count)
+			}
+		}
+	}
+}
+
+// decodeSignature 解析任务消息
+func decodeSignature(msg []byte) (*tasks.Signature, error) {
+	signature := new(tasks.Signature)
+	decoder := json.NewDecoder(bytes.NewReader(msg))
+	decoder.UseNumber()
+	if err := decoder.Decode(signature); err != nil {
+		return nil, err
+	}
+	return signature, nil
+}
+
+// decodeSignatures 解析多个任务消息
+func decodeSignatures(msgs [][]byte) ([]*tasks.Signature, error) {
+	signatures := make([]*tasks.Signature, 0, len(msgs))
+	for _, msg := range msgs {
+		signature, err := decodeSignature(msg)
+		if err != nil {
+			return nil, err
+		}
+		signatures = append(signatures, signature)
+	}
+	return signatures, nil
+}
+
+// wait 等待一段时间，stop后立即返回
+func wait(stop <-chan int, d time.Duration) {
+	select {
+	case <-stop:
+	case <-time.After(d):
+	}
+}
@@ -0,0 +1,420 @@
+package ampq
+
+import (
+	"context"
+	"fmt"
+	amqpbroker "github.com/RichardKnop/machinery/v2/brokers/amqp"
+	"github.com/google/uuid"
+	"github.com/hanc00l/nemo_go/pkg/logging"
+	"github.com/streadway/amqp"
+	"sync"
+	"time"
+)
+
+const (
+	// amqpLeasePollInterval AMQP队列为空时再次读取的间隔
+	amqpLeasePollInterval = 500 * time.Millisecond
+	// taskLeaseTimeout worker读取任务后没有确认、也没有延长时，任务重新放回队列的时间
+	taskLeaseTimeout = 5 * time.Minute
+	// taskLeaseExtendInterval worker延长正在执行的任务的间隔，需小于taskLeaseTimeout
+	taskLeaseExtendInterval = time.Minute
+	// taskLeaseCheckInterval server检查超时任务的间隔，同时保持server读取Redis队列的consumer在线
+	taskLeaseCheckInterval = 10 * time.Second
+)
+
+// TaskTransport HTTP方式的worker通过server读取和放回任务
+type TaskTransport interface {
+	// LeaseTask 从routingKey对应的队列中读取一个任务，没有任务时返回nil
+	LeaseTask(routingKey string) ([]byte, error)
+	// RequeueTask 将任务放回队列，eta不为零时延迟到eta后执行
+	RequeueTask(routingKey string, msg []byte, eta time.Time) error
+	// AckTask 确认任务已执行完成
+	AckTask(taskIds []string) error
+	// ExtendTask 延长正在执行的任务，避免超时后被重新放回队列
+	ExtendTask(taskIds []string) error
+}
+
+// HTTPTaskTransport worker使用HTTP方式连接server时，读取和放回任务的接口
+var HTTPTaskTransport TaskTransport
+
+var (
+	// amqpLeaseChannels server为HTTP方式的worker读取AMQP队列的连接，按routingKey复用
+	amqpLeaseChannels      = make(map[string]*amqpLeaseChannel)
+	amqpLeaseChannelsMutex sync.Mutex
+)
+
+var (
+	// taskLeases server发送给HTTP方式的worker、还没有确认的任务，key为任务的UUID
+	taskLeases = make(map[string]*taskLease)
+	// taskLeaseBrokers 读取过任务的queueBroker，检查超时任务时保持Redis的consumer在线
+	taskLeaseBrokers   = make(map[*queueBroker]struct{})
+	taskLeasesMutex    sync.Mutex
+	taskLeaseCheckOnce sync.Once
+)
+
+// amqpLeaseChannel 读取一个AMQP队列的连接
+type amqpLeaseChannel struct {
+	sync.Mutex
+	broker  *amqpbroker.Broker
+	conn    *amqp.Connection
+	channel *amqp.Channel
+}
+
+// taskLease 发送给worker、还没有确认的任务
+type taskLease struct {
+	routingKey string
+	deadline   time.Time
+	// ack 确认任务，从队列中删除
+	ack func() error
+	// requeue 将任务放回队列
+	requeue func() error
+}
+
+// httpQueue worker通过server的HTTP接口读取任务的队列；延迟执行的任务由server的消息队列处理；
+// 读取的任务在执行完成后确认，执行期间定时延长，worker异常退出后由server在超时后放回队列
+type httpQueue struct {
+	sync.Mutex
+	transport  TaskTransport
+	tasks      map[string]struct{}
+	extendTime time.Time
+}
+
+func (q *httpQueue) push(routingKey string, msg []byte, eta time.Time) error {
+	if err := q.transport.RequeueTask(routingKey, msg, eta); err != nil {
+		return err
+	}
+	// 放回队列时server已确认原任务
+	if signature, err := decodeSignature(msg); err == nil {
+		q.Lock()
+		delete(q.tasks, signature.UUID)
+		q.Unlock()
+	}
+	return nil
+}
+
+func (q *httpQueue) pop(routingKey string, stop <-chan int) ([]byte, error) {
+	select {
+	case <-stop:
+		return nil, nil
+	default:
+	}
+	msg, err := q.transport.LeaseTask(routingKey)
+	if err != nil || len(msg) == 0 {
+		return msg, err
+	}
+	if signature, err := decodeSignature(msg); err == nil {
+		q.Lock()
+		if q.tasks == nil {
+			q.tasks = make(map[string]struct{})
+		}
+		q.tasks[signature.UUID] = struct{}{}
+		q.Unlock()
+	}
+	return msg, nil
+}
+
+func (q *httpQueue) ack(routingKey string, msg []byte) error {
+	signature, err := decodeSignature(msg)
+	if err != nil {
+		return err
+	}
+	q.Lock()
+	_, ok := q.tasks[signature.UUID]
+	delete(q.tasks, signature.UUID)
+	q.Unlock()
+	if !ok {
+		return nil
+	}
+	return q.transport.AckTask([]string{signature.UUID})
+}
+
+func (q *httpQueue) pending(routingKey string) ([][]byte, error) {
+	return nil, nil
+}
+
+func (q *httpQueue) delayed() ([][]byte, error) {
+	return nil, nil
+}
+
+func (q *httpQueue) moveDelayed() (int, error) {
+	return 0, nil
+}
+
+// requeueExpired 由server放回超时的任务；worker定时延长正在执行的任务
+func (q *httpQueue) requeueExpired() (int, error) {
+	q.Lock()
+	if time.Since(q.extendTime) < taskLeaseExtendInterval || len(q.tasks) == 0 {
+		q.Unlock()
+		return 0, nil
+	}
+	q.extendTime = time.Now()
+	var taskIds []string
+	for taskId := range q.tasks {
+		taskIds = append(taskIds, taskId)
+	}
+	q.Unlock()
+	return 0, q.transport.ExtendTask(taskIds)
+}
+
+// LeaseTask server为HTTP方式的worker从队列中读取一个任务，没有任务时等待，超时或ctx结束后返回nil；
+// 读取的任务在worker确认前保留在队列中，超时后重新放回队列
+func LeaseTask(ctx context.Context, routingKey string, timeout time.Duration) ([]byte, error) {
+	topicName := GetTopicByMQRoutingKey(routingKey)
+	if topicName == "" {
+		return nil, fmt.Errorf("invalid routing key:%s", routingKey)
+	}
+	server := GetServerTaskAMPQServer(topicName)
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+	taskLeaseCheckOnce.Do(func() {
+		go checkTaskLeases()
+	})
+
+	switch broker := server.GetBroker().(type) {
+	case *queueBroker:
+		taskLeasesMutex.Lock()
+		taskLeaseBrokers[broker] = struct{}{}
+		taskLeasesMutex.Unlock()
+		stop := make(chan int)
+		go func() {
+			<-ctx.Done()
+			close(stop)
+		}()
+		for ctx.Err() == nil {
+			msg, err := broker.queue.pop(routingKey, stop)
+			if err != nil {
+				return nil, err
+			}
+			if len(msg) > 0 {
+				addTaskLease(routingKey, msg, func() error {
+					return broker.queue.ack(routingKey, msg)
+				}, func() error {
+					if err := broker.queue.push(routingKey, msg, time.Time{}); err != nil {
+						return err
+					}
+					return broker.queue.ack(routingKey, msg)
+				})
+				return msg, nil
+			}
+		}
+		return nil, nil
+	case *amqpbroker.Broker:
+		lc := getAMQPLeaseChannel(routingKey, broker)
+		for {
+			delivery, ok, err := lc.get(routingKey)
+			if err != nil {
+				return nil, err
+			}
+			if ok {
+				addTaskLease(routingKey, delivery.Body, func() error {
+					return delivery.Ack(false)
+				}, func() error {
+					return delivery.Nack(false, true)
+				})
+				return delivery.Body, nil
+			}
+			select {
+			case <-ctx.Done():
+				return nil, nil
+			case <-time.After(amqpLeasePollInterval):
+			}
+		}
+	}
+	return nil, fmt.Errorf("broker not support lease task")
+}
+
+// RequeueTask server将HTTP方式的worker发送的任务放回队列，并确认worker读取的原任务
+func RequeueTask(routingKey string, msg []byte, eta time.Time) error {
+	topicName := GetTopicByMQRoutingKey(routingKey)
+	if topicName == "" {
+		return fmt.Errorf("invalid routing key:%s", routingKey)
+	}
+	signature, err := decodeSignature(msg)
+	if err != nil {
+		return err
+	}
+	signature.RoutingKey = routingKey
+	if !eta.IsZero() {
+		signature.ETA = &eta
+	}
+	// 先取出原任务，避免放回队列的任务被再次读取后误确认
+	lease := removeTaskLease(signature.UUID)
+	if err = GetServerTaskAMPQServer(topicName).GetBroker().Publish(context.Background(), signature); err != nil {
+		if lease != nil {
+			restoreTaskLease(signature.UUID, lease)
+		}
+		return err
+	}
+	if lease != nil {
+		if err = lease.ack(); err != nil {
+			logging.RuntimeLog.Errorf("ack requeued task:%s fail:%v", signature.UUID, err)
+		}
+	}
+	return nil
+}
+
+// AckTask 确认HTTP方式的worker已执行完成的任务，不存在时忽略
+func AckTask(taskId string) {
+	lease := removeTaskLease(taskId)
+	if lease == nil {
+		return
+	}
+	if err := lease.ack(); err != nil {
+		logging.RuntimeLog.Errorf("ack task:%s fail:%v", taskId, err)
+	}
+}
+
+// ExtendTask 延长HTTP方式的worker正在执行的任务
+func ExtendTask(taskId string) {
+	taskLeasesMutex.Lock()
+	defer taskLeasesMutex.Unlock()
+
+	if lease, ok := taskLeases[taskId]; ok {
+		lease.deadline = time.Now().Add(taskLeaseTimeout)
+	}
+}
+
+// RequeueLeasedTask 将发送给worker失败的任务立即放回队列
+func RequeueLeasedTask(msg []byte) {
+	signature, err := decodeSignature(msg)
+	if err != nil {
+		return
+	}
+	lease := removeTaskLease(signature.UUID)
+	if lease == nil {
+		return
+	}
+	if err = lease.requeue(); err != nil {
+		logging.RuntimeLog.Errorf("requeue task:%s fail:%v", signature.UUID, err)
+		restoreTaskLease(signature.UUID, lease)
+	}
+}
+
+// addTaskLease 记录发送给worker的任务；同一任务已存在时确认原任务
+func addTaskLease(routingKey string, msg []byte, ack func() error, requeue func() error) {
+	taskId := uuid.New().String()
+	if signature, err := decodeSignature(msg); err == nil && signature.UUID != "" {
+		taskId = signature.UUID
+	}
+	taskLeasesMutex.Lock()
+	old := taskLeases[taskId]
+	taskLeases[taskId] = &taskLease{routingKey: routingKey, deadline: time.Now().Add(taskLeaseTimeout), ack: ack, requeue: requeue}
+	taskLeasesMutex.Unlock()
+
+	if old != nil {
+		logging.RuntimeLog.Warningf("task:%s leased again,ack the previous one", taskId)
+		if err := old.ack(); err != nil {
+			logging.RuntimeLog.Errorf("ack task:%s fail:%v", taskId, err)
+		}
+	}
+}
+
+// removeTaskLease 取出发送给worker的任务
+func removeTaskLease(taskId string) *taskLease {
+	taskLeasesMutex.Lock()
+	defer taskLeasesMutex.Unlock()
+
+	lease, ok := taskLeases[taskId]
+	if !ok {
+		return nil
+	}
+	delete(taskLeases, taskId)
+	return lease
+}
+
+// restoreTaskLease 确认或放回失败后重新记录任务，等待超时后再次放回
+func restoreTaskLease(taskId string, lease *taskLease) {
+	taskLeasesMutex.Lock()
+	defer taskLeasesMutex.Unlock()
+
+	if _, ok := taskLeases[taskId]; !ok {
+		taskLeases[taskId] = lease
+	}
+}
+
+// checkTaskLeases 定时将超时未确认的任务放回队列
+func checkTaskLeases() {
+	ticker := time.NewTicker(taskLeaseCheckInterval)
+	defer ticker.Stop()
+	for range ticker.C {
+		requeueExpiredTaskLeases()
+	}
+}
+
+// requeueExpiredTaskLeases 将超时未确认的任务放回队列，并保持server读取Redis队列的consumer在线
+func requeueExpiredTaskLeases() {
+	now := time.Now()
+	expired := make(map[string]*taskLease)
+	var brokers []*queueBroker
+	taskLeasesMutex.Lock()
+	for taskId, lease := range taskLeases {
+		if lease.deadline.Before(now) {
+			expired[taskId] = lease
+			delete(taskLeases, taskId)
+		}
+	}
+	for broker := range taskLeaseBrokers {
+		brokers = append(brokers, broker)
+	}
+	taskLeasesMutex.Unlock()
+
+	for _, broker := range brokers {
+		if _, err := broker.queue.requeueExpired(); err != nil {
+			logging.RuntimeLog.Errorf("requeue expired task fail:%v", err)
+		}
+	}
+	for taskId, lease := range expired {
+		if err := lease.requeue(); err != nil {
+			logging.RuntimeLog.Errorf("requeue expired task:%s fail:%v", taskId, err)
+			lease.deadline = time.Now().Add(taskLeaseCheckInterval)
+			restoreTaskLease(taskId, lease)
+			continue
+		}
+		logging.RuntimeLog.Warningf("task:%s not acked by worker in time,requeue to %s", taskId, lease.routingKey)
+	}
+}
+
+// getAMQPLeaseChannel 获取读取AMQP队列的连接
+func getAMQPLeaseChannel(routingKey string, broker *amqpbroker.Broker) *amqpLeaseChannel {
+	amqpLeaseChannelsMutex.Lock()
+	defer amqpLeaseChannelsMutex.Unlock()
+
+	if _, ok := amqpLeaseChannels[routingKey]; !ok {
+		amqpLeaseChannels[routingKey] = &amqpLeaseChannel{broker: broker}
+	}
+	return amqpLeaseChannels[routingKey]
+}
+
+// get 读取队列中的一个任务，任务需要确认；队列的声明与machinery一致，连接出错时在下次读取时重新连接，未确认的任务由rabbitmq放回队列
+func (c *amqpLeaseChannel) get(routingKey string) (delivery amqp.Delivery, ok bool, err error) {
+	c.Lock()
+	defer c.Unlock()
+
+	if c.channel == nil {
+		cnf := c.broker.GetConfig()
+		conn, channel, _, _, _, connErr := c.broker.Connect(
+			cnf.Broker,
+			cnf.MultipleBrokerSeparator,
+			cnf.TLSConfig,
+			cnf.AMQP.Exchange,
+			cnf.AMQP.ExchangeType,
+			routingKey,
+			true,
+			false,
+			routingKey,
+			nil,
+			amqp.Table(cnf.AMQP.QueueDeclareArgs),
+			amqp.Table(cnf.AMQP.QueueBindingArgs),
+		)
+		if connErr != nil {
+			return delivery, false, connErr
+		}
+		c.conn, c.channel = conn, channel
+	}
+	delivery, ok, err = c.channel.Get(routingKey, false)
+	if err != nil {
+		c.broker.Close(c.channel, c.conn)
+		c.conn, c.channel = nil, nil
+	}
+	return
+}
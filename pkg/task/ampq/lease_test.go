@@ -0,0 +1,52 @@
+package ampq
+
+import (
+	"context"
+	"encoding/json"
+	"github.com/RichardKnop/machinery/v2/tasks"
+	"testing"
+	"time"
+)
+
+func TestLeaseTask(t *testing.T) {
+	topicName := "test.lease"
+	routingKey := GetRoutingKeyByTopic(topicName)
+	taskServerConnMutex.Lock()
+	taskServerConn[topicName] = startMemoryServer(topicName, 1)
+	taskServerConnMutex.Unlock()
+
+	msg, _ := json.Marshal(&tasks.Signature{UUID: "task_lease", Name: "test", RoutingKey: routingKey})
+	_ = memoryTaskQueue.push(routingKey, msg, time.Time{})
+	leased, err := LeaseTask(context.Background(), routingKey, time.Second)
+	if err != nil || string(leased) != string(msg) {
+		t.Fatalf("lease task:%s,%v", leased, err)
+	}
+	if _, ok := taskLeases["task_lease"]; !ok {
+		t.Fatal("leased task not recorded")
+	}
+	// 超时未确认的任务放回队列
+	taskLeases["task_lease"].deadline = time.Now().Add(-time.Second)
+	requeueExpiredTaskLeases()
+	if msgs, _ := memoryTaskQueue.pending(routingKey); len(msgs) != 1 {
+		t.Fatalf("expired task not requeued:%d", len(msgs))
+	}
+	// 确认后不再放回队列
+	if leased, _ = LeaseTask(context.Background(), routingKey, time.Second); len(leased) == 0 {
+		t.Fatal("lease requeued task fail")
+	}
+	AckTask("task_lease")
+	requeueExpiredTaskLeases()
+	if _, ok := taskLeases["task_lease"]; ok {
+		t.Error("acked task still recorded")
+	}
+	if msgs, _ := memoryTaskQueue.pending(routingKey); len(msgs) != 0 {
+		t.Errorf("acked task requeued:%d", len(msgs))
+	}
+	// 发送给worker失败的任务立即放回队列
+	_ = memoryTaskQueue.push(routingKey, msg, time.Time{})
+	leased, _ = LeaseTask(context.Background(), routingKey, time.Second)
+	RequeueLeasedTask(leased)
+	if msgs, _ := memoryTaskQueue.pending(routingKey); len(msgs) != 1 {
+		t.Errorf("task not requeued:%d", len(msgs))
+	}
+}
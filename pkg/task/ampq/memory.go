@@ -0,0 +1,291 @@
+package ampq
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	backendsiface "github.com/RichardKnop/machinery/v2/backends/iface"
+	"github.com/RichardKnop/machinery/v2/common"
+	"github.com/RichardKnop/machinery/v2/config"
+	"github.com/RichardKnop/machinery/v2/tasks"
+	"sort"
+	"sync"
+	"time"
+)
+
+// memoryTaskQueue 进程内全局的消息队列，server与worker通过routingKey共享
+var memoryTaskQueue = newMemoryQueue()
+
+// memoryQueue 进程内的消息队列
+type memoryQueue struct {
+	sync.Mutex
+	queues       map[string][][]byte
+	notify       map[string]chan struct{}
+	delayedTasks []memoryDelayedTask
+}
+
+// memoryDelayedTask 还没有到执行时间的任务
+type memoryDelayedTask struct {
+	routingKey string
+	msg        []byte
+	eta        time.Time
+}
+
+func newMemoryQueue() *memoryQueue {
+	return &memoryQueue{
+		queues: make(map[string][][]byte),
+		notify: make(map[string]chan struct{}),
+	}
+}
+
+func (q *memoryQueue) push(routingKey string, msg []byte, eta time.Time) error {
+	q.Lock()
+	defer q.Unlock()
+
+	if !eta.IsZero() && eta.After(time.Now()) {
+		q.delayedTasks = append(q.delayedTasks, memoryDelayedTask{routingKey: routingKey, msg: msg, eta: eta})
+		return nil
+	}
+	q.queues[routingKey] = append(q.queues[routingKey], msg)
+	q.signal(routingKey)
+	return nil
+}
+
+func (q *memoryQueue) pop(routingKey string, stop <-chan int) ([]byte, error) {
+	for i := 0; i < 2; i++ {
+		q.Lock()
+		if items := q.queues[routingKey]; len(items) > 0 {
+			msg := items[0]
+			if len(items) == 1 {
+				delete(q.queues, routingKey)
+			} else {
+				q.queues[routingKey] = items[1:]
+				// 还有任务时通知其它等待的读取
+				q.signal(routingKey)
+			}
+			q.Unlock()
+			return msg, nil
+		}
+		notify := q.getNotify(routingKey)
+		q.Unlock()
+		select {
+		case <-stop:
+			return nil, nil
+		case <-notify:
+		case <-time.After(time.Second):
+		}
+	}
+	return nil, nil
+}
+
+func (q *memoryQueue) ack(routingKey string, msg []byte) error {
+	return nil
+}
+
+func (q *memoryQueue) pending(routingKey string) ([][]byte, error) {
+	q.Lock()
+	defer q.Unlock()
+
+	msgs := make([][]byte, len(q.queues[routingKey]))
+	copy(msgs, q.queues[routingKey])
+	return msgs, nil
+}
+
+func (q *memoryQueue) delayed() ([][]byte, error) {
+	q.Lock()
+	defer q.Unlock()
+
+	delayedTasks := make([]memoryDelayedTask, len(q.delayedTasks))
+	copy(delayedTasks, q.delayedTasks)
+	sort.Slice(delayedTasks, func(i, j int) bool {
+		return delayedTasks[i].eta.Before(delayedTasks[j].eta)
+	})
+	var msgs [][]byte
+	for _, t := range delayedTasks {
+		msgs = append(msgs, t.msg)
+	}
+	return msgs, nil
+}
+
+func (q *memoryQueue) moveDelayed() (count int, err error) {
+	q.Lock()
+	defer q.Unlock()
+
+	now := time.Now()
+	var remains []memoryDelayedTask
+	for _, t := range q.delayedTasks {
+		if t.eta.After(now) {
+			remains = append(remains, t)
+			continue
+		}
+		q.queues[t.routingKey] = append(q.queues[t.routingKey], t.msg)
+		q.signal(t.routingKey)
+		count++
+	}
+	q.delayedTasks = remains
+	return
+}
+
+func (q *memoryQueue) requeueExpired() (int, error) {
+	return 0, nil
+}
+
+// getNotify 获取队列有新任务时的通知；调用时需持有锁
+func (q *memoryQueue) getNotify(routingKey string) chan struct{} {
+	if _, ok := q.notify[routingKey]; !ok {
+		q.notify[routingKey] = make(chan struct{}, 1)
+	}
+	return q.notify[routingKey]
+}
+
+// signal 通知等待读取队列的worker；调用时需持有锁
+func (q *memoryQueue) signal(routingKey string) {
+	select {
+	case q.getNotify(routingKey) <- struct{}{}:
+	default:
+	}
+}
+
+// memoryBackend 保存在进程内的任务结果，超过保存时间后删除
+type memoryBackend struct {
+	common.Backend
+	sync.Mutex
+	states map[string]memoryTaskState
+	groups map[string][]string
+}
+
+// memoryTaskState 任务结果及过期时间
+type memoryTaskState struct {
+	state    []byte
+	expireAt time.Time
+}
+
+func newMemoryBackend(cnf *config.Config) backendsiface.Backend {
+	return &memoryBackend{
+		Backend: common.NewBackend(cnf),
+		states:  make(map[string]memoryTaskState),
+		groups:  make(map[string][]string),
+	}
+}
+
+func (b *memoryBackend) InitGroup(groupUUID string, taskUUIDs []string) error {
+	b.Lock()
+	defer b.Unlock()
+
+	b.groups[groupUUID] = append([]string{}, taskUUIDs...)
+	return nil
+}
+
+func (b *memoryBackend) GroupCompleted(groupUUID string, groupTaskCount int) (bool, error) {
+	states, err := b.GroupTaskStates(groupUUID, groupTaskCount)
+	if err != nil {
+		return false, err
+	}
+	var completed int
+	for _, state := range states {
+		if state.IsCompleted() {
+			completed++
+		}
+	}
+	return completed == groupTaskCount, nil
+}
+
+func (b *memoryBackend) GroupTaskStates(groupUUID string, groupTaskCount int) ([]*tasks.TaskState, error) {
+	b.Lock()
+	taskUUIDs, ok := b.groups[groupUUID]
+	b.Unlock()
+	if !ok {
+		return nil, fmt.Errorf("group not found: %s", groupUUID)
+	}
+	states := make([]*tasks.TaskState, 0, groupTaskCount)
+	for _, taskUUID := range taskUUIDs {
+		state, err := b.GetState(taskUUID)
+		if err != nil {
+			return nil, err
+		}
+		states = append(states, state)
+	}
+	return states, nil
+}
+
+func (b *memoryBackend) TriggerChord(groupUUID string) (bool, error) {
+	return true, nil
+}
+
+func (b *memoryBackend) SetStatePending(signature *tasks.Signature) error {
+	return b.updateState(tasks.NewPendingTaskState(signature))
+}
+
+func (b *memoryBackend) SetStateReceived(signature *tasks.Signature) error {
+	return b.updateState(tasks.NewReceivedTaskState(signature))
+}
+
+func (b *memoryBackend) SetStateStarted(signature *tasks.Signature) error {
+	return b.updateState(tasks.NewStartedTaskState(signature))
+}
+
+func (b *memoryBackend) SetStateRetry(signature *tasks.Signature) error {
+	return b.updateState(tasks.NewRetryTaskState(signature))
+}
+
+func (b *memoryBackend) SetStateSuccess(signature *tasks.Signature, results []*tasks.TaskResult) error {
+	return b.updateState(tasks.NewSuccessTaskState(signature, results))
+}
+
+func (b *memoryBackend) SetStateFailure(signature *tasks.Signature, err string) error {
+	return b.updateState(tasks.NewFailureTaskState(signature, err))
+}
+
+func (b *memoryBackend) GetState(taskUUID string) (*tasks.TaskState, error) {
+	b.Lock()
+	s, ok := b.states[taskUUID]
+	b.Unlock()
+	if !ok || time.Now().After(s.expireAt) {
+		return nil, fmt.Errorf("task not found: %s", taskUUID)
+	}
+	state := new(tasks.TaskState)
+	decoder := json.NewDecoder(bytes.NewReader(s.state))
+	decoder.UseNumber()
+	if err := decoder.Decode(state); err != nil {
+		return nil, fmt.Errorf("unmarshal task state error: %v", err)
+	}
+	return state, nil
+}
+
+func (b *memoryBackend) PurgeState(taskUUID string) error {
+	b.Lock()
+	defer b.Unlock()
+
+	delete(b.states, taskUUID)
+	return nil
+}
+
+func (b *memoryBackend) PurgeGroupMeta(groupUUID string) error {
+	b.Lock()
+	defer b.Unlock()
+
+	delete(b.groups, groupUUID)
+	return nil
+}
+
+// updateState 保存任务结果，同时删除已过期的结果
+func (b *memoryBackend) updateState(state *tasks.TaskState) error {
+	msg, err := json.Marshal(state)
+	if err != nil {
+		return fmt.Errorf("marshal task state error: %v", err)
+	}
+	now := time.Now()
+	b.Lock()
+	defer b.Unlock()
+
+	for taskUUID, s := range b.states {
+		if now.After(s.expireAt) {
+			delete(b.states, taskUUID)
+		}
+	}
+	b.states[state.TaskUUID] = memoryTaskState{
+		state:    msg,
+		expireAt: now.Add(time.Duration(b.GetConfig().ResultsExpireIn) * time.Second),
+	}
+	return nil
+}
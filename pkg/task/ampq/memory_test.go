@@ -0,0 +1,91 @@
+package ampq
+
+import (
+	"fmt"
+	"github.com/RichardKnop/machinery/v2/backends/result"
+	"github.com/RichardKnop/machinery/v2/tasks"
+	"testing"
+	"time"
+)
+
+func TestMemoryQueue(t *testing.T) {
+	q := newMemoryQueue()
+	stop := make(chan int)
+	_ = q.push("a", []byte("1"), time.Time{})
+	_ = q.push("a", []byte("2"), time.Now().Add(time.Hour))
+	_ = q.push("b", []byte("3"), time.Time{})
+
+	if msg, _ := q.pop("a", stop); string(msg) != "1" {
+		t.Errorf("pop a:%s", msg)
+	}
+	if msg, _ := q.pop("a", stop); msg != nil {
+		t.Errorf("pop delayed task before eta:%s", msg)
+	}
+	if msgs, _ := q.delayed(); len(msgs) != 1 {
+		t.Errorf("delayed:%d", len(msgs))
+	}
+	q.delayedTasks[0].eta = time.Now()
+	if count, _ := q.moveDelayed(); count != 1 {
+		t.Errorf("move delayed:%d", count)
+	}
+	if msgs, _ := q.pending("a"); len(msgs) != 1 || string(msgs[0]) != "2" {
+		t.Errorf("pending a:%v", msgs)
+	}
+	close(stop)
+	if msg, _ := q.pop("c", stop); msg != nil {
+		t.Errorf("pop c:%s", msg)
+	}
+}
+
+func TestMemoryServer(t *testing.T) {
+	topicName := "test.memory"
+	server := startMemoryServer(topicName, 2)
+	processed := make(chan string, 3)
+	if err := server.RegisterTasks(map[string]interface{}{
+		"test": func(arg string) (string, error) {
+			processed <- arg
+			return "ok:" + arg, nil
+		},
+	}); err != nil {
+		t.Fatal(err)
+	}
+	states := make(chan string, 3)
+	worker := server.NewWorker("test", 2)
+	worker.SetPostTaskHandler(func(signature *tasks.Signature) {
+		r := result.NewAsyncResult(signature, server.GetBackend())
+		rr, _ := r.Get(0)
+		states <- fmt.Sprintf("%s:%s", r.GetState().State, tasks.HumanReadableResults(rr))
+	})
+	worker.LaunchAsync(make(chan error, 1))
+	defer worker.Quit()
+
+	start := time.Now()
+	for i := 0; i < 3; i++ {
+		eta := start.Add(time.Duration(i) * time.Second)
+		_, err := startMemoryServer(topicName, 2).SendTask(&tasks.Signature{
+			Name:       "test",
+			ETA:        &eta,
+			RoutingKey: GetRoutingKeyByTopic(topicName),
+			Args:       []tasks.Arg{{Type: "string", Value: fmt.Sprint(i)}},
+		})
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+	for i := 0; i < 3; i++ {
+		select {
+		case arg := <-processed:
+			if arg != fmt.Sprint(i) {
+				t.Errorf("task processed out of eta order:%s", arg)
+			}
+		case <-time.After(10 * time.Second):
+			t.Fatal("task not processed")
+		}
+		if state := <-states; state != fmt.Sprintf("%s:ok:%d", SUCCESS, i) {
+			t.Errorf("task state:%s", state)
+		}
+	}
+	if time.Since(start) < 2*time.Second {
+		t.Errorf("task executed before eta")
+	}
+}
@@ -0,0 +1,189 @@
+package ampq
+
+import (
+	"fmt"
+	"github.com/gomodule/redigo/redis"
+	"github.com/google/uuid"
+	"github.com/hanc00l/nemo_go/pkg/conf"
+	"sync"
+	"time"
+)
+
+const (
+	// redisDelayedTasksKey 保存延迟执行任务的有序集合，score为执行时间
+	redisDelayedTasksKey = TopicMQPrefix + ".delayed"
+	// redisConsumersKey 保存全部consumer的集合
+	redisConsumersKey = TopicMQPrefix + ".consumers"
+	// redisProcessingKeyPrefix consumer正在执行的任务列表，任务执行完成后删除
+	redisProcessingKeyPrefix = TopicMQPrefix + ".processing."
+	// redisAliveKeyPrefix consumer在线的标记，过期后consumer正在执行的任务被放回队列
+	redisAliveKeyPrefix = TopicMQPrefix + ".alive."
+	// redisAliveTTL consumer在线标记的过期时间（秒）
+	redisAliveTTL = 60
+	// redisPopTimeout 读取队列的阻塞等待时间（秒）
+	redisPopTimeout = 1
+)
+
+// redisMoveDelayedScript 将已到执行时间的任务原子地移动到任务routingKey对应的队列
+var redisMoveDelayedScript = redis.NewScript(1, `
+local msgs = redis.call('ZRANGEBYSCORE', KEYS[1], '-inf', ARGV[1], 'LIMIT', 0, 100)
+for _, msg in ipairs(msgs) do
+	redis.call('ZREM', KEYS[1], msg)
+	redis.call('LPUSH', cjson.decode(msg)['RoutingKey'], msg)
+end
+return #msgs
+`)
+
+// redisRequeueExpiredScript 将已离线的consumer正在执行的任务放回任务routingKey对应的队列，并优先被读取
+var redisRequeueExpiredScript = redis.NewScript(1, `
+local count = 0
+for _, id in ipairs(redis.call('SMEMBERS', KEYS[1])) do
+	if redis.call('EXISTS', ARGV[2] .. id) == 0 then
+		local key = ARGV[1] .. id
+		local msg = redis.call('RPOP', key)
+		while msg do
+			redis.call('RPUSH', cjson.decode(msg)['RoutingKey'], msg)
+			count = count + 1
+			msg = redis.call('RPOP', key)
+		end
+		redis.call('SREM', KEYS[1], id)
+	end
+end
+return count
+`)
+
+// redisQueue 使用Redis的list作为任务队列，延迟执行的任务保存在有序集合中；
+// 读取的任务同时放入consumer的processing列表，执行完成后才删除，consumer离线后由其它consumer放回队列
+type redisQueue struct {
+	pool       *redis.Pool
+	consumerId string
+	aliveMutex sync.Mutex
+	isAliveSet bool
+}
+
+func newRedisQueue(config conf.Redis) *redisQueue {
+	address := fmt.Sprintf("%s:%d", config.Host, config.Port)
+	pool := &redis.Pool{
+		MaxIdle:     10,
+		IdleTimeout: 5 * time.Minute,
+		Dial: func() (redis.Conn, error) {
+			return redis.Dial("tcp", address,
+				redis.DialPassword(config.Password),
+				redis.DialDatabase(config.DB),
+				redis.DialConnectTimeout(10*time.Second),
+				// 读取超时需大于BRPOPLPUSH的阻塞时间
+				redis.DialReadTimeout((redisPopTimeout+10)*time.Second),
+				redis.DialWriteTimeout(10*time.Second),
+			)
+		},
+		TestOnBorrow: func(c redis.Conn, t time.Time) error {
+			if time.Since(t) < time.Minute {
+				return nil
+			}
+			_, err := c.Do("PING")
+			return err
+		},
+	}
+	return &redisQueue{pool: pool, consumerId: uuid.New().String()}
+}
+
+func (q *redisQueue) push(routingKey string, msg []byte, eta time.Time) (err error) {
+	conn := q.pool.Get()
+	defer conn.Close()
+
+	if !eta.IsZero() && eta.After(time.Now()) {
+		_, err = conn.Do("ZADD", redisDelayedTasksKey, eta.UnixNano(), msg)
+		return
+	}
+	_, err = conn.Do("LPUSH", routingKey, msg)
+	return
+}
+
+func (q *redisQueue) pop(routingKey string, stop <-chan int) ([]byte, error) {
+	conn := q.pool.Get()
+	defer conn.Close()
+
+	q.aliveMutex.Lock()
+	isAliveSet := q.isAliveSet
+	q.aliveMutex.Unlock()
+	if !isAliveSet {
+		if err := q.keepAlive(conn); err != nil {
+			return nil, err
+		}
+	}
+	// 使用BRPOPLPUSH以兼容Redis 6.2以前的版本（BLMOVE）
+	msg, err := redis.Bytes(conn.Do("BRPOPLPUSH", routingKey, q.processingKey(), redisPopTimeout))
+	if err == redis.ErrNil {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return msg, nil
+}
+
+func (q *redisQueue) ack(routingKey string, msg []byte) error {
+	conn := q.pool.Get()
+	defer conn.Close()
+
+	_, err := conn.Do("LREM", q.processingKey(), 1, msg)
+	return err
+}
+
+func (q *redisQueue) pending(routingKey string) ([][]byte, error) {
+	conn := q.pool.Get()
+	defer conn.Close()
+
+	msgs, err := redis.ByteSlices(conn.Do("LRANGE", routingKey, 0, -1))
+	if err != nil {
+		return nil, err
+	}
+	// 队列从左侧放入、右侧读取，按放入的先后顺序返回
+	for i, j := 0, len(msgs)-1; i < j; i, j = i+1, j-1 {
+		msgs[i], msgs[j] = msgs[j], msgs[i]
+	}
+	return msgs, nil
+}
+
+func (q *redisQueue) delayed() ([][]byte, error) {
+	conn := q.pool.Get()
+	defer conn.Close()
+
+	return redis.ByteSlices(conn.Do("ZRANGE", redisDelayedTasksKey, 0, -1))
+}
+
+func (q *redisQueue) moveDelayed() (int, error) {
+	conn := q.pool.Get()
+	defer conn.Close()
+
+	return redis.Int(redisMoveDelayedScript.Do(conn, redisDelayedTasksKey, time.Now().UnixNano()))
+}
+
+func (q *redisQueue) requeueExpired() (int, error) {
+	conn := q.pool.Get()
+	defer conn.Close()
+
+	if err := q.keepAlive(conn); err != nil {
+		return 0, err
+	}
+	return redis.Int(redisRequeueExpiredScript.Do(conn, redisConsumersKey, redisProcessingKeyPrefix, redisAliveKeyPrefix))
+}
+
+// keepAlive 注册consumer并更新在线标记
+func (q *redisQueue) keepAlive(conn redis.Conn) (err error) {
+	if _, err = conn.Do("SET", redisAliveKeyPrefix+q.consumerId, time.Now().Unix(), "EX", redisAliveTTL); err != nil {
+		return
+	}
+	if _, err = conn.Do("SADD", redisConsumersKey, q.consumerId); err != nil {
+		return
+	}
+	q.aliveMutex.Lock()
+	q.isAliveSet = true
+	q.aliveMutex.Unlock()
+	return
+}
+
+// processingKey consumer正在执行的任务列表
+func (q *redisQueue) processingKey() string {
+	return redisProcessingKeyPrefix + q.consumerId
+}
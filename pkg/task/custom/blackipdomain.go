@@ -130,6 +130,22 @@ func (t *IPTarget) CheckBlackTarget(target string) bool {
 		_, existed := t.BlackMapList[target]
 		return existed
 	}
+	if utils.CheckIPV6(target) {
+		ip := utils.FormatIPV6(target)
+		if _, existed := t.BlackMapList[ip]; existed {
+			return true
+		}
+		// 未展开的IPv6子网及范围
+		ipInt, _, _ := utils.IPV6Bounds(ip)
+		for black := range t.BlackMapList {
+			if !utils.CheckIPV6Unexpanded(black) {
+				continue
+			}
+			if start, end, ok := utils.IPV6Bounds(black); ok && start.Cmp(ipInt) <= 0 && ipInt.Cmp(end) <= 0 {
+				return true
+			}
+		}
+	}
 	return false
 }
 
@@ -164,7 +180,7 @@ func (t *DomainTarget) LoadBlackTargetList() error {
 }
 
 func (t *DomainTarget) CheckBlackTarget(target string) bool {
-	if !utils.CheckIPV4(target) && utils.CheckDomain(target) {
+	if !utils.CheckIP(target) && utils.CheckDomain(target) {
 		for txt := range t.BlackMapList {
 			// 生成格式为.qq.com$
 			regPattern := strings.ReplaceAll(txt, ".", "\\.") + "$"
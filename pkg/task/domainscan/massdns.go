@@ -30,7 +30,7 @@ func (m *Massdns) Do() {
 	blackDomain := custom.NewBlackTargetCheck(custom.CheckDomain)
 	for _, line := range strings.Split(m.Config.Target, ",") {
 		domain := strings.TrimSpace(line)
-		if domain == "" || utils.CheckIPV4(domain) || utils.CheckIPV4Subnet(domain) {
+		if domain == "" || utils.CheckIP(domain) || utils.CheckIPSubnet(domain) {
 			continue
 		}
 		if blackDomain.CheckBlack(domain) {
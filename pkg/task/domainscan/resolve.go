@@ -42,7 +42,7 @@ func (r *Resolve) Do() {
 		r.Result.DomainResult = make(map[string]*DomainResult)
 		for _, line := range strings.Split(r.Config.Target, ",") {
 			domain := strings.TrimSpace(line)
-			if domain == "" || utils.CheckIPV4(domain) || utils.CheckIPV4Subnet(domain) {
+			if domain == "" || utils.CheckIP(domain) || utils.CheckIPSubnet(domain) {
 				continue
 			}
 			if blackDomain.CheckBlack(domain) {
@@ -69,7 +69,7 @@ func (r *Resolve) RunResolve(domain string) {
 		for _, h := range host {
 			r.Result.SetDomainAttr(domain, DomainAttrResult{
 				Source:  "domainscan",
-				Tag:     "A",
+				Tag:     GetResolveTag(h),
 				Content: h,
 			})
 		}
@@ -93,14 +93,24 @@ func (r *Resolve) RunResolve(domain string) {
 	}
 }
 
-// ResolveDomain 解析一个域名的A记录和CNAME记录
+// ResolveDomain 解析一个域名的A、AAAA记录和CNAME记录
 func ResolveDomain(domain string) (CName string, Host []string) {
 	//CName, _ = net.LookupCNAME(domain)
 	host, _ := net.LookupHost(domain)
 	for _, h := range host {
 		if utils.CheckIPV4(h) {
 			Host = append(Host, h)
+		} else if utils.CheckIPV6(h) {
+			Host = append(Host, utils.FormatIPV6(h))
 		}
 	}
 	return
 }
+
+// GetResolveTag 根据解析的IP地址类型返回对应的记录类型：A或AAAA
+func GetResolveTag(ip string) string {
+	if utils.CheckIPV6(ip) {
+		return "AAAA"
+	}
+	return "A"
+}
@@ -40,6 +40,7 @@ type Config struct {
 	IsIgnoreCDN        bool   `json:"ignorecdn"`
 	IsIgnoreOutofChina bool   `json:"ignoreoutofchina"`
 	WorkspaceId        int    `json:"workspaceId"`
+	MainTaskId         string `json:"-"`
 }
 
 // DomainAttrResult 域名属性结果
@@ -48,6 +49,7 @@ type DomainAttrResult struct {
 	Source    string
 	Tag       string
 	Content   string
+	IsNew     bool `json:"-"` //server保存结果时设置：新的属性内容
 }
 
 type HttpResult struct {
@@ -63,6 +65,7 @@ type DomainResult struct {
 	OrgId       *int
 	DomainAttrs []DomainAttrResult
 	HttpInfo    []HttpResult
+	IsNew       bool `json:"-"` //server保存结果时设置：新增（或删除后重新解析）的域名
 }
 
 // Result 域名结果
@@ -140,10 +143,14 @@ func (r *Result) SaveResult(config Config) string {
 			if isNew {
 				newDomain++
 			}
+			domainResult.IsNew = isNew || config.isDomainRemoved(domainName)
+			if domainResult.IsNew {
+				config.addHistory(domainName, db.HistoryItemDomain, db.HistoryActionAdd, "", "", "")
+			}
 		}
 		resultDomainCount++
 		// save domain attr
-		for _, domainAttrResult := range domainResult.DomainAttrs {
+		for i, domainAttrResult := range domainResult.DomainAttrs {
 			domainAttr := &db.DomainAttr{
 				RelatedId: domain.Id,
 				Source:    domainAttrResult.Source,
@@ -155,6 +162,7 @@ func (r *Result) SaveResult(config Config) string {
 			} else {
 				domainAttr.Content = domainAttrResult.Content
 			}
+			domainResult.DomainAttrs[i].IsNew = config.addDomainAttrHistory(domainName, *domainAttr)
 			domainAttr.SaveOrUpdate()
 		}
 		//save http info
@@ -173,6 +181,7 @@ func (r *Result) SaveResult(config Config) string {
 			httpInfo.SaveOrUpdate()
 		}
 	}
+	config.addRemovedDomainHistory(r.DomainResult, blackDomain)
 	var sb strings.Builder
 	sb.WriteString(fmt.Sprintf("domain:%d", resultDomainCount))
 	if newDomain > 0 {
@@ -181,13 +190,69 @@ func (r *Result) SaveResult(config Config) string {
 	return sb.String()
 }
 
+// addHistory 记录一条域名资产的变更
+func (config *Config) addHistory(domainName, item, action, field, oldValue, newValue string) {
+	history := &db.AssetHistory{
+		WorkspaceId: config.WorkspaceId,
+		AssetType:   db.AssetTypeDomain,
+		AssetName:   domainName,
+		Item:        item,
+		Action:      action,
+		Field:       field,
+		OldValue:    oldValue,
+		NewValue:    newValue,
+		MainTaskId:  config.MainTaskId,
+	}
+	if !history.Add() {
+		logging.RuntimeLog.Errorf("save asset history fail:%s %s", domainName, item)
+	}
+}
+
+// isDomainRemoved 域名最近的变更记录是否为已删除
+func (config *Config) isDomainRemoved(domainName string) bool {
+	history := &db.AssetHistory{WorkspaceId: config.WorkspaceId, AssetName: domainName}
+	return history.GetLatestByDomain() && history.Action == db.HistoryActionRemove
+}
+
+// addRemovedDomainHistory 域名扫描时，扫描目标中已保存的域名本次没有解析结果的，记录为删除
+func (config *Config) addRemovedDomainHistory(domainResults map[string]*DomainResult, blackDomain *custom.BlackTargetCheck) {
+	for _, target := range strings.Split(config.Target, ",") {
+		domainName := strings.TrimSpace(target)
+		if domainName == "" || utils.CheckIP(domainName) || utils.CheckIPSubnet(domainName) || blackDomain.CheckBlack(domainName) {
+			continue
+		}
+		if _, ok := domainResults[domainName]; ok {
+			continue
+		}
+		domain := &db.Domain{DomainName: domainName, WorkspaceId: config.WorkspaceId}
+		if !domain.GetByDomain() || config.isDomainRemoved(domainName) {
+			continue
+		}
+		config.addHistory(domainName, db.HistoryItemDomain, db.HistoryActionRemove, "", "", "")
+	}
+}
+
+// addDomainAttrHistory 域名属性为新的内容时记录变更：同一来源和属性已有记录的为修改，否则为新增；返回是否为新的内容
+func (config *Config) addDomainAttrHistory(domainName string, domainAttr db.DomainAttr) bool {
+	if exist := domainAttr; exist.GetByDomainAttr() {
+		return false
+	}
+	oldAttr := &db.DomainAttr{RelatedId: domainAttr.RelatedId, Source: domainAttr.Source, Tag: domainAttr.Tag}
+	if oldAttr.GetLatestBySourceAndTag() {
+		config.addHistory(domainName, db.HistoryItemDomainAttr, db.HistoryActionChange, domainAttr.Tag, oldAttr.Content, domainAttr.Content)
+	} else {
+		config.addHistory(domainName, db.HistoryItemDomainAttr, db.HistoryActionAdd, domainAttr.Tag, "", domainAttr.Content)
+	}
+	return true
+}
+
 // FilterDomainHasTooMuchIP 对域名结果中同一个IP对应太多进行过滤
 func FilterDomainHasTooMuchIP(result *Result) { //result map[string]*DomainResult) {
 	ip2DomainMap := make(map[string]map[string]struct{})
 	// 建立解析ip到domain的反向映射Map
 	for domain, domainResult := range result.DomainResult {
 		for _, attr := range domainResult.DomainAttrs {
-			if attr.Tag == "A" {
+			if attr.Tag == "A" || attr.Tag == "AAAA" {
 				ip := attr.Content
 				if _, ok := ip2DomainMap[ip]; !ok {
 					ip2DomainMap[ip] = make(map[string]struct{})
@@ -31,7 +31,7 @@ func (s *SubFinder) Do() {
 
 	for _, line := range strings.Split(s.Config.Target, ",") {
 		domain := strings.TrimSpace(line)
-		if domain == "" || utils.CheckIPV4(domain) || utils.CheckIPV4Subnet(domain) {
+		if domain == "" || utils.CheckIP(domain) || utils.CheckIPSubnet(domain) {
 			continue
 		}
 		if blackDomain.CheckBlack(domain) {
@@ -91,7 +91,7 @@ func (f *FingerprintHub) Do() {
 				if _, ok := blankPort[port]; ok {
 					continue
 				}
-				url := fmt.Sprintf("%s:%d", domain, port)
+				url := utils.FormatHostPort(domain, port)
 				swg.Add()
 				go func(d string, u string) {
 					defer swg.Done()
@@ -119,7 +119,7 @@ func (i *IconHash) Do() {
 				if _, ok := blankPort[port]; ok {
 					continue
 				}
-				url := fmt.Sprintf("%s:%d", domain, port)
+				url := utils.FormatHostPort(domain, port)
 				swg.Add()
 				go func(d string, u string) {
 					defer swg.Done()
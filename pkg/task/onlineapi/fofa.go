@@ -44,7 +44,7 @@ func (f *FOFA) GetQueryString(domain string, config OnlineAPIConfig, filterKeywo
 	if config.SearchByKeyWord {
 		query = config.Target
 	} else {
-		if utils.CheckIPV4(domain) || utils.CheckIPV4Subnet(domain) {
+		if utils.CheckIP(domain) || utils.CheckIPSubnet(domain) {
 			query = fmt.Sprintf("ip=\"%s\"", domain)
 		} else {
 			query = fmt.Sprintf("domain=\"%s\"", domain)
@@ -152,14 +152,14 @@ func (f *FOFA) ParseContentResult(content []byte) (ipResult portscan.Result, dom
 		title := strings.TrimSpace(row[4])
 		service := strings.TrimSpace(row[6])
 		//域名属性：
-		if len(domain) > 0 && utils.CheckIPV4(domain) == false {
+		if len(domain) > 0 && utils.CheckIP(domain) == false {
 			if domainResult.HasDomain(domain) == false {
 				domainResult.SetDomain(domain)
 			}
 			if len(ip) > 0 {
 				domainResult.SetDomainAttr(domain, domainscan.DomainAttrResult{
 					Source:  "fofa",
-					Tag:     "A",
+					Tag:     domainscan.GetResolveTag(ip),
 					Content: ip,
 				})
 			}
@@ -172,7 +172,7 @@ func (f *FOFA) ParseContentResult(content []byte) (ipResult portscan.Result, dom
 			}
 		}
 		//IP属性（由于不是主动扫描，忽略导入StatusCode）
-		if len(ip) == 0 || utils.CheckIPV4(ip) == false || portErr != nil {
+		if len(ip) == 0 || utils.CheckIP(ip) == false || portErr != nil {
 			continue
 		}
 		if ipResult.HasIP(ip) == false {
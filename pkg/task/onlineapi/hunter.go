@@ -65,7 +65,7 @@ func (h *Hunter) GetQueryString(domain string, config OnlineAPIConfig, filterKey
 	if config.SearchByKeyWord {
 		query = config.Target
 	} else {
-		if utils.CheckIPV4(domain) || utils.CheckIPV4Subnet(domain) {
+		if utils.CheckIP(domain) || utils.CheckIPSubnet(domain) {
 			query = fmt.Sprintf("ip=\"%s\"", domain)
 		} else {
 			query = fmt.Sprintf("domain=\"%s\"", domain)
@@ -172,14 +172,14 @@ func (h *Hunter) ParseContentResult(content []byte) (ipResult portscan.Result, d
 		banners := strings.Split(strings.TrimSpace(row[11]), ",")
 
 		//域名属性：
-		if len(domain) > 0 && utils.CheckIPV4(domain) == false {
+		if len(domain) > 0 && utils.CheckIP(domain) == false {
 			if domainResult.HasDomain(domain) == false {
 				domainResult.SetDomain(domain)
 			}
 			if len(ip) > 0 {
 				domainResult.SetDomainAttr(domain, domainscan.DomainAttrResult{
 					Source:  "hunter",
-					Tag:     "A",
+					Tag:     domainscan.GetResolveTag(ip),
 					Content: ip,
 				})
 			}
@@ -203,7 +203,7 @@ func (h *Hunter) ParseContentResult(content []byte) (ipResult portscan.Result, d
 			}
 		}
 		//IP属性（由于不是主动扫描，忽略导入StatusCode）
-		if len(ip) == 0 || utils.CheckIPV4(ip) == false || portErr != nil {
+		if len(ip) == 0 || utils.CheckIP(ip) == false || portErr != nil {
 			continue
 		}
 		if ipResult.HasIP(ip) == false {
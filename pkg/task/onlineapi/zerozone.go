@@ -51,7 +51,7 @@ func (z *ZeroZone) ParseContentResult(content []byte) (ipResult portscan.Result,
 		title := strings.TrimSpace(row[5])
 		service := strings.TrimSpace(row[7])
 		//域名属性：
-		if len(domain) > 0 && utils.CheckIPV4(domain) == false {
+		if len(domain) > 0 && utils.CheckIP(domain) == false {
 			if btc.CheckBlack(domain) {
 				logging.RuntimeLog.Warningf("%s is in blacklist,skip...", domain)
 				continue
@@ -62,7 +62,7 @@ func (z *ZeroZone) ParseContentResult(content []byte) (ipResult portscan.Result,
 			if len(ip) > 0 {
 				domainResult.SetDomainAttr(domain, domainscan.DomainAttrResult{
 					Source:  "0zone",
-					Tag:     "A",
+					Tag:     domainscan.GetResolveTag(ip),
 					Content: ip,
 				})
 			}
@@ -75,7 +75,7 @@ func (z *ZeroZone) ParseContentResult(content []byte) (ipResult portscan.Result,
 			}
 		}
 		//IP属性（由于不是主动扫描，忽略导入StatusCode）
-		if len(ip) == 0 || utils.CheckIPV4(ip) == false || portErr != nil {
+		if len(ip) == 0 || utils.CheckIP(ip) == false || portErr != nil {
 			continue
 		}
 		if btc.CheckBlack(ip) {
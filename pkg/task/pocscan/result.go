@@ -36,6 +36,7 @@ type Result struct {
 	Source      string `json:"source"`
 	Extra       string `json:"extra"`
 	WorkspaceId int    `json:"workspaceId"`
+	IsNew       bool   `json:"-"` //server保存结果时设置：新增的漏洞
 }
 
 type xrayJSONResult struct {
@@ -208,7 +209,7 @@ func (r *DomainscanVulResult) SetDomainVul(domain string, vul string) {
 func SaveResult(result []Result) string {
 	var resultCount int
 	var newVul int
-	for _, r := range result {
+	for i, r := range result {
 		target := utils.HostStrip(r.Target)
 		extra := r.Extra
 		if len(r.Extra) > 2000 {
@@ -224,6 +225,7 @@ func SaveResult(result []Result) string {
 		}
 		if ok, isNew := vul.SaveOrUpdate(); ok {
 			resultCount++
+			result[i].IsNew = isNew
 			if isNew {
 				newVul++
 			}
@@ -103,7 +103,7 @@ func (m *Masscan) parsResult(outputTempFile string) {
 		}
 		data := strings.Split(txt, " ")
 		if data[0] == "open" && data[1] == "tcp" {
-			ip := strings.TrimSpace(data[3])
+			ip := utils.FormatIPV6(strings.TrimSpace(data[3]))
 			portNumber, err := strconv.Atoi(data[2])
 			if err != nil {
 				logging.RuntimeLog.Error(err)
@@ -139,13 +139,7 @@ func (m *Masscan) ParseContentResult(content []byte) (result Result) {
 		if len(host.Ports) == 0 || len(host.Addresses) == 0 {
 			continue
 		}
-		var ip string
-		for _, addr := range host.Addresses {
-			if addr.AddrType == "ipv4" {
-				ip = addr.Addr
-				break
-			}
-		}
+		ip := getHostAddress(host.Addresses)
 		if ip == "" {
 			continue
 		}
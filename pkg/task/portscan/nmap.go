@@ -32,21 +32,38 @@ func NewNmap(config Config) *Nmap {
 // Do 执行nmap
 func (nmap *Nmap) Do() {
 	nmap.Result.IPResult = make(map[string]*IPResult)
-	inputTargetFile := utils.GetTempPathFileName()
-	resultTempFile := utils.GetTempPathFileName()
-	defer os.Remove(inputTargetFile)
-	defer os.Remove(resultTempFile)
 
 	btc := custom.NewBlackTargetCheck(custom.CheckIP)
-	var targets []string
+	var targets, targetsIPV6 []string
 	for _, target := range strings.Split(nmap.Config.Target, ",") {
 		t := strings.TrimSpace(target)
 		if btc.CheckBlack(t) {
 			logging.RuntimeLog.Warningf("%s is in blacklist,skip...", t)
 			continue
 		}
-		targets = append(targets, t)
+		// nmap不能在一次扫描中同时扫描IPv4与IPv6，需要分开执行
+		if checkIPV6Target(t) {
+			targetsIPV6 = append(targetsIPV6, expandIPV6Range(t)...)
+		} else {
+			targets = append(targets, t)
+		}
 	}
+	if len(targets) > 0 {
+		nmap.run(targets, false)
+	}
+	if len(targetsIPV6) > 0 {
+		nmap.run(targetsIPV6, true)
+	}
+	FilterIPHasTooMuchPort(&nmap.Result, false)
+}
+
+// run 调用nmap对同一地址族的目标进行扫描
+func (nmap *Nmap) run(targets []string, isIPV6 bool) {
+	inputTargetFile := utils.GetTempPathFileName()
+	resultTempFile := utils.GetTempPathFileName()
+	defer os.Remove(inputTargetFile)
+	defer os.Remove(resultTempFile)
+
 	err := os.WriteFile(inputTargetFile, []byte(strings.Join(targets, "\n")), 0666)
 	if err != nil {
 		logging.RuntimeLog.Error(err.Error())
@@ -59,6 +76,9 @@ func (nmap *Nmap) Do() {
 		nmap.Config.Tech, "-T4", "--open", "-n", "--randomize-hosts",
 		"--min-rate", strconv.Itoa(nmap.Config.Rate), "-oG", resultTempFile, "-iL", inputTargetFile,
 	)
+	if isIPV6 {
+		cmdArgs = append(cmdArgs, "-6")
+	}
 	if !nmap.Config.IsPing {
 		cmdArgs = append(cmdArgs, "-Pn")
 	}
@@ -68,8 +88,8 @@ func (nmap *Nmap) Do() {
 	} else {
 		cmdArgs = append(cmdArgs, "-p", nmap.Config.Port)
 	}
-	if nmap.Config.ExcludeTarget != "" {
-		cmdArgs = append(cmdArgs, "--exclude", nmap.Config.ExcludeTarget)
+	if excludeTarget := filterExcludeTarget(nmap.Config.ExcludeTarget, isIPV6); excludeTarget != "" {
+		cmdArgs = append(cmdArgs, "--exclude", excludeTarget)
 	}
 	cmd := exec.Command(nmap.Config.CmdBin, cmdArgs...)
 	var stderr bytes.Buffer
@@ -82,7 +102,43 @@ func (nmap *Nmap) Do() {
 		return
 	}
 	nmap.parseResult(resultTempFile)
-	FilterIPHasTooMuchPort(&nmap.Result, false)
+}
+
+// filterExcludeTarget 获取指定地址族的排除目标
+func filterExcludeTarget(excludeTarget string, isIPV6 bool) string {
+	var result []string
+	for _, target := range strings.Split(excludeTarget, ",") {
+		t := strings.TrimSpace(target)
+		if t == "" {
+			continue
+		}
+		if isIPV6 == checkIPV6Target(t) {
+			if isIPV6 {
+				result = append(result, expandIPV6Range(t)...)
+			} else {
+				result = append(result, t)
+			}
+		}
+	}
+	return strings.Join(result, ",")
+}
+
+// checkIPV6Target 是否为IPv6的目标（地址、子网及范围）
+func checkIPV6Target(target string) bool {
+	return utils.CheckIPV6(target) || utils.CheckIPV6Unexpanded(target)
+}
+
+// expandIPV6Range nmap不支持IPv6的范围格式（如2001:db8::1-2001:db8::ff），展开为IP地址；过大的范围不展开，忽略该目标
+func expandIPV6Range(target string) []string {
+	if !utils.CheckIPRange(target) {
+		return []string{target}
+	}
+	ips := utils.ParseIP(target)
+	if len(ips) == 1 && utils.CheckIPV6Unexpanded(ips[0]) {
+		logging.RuntimeLog.Warningf("ipv6 range %s is too large for nmap,skip...", target)
+		return nil
+	}
+	return ips
 }
 
 // parseResult 解析nmap结果
@@ -94,7 +150,6 @@ func (nmap *Nmap) parseResult(outputTempFile string) {
 	}
 
 	hostAndPortsReg := regexp.MustCompile("^Host:(.+)Ports:(.+)")
-	ipReg := regexp.MustCompile("\\d{1,3}\\.\\d{1,3}\\.\\d{1,3}\\.\\d{1,3}")
 
 	s := custom.Service{}
 	for _, line := range strings.Split(string(content), "\n") {
@@ -107,11 +162,12 @@ func (nmap *Nmap) parseResult(outputTempFile string) {
 		if len(hostAndPorts) < 1 || len(hostAndPorts[0]) != 3 {
 			continue
 		}
-		//ip
-		ip := ipReg.FindString(hostAndPorts[0][1])
-		if ip == "" {
+		//ip：Host: 192.168.1.1 ()  Host: 2001:db8::1 ()
+		hostFields := strings.Fields(hostAndPorts[0][1])
+		if len(hostFields) == 0 || !utils.CheckIP(hostFields[0]) {
 			continue
 		}
+		ip := utils.FormatIPV6(hostFields[0])
 		if !nmap.Result.HasIP(ip) {
 			nmap.Result.SetIP(ip)
 		}
@@ -146,6 +202,19 @@ func (nmap *Nmap) parseResult(outputTempFile string) {
 	}
 }
 
+// getHostAddress 获取主机的IP地址，优先使用IPv4地址
+func getHostAddress(addresses []gonmap.Address) (ip string) {
+	for _, addr := range addresses {
+		if addr.AddrType == "ipv4" {
+			return addr.Addr
+		}
+		if addr.AddrType == "ipv6" && ip == "" {
+			ip = utils.FormatIPV6(addr.Addr)
+		}
+	}
+	return
+}
+
 // ParseContentResult 解析nmap的XML文件
 func (nmap *Nmap) ParseContentResult(content []byte) (result Result) {
 	result.IPResult = make(map[string]*IPResult)
@@ -159,13 +228,7 @@ func (nmap *Nmap) ParseContentResult(content []byte) (result Result) {
 		if len(host.Ports) == 0 || len(host.Addresses) == 0 {
 			continue
 		}
-		var ip string
-		for _, addr := range host.Addresses {
-			if addr.AddrType == "ipv4" {
-				ip = addr.Addr
-				break
-			}
-		}
+		ip := getHostAddress(host.Addresses)
 		if ip == "" {
 			continue
 		}
@@ -44,3 +44,13 @@ func TestNmap_ParseXMLResult(t *testing.T) {
 		}
 	}
 }
+
+func TestFilterExcludeTarget(t *testing.T) {
+	excludeTarget := "192.168.1.1,2001:db8::1,2001:db8::10-2001:db8::11"
+	if result := filterExcludeTarget(excludeTarget, false); result != "192.168.1.1" {
+		t.Errorf("ipv4 exclude:%s", result)
+	}
+	if result := filterExcludeTarget(excludeTarget, true); result != "2001:db8::1,2001:db8::10,2001:db8::11" {
+		t.Errorf("ipv6 exclude:%s", result)
+	}
+}
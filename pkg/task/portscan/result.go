@@ -5,6 +5,7 @@ import (
 	"github.com/hanc00l/nemo_go/pkg/db"
 	"github.com/hanc00l/nemo_go/pkg/logging"
 	"github.com/hanc00l/nemo_go/pkg/task/custom"
+	"github.com/hanc00l/nemo_go/pkg/utils"
 	"strings"
 	"sync"
 )
@@ -31,6 +32,7 @@ type Config struct {
 	IsLoadOpenedPort bool   `json:"loadOpenedPort"`
 	IsPortscan       bool   `json:"isPortscan"`
 	WorkspaceId      int    `json:"workspaceId"`
+	MainTaskId       string `json:"-"`
 }
 
 // PortAttrResult 端口属性结果
@@ -39,6 +41,7 @@ type PortAttrResult struct {
 	Source    string
 	Tag       string
 	Content   string
+	IsNew     bool `json:"-"` //server保存结果时设置：新的属性内容
 }
 
 type HttpResult struct {
@@ -53,6 +56,7 @@ type PortResult struct {
 	Status    string
 	PortAttrs []PortAttrResult
 	HttpInfo  []HttpResult
+	IsNew     bool `json:"-"` //server保存结果时设置：新增或重新开放的端口
 }
 
 // IPResult IP结果
@@ -61,6 +65,7 @@ type IPResult struct {
 	Location string
 	Status   string
 	Ports    map[int]*PortResult
+	IsNew    bool `json:"-"` //server保存结果时设置：新增的IP
 }
 
 // Result 端口扫描结果
@@ -177,11 +182,17 @@ func (r *Result) SaveResult(config Config) string {
 			Status:      ipResult.Status,
 			WorkspaceId: config.WorkspaceId,
 		}
+		oldIp := &db.Ip{IpName: ipName, WorkspaceId: config.WorkspaceId}
+		oldIp.GetByIp()
 		if ok, isNew := ip.SaveOrUpdate(); !ok {
 			continue
 		} else {
+			ipResult.IsNew = isNew
 			if isNew {
 				newIP++
+				config.addHistory(ipName, db.HistoryItemIP, 0, db.HistoryActionAdd, "", "", "")
+			} else if ip.Status != "" && ip.Status != oldIp.Status {
+				config.addHistory(ipName, db.HistoryItemIP, 0, db.HistoryActionChange, "status", oldIp.Status, ip.Status)
 			}
 		}
 		resultIPCount++
@@ -192,16 +203,24 @@ func (r *Result) SaveResult(config Config) string {
 				PortNum: portNumber,
 				Status:  portResult.Status,
 			}
+			oldPort := &db.Port{IpId: ip.Id, PortNum: portNumber}
+			oldPort.GetByIPPort()
 			if ok, isNew := port.SaveOrUpdate(); !ok {
 				continue
 			} else {
 				if isNew {
 					newPort++
 				}
+				portResult.IsNew = isNew || config.isPortRemoved(ipName, portNumber)
+				if portResult.IsNew {
+					config.addHistory(ipName, db.HistoryItemPort, portNumber, db.HistoryActionAdd, "", "", port.Status)
+				} else if port.Status != "" && port.Status != oldPort.Status {
+					config.addHistory(ipName, db.HistoryItemPort, portNumber, db.HistoryActionChange, "status", oldPort.Status, port.Status)
+				}
 			}
 			resultPortCount++
 			//save port attribute
-			for _, portAttrResult := range portResult.PortAttrs {
+			for i, portAttrResult := range portResult.PortAttrs {
 				portAttr := &db.PortAttr{
 					RelatedId: port.Id,
 					Source:    portAttrResult.Source,
@@ -212,6 +231,7 @@ func (r *Result) SaveResult(config Config) string {
 				} else {
 					portAttr.Content = portAttrResult.Content
 				}
+				portResult.PortAttrs[i].IsNew = config.addPortAttrHistory(ipName, portNumber, *portAttr)
 				portAttr.SaveOrUpdate()
 			}
 			//save http info
@@ -229,6 +249,12 @@ func (r *Result) SaveResult(config Config) string {
 				httpInfo.SaveOrUpdate()
 			}
 		}
+		if config.IsPortscan {
+			config.addClosedPortHistory(ipName, ip.Id, ipResult.Ports)
+		}
+	}
+	if config.IsPortscan {
+		config.addClosedHostHistory(r.IPResult)
 	}
 	var sb strings.Builder
 	sb.WriteString(fmt.Sprintf("ip:%d", resultIPCount))
@@ -242,6 +268,90 @@ func (r *Result) SaveResult(config Config) string {
 	return sb.String()
 }
 
+// addHistory 记录一条IP资产的变更
+func (config *Config) addHistory(ipName, item string, port int, action, field, oldValue, newValue string) {
+	history := &db.AssetHistory{
+		WorkspaceId: config.WorkspaceId,
+		AssetType:   db.AssetTypeIP,
+		AssetName:   ipName,
+		Item:        item,
+		Port:        port,
+		Action:      action,
+		Field:       field,
+		OldValue:    oldValue,
+		NewValue:    newValue,
+		MainTaskId:  config.MainTaskId,
+	}
+	if !history.Add() {
+		logging.RuntimeLog.Errorf("save asset history fail:%s %s %d", ipName, item, port)
+	}
+}
+
+// isPortRemoved 端口最近的变更记录是否为已关闭
+func (config *Config) isPortRemoved(ipName string, port int) bool {
+	history := &db.AssetHistory{WorkspaceId: config.WorkspaceId, AssetType: db.AssetTypeIP, AssetName: ipName, Port: port}
+	return history.GetLatestByPort() && history.Action == db.HistoryActionRemove
+}
+
+// addPortAttrHistory 端口属性为新的内容时记录变更：同一来源和属性已有记录的为修改，否则为新增；返回是否为新的内容
+func (config *Config) addPortAttrHistory(ipName string, port int, portAttr db.PortAttr) bool {
+	if exist := portAttr; exist.GetByPortAttr() {
+		return false
+	}
+	oldAttr := &db.PortAttr{RelatedId: portAttr.RelatedId, Source: portAttr.Source, Tag: portAttr.Tag}
+	if oldAttr.GetLatestBySourceAndTag() {
+		config.addHistory(ipName, db.HistoryItemPortAttr, port, db.HistoryActionChange, portAttr.Tag, oldAttr.Content, portAttr.Content)
+	} else {
+		config.addHistory(ipName, db.HistoryItemPortAttr, port, db.HistoryActionAdd, portAttr.Tag, "", portAttr.Content)
+	}
+	return true
+}
+
+// addClosedPortHistory 端口扫描时，已保存的端口在扫描范围内但本次未发现开放的，记录为关闭
+func (config *Config) addClosedPortHistory(ipName string, ipId int, ports map[int]*PortResult) {
+	scanPorts := utils.ParsePort(config.Port)
+	port := &db.Port{IpId: ipId}
+	for _, p := range port.GetsByIPId() {
+		if _, ok := scanPorts[p.PortNum]; !ok {
+			continue
+		}
+		if _, ok := ports[p.PortNum]; ok {
+			continue
+		}
+		history := &db.AssetHistory{WorkspaceId: config.WorkspaceId, AssetType: db.AssetTypeIP, AssetName: ipName, Port: p.PortNum}
+		if history.GetLatestByPort() && history.Action == db.HistoryActionRemove {
+			continue
+		}
+		config.addHistory(ipName, db.HistoryItemPort, p.PortNum, db.HistoryActionRemove, "", p.Status, "")
+	}
+}
+
+// addClosedHostHistory 端口扫描时，扫描目标中已保存的IP本次没有任何结果的，其在扫描范围内的端口记录为关闭
+func (config *Config) addClosedHostHistory(ipResults map[string]*IPResult) {
+	var targets []string
+	for _, target := range strings.Split(config.Target, ",") {
+		if t := strings.TrimSpace(target); t != "" {
+			targets = append(targets, t)
+		}
+	}
+	if config.ExcludeTarget != "" {
+		targets = utils.ExcludeIPTarget(targets, strings.Split(config.ExcludeTarget, ","))
+	}
+	for _, target := range targets {
+		start, end, ok := utils.IPTargetToSortRange(target)
+		if !ok {
+			continue
+		}
+		ip := &db.Ip{WorkspaceId: config.WorkspaceId}
+		for _, savedIP := range ip.GetsBySortRange(start, end) {
+			if _, ok = ipResults[savedIP.IpName]; ok {
+				continue
+			}
+			config.addClosedPortHistory(savedIP.IpName, savedIP.Id, nil)
+		}
+	}
+}
+
 // FilterIPHasTooMuchPort 过滤有安全防护、显示太多端口开放的IP
 func FilterIPHasTooMuchPort(result *Result, isOnline bool) {
 	MaxNumber := IpOpenedPortFilterNumber
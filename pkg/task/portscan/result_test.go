@@ -0,0 +1,47 @@
+package portscan
+
+import (
+	"github.com/hanc00l/nemo_go/pkg/conf"
+	"github.com/hanc00l/nemo_go/pkg/db"
+	"os"
+	"testing"
+	"time"
+)
+
+func TestResult_SaveResultClosedHost(t *testing.T) {
+	if err := os.Chdir("../../.."); err != nil {
+		t.Fatal(err)
+	}
+	defer os.Chdir("pkg/task/portscan")
+	conf.EnableStandalone(db.SQLiteMemory)
+	if err := db.Migrate(); err != nil {
+		t.Fatal(err)
+	}
+	workspace := db.Workspace{WorkspaceName: "test", State: "enable"}
+	if !workspace.Add() {
+		t.Fatal("add workspace fail")
+	}
+	config := Config{Target: "192.168.1.0/30", Port: "80,443", IsPortscan: true, WorkspaceId: workspace.Id, MainTaskId: "task1"}
+	r := Result{IPResult: make(map[string]*IPResult)}
+	r.SetIP("192.168.1.1")
+	r.SetPort("192.168.1.1", 80)
+	r.SaveResult(config)
+
+	// 再次扫描时该IP没有任何结果，扫描范围内的端口记录为关闭
+	start := time.Now().Add(-time.Second)
+	config.MainTaskId = "task2"
+	r = Result{IPResult: make(map[string]*IPResult)}
+	r.SaveResult(config)
+	history := db.AssetHistory{WorkspaceId: workspace.Id, MainTaskId: "task2"}
+	results := history.GetsByMainTaskAndDatetime(start, time.Now().Add(time.Second))
+	if len(results) != 1 || results[0].AssetName != "192.168.1.1" || results[0].Port != 80 || results[0].Action != db.HistoryActionRemove {
+		t.Fatalf("closed host history:%v", results)
+	}
+	// 已记录为关闭的端口不重复记录，其它任务的变更不包括在内
+	config.MainTaskId = "task3"
+	r.SaveResult(config)
+	history.MainTaskId = "task3"
+	if results = history.GetsByMainTaskAndDatetime(start, time.Now().Add(time.Second)); len(results) != 0 {
+		t.Errorf("closed port recorded again:%v", results)
+	}
+}
@@ -0,0 +1,216 @@
+package portscan
+
+import (
+	"github.com/hanc00l/nemo_go/pkg/conf"
+	"github.com/hanc00l/nemo_go/pkg/logging"
+	"github.com/hanc00l/nemo_go/pkg/task/custom"
+	"github.com/hanc00l/nemo_go/pkg/utils"
+	"github.com/remeh/sizedwaitgroup"
+	"net"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+// TCPScanCmdBin 内置的TCP connect端口扫描，不需要root权限及masscan、nmap等第三方程序
+const TCPScanCmdBin = "tcpscan"
+
+const (
+	tcpScanConnectTimeout = 3 * time.Second
+	tcpScanBannerTimeout  = 3 * time.Second
+	tcpScanBannerMaxSize  = 256
+)
+
+var tcpScanThreadNumber = make(map[string]int)
+
+func init() {
+	tcpScanThreadNumber[conf.HighPerformance] = 400
+	tcpScanThreadNumber[conf.NormalPerformance] = 200
+}
+
+type TCPScan struct {
+	Config Config
+	Result Result
+}
+
+// tcpOpenedPort 扫描到的开放端口
+type tcpOpenedPort struct {
+	ip     string
+	port   int
+	banner string
+}
+
+// NewTCPScan 创建TCP connect端口扫描对象
+func NewTCPScan(config Config) *TCPScan {
+	config.CmdBin = TCPScanCmdBin
+	return &TCPScan{Config: config}
+}
+
+// Do 执行TCP connect端口扫描：Rate为每秒发起的连接数，Tech为-sV时获取端口的banner
+func (t *TCPScan) Do() {
+	t.Result.IPResult = make(map[string]*IPResult)
+
+	ips := t.parseTargetIP()
+	ports := t.parsePort()
+	if len(ips) == 0 || len(ports) == 0 {
+		return
+	}
+	isBanner := t.Config.Tech == "-sV"
+	var limiter <-chan time.Time
+	if t.Config.Rate > 0 {
+		interval := time.Second / time.Duration(t.Config.Rate)
+		if interval <= 0 {
+			interval = time.Nanosecond
+		}
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+		limiter = ticker.C
+	}
+	var openedPorts []tcpOpenedPort
+	var mutex sync.Mutex
+	swg := sizedwaitgroup.New(tcpScanThreadNumber[conf.WorkerPerformanceMode])
+	// 按端口依次扫描全部IP，避免短时间内对同一IP发起大量连接
+	for _, port := range ports {
+		for _, ip := range ips {
+			if limiter != nil {
+				<-limiter
+			}
+			swg.Add()
+			go func(ip string, port int) {
+				defer swg.Done()
+				opened, banner := tcpConnect(ip, port, isBanner)
+				if !opened {
+					return
+				}
+				mutex.Lock()
+				openedPorts = append(openedPorts, tcpOpenedPort{ip: ip, port: port, banner: banner})
+				mutex.Unlock()
+			}(ip, port)
+		}
+	}
+	swg.Wait()
+	t.parseResult(openedPorts)
+	FilterIPHasTooMuchPort(&t.Result, false)
+}
+
+// parseTargetIP 解析扫描的目标，去除排除的目标及黑名单中的IP；未展开的IPv6子网不进行扫描
+func (t *TCPScan) parseTargetIP() (ips []string) {
+	var targets []string
+	for _, target := range strings.Split(t.Config.Target, ",") {
+		if tt := strings.TrimSpace(target); tt != "" {
+			targets = append(targets, tt)
+		}
+	}
+	if t.Config.ExcludeTarget != "" {
+		targets = utils.ExcludeIPTarget(targets, strings.Split(t.Config.ExcludeTarget, ","))
+	}
+	btc := custom.NewBlackTargetCheck(custom.CheckIP)
+	ipMap := make(map[string]struct{})
+	for _, target := range targets {
+		if btc.CheckBlack(target) {
+			logging.RuntimeLog.Warningf("%s is in blacklist,skip...", target)
+			continue
+		}
+		targetIPs := utils.ParseIP(target)
+		if len(targetIPs) == 0 {
+			logging.RuntimeLog.Warningf("invalid target:%s,skip...", target)
+			continue
+		}
+		for _, ip := range targetIPs {
+			if utils.CheckIPV6Unexpanded(ip) {
+				logging.RuntimeLog.Warningf("ipv6 subnet or range %s is too large to scan,skip...", ip)
+				continue
+			}
+			if _, ok := ipMap[ip]; ok {
+				continue
+			}
+			if btc.CheckBlack(ip) {
+				logging.RuntimeLog.Warningf("%s is in blacklist,skip...", ip)
+				continue
+			}
+			ipMap[ip] = struct{}{}
+			ips = append(ips, ip)
+		}
+	}
+	return
+}
+
+// parsePort 解析扫描的端口（支持nmap格式的端口列表及--top-ports）
+func (t *TCPScan) parsePort() (ports []int) {
+	portStr := strings.TrimSpace(t.Config.Port)
+	if !strings.HasPrefix(portStr, "--top-ports") {
+		portStr = strings.ReplaceAll(portStr, " ", "")
+	}
+	for port := range utils.ParsePort(portStr) {
+		if port > 0 && port <= 65535 {
+			ports = append(ports, port)
+		}
+	}
+	sort.Ints(ports)
+	return
+}
+
+// parseResult 生成扫描结果
+func (t *TCPScan) parseResult(openedPorts []tcpOpenedPort) {
+	s := custom.NewService()
+	for _, op := range openedPorts {
+		if !t.Result.HasIP(op.ip) {
+			t.Result.SetIP(op.ip)
+		}
+		if !t.Result.HasPort(op.ip, op.port) {
+			t.Result.SetPort(op.ip, op.port)
+		}
+		t.Result.SetPortAttr(op.ip, op.port, PortAttrResult{
+			Source:  "portscan",
+			Tag:     "service",
+			Content: s.FindService(op.port, op.ip),
+		})
+		if op.banner != "" {
+			t.Result.SetPortAttr(op.ip, op.port, PortAttrResult{
+				Source:  "portscan",
+				Tag:     "banner",
+				Content: op.banner,
+			})
+		}
+	}
+}
+
+// tcpConnect 连接指定的端口，连接成功则端口开放；isBanner时读取服务端的banner，没有数据时发送HTTP请求后再读取
+func tcpConnect(ip string, port int, isBanner bool) (opened bool, banner string) {
+	conn, err := net.DialTimeout("tcp", utils.FormatHostPort(ip, port), tcpScanConnectTimeout)
+	if err != nil {
+		return
+	}
+	defer conn.Close()
+
+	opened = true
+	if !isBanner {
+		return
+	}
+	if banner = readBanner(conn); banner != "" {
+		return
+	}
+	conn.SetWriteDeadline(time.Now().Add(tcpScanBannerTimeout))
+	if _, err = conn.Write([]byte("GET / HTTP/1.0\r\n\r\n")); err != nil {
+		return
+	}
+	banner = readBanner(conn)
+	return
+}
+
+// readBanner 读取连接返回的数据，只保留可显示的字符
+func readBanner(conn net.Conn) string {
+	conn.SetReadDeadline(time.Now().Add(tcpScanBannerTimeout))
+	buf := make([]byte, tcpScanBannerMaxSize)
+	n, _ := conn.Read(buf)
+	var sb strings.Builder
+	for _, c := range buf[:n] {
+		if c >= 0x20 && c < 0x7f {
+			sb.WriteByte(c)
+		} else if c == '\r' || c == '\n' || c == '\t' {
+			sb.WriteByte(' ')
+		}
+	}
+	return strings.Join(strings.Fields(sb.String()), " ")
+}
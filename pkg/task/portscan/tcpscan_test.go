@@ -0,0 +1,54 @@
+package portscan
+
+import (
+	"fmt"
+	"net"
+	"testing"
+)
+
+func TestTCPScan_Do(t *testing.T) {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer listener.Close()
+	go func() {
+		for {
+			conn, err := listener.Accept()
+			if err != nil {
+				return
+			}
+			conn.Write([]byte("SSH-2.0-OpenSSH_8.9\r\n"))
+			conn.Close()
+		}
+	}()
+	port := listener.Addr().(*net.TCPAddr).Port
+
+	config := Config{
+		Target: "127.0.0.1",
+		Port:   fmt.Sprintf("1,%d", port),
+		Rate:   1000,
+		Tech:   "-sV",
+	}
+	ts := NewTCPScan(config)
+	ts.Do()
+	if !ts.Result.HasIP("127.0.0.1") || !ts.Result.HasPort("127.0.0.1", port) || ts.Result.HasPort("127.0.0.1", 1) {
+		t.Fatalf("invalid result:%v", ts.Result.IPResult)
+	}
+	var banner string
+	for _, attr := range ts.Result.IPResult["127.0.0.1"].Ports[port].PortAttrs {
+		if attr.Tag == "banner" {
+			banner = attr.Content
+		}
+	}
+	if banner != "SSH-2.0-OpenSSH_8.9" {
+		t.Errorf("banner:%s", banner)
+	}
+	// 排除的目标不扫描
+	config.ExcludeTarget = "127.0.0.0/24"
+	ts = NewTCPScan(config)
+	ts.Do()
+	if len(ts.Result.IPResult) > 0 {
+		t.Errorf("exclude target scanned:%v", ts.Result.IPResult)
+	}
+}
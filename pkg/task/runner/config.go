@@ -2,6 +2,7 @@ package runner
 
 type PortscanRequestParam struct {
 	Target             string `form:"target"`
+	TargetSetId        int    `form:"targetset_id"`
 	IsPortScan         bool   `form:"portscan"`
 	IsIPLocation       bool   `form:"iplocation"`
 	IsFofa             bool   `form:"fofasearch"`
@@ -19,6 +20,7 @@ type PortscanRequestParam struct {
 	IsFingerprintHub   bool   `form:"fingerprinthub"`
 	IsIconHash         bool   `form:"iconhash"`
 	TaskMode           int    `form:"taskmode"`
+	WorkerTag          string `form:"worker_tag"`
 	IsTaskCron         bool   `form:"taskcron" json:"-"`
 	TaskCronRule       string `form:"cronrule" json:"-"`
 	TaskCronComment    string `form:"croncomment" json:"-"`
@@ -29,6 +31,7 @@ type PortscanRequestParam struct {
 
 type DomainscanRequestParam struct {
 	Target             string `form:"target"`
+	TargetSetId        int    `form:"targetset_id"`
 	OrgId              int    `form:"org_id"`
 	IsSubfinder        bool   `form:"subfinder"`
 	IsSubdomainBrute   bool   `form:"subdomainbrute"`
@@ -47,6 +50,7 @@ type DomainscanRequestParam struct {
 	IsIconHash         bool   `form:"iconhash"`
 	TaskMode           int    `form:"taskmode"`
 	PortTaskMode       int    `form:"porttaskmode"`
+	WorkerTag          string `form:"worker_tag"`
 	IsTaskCron         bool   `form:"taskcron" json:"-"`
 	TaskCronRule       string `form:"cronrule" json:"-"`
 	TaskCronComment    string `form:"croncomment" json:"-"`
@@ -56,6 +60,7 @@ type DomainscanRequestParam struct {
 
 type PocscanRequestParam struct {
 	Target           string `form:"target"`
+	TargetSetId      int    `form:"targetset_id"`
 	IsXrayVerify     bool   `form:"xrayverify"`
 	XrayPocFile      string `form:"xray_poc_file"`
 	IsNucleiVerify   bool   `form:"nucleiverify"`
@@ -64,6 +69,7 @@ type PocscanRequestParam struct {
 	IsDirsearch      bool   `form:"dirsearch"`
 	DirsearchExtName string `form:"ext"`
 	IsLoadOpenedPort bool   `form:"load_opened_port"`
+	WorkerTag        string `form:"worker_tag"`
 	IsTaskCron       bool   `form:"taskcron" json:"-"`
 	TaskCronRule     string `form:"cronrule" json:"-"`
 	TaskCronComment  string `form:"croncomment" json:"-"`
@@ -72,6 +78,7 @@ type PocscanRequestParam struct {
 type XScanRequestParam struct {
 	XScanType       string `form:"xscan_type"`
 	Target          string `form:"target"`
+	TargetSetId     int    `form:"targetset_id"`
 	Port            string `form:"port"`
 	OrgId           int    `form:"org_id"`
 	OnlineAPIEngine string `form:"onlineapi_engine"`
@@ -84,6 +91,18 @@ type XScanRequestParam struct {
 	IsNucleiPocscan bool   `form:"nucleipoc"`
 	NucleiPocFile   string `form:"nucleipocfile"`
 	IsGobyPocscan   bool   `form:"gobypoc"`
+	WorkerTag       string `form:"worker_tag"`
+	IsTaskCron      bool   `form:"taskcron" json:"-"`
+	TaskCronRule    string `form:"cronrule" json:"-"`
+	TaskCronComment string `form:"croncomment" json:"-"`
+}
+
+type WorkflowRequestParam struct {
+	WorkflowId      int    `form:"workflow_id"`
+	Target          string `form:"target"`
+	TargetSetId     int    `form:"targetset_id"`
+	OrgId           int    `form:"org_id"`
+	WorkerTag       string `form:"worker_tag"`
 	IsTaskCron      bool   `form:"taskcron" json:"-"`
 	TaskCronRule    string `form:"cronrule" json:"-"`
 	TaskCronComment string `form:"croncomment" json:"-"`
@@ -128,8 +128,11 @@ func (j CronTaskJob) Run() {
 	updateMap["run_count"] = ct.RunCount + 1
 	ct.Update(updateMap)
 	// 消息通知
-	message := fmt.Sprintf("启动计划任务->%s:%s", ct.TaskName, ct.Comment)
-	go notify.Send(message)
+	event := notify.NewEvent(notify.EventTypeTask, fmt.Sprintf("启动计划任务->%s:%s", ct.TaskName, ct.Comment))
+	event.TaskId = taskId
+	event.TaskName = ct.TaskName
+	event.WorkspaceId = ct.WorkspaceId
+	go notify.Send(event)
 }
 
 // StartCronTask 启动定时任务守护和调度
@@ -6,6 +6,7 @@ import (
 	"fmt"
 	"github.com/google/uuid"
 	"github.com/hanc00l/nemo_go/pkg/comm"
+	"github.com/hanc00l/nemo_go/pkg/conf"
 	"github.com/hanc00l/nemo_go/pkg/db"
 	"github.com/hanc00l/nemo_go/pkg/logging"
 	"github.com/hanc00l/nemo_go/pkg/notify"
@@ -14,16 +15,29 @@ import (
 	"time"
 )
 
+// notifyNewAssetsLimit 任务通知中新增资产的最大数量
+const notifyNewAssetsLimit = 1000
+
+// reconcileInterval 检查全部已开始任务的间隔：主任务的进度及完成状态由子任务状态变化驱动更新，定期检查只用于兜底
+const reconcileInterval = 5 * time.Minute
+
 // StartMainTaskDamon MainTask任务的后台监控
 func StartMainTaskDamon() {
-	comm.MainTaskResult = make(map[string]comm.MainTaskResultMap)
+	comm.RunTaskStateHandler = UpdateMainTaskProgress
+
 	var err error
+	var lastReconcileTime time.Time
 	for {
 		// 处理已开始的任务
-		err = processStartedTask()
-		if err != nil {
-			logging.CLILog.Error(err)
-			logging.RuntimeLog.Error(err)
+		if time.Since(lastReconcileTime) >= reconcileInterval {
+			err = processStartedTask()
+			if err != nil {
+				logging.CLILog.Error(err)
+				logging.RuntimeLog.Error(err)
+			}
+			// 同时清理过期的worker补传调用记录
+			comm.PruneSpoolReplay()
+			lastReconcileTime = time.Now()
 		}
 		// 处理新建的任务
 		err = processCreatedTask()
@@ -46,6 +60,7 @@ func SaveMainTask(taskName, configJSON, cronTaskId string, workspaceId int) (tas
 		State:       ampq.CREATED,
 		CronTaskId:  cronTaskId,
 		WorkspaceId: workspaceId,
+		WorkerTag:   getWorkerTag(configJSON),
 	}
 	//kwargs可能因为target很多导致超过数据库中的字段设计长度，因此作一个长度截取
 	const argsLength = 6000
@@ -64,6 +79,17 @@ func SaveMainTask(taskName, configJSON, cronTaskId string, workspaceId int) (tas
 	return
 }
 
+// getWorkerTag 获取任务参数中指定的worker标签，由该主任务的全部子任务继承
+func getWorkerTag(configJSON string) string {
+	var req struct {
+		WorkerTag string
+	}
+	if err := json.Unmarshal([]byte(configJSON), &req); err != nil {
+		return ""
+	}
+	return req.WorkerTag
+}
+
 // runMainTask 运行一个创建的maintask
 func runMainTask(taskName, taskId, kwArgs string, workspaceId int) (err error) {
 	var taskRunId string
@@ -129,6 +155,16 @@ func runMainTask(taskName, taskId, kwArgs string, workspaceId int) (err error) {
 			logging.RuntimeLog.Error(err)
 			return
 		}
+	} else if taskName == "workflow" {
+		var req WorkflowRequestParam
+		if err = json.Unmarshal([]byte(kwArgs), &req); err != nil {
+			logging.RuntimeLog.Error(err)
+			return
+		}
+		if taskRunId, err = StartWorkflowTask(req, taskId, workspaceId); err != nil {
+			logging.RuntimeLog.Error(err)
+			return
+		}
 	} else {
 		logging.RuntimeLog.Errorf("invalid task name:%s in %s...", taskName, taskId)
 		return
@@ -146,13 +182,6 @@ func processCreatedTask() (err error) {
 	searchMap["state"] = ampq.CREATED
 	results, _ := task.Gets(searchMap, -1, -1)
 	for _, t := range results {
-		comm.MainTaskResultMutex.Lock()
-		comm.MainTaskResult[t.TaskId] = comm.MainTaskResultMap{
-			IPResult:     make(map[string]map[int]interface{}),
-			DomainResult: make(map[string]interface{}),
-			VulResult:    make(map[string]map[string]interface{}),
-		}
-		comm.MainTaskResultMutex.Unlock()
 		// 启动任务执行
 		if err = runMainTask(t.TaskName, t.TaskId, t.KwArgs, t.WorkspaceId); err != nil {
 			logging.RuntimeLog.Error(err)
@@ -168,64 +197,125 @@ func processCreatedTask() (err error) {
 	return
 }
 
-// processStartedTask 处理正在运行的maintask
+// processStartedTask 检查全部正在运行的maintask
 func processStartedTask() (err error) {
 	task := db.TaskMain{}
 	searchMap := make(map[string]interface{})
 	searchMap["state"] = ampq.STARTED
 	results, _ := task.Gets(searchMap, -1, -1)
-	var finishedTask []string
-	for _, t := range results {
-		// 如果数据库中的任务是STARTED状态，但缓存中有结果则重建（服务端重启有可能导致）
-		comm.MainTaskResultMutex.Lock()
-		if _, ok := comm.MainTaskResult[t.TaskId]; !ok {
-			comm.MainTaskResult[t.TaskId] = comm.MainTaskResultMap{
-				IPResult:     make(map[string]map[int]interface{}),
-				DomainResult: make(map[string]interface{}),
-				VulResult:    make(map[string]map[string]interface{}),
-			}
-		}
-		comm.MainTaskResultMutex.Unlock()
-		// 检查子任务runtask
-		createdTask, startedTask, totalTask := checkRunTask(t.TaskId)
-		updatedProgress := fmt.Sprintf("%d/%d/%d", startedTask, createdTask, totalTask)
-		// 任务已完成，需要更改任务状态和任务结果
-		var updatedState, updatedResult string
-		if totalTask > 0 && createdTask == 0 && startedTask == 0 {
-			updatedState = ampq.SUCCESS
-			updatedResult = checkMainTaskResult(t.TaskId)
-			finishedTask = append(finishedTask, t.TaskId)
+	// 暂停的任务只更新进度，不会完成
+	searchMap["state"] = ampq.PAUSED
+	pausedResults, _ := task.Gets(searchMap, -1, -1)
+	results = append(results, pausedResults...)
+	for i := range results {
+		if err = updateMainTaskProgress(&results[i]); err != nil {
+			return
 		}
-		// 如果进度相同则不需要更新
-		if updatedProgress == t.ProgressMessage {
-			updatedProgress = ""
+	}
+	return
+}
+
+// UpdateMainTaskProgress 子任务状态变化时，更新maintask的进度；全部子任务完成时将maintask置为完成
+func UpdateMainTaskProgress(mainTaskId string) {
+	task := db.TaskMain{TaskId: mainTaskId}
+	if !task.GetByTaskId() {
+		return
+	}
+	if task.State != ampq.STARTED && task.State != ampq.PAUSED {
+		return
+	}
+	if err := updateMainTaskProgress(&task); err != nil {
+		logging.RuntimeLog.Error(err)
+	}
+}
+
+// updateMainTaskProgress 根据子任务的状态更新maintask的进度及完成状态
+func updateMainTaskProgress(t *db.TaskMain) error {
+	createdTask, startedTask, totalTask := checkRunTask(t.TaskId)
+	updatedProgress := fmt.Sprintf("%d/%d/%d", startedTask, createdTask, totalTask)
+	// 任务已完成，需要更改任务状态和任务结果
+	if t.State == ampq.STARTED && totalTask > 0 && createdTask == 0 && startedTask == 0 {
+		updateMap := map[string]interface{}{
+			"state":            ampq.SUCCESS,
+			"succeeded":        time.Now(),
+			"progress_message": updatedProgress,
+			"result":           checkMainTaskResult(t.TaskId),
 		}
-		// 更新任务
-		if updatedState != "" || updatedProgress != "" || updatedResult != "" {
-			if updateMainTask(&t, updatedState, updatedProgress, updatedResult) == false {
-				msg := fmt.Sprintf("update maintask status fail:%s", t.TaskId)
-				logging.RuntimeLog.Error(msg)
-				return errors.New(msg)
+		// 多个子任务同时完成（或多个server）时，只有一个能更新成功并发送任务通知
+		if t.UpdateByState(ampq.STARTED, updateMap) {
+			if event := newTaskNotifyEvent(t.TaskId); event != nil {
+				go notify.Send(event)
 			}
 		}
+		return nil
 	}
-	// 发送任务通知，从map中移除已完成任务
-	comm.MainTaskResultMutex.Lock()
-	for _, taskId := range finishedTask {
-		message := formatNotifyMessage(taskId)
-		go notify.Send(message)
-		delete(comm.MainTaskResult, taskId)
+	// 如果进度相同则不需要更新
+	if updatedProgress == t.ProgressMessage {
+		return nil
 	}
-	comm.MainTaskResultMutex.Unlock()
-	return
+	if updateMainTask(t, "", updatedProgress, "") == false {
+		msg := fmt.Sprintf("update maintask status fail:%s", t.TaskId)
+		logging.RuntimeLog.Error(msg)
+		return errors.New(msg)
+	}
+	return nil
 }
 
-// formatNotifyMessage 返回发送通知消息内容
-func formatNotifyMessage(taskId string) (message string) {
+// newTaskNotifyEvent 生成任务完成的通知事件
+func newTaskNotifyEvent(taskId string) *notify.Event {
 	task := db.TaskMain{TaskId: taskId}
 	if !task.GetByTaskId() {
-		return
+		return nil
+	}
+	event := notify.NewEvent(notify.EventTypeTask, formatNotifyMessage(&task))
+	event.TaskId = task.TaskId
+	event.TaskName = task.TaskName
+	event.WorkspaceId = task.WorkspaceId
+	workspace := db.Workspace{Id: task.WorkspaceId}
+	if workspace.Get() {
+		event.Workspace = workspace.WorkspaceName
+	}
+	event.Target = ParseTargetFromKwArgs(task.TaskName, task.KwArgs)
+	if task.StartedTime != nil && task.SucceededTime != nil {
+		event.Runtime = task.SucceededTime.Sub(*task.StartedTime).Truncate(time.Second).String()
+	}
+	event.Progress = task.ProgressMessage
+	event.Result = task.Result
+	if webURL := strings.TrimSuffix(conf.GlobalServerConfig().Web.URL, "/"); webURL != "" {
+		event.URL = fmt.Sprintf("%s/task-info-main?task_id=%s", webURL, taskId)
 	}
+	taskResult := db.TaskMainResult{MainTaskId: taskId}
+	event.Counts = countMainTaskResult(taskId)
+	event.Vulnerabilities = taskResult.GetContents(db.TaskResultItemVulnerability)
+	history := db.AssetHistory{MainTaskId: taskId}
+	for _, h := range history.GetsNewByMainTask(notifyNewAssetsLimit) {
+		switch h.Item {
+		case db.HistoryItemIP:
+			event.NewIPs = append(event.NewIPs, h.AssetName)
+		case db.HistoryItemPort:
+			event.NewPorts = append(event.NewPorts, fmt.Sprintf("%s:%d", h.AssetName, h.Port))
+		case db.HistoryItemDomain:
+			event.NewDomains = append(event.NewDomains, h.AssetName)
+		}
+	}
+	return event
+}
+
+// countMainTaskResult 统计maintask的结果数量
+func countMainTaskResult(taskId string) map[string]int {
+	taskResult := db.TaskMainResult{MainTaskId: taskId}
+	counts := taskResult.GetCounts()
+	for _, item := range []string{db.TaskResultItemIP, db.TaskResultItemPort, db.TaskResultItemDomain, db.TaskResultItemVulnerability, db.TaskResultItemScreenshot,
+		db.TaskResultItemIPNew, db.TaskResultItemPortNew, db.TaskResultItemDomainNew, db.TaskResultItemVulnerabilityNew} {
+		if _, ok := counts[item]; !ok {
+			counts[item] = 0
+		}
+	}
+	return counts
+}
+
+// formatNotifyMessage 返回发送通知消息内容
+func formatNotifyMessage(task *db.TaskMain) (message string) {
 	var sb strings.Builder
 	sb.WriteString(task.TaskName)
 	sb.WriteString("->")
@@ -245,73 +335,38 @@ func formatNotifyMessage(taskId string) (message string) {
 
 // checkRunTask 根据maintaskId，获取runtask运行情况
 func checkRunTask(taskId string) (createdTask, startedTask, totalTask int) {
-	taskRun := db.TaskRun{}
-	searchMapRun := make(map[string]interface{})
-	searchMapRun["main_id"] = taskId
-	runTasks, _ := taskRun.Gets(searchMapRun, -1, -1)
-	for _, t := range runTasks {
-		if t.State == ampq.CREATED {
-			createdTask++
-		} else if t.State == ampq.STARTED {
-			startedTask++
+	taskRun := db.TaskRun{MainTaskId: taskId}
+	for state, count := range taskRun.CountStateByMainTaskId() {
+		if state == ampq.CREATED || state == ampq.PAUSED {
+			createdTask += count
+		} else if state == ampq.STARTED {
+			startedTask += count
 		}
+		totalTask += count
 	}
-	totalTask = len(runTasks)
 	return
 }
 
 // checkMainTaskResult 获取maintask的任务结果汇总
 func checkMainTaskResult(taskId string) (result string) {
-	comm.MainTaskResultMutex.Lock()
-	defer comm.MainTaskResultMutex.Unlock()
-
-	if _, ok := comm.MainTaskResult[taskId]; !ok {
-		return
-	}
+	counts := countMainTaskResult(taskId)
 	var resultAllString []string
-	taskObj := comm.MainTaskResult[taskId]
-	if len(taskObj.IPResult) > 0 {
-		if taskObj.IPNew > 0 {
-			resultAllString = append(resultAllString, fmt.Sprintf("ip:%d(+%d)", len(taskObj.IPResult), taskObj.IPNew))
-
-		} else {
-			resultAllString = append(resultAllString, fmt.Sprintf("ip:%d", len(taskObj.IPResult)))
-		}
-		var portNum int
-		for _, ports := range taskObj.IPResult {
-			portNum += len(ports)
+	for _, item := range []struct{ name, newName string }{
+		{db.TaskResultItemIP, db.TaskResultItemIPNew},
+		{db.TaskResultItemPort, db.TaskResultItemPortNew},
+		{db.TaskResultItemDomain, db.TaskResultItemDomainNew},
+		{db.TaskResultItemVulnerability, db.TaskResultItemVulnerabilityNew},
+		{db.TaskResultItemScreenshot, ""},
+	} {
+		if counts[item.name] <= 0 {
+			continue
 		}
-		if portNum > 0 {
-			if taskObj.PortNew > 0 {
-				resultAllString = append(resultAllString, fmt.Sprintf("port:%d(+%d)", portNum, taskObj.PortNew))
-			} else {
-				resultAllString = append(resultAllString, fmt.Sprintf("port:%d", portNum))
-			}
-		}
-	}
-	if len(taskObj.DomainResult) > 0 {
-		if taskObj.DomainNew > 0 {
-			resultAllString = append(resultAllString, fmt.Sprintf("domain:%d(+%d)", len(taskObj.DomainResult), taskObj.DomainNew))
+		if item.newName != "" && counts[item.newName] > 0 {
+			resultAllString = append(resultAllString, fmt.Sprintf("%s:%d(+%d)", item.name, counts[item.name], counts[item.newName]))
 		} else {
-			resultAllString = append(resultAllString, fmt.Sprintf("domain:%d", len(taskObj.DomainResult)))
-		}
-	}
-	if len(taskObj.VulResult) > 0 {
-		var vulNum int
-		for _, vul := range taskObj.VulResult {
-			vulNum += len(vul)
-		}
-		if vulNum > 0 {
-			if taskObj.VulnerabilityNew > 0 {
-				resultAllString = append(resultAllString, fmt.Sprintf("vulnerability:%d(+%d)", vulNum, taskObj.VulnerabilityNew))
-			} else {
-				resultAllString = append(resultAllString, fmt.Sprintf("vulnerability:%d", vulNum))
-			}
+			resultAllString = append(resultAllString, fmt.Sprintf("%s:%d", item.name, counts[item.name]))
 		}
 	}
-	if taskObj.ScreenShotResult > 0 {
-		resultAllString = append(resultAllString, fmt.Sprintf("screenshot:%d", taskObj.ScreenShotResult))
-	}
 	if len(resultAllString) > 0 {
 		result = strings.Join(resultAllString, ",")
 	}
@@ -0,0 +1,99 @@
+package runner
+
+import (
+	"github.com/google/uuid"
+	"github.com/hanc00l/nemo_go/pkg/conf"
+	"github.com/hanc00l/nemo_go/pkg/db"
+	"github.com/hanc00l/nemo_go/pkg/task/ampq"
+	"os"
+	"testing"
+	"time"
+)
+
+// addTestMainTask 使用单机模式（内存sqlite数据库）添加一个测试用的主任务及指定状态的子任务
+func addTestMainTask(t *testing.T, state string, runTaskStates ...string) *db.TaskMain {
+	workspace := db.Workspace{WorkspaceName: "test", State: "enable"}
+	if !workspace.Add() {
+		t.Fatal("add workspace fail")
+	}
+	dt := time.Now()
+	mainTask := &db.TaskMain{TaskId: uuid.New().String(), TaskName: "xportscan", State: state, StartedTime: &dt, WorkspaceId: workspace.Id}
+	if !mainTask.Add() {
+		t.Fatal("add maintask fail")
+	}
+	for _, runTaskState := range runTaskStates {
+		task := db.TaskRun{TaskId: uuid.New().String(), TaskName: "portscan", State: runTaskState, MainTaskId: mainTask.TaskId, WorkspaceId: workspace.Id}
+		if !task.Add() {
+			t.Fatal("add runtask fail")
+		}
+	}
+	return mainTask
+}
+
+func TestUpdateMainTaskProgress(t *testing.T) {
+	if err := os.Chdir("../../.."); err != nil {
+		t.Fatal(err)
+	}
+	defer os.Chdir("pkg/task/runner")
+	conf.EnableStandalone(db.SQLiteMemory)
+	if err := db.Migrate(); err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name          string
+		state         string
+		runTaskStates []string
+		newState      string
+		progress      string
+	}{
+		{"created", ampq.STARTED, []string{ampq.SUCCESS, ampq.CREATED, ampq.CREATED}, ampq.STARTED, "0/2/3"},
+		{"started", ampq.STARTED, []string{ampq.STARTED, ampq.FAILURE}, ampq.STARTED, "1/0/2"},
+		{"retry", ampq.STARTED, []string{ampq.SUCCESS, ampq.RETRY, ampq.CREATED}, ampq.STARTED, "0/1/3"},
+		{"paused runtask", ampq.STARTED, []string{ampq.SUCCESS, ampq.PAUSED}, ampq.STARTED, "0/1/2"},
+		{"finished", ampq.STARTED, []string{ampq.SUCCESS, ampq.RETRY, ampq.FAILURE, ampq.REVOKED}, ampq.SUCCESS, "0/0/4"},
+		{"paused", ampq.PAUSED, []string{ampq.SUCCESS, ampq.FAILURE}, ampq.PAUSED, "0/0/2"},
+		{"no runtask", ampq.STARTED, nil, ampq.STARTED, "0/0/0"},
+	}
+	for _, tt := range tests {
+		mainTask := addTestMainTask(t, tt.state, tt.runTaskStates...)
+		taskRun := db.TaskRun{MainTaskId: mainTask.TaskId}
+		total := 0
+		for _, count := range taskRun.CountStateByMainTaskId() {
+			total += count
+		}
+		if total != len(tt.runTaskStates) {
+			t.Errorf("%s: count state:%d", tt.name, total)
+		}
+		UpdateMainTaskProgress(mainTask.TaskId)
+		if !mainTask.GetByTaskId() || mainTask.State != tt.newState || mainTask.ProgressMessage != tt.progress {
+			t.Errorf("%s: maintask state:%s,progress:%s, want %s,%s", tt.name, mainTask.State, mainTask.ProgressMessage, tt.newState, tt.progress)
+		}
+		if tt.newState == ampq.SUCCESS && mainTask.SucceededTime == nil {
+			t.Errorf("%s: no succeeded time", tt.name)
+		}
+	}
+}
+
+func TestMainTaskUpdateByState(t *testing.T) {
+	if err := os.Chdir("../../.."); err != nil {
+		t.Fatal(err)
+	}
+	defer os.Chdir("pkg/task/runner")
+	conf.EnableStandalone(db.SQLiteMemory)
+	if err := db.Migrate(); err != nil {
+		t.Fatal(err)
+	}
+	mainTask := addTestMainTask(t, ampq.STARTED, ampq.SUCCESS)
+	taskRun := db.TaskRun{MainTaskId: mainTask.TaskId}
+	if counts := taskRun.CountStateByMainTaskId(); len(counts) != 1 || counts[ampq.SUCCESS] != 1 {
+		t.Errorf("count state:%v", counts)
+	}
+	// 多个子任务同时完成时，只有一个能将主任务置为完成
+	if !mainTask.UpdateByState(ampq.STARTED, map[string]interface{}{"state": ampq.SUCCESS}) {
+		t.Error("update started maintask fail")
+	}
+	if mainTask.UpdateByState(ampq.STARTED, map[string]interface{}{"state": ampq.SUCCESS}) {
+		t.Error("update finished maintask twice")
+	}
+}
@@ -13,6 +13,7 @@ import (
 	"github.com/hanc00l/nemo_go/pkg/task/portscan"
 	"github.com/hanc00l/nemo_go/pkg/task/serverapi"
 	"github.com/hanc00l/nemo_go/pkg/task/workerapi"
+	"github.com/hanc00l/nemo_go/pkg/task/workflow"
 	"github.com/hanc00l/nemo_go/pkg/utils"
 	"strings"
 	"time"
@@ -21,9 +22,15 @@ import (
 // StartPortScanTask 端口扫描任务
 func StartPortScanTask(req PortscanRequestParam, mainTaskId string, workspaceId int) (taskId string, err error) {
 	// 解析参数
+	target, excludeIP, err := getTaskTarget(req.Target, req.TargetSetId, workspaceId)
+	if err != nil {
+		logging.RuntimeLog.Error(err)
+		return "", err
+	}
 	ts := utils.NewTaskSlice()
 	ts.TaskMode = req.TaskMode
-	ts.IpTarget = formatIpTarget(req.Target, req.OrgId)
+	ts.IpTarget = formatIpTarget(target, req.OrgId)
+	ts.IpExclude = excludeIP
 	ts.Port = req.Port
 	tc := conf.GlobalServerConfig().Task
 	ts.IpSliceNumber = tc.IpSliceNumber
@@ -38,7 +45,7 @@ func StartPortScanTask(req PortscanRequestParam, mainTaskId string, workspaceId
 			}
 			// IP归属地：如果有端口执行任务，则IP归属地任务在端口扫描中执行，否则单独执行
 			// 如果IP地址是带掩码的子网（如192.168.1.0/24）则不进行归属地查询（在实际中容易出现误操作，导致整段IP地址无意义地进行归属地查询）
-			if !req.IsPortScan && req.IsIPLocation && utils.CheckIPV4Subnet(t) == false {
+			if !req.IsPortScan && req.IsIPLocation && utils.CheckIPSubnet(t) == false {
 				if taskId, err = doIPLocation(mainTaskId, t, &req.OrgId); err != nil {
 					logging.RuntimeLog.Error(err)
 					return
@@ -72,9 +79,15 @@ func StartPortScanTask(req PortscanRequestParam, mainTaskId string, workspaceId
 
 // StartBatchScanTask 探测+扫描任务
 func StartBatchScanTask(req PortscanRequestParam, mainTaskId string, workspaceId int) (taskId string, err error) {
+	target, excludeIP, err := getTaskTarget(req.Target, req.TargetSetId, workspaceId)
+	if err != nil {
+		logging.RuntimeLog.Error(err)
+		return "", err
+	}
 	ts := utils.NewTaskSlice()
 	ts.TaskMode = req.TaskMode
-	ts.IpTarget = formatIpTarget(req.Target, req.OrgId)
+	ts.IpTarget = formatIpTarget(target, req.OrgId)
+	ts.IpExclude = excludeIP
 	ts.Port = req.Port
 	tc := conf.GlobalServerConfig().Task
 	ts.IpSliceNumber = tc.IpSliceNumber
@@ -94,8 +107,13 @@ func StartBatchScanTask(req PortscanRequestParam, mainTaskId string, workspaceId
 
 // StartDomainScanTask 域名任务
 func StartDomainScanTask(req DomainscanRequestParam, mainTaskId string, workspaceId int) (taskId string, err error) {
+	target, _, err := getTaskTarget(req.Target, req.TargetSetId, workspaceId)
+	if err != nil {
+		logging.RuntimeLog.Error(err)
+		return "", err
+	}
 	ts := utils.NewTaskSlice()
-	domainTargetList := formatDomainTarget(req.Target)
+	domainTargetList := formatDomainTarget(target)
 	// 域名的FLD
 	if req.IsFldDomain {
 		ts.DomainTarget = getDomainFLD(domainTargetList)
@@ -180,8 +198,13 @@ func StartDomainScanTask(req DomainscanRequestParam, mainTaskId string, workspac
 
 // StartPocScanTask pocscan任务
 func StartPocScanTask(req PocscanRequestParam, mainTaskId string, workspaceId int) (taskId string, err error) {
+	target, _, err := getTaskTarget(req.Target, req.TargetSetId, workspaceId)
+	if err != nil {
+		logging.RuntimeLog.Error(err)
+		return "", err
+	}
 	var targetList []string
-	for _, t := range strings.Split(req.Target, "\n") {
+	for _, t := range strings.Split(target, "\n") {
 		if tt := strings.TrimSpace(t); tt != "" {
 			targetList = append(targetList, tt)
 		}
@@ -328,10 +351,15 @@ func StartXDomainScanTask(req XScanRequestParam, mainTaskId string, workspaceId
 	if *config.OrgId == 0 {
 		config.OrgId = nil
 	}
-	targetList := formatDomainTarget(req.Target)
+	taskTarget, _, err := getTaskTarget(req.Target, req.TargetSetId, workspaceId)
+	if err != nil {
+		logging.RuntimeLog.Error(err)
+		return "", err
+	}
+	targetList := formatDomainTarget(taskTarget)
 	for _, target := range targetList {
 		// 忽略IP
-		if utils.CheckIPV4(target) || utils.CheckIPV4Subnet(target) {
+		if utils.CheckIP(target) || utils.CheckIPSubnet(target) {
 			continue
 		}
 		// 子域名枚举、爆破、爬虫拆分成为多个任务并行执行
@@ -444,9 +472,15 @@ func StartXPortScanTask(req XScanRequestParam, mainTaskId string, workspaceId in
 	if *config.OrgId == 0 {
 		config.OrgId = nil
 	}
+	taskTarget, excludeIP, err := getTaskTarget(req.Target, req.TargetSetId, workspaceId)
+	if err != nil {
+		logging.RuntimeLog.Error(err)
+		return "", err
+	}
 	ts := utils.NewTaskSlice()
 	ts.TaskMode = utils.SliceByIP
-	ts.IpTarget = formatIpTarget(req.Target, req.OrgId)
+	ts.IpTarget = formatIpTarget(taskTarget, req.OrgId)
+	ts.IpExclude = excludeIP
 	ts.Port = req.Port
 	tc := conf.GlobalServerConfig().Task
 	ts.IpSliceNumber = tc.IpSliceNumber
@@ -596,8 +630,8 @@ func doBatchScan(workspaceId int, mainTaskId string, target string, port string,
 		CmdBin:           "masscan",
 		WorkspaceId:      workspaceId,
 	}
-	if req.CmdBin == "nmap" {
-		config.CmdBin = "nmap"
+	if req.CmdBin == "nmap" || req.CmdBin == portscan.TCPScanCmdBin {
+		config.CmdBin = req.CmdBin
 	}
 	if config.Port == "" {
 		config.Port = "80,443,8080|" + conf.GlobalWorkerConfig().Portscan.Port
@@ -763,7 +797,90 @@ func getDomainFLD(domainTargetList []string) (fldDomain []string) {
 	return
 }
 
-// formatIpTarget 将从web端传入的ip参数（以\n分隔）转换为ip列表，对域名进行解析转换为，并保存域名及A记录到数据库中
+// StartWorkflowTask 工作流任务：按工作流的定义开始执行起始步骤，后续步骤由worker在上游步骤完成后生成
+func StartWorkflowTask(req WorkflowRequestParam, mainTaskId string, workspaceId int) (taskId string, err error) {
+	wf := db.Workflow{Id: req.WorkflowId}
+	if !wf.Get() || wf.WorkspaceId != workspaceId {
+		return "", fmt.Errorf("workflow %d not exist", req.WorkflowId)
+	}
+	definition, err := workflow.Parse(wf.Definition)
+	if err != nil {
+		return "", err
+	}
+	config := workerapi.XScanConfig{
+		OrgId:       &req.OrgId,
+		WorkspaceId: workspaceId,
+		Workflow:    definition,
+	}
+	// config.OrgId 为int，默认为0
+	// db.Organization.OrgId为指针，默认nil
+	if *config.OrgId == 0 {
+		config.OrgId = nil
+	}
+	target, excludeIP, err := getTaskTarget(req.Target, req.TargetSetId, workspaceId)
+	if err != nil {
+		return "", err
+	}
+	// 端口扫描及在线资产平台的步骤需要IP目标（域名会被解析为IP）
+	var ipTargets []string
+	for _, step := range definition.Roots() {
+		if step.Task == workflow.TaskPortscan || step.Task == workflow.TaskOnlineAPI {
+			ts := utils.NewTaskSlice()
+			ts.TaskMode = utils.SliceByIP
+			ts.IpTarget = formatIpTarget(target, req.OrgId)
+			ts.IpExclude = excludeIP
+			ts.IpSliceNumber = conf.GlobalServerConfig().Task.IpSliceNumber
+			ipTargets, _ = ts.DoIpSlice()
+			break
+		}
+	}
+	domainTargets := formatDomainTarget(target)
+	for _, step := range definition.Roots() {
+		stepConfig := workerapi.NewWorkflowStepConfig(config, step)
+		switch step.Task {
+		case workflow.TaskPortscan:
+			port := workerapi.WorkflowPortscanPort(step)
+			for _, target := range ipTargets {
+				configRun := stepConfig
+				configRun.IPPortString = map[string]string{target: port}
+				if taskId, err = startWorkflowStep(workerapi.WorkflowTaskName(step), configRun, mainTaskId, step); err != nil {
+					return "", err
+				}
+			}
+		case workflow.TaskDomainscan:
+			for taskName, configDomain := range workerapi.WorkflowDomainscanTasks(stepConfig, step) {
+				for _, target := range domainTargets {
+					configRun := configDomain
+					configRun.Domain = map[string]struct{}{target: {}}
+					if taskId, err = startWorkflowStep(taskName, configRun, mainTaskId, step); err != nil {
+						return "", err
+					}
+				}
+			}
+		case workflow.TaskOnlineAPI:
+			for _, target := range append(ipTargets, domainTargets...) {
+				configRun := stepConfig
+				configRun.OnlineAPITarget = target
+				if taskId, err = startWorkflowStep(workerapi.WorkflowTaskName(step), configRun, mainTaskId, step); err != nil {
+					return "", err
+				}
+			}
+		}
+	}
+	return taskId, nil
+}
+
+// startWorkflowStep 开始执行工作流步骤的一个任务
+func startWorkflowStep(taskName string, config workerapi.XScanConfig, mainTaskId string, step workflow.Step) (taskId string, err error) {
+	configJSON, _ := json.Marshal(config)
+	taskId, err = serverapi.NewWorkflowRunTask(taskName, string(configJSON), mainTaskId, "", step.Id, step.Tag)
+	if err != nil {
+		logging.RuntimeLog.Errorf("start workflow step %s fail:%s", step.Id, err.Error())
+	}
+	return
+}
+
+// formatIpTarget 将从web端传入的ip参数（以\n分隔）转换为ip列表，对域名进行解析转换为，并保存域名及A、AAAA记录到数据库中
 func formatIpTarget(target string, orgId int) (ipTargetList []string) {
 	for _, t := range strings.Split(target, "\n") {
 		if tt := strings.TrimSpace(t); tt != "" {
@@ -772,9 +889,17 @@ func formatIpTarget(target string, orgId int) (ipTargetList []string) {
 				ipTargetList = append(ipTargetList, tt)
 				continue
 			}
-			//192.168.1.1-192.168.1.5
-			address := strings.Split(tt, "-")
-			if len(address) == 2 && utils.CheckIPV4(address[0]) && utils.CheckIPV4(address[1]) {
+			//2001:db8::1  2001:db8::/64
+			if utils.CheckIPV6(tt) {
+				ipTargetList = append(ipTargetList, utils.FormatIPV6(tt))
+				continue
+			}
+			if utils.CheckIPV6Subnet(tt) {
+				ipTargetList = append(ipTargetList, tt)
+				continue
+			}
+			//192.168.1.1-192.168.1.5  2001:db8::1-2001:db8::ff
+			if utils.CheckIPRange(tt) {
 				ipTargetList = append(ipTargetList, tt)
 				continue
 			}
@@ -787,7 +912,7 @@ func formatIpTarget(target string, orgId int) (ipTargetList []string) {
 					ipTargetList = append(ipTargetList, h)
 					domainResult.SetDomainAttr(tt, domainscan.DomainAttrResult{
 						Source:  "portscan",
-						Tag:     "A",
+						Tag:     domainscan.GetResolveTag(h),
 						Content: h,
 					})
 				}
@@ -809,13 +934,12 @@ func formatIpTarget(target string, orgId int) (ipTargetList []string) {
 func formatDomainTarget(target string) (domainTargetList []string) {
 	for _, t := range strings.Split(target, "\n") {
 		if tt := strings.TrimSpace(t); tt != "" {
-			//192.168.1.1  192.168.1.0/24
-			if utils.CheckIPV4(tt) || utils.CheckIPV4Subnet(tt) {
+			//192.168.1.1  192.168.1.0/24  2001:db8::1  2001:db8::/64
+			if utils.CheckIP(tt) || utils.CheckIPSubnet(tt) {
 				continue
 			}
-			//192.168.1.1-192.168.1.5
-			address := strings.Split(tt, "-")
-			if len(address) == 2 && utils.CheckIPV4(address[0]) && utils.CheckIPV4(address[1]) {
+			//192.168.1.1-192.168.1.5  2001:db8::1-2001:db8::ff
+			if utils.CheckIPRange(tt) {
 				continue
 			}
 			domainTargetList = append(domainTargetList, tt)
@@ -828,7 +952,8 @@ func formatDomainTarget(target string) (domainTargetList []string) {
 func ParseTargetFromKwArgs(taskName, args string) (target string) {
 	const displayedLength = 100
 	type TargetStrut struct {
-		Target string `json:"target"`
+		Target      string `json:"target"`
+		TargetSetId int
 	}
 	type FingerTargetStrut struct {
 		IPTargetMap     *map[string][]int    `json:"IPTargetMap"`
@@ -846,6 +971,7 @@ func ParseTargetFromKwArgs(taskName, args string) (target string) {
 		IPPortString     map[string]string   `json:"ipportstring"`
 		Domain           map[string]struct{} `json:"domain"`
 		Target           string              `json:"target"`
+		TargetSetId      int
 	}
 	if taskName == "fingerprint" {
 		var t FingerTargetStrut
@@ -898,6 +1024,9 @@ func ParseTargetFromKwArgs(taskName, args string) (target string) {
 			if len(t.Target) > 0 {
 				allTarget = append(allTarget, t.Target)
 			}
+			if t.TargetSetId > 0 {
+				allTarget = append(allTarget, getTargetSetDisplayName(t.TargetSetId))
+			}
 			if taskName == "xorgscan" || taskName == "xonlineapi_custom" {
 				orgDb := db.Organization{Id: *t.OrgId}
 				if orgDb.Get() {
@@ -913,6 +1042,12 @@ func ParseTargetFromKwArgs(taskName, args string) (target string) {
 			target = args
 		} else {
 			target = t.Target
+			if t.TargetSetId > 0 {
+				if target != "" {
+					target += ","
+				}
+				target += getTargetSetDisplayName(t.TargetSetId)
+			}
 		}
 	}
 	if len(target) > displayedLength {
@@ -0,0 +1,73 @@
+package runner
+
+import (
+	"fmt"
+	"github.com/hanc00l/nemo_go/pkg/db"
+	"github.com/hanc00l/nemo_go/pkg/utils"
+	"net/url"
+	"strings"
+)
+
+// getTaskTarget 合并任务的目标与目标集合中包含的条目，返回以\n分隔的目标：
+// 与排除条目相同的目标、排除的域名及其子域名直接去除；排除的IP（IP、CIDR及IP范围）由TaskSlice在切分时去除
+func getTaskTarget(target string, targetSetId, workspaceId int) (taskTarget string, excludeIP []string, err error) {
+	if targetSetId <= 0 {
+		return target, nil, nil
+	}
+	targetSet := db.TargetSet{Id: targetSetId}
+	if !targetSet.Get() || targetSet.WorkspaceId != workspaceId {
+		return "", nil, fmt.Errorf("targetset %d not exist", targetSetId)
+	}
+	var excludeDomain []string
+	excludes := make(map[string]struct{})
+	for _, e := range targetSet.GetEntries(true) {
+		excludes[e] = struct{}{}
+		if isIPTarget(e) {
+			excludeIP = append(excludeIP, e)
+		} else {
+			excludeDomain = append(excludeDomain, e)
+		}
+	}
+	targets := make(map[string]struct{})
+	var targetList []string
+	for _, t := range append(strings.Split(target, "\n"), targetSet.GetEntries(false)...) {
+		tt := strings.TrimSpace(t)
+		if tt == "" {
+			continue
+		}
+		if _, ok := targets[tt]; ok {
+			continue
+		}
+		targets[tt] = struct{}{}
+		if _, ok := excludes[tt]; ok {
+			continue
+		}
+		if !isIPTarget(tt) && utils.IsExcludedDomain(getTargetHost(tt), excludeDomain) {
+			continue
+		}
+		targetList = append(targetList, tt)
+	}
+	return strings.Join(targetList, "\n"), excludeIP, nil
+}
+
+// isIPTarget 是否为IP、CIDR或IP范围
+func isIPTarget(target string) bool {
+	return utils.CheckIP(target) || utils.CheckIPSubnet(target) || utils.CheckIPRange(target)
+}
+
+// getTargetHost 获取目标的主机名：漏洞扫描的目标可以是URL
+func getTargetHost(target string) string {
+	if u, err := url.Parse(target); err == nil && u.Hostname() != "" {
+		return u.Hostname()
+	}
+	return target
+}
+
+// getTargetSetDisplayName 任务目标中显示的目标集合名称
+func getTargetSetDisplayName(targetSetId int) string {
+	targetSet := db.TargetSet{Id: targetSetId}
+	if !targetSet.Get() {
+		return fmt.Sprintf("targetset:%d", targetSetId)
+	}
+	return fmt.Sprintf("targetset:%s(%d)", targetSet.TargetSetName, targetSet.IncludeCount)
+}
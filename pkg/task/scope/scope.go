@@ -0,0 +1,251 @@
+package scope
+
+import (
+	"errors"
+	"fmt"
+	"github.com/hanc00l/nemo_go/pkg/utils"
+	"gopkg.in/yaml.v2"
+	"math/big"
+	"net"
+	"net/url"
+	"strings"
+	"time"
+)
+
+// Scope 工作空间的授权范围：IP（IP、CIDR、IP范围）、域名后缀及允许执行主动扫描的时间窗口
+// 设置了IP或域名时，只有在范围内的目标才允许主动扫描；未设置时间窗口时不限制执行时间
+type Scope struct {
+	IP     []string     `yaml:"ip,omitempty"`
+	Domain []string     `yaml:"domain,omitempty"`
+	Window []TimeWindow `yaml:"window,omitempty"`
+
+	ipRanges []ipRange
+	domains  []string
+}
+
+// TimeWindow 允许执行主动扫描的时间窗口；Weekday为1-7（周一至周日），为空时每天有效；End小于Start时表示跨越零点，相等时为全天
+type TimeWindow struct {
+	Weekday []int  `yaml:"weekday,omitempty"`
+	Start   string `yaml:"start"`
+	End     string `yaml:"end"`
+
+	start int
+	end   int
+}
+
+// ipRange 起止地址统一为16字节表示的IP范围
+type ipRange struct {
+	start *big.Int
+	end   *big.Int
+}
+
+// Parse 解析yaml格式的授权范围；内容为空时返回nil，表示不限制
+func Parse(content string) (s *Scope, err error) {
+	if strings.TrimSpace(content) == "" {
+		return nil, nil
+	}
+	s = &Scope{}
+	if err = yaml.Unmarshal([]byte(content), s); err != nil {
+		return nil, err
+	}
+	for _, v := range s.IP {
+		v = strings.TrimSpace(v)
+		r, ok := parseIPRange(v)
+		if !ok {
+			return nil, fmt.Errorf("invalid ip:%s", v)
+		}
+		s.ipRanges = append(s.ipRanges, r)
+	}
+	for _, v := range s.Domain {
+		domain := strings.Trim(strings.ToLower(strings.TrimSpace(v)), ".")
+		domain = strings.TrimPrefix(domain, "*.")
+		if domain == "" {
+			return nil, errors.New("invalid domain:" + v)
+		}
+		s.domains = append(s.domains, domain)
+	}
+	for i := range s.Window {
+		w := &s.Window[i]
+		if w.start, err = parseClock(w.Start); err != nil {
+			return nil, err
+		}
+		if w.end, err = parseClock(w.End); err != nil {
+			return nil, err
+		}
+		for _, d := range w.Weekday {
+			if d < 1 || d > 7 {
+				return nil, fmt.Errorf("invalid weekday:%d", d)
+			}
+		}
+	}
+	return s, nil
+}
+
+// IsTargetLimited 是否限制主动扫描的目标
+func (s *Scope) IsTargetLimited() bool {
+	return s != nil && (len(s.ipRanges) > 0 || len(s.domains) > 0)
+}
+
+// ContainsIP IP（或CIDR、IP范围）是否在授权范围内；CIDR及IP范围需整个位于范围内
+func (s *Scope) ContainsIP(ip string) bool {
+	if !s.IsTargetLimited() {
+		return true
+	}
+	r, ok := parseIPRange(ip)
+	if !ok {
+		return false
+	}
+	for _, v := range s.ipRanges {
+		if v.start.Cmp(r.start) <= 0 && v.end.Cmp(r.end) >= 0 {
+			return true
+		}
+	}
+	return false
+}
+
+// ContainsDomain 域名是否为授权的域名或其子域名
+func (s *Scope) ContainsDomain(domain string) bool {
+	if !s.IsTargetLimited() {
+		return true
+	}
+	domain = strings.Trim(strings.ToLower(strings.TrimSpace(domain)), ".")
+	for _, v := range s.domains {
+		if domain == v || strings.HasSuffix(domain, "."+v) {
+			return true
+		}
+	}
+	return false
+}
+
+// ContainsHost 主机（IP或域名，可以是ip:port、url等格式）是否在授权范围内
+func (s *Scope) ContainsHost(host string) bool {
+	if !s.IsTargetLimited() {
+		return true
+	}
+	if utils.CheckIPSubnet(host) || utils.CheckIPRange(host) {
+		return s.ContainsIP(host)
+	}
+	host = getHost(host)
+	if utils.CheckIP(host) {
+		return s.ContainsIP(host)
+	}
+	return s.ContainsDomain(host)
+}
+
+// FilterTarget 去除授权范围外的目标：IP、CIDR及IP范围部分在范围内时展开后保留范围内的IP并重新进行cidr聚合，其它目标按主机判断
+func (s *Scope) FilterTarget(targetList []string) (results []string) {
+	if !s.IsTargetLimited() {
+		return targetList
+	}
+	var ipTargets []string
+	for _, target := range targetList {
+		target = strings.TrimSpace(target)
+		if target == "" {
+			continue
+		}
+		if utils.CheckIP(target) || utils.CheckIPSubnet(target) || utils.CheckIPRange(target) {
+			ipTargets = append(ipTargets, target)
+			continue
+		}
+		if s.ContainsHost(target) {
+			results = append(results, target)
+		}
+	}
+	if len(ipTargets) > 0 {
+		results = append(results, utils.FilterIPTarget(ipTargets, s.ContainsIP)...)
+	}
+	return
+}
+
+// InWindow 指定的时间是否在允许执行的时间窗口内
+func (s *Scope) InWindow(t time.Time) bool {
+	if s == nil || len(s.Window) == 0 {
+		return true
+	}
+	weekday := int(t.Weekday())
+	if weekday == 0 {
+		weekday = 7
+	}
+	yesterday := weekday - 1
+	if yesterday == 0 {
+		yesterday = 7
+	}
+	clock := t.Hour()*60 + t.Minute()
+	for _, w := range s.Window {
+		if w.start <= w.end {
+			if w.matchWeekday(weekday) && (w.start == w.end || (clock >= w.start && clock < w.end)) {
+				return true
+			}
+			continue
+		}
+		// 跨越零点：零点之后的部分属于前一天的时间窗口
+		if (w.matchWeekday(weekday) && clock >= w.start) || (w.matchWeekday(yesterday) && clock < w.end) {
+			return true
+		}
+	}
+	return false
+}
+
+// matchWeekday 是否为时间窗口有效的星期
+func (w *TimeWindow) matchWeekday(weekday int) bool {
+	if len(w.Weekday) == 0 {
+		return true
+	}
+	for _, d := range w.Weekday {
+		if d == weekday {
+			return true
+		}
+	}
+	return false
+}
+
+// parseClock 解析HH:MM格式的时间，返回从零点开始的分钟数
+func parseClock(clock string) (minutes int, err error) {
+	t, err := time.Parse("15:04", strings.TrimSpace(clock))
+	if err != nil {
+		return 0, fmt.Errorf("invalid time:%s", clock)
+	}
+	return t.Hour()*60 + t.Minute(), nil
+}
+
+// parseIPRange 将IP、CIDR或IP范围转换为起止地址
+func parseIPRange(ip string) (r ipRange, ok bool) {
+	if utils.CheckIP(ip) {
+		i := ipToBigInt(net.ParseIP(ip))
+		return ipRange{start: i, end: i}, true
+	}
+	if utils.CheckIPSubnet(ip) {
+		start, end, err := utils.IPSubnetToSortRange(ip)
+		if err != nil {
+			return
+		}
+		r.start, _ = new(big.Int).SetString(start, 16)
+		r.end, _ = new(big.Int).SetString(end, 16)
+		return r, r.start != nil && r.end != nil
+	}
+	if utils.CheckIPRange(ip) {
+		address := strings.Split(ip, "-")
+		r = ipRange{start: ipToBigInt(net.ParseIP(address[0])), end: ipToBigInt(net.ParseIP(address[1]))}
+		return r, r.start.Cmp(r.end) <= 0
+	}
+	return
+}
+
+// ipToBigInt 将IP转换为16字节表示的整数，IPv4使用IPv4-mapped格式
+func ipToBigInt(ip net.IP) *big.Int {
+	return new(big.Int).SetBytes(ip.To16())
+}
+
+// getHost 从ip:port、url等格式的目标中获取主机
+func getHost(target string) string {
+	target = strings.TrimSpace(target)
+	if strings.Contains(target, "://") {
+		if u, err := url.Parse(target); err == nil && u.Hostname() != "" {
+			return u.Hostname()
+		}
+	}
+	if host, _, err := net.SplitHostPort(target); err == nil {
+		return host
+	}
+	return strings.Split(target, "/")[0]
+}
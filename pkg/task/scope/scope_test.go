@@ -0,0 +1,105 @@
+package scope
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+const testScope = `
+ip:
+  - 192.168.1.0/24
+  - 10.0.0.1-10.0.0.10
+  - 2001:db8::/64
+domain:
+  - example.com
+window:
+  - weekday: [1, 2, 3, 4, 5]
+    start: "09:00"
+    end: "18:00"
+  - weekday: [6]
+    start: "22:00"
+    end: "02:00"
+`
+
+func TestContains(t *testing.T) {
+	s, err := Parse(testScope)
+	if err != nil {
+		t.Fatal(err)
+	}
+	hosts := map[string]bool{
+		"192.168.1.100":             true,
+		"192.168.2.1":               false,
+		"192.168.1.0/25":            true,
+		"192.168.1.0/23":            false,
+		"10.0.0.5":                  true,
+		"10.0.0.11":                 false,
+		"2001:db8::1":               true,
+		"2001:db9::1":               false,
+		"example.com":               true,
+		"www.Example.com":           true,
+		"badexample.com":            false,
+		"https://www.example.com/a": true,
+		"192.168.1.1:8080":          true,
+		"[2001:db8::1]:443":         true,
+		"www.test.com:80":           false,
+	}
+	for host, expected := range hosts {
+		if s.ContainsHost(host) != expected {
+			t.Errorf("%s:expected %v", host, expected)
+		}
+	}
+}
+
+func TestFilterTarget(t *testing.T) {
+	s, err := Parse(testScope)
+	if err != nil {
+		t.Fatal(err)
+	}
+	results := s.FilterTarget([]string{"192.168.1.0/24", "192.168.0.0/23", "10.0.0.8-10.0.0.12", "www.example.com", "www.test.com", "172.16.0.1"})
+	expected := "www.example.com,192.168.1.0/24,192.168.1.0/24,10.0.0.8/31,10.0.0.10"
+	if strings.Join(results, ",") != expected {
+		t.Errorf("filter target:%v", results)
+	}
+	// 未设置IP及域名时不限制目标
+	var empty *Scope
+	if len(empty.FilterTarget([]string{"172.16.0.1"})) != 1 || !empty.ContainsHost("www.test.com") {
+		t.Error("empty scope should not limit target")
+	}
+}
+
+func TestInWindow(t *testing.T) {
+	s, err := Parse(testScope)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// 2023-01-02为周一
+	times := map[string]bool{
+		"2023-01-02 10:00": true,
+		"2023-01-02 18:00": false,
+		"2023-01-01 10:00": false,
+		"2023-01-07 23:00": true,
+		"2023-01-08 01:30": true,
+		"2023-01-08 02:30": false,
+	}
+	for v, expected := range times {
+		tm, _ := time.Parse("2006-01-02 15:04", v)
+		if s.InWindow(tm) != expected {
+			t.Errorf("%s:expected %v", v, expected)
+		}
+	}
+}
+
+func TestParseInvalid(t *testing.T) {
+	contents := []string{
+		"ip:\n  - 192.168.1.256",
+		"domain:\n  - '.'",
+		"window:\n  - start: '25:00'\n    end: '18:00'",
+		"window:\n  - weekday: [0]\n    start: '09:00'\n    end: '18:00'",
+	}
+	for _, content := range contents {
+		if _, err := Parse(content); err == nil {
+			t.Errorf("invalid scope parsed:%s", content)
+		}
+	}
+}
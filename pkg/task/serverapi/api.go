@@ -13,48 +13,86 @@ import (
 
 // NewRunTask 创建一个新执行任务
 func NewRunTask(taskName, configJSON, mainTaskId, lastRunTaskId string) (taskId string, err error) {
-	dbMTask := db.TaskMain{TaskId: mainTaskId}
+	return newRunTask(&db.TaskRun{TaskName: taskName, KwArgs: configJSON, MainTaskId: mainTaskId, LastRunTaskId: lastRunTaskId}, 0)
+}
+
+// NewWorkflowRunTask 开始执行工作流中一个步骤的任务；workerTag为空时使用主任务的worker标签
+func NewWorkflowRunTask(taskName, configJSON, mainTaskId, lastRunTaskId, workflowStep, workerTag string) (taskId string, err error) {
+	return newRunTask(&db.TaskRun{TaskName: taskName, KwArgs: configJSON, MainTaskId: mainTaskId, LastRunTaskId: lastRunTaskId, WorkflowStep: workflowStep, WorkerTag: workerTag}, 0)
+}
+
+// runTaskArgsLength 子任务的参数保存到数据库中的最大长度，超过时被截断
+const runTaskArgsLength = 6000
+
+// newRunTask 发送任务到消息队列（延迟delay后执行），并记录到数据库中
+func newRunTask(task *db.TaskRun, delay time.Duration) (taskId string, err error) {
+	dbMTask := db.TaskMain{TaskId: task.MainTaskId}
 	if dbMTask.GetByTaskId() == false {
-		msg := fmt.Sprintf("maintask %s not exist", mainTaskId)
+		msg := fmt.Sprintf("maintask %s not exist", task.MainTaskId)
 		logging.RuntimeLog.Error(msg)
 		return "", errors.New(msg)
 	}
-	dbWorkspace := db.Workspace{Id: dbMTask.WorkspaceId}
+	// 未指定worker标签时，继承主任务的worker标签
+	if task.WorkerTag == "" {
+		task.WorkerTag = dbMTask.WorkerTag
+	}
+	task.WorkspaceId = dbMTask.WorkspaceId
+	topicName, err := getTaskTopic(task)
+	if err != nil {
+		return "", err
+	}
+	taskId = uuid.New().String()
+	// 主任务暂停时，子任务不发送到消息队列而由server保存，恢复后再发送；参数超长的无法完整保存，仍发送到消息队列由worker延迟执行
+	if dbMTask.State == ampq.PAUSED && len(task.KwArgs) <= runTaskArgsLength {
+		task.State = ampq.PAUSED
+	} else if err = sendTask(topicName, taskId, task, delay); err != nil {
+		return "", err
+	}
+	task.TaskId = taskId
+	addTask(task)
+
+	return taskId, nil
+}
+
+// getTaskTopic 获取子任务对应的消息队列
+func getTaskTopic(task *db.TaskRun) (topicName string, err error) {
+	dbWorkspace := db.Workspace{Id: task.WorkspaceId}
 	if dbWorkspace.Get() == false {
-		msg := fmt.Sprintf("maintask %s workspace %d not exist", mainTaskId, dbMTask.WorkspaceId)
+		msg := fmt.Sprintf("maintask %s workspace %d not exist", task.MainTaskId, task.WorkspaceId)
 		logging.RuntimeLog.Error(msg)
 		return "", errors.New(msg)
 	}
-	topicName := ampq.GetTopicByTaskName(taskName, dbWorkspace.WorkspaceGUID)
+	topicName = ampq.GetTopicByTaskName(task.TaskName, dbWorkspace.WorkspaceGUID, task.WorkerTag)
 	if topicName == "" {
-		msg := fmt.Sprintf("task not defined for topic:%s", taskName)
+		msg := fmt.Sprintf("task not defined for topic:%s", task.TaskName)
 		logging.RuntimeLog.Error(msg)
 		return "", errors.New(msg)
 	}
+	return topicName, nil
+}
+
+// sendTask 将子任务发送到消息队列，延迟delay后执行
+func sendTask(topicName, taskId string, task *db.TaskRun, delay time.Duration) error {
 	server := ampq.GetServerTaskAMPQServer(topicName)
 	// 延迟5秒后执行：如果不延迟，有可能任务在完成数据库之前执行，从而导致task not exist错误
-	eta := time.Now().Add(time.Second * 5)
-	taskId = uuid.New().String()
+	eta := time.Now().Add(time.Second*5 + delay)
 	workerTask := tasks.Signature{
-		Name: taskName,
+		Name: task.TaskName,
 		UUID: taskId,
 		ETA:  &eta,
 		Args: []tasks.Arg{
 			{Name: "taskId", Type: "string", Value: taskId},
-			{Name: "mainTaskId", Type: "string", Value: mainTaskId},
-			{Name: "configJSON", Type: "string", Value: configJSON},
+			{Name: "mainTaskId", Type: "string", Value: task.MainTaskId},
+			{Name: "configJSON", Type: "string", Value: task.KwArgs},
 		},
 		//RoutingKey：分发到不同功能的worker队列
 		RoutingKey: ampq.GetRoutingKeyByTopic(topicName),
 	}
-	_, err = server.SendTask(&workerTask)
-	if err != nil {
+	if _, err := server.SendTask(&workerTask); err != nil {
 		logging.RuntimeLog.Error(err)
-		return "", err
+		return err
 	}
-	addTask(taskId, taskName, configJSON, mainTaskId, lastRunTaskId, dbWorkspace.Id)
-
-	return taskId, nil
+	return nil
 }
 
 // RevokeUnexcusedTask 取消一个未开始执行的任务
@@ -74,22 +112,19 @@ func RevokeUnexcusedTask(taskId string) (isRevoked bool, err error) {
 }
 
 // addTask 将任务写入到数据库中
-func addTask(taskId, taskName, kwArgs, mainTaskId, lastRunTaskId string, workspaceId int) {
+func addTask(task *db.TaskRun) {
 	dt := time.Now()
-	task := &db.TaskRun{
-		TaskId:        taskId,
-		TaskName:      taskName,
-		KwArgs:        kwArgs,
-		State:         ampq.CREATED,
-		ReceivedTime:  &dt,
-		MainTaskId:    mainTaskId,
-		LastRunTaskId: lastRunTaskId,
-		WorkspaceId:   workspaceId,
+	if task.State == "" {
+		task.State = ampq.CREATED
+	}
+	task.ReceivedTime = &dt
+	if task.Attempt <= 0 {
+		task.Attempt = 1
 	}
+	taskId, taskName, kwArgs := task.TaskId, task.TaskName, task.KwArgs
 	//kwargs可能因为target很多导致超过数据库中的字段设计长度，因此作一个长度截取
-	const argsLength = 6000
-	if len(kwArgs) > argsLength {
-		task.KwArgs = fmt.Sprintf("%s...", kwArgs[:argsLength])
+	if len(kwArgs) > runTaskArgsLength {
+		task.KwArgs = fmt.Sprintf("%s...", kwArgs[:runTaskArgsLength])
 		logging.RuntimeLog.Warningf("task:%s args too long:%d", taskId, len(kwArgs))
 	}
 	if !task.Add() {
@@ -1 +1,31 @@
 package serverapi
+
+import (
+	"github.com/google/uuid"
+	"github.com/hanc00l/nemo_go/pkg/conf"
+	"github.com/hanc00l/nemo_go/pkg/db"
+	"github.com/hanc00l/nemo_go/pkg/task/ampq"
+	"os"
+	"testing"
+)
+
+// useStandalone 使用单机模式（内存sqlite数据库与进程内的消息队列）进行测试，返回测试用的主任务
+func useStandalone(t *testing.T) *db.TaskMain {
+	if err := os.Chdir("../../.."); err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { _ = os.Chdir("pkg/task/serverapi") })
+	conf.EnableStandalone(db.SQLiteMemory)
+	if err := db.Migrate(); err != nil {
+		t.Fatal(err)
+	}
+	workspace := db.Workspace{WorkspaceName: "test", State: "enable"}
+	if !workspace.Add() {
+		t.Fatal("add workspace fail")
+	}
+	mainTask := &db.TaskMain{TaskId: uuid.New().String(), TaskName: "xportscan", State: ampq.STARTED, WorkspaceId: workspace.Id}
+	if !mainTask.Add() {
+		t.Fatal("add maintask fail")
+	}
+	return mainTask
+}
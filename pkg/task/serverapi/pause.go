@@ -0,0 +1,95 @@
+package serverapi
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"github.com/google/uuid"
+	"github.com/hanc00l/nemo_go/pkg/db"
+	"github.com/hanc00l/nemo_go/pkg/logging"
+	"github.com/hanc00l/nemo_go/pkg/task/ampq"
+	"time"
+)
+
+// PauseMainTask 暂停一个执行中的主任务：未开始执行的子任务由server保存，不再由worker执行，恢复后重新发送到消息队列；
+// force为false时执行中的子任务继续执行直至完成，为true时不再等待执行中的子任务（worker上无法中断），恢复后重新执行；返回暂停的子任务数量
+func PauseMainTask(mainTaskId string, force bool) (count int, err error) {
+	mainTask := db.TaskMain{TaskId: mainTaskId}
+	if !mainTask.GetByTaskId() {
+		return 0, errors.New("maintask not exists")
+	}
+	if mainTask.State != ampq.STARTED {
+		return 0, errors.New("只能暂停执行中的任务")
+	}
+	if !mainTask.UpdateByState(ampq.STARTED, map[string]interface{}{"state": ampq.PAUSED}) {
+		logging.RuntimeLog.Errorf("update maintask:%s,state:%s fail !", mainTaskId, ampq.PAUSED)
+		return 0, errors.New("update maintask fail")
+	}
+	states := []string{ampq.CREATED}
+	if force {
+		states = append(states, ampq.STARTED)
+	}
+	taskRun := db.TaskRun{}
+	for _, state := range states {
+		results, _ := taskRun.Gets(map[string]interface{}{"main_id": mainTaskId, "state": state}, -1, -1)
+		for i := range results {
+			// 参数被截断的子任务无法重新发送，仍保留在消息队列中，由worker延迟到恢复后执行
+			if !json.Valid([]byte(results[i].KwArgs)) {
+				continue
+			}
+			if results[i].UpdateByState(state, map[string]interface{}{"state": ampq.PAUSED}) {
+				count++
+			}
+		}
+	}
+	logging.RuntimeLog.Infof("maintask paused:%s,force:%v,runtask:%d", mainTaskId, force, count)
+	return count, nil
+}
+
+// ResumeMainTask 恢复一个暂停的主任务，将server保存的暂停子任务重新发送到消息队列；返回恢复的子任务数量
+func ResumeMainTask(mainTaskId string) (count int, err error) {
+	mainTask := db.TaskMain{TaskId: mainTaskId}
+	if !mainTask.GetByTaskId() {
+		return 0, errors.New("maintask not exists")
+	}
+	if mainTask.State != ampq.PAUSED {
+		return 0, errors.New("只能恢复暂停的任务")
+	}
+	if !mainTask.UpdateByState(ampq.PAUSED, map[string]interface{}{"state": ampq.STARTED}) {
+		logging.RuntimeLog.Errorf("update maintask:%s,state:%s fail !", mainTaskId, ampq.STARTED)
+		return 0, errors.New("update maintask fail")
+	}
+	taskRun := db.TaskRun{}
+	results, _ := taskRun.Gets(map[string]interface{}{"main_id": mainTaskId, "state": ampq.PAUSED}, -1, -1)
+	for i := range results {
+		if resumeRunTask(&results[i]) == nil {
+			count++
+		}
+	}
+	logging.RuntimeLog.Infof("maintask resumed:%s,runtask:%d", mainTaskId, count)
+	return count, nil
+}
+
+// resumeRunTask 使用新的任务id将暂停的子任务发送到消息队列：暂停前已发送的消息（或强制暂停时仍在执行的任务）因任务id不存在而被忽略
+func resumeRunTask(task *db.TaskRun) error {
+	if !json.Valid([]byte(task.KwArgs)) {
+		msg := fmt.Sprintf("task:%s args truncated, can not resume", task.TaskId)
+		logging.RuntimeLog.Warning(msg)
+		dt := time.Now()
+		task.UpdateByState(ampq.PAUSED, map[string]interface{}{"state": ampq.FAILURE, "result": msg, "failed": &dt})
+		return errors.New(msg)
+	}
+	topicName, err := getTaskTopic(task)
+	if err != nil {
+		return err
+	}
+	taskId := uuid.New().String()
+	if err = sendTask(topicName, taskId, task, 0); err != nil {
+		return err
+	}
+	if !task.UpdateByState(ampq.PAUSED, map[string]interface{}{"task_id": taskId, "state": ampq.CREATED, "worker": "", "started": nil}) {
+		logging.RuntimeLog.Errorf("update task:%s,state:%s fail !", task.TaskId, ampq.CREATED)
+		return errors.New("update task fail")
+	}
+	return nil
+}
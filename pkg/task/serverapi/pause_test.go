@@ -0,0 +1,98 @@
+package serverapi
+
+import (
+	"github.com/google/uuid"
+	"github.com/hanc00l/nemo_go/pkg/db"
+	"github.com/hanc00l/nemo_go/pkg/task/ampq"
+	"testing"
+)
+
+// addTestRunTask 直接在数据库中添加一个指定状态的子任务
+func addTestRunTask(t *testing.T, mainTask *db.TaskMain, state, kwArgs string) *db.TaskRun {
+	task := &db.TaskRun{TaskId: uuid.New().String(), TaskName: "portscan", KwArgs: kwArgs, State: state, MainTaskId: mainTask.TaskId, WorkspaceId: mainTask.WorkspaceId}
+	if !task.Add() {
+		t.Fatal("add runtask fail")
+	}
+	return task
+}
+
+// checkRunTaskState 检查子任务的状态，返回子任务当前的记录
+func checkRunTaskState(t *testing.T, task *db.TaskRun, state string) db.TaskRun {
+	current := db.TaskRun{Id: task.Id}
+	if !current.Get() {
+		t.Fatalf("runtask:%d not exists", task.Id)
+	}
+	if current.State != state {
+		t.Errorf("runtask:%d state:%s, want %s", task.Id, current.State, state)
+	}
+	return current
+}
+
+func TestPauseResumeMainTask(t *testing.T) {
+	mainTask := useStandalone(t)
+	created := addTestRunTask(t, mainTask, ampq.CREATED, `{"target":"192.168.1.1"}`)
+	started := addTestRunTask(t, mainTask, ampq.STARTED, `{"target":"192.168.1.2"}`)
+	truncated := addTestRunTask(t, mainTask, ampq.CREATED, `{"target":"192.168.1.3...`)
+
+	if count, err := PauseMainTask(mainTask.TaskId, false); err != nil || count != 1 {
+		t.Fatalf("pause:%d,%v", count, err)
+	}
+	checkRunTaskState(t, created, ampq.PAUSED)
+	checkRunTaskState(t, started, ampq.STARTED)
+	checkRunTaskState(t, truncated, ampq.CREATED)
+	if _, err := PauseMainTask(mainTask.TaskId, false); err == nil {
+		t.Error("pause a paused maintask")
+	}
+	// 暂停期间新建的子任务由server保存，不发送到消息队列
+	taskId, err := NewRunTask("portscan", `{"target":"192.168.1.4"}`, mainTask.TaskId, "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	held := db.TaskRun{TaskId: taskId}
+	if !held.GetByTaskId() || held.State != ampq.PAUSED {
+		t.Errorf("new runtask when paused:%s", held.State)
+	}
+
+	if count, err := ResumeMainTask(mainTask.TaskId); err != nil || count != 2 {
+		t.Fatalf("resume:%d,%v", count, err)
+	}
+	// 恢复后使用新的任务id重新发送，暂停前的任务id不再存在
+	for _, task := range []*db.TaskRun{created, &held} {
+		current := checkRunTaskState(t, task, ampq.CREATED)
+		if current.TaskId == task.TaskId {
+			t.Errorf("runtask:%d resumed with the same task id", task.Id)
+		}
+		old := db.TaskRun{TaskId: task.TaskId}
+		if old.GetByTaskId() {
+			t.Errorf("runtask:%d old task id still exists", task.Id)
+		}
+	}
+	checkRunTaskState(t, started, ampq.STARTED)
+	checkRunTaskState(t, truncated, ampq.CREATED)
+	if _, err := ResumeMainTask(mainTask.TaskId); err == nil {
+		t.Error("resume a started maintask")
+	}
+}
+
+func TestPauseMainTaskForce(t *testing.T) {
+	mainTask := useStandalone(t)
+	created := addTestRunTask(t, mainTask, ampq.CREATED, `{"target":"192.168.1.1"}`)
+	started := addTestRunTask(t, mainTask, ampq.STARTED, `{"target":"192.168.1.2"}`)
+
+	if count, err := PauseMainTask(mainTask.TaskId, true); err != nil || count != 2 {
+		t.Fatalf("pause:%d,%v", count, err)
+	}
+	checkRunTaskState(t, created, ampq.PAUSED)
+	checkRunTaskState(t, started, ampq.PAUSED)
+	// 暂停后参数被截断的子任务无法重新发送
+	truncated := addTestRunTask(t, mainTask, ampq.PAUSED, `{"target":"192.168.1.3...`)
+
+	if count, err := ResumeMainTask(mainTask.TaskId); err != nil || count != 2 {
+		t.Fatalf("resume:%d,%v", count, err)
+	}
+	checkRunTaskState(t, created, ampq.CREATED)
+	if current := checkRunTaskState(t, started, ampq.CREATED); current.Worker != "" || current.StartedTime != nil {
+		t.Errorf("resumed runtask keeps the old worker:%s", current.Worker)
+	}
+	checkRunTaskState(t, truncated, ampq.FAILURE)
+}
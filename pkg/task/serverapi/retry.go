@@ -0,0 +1,90 @@
+package serverapi
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"github.com/hanc00l/nemo_go/pkg/conf"
+	"github.com/hanc00l/nemo_go/pkg/db"
+	"github.com/hanc00l/nemo_go/pkg/logging"
+	"github.com/hanc00l/nemo_go/pkg/task/ampq"
+	"time"
+)
+
+// RetryFailedTask 按任务的重试策略，将执行失败的任务重新发送到消息队列；返回新任务的id，不满足重试条件时返回空
+func RetryFailedTask(task *db.TaskRun) (taskId string) {
+	policy, ok := conf.GlobalServerConfig().Task.GetRetryPolicy(task.TaskName)
+	if !ok {
+		return ""
+	}
+	attempt := task.Attempt
+	if attempt <= 0 {
+		attempt = 1
+	}
+	if attempt >= policy.MaxAttempts {
+		return ""
+	}
+	taskId, err := retryRunTask(task, policy.Delay(attempt))
+	if err != nil {
+		return ""
+	}
+	logging.RuntimeLog.Infof("task:%s,attempt:%d failed, retry as task:%s", task.TaskId, attempt, taskId)
+	return taskId
+}
+
+// RetryMainTaskFailed 重新执行主任务中全部执行失败的子任务（不受重试策略的次数限制）
+func RetryMainTaskFailed(mainTaskId string) (count int, err error) {
+	mainTask := db.TaskMain{TaskId: mainTaskId}
+	if !mainTask.GetByTaskId() {
+		return 0, errors.New("maintask not exists")
+	}
+	taskRun := db.TaskRun{}
+	results, _ := taskRun.Gets(map[string]interface{}{"main_id": mainTaskId, "state": ampq.FAILURE}, -1, -1)
+	for i := range results {
+		if _, err = retryRunTask(&results[i], 0); err != nil {
+			continue
+		}
+		dt := time.Now()
+		failedTask := db.TaskRun{TaskId: results[i].TaskId, State: ampq.RETRY, RetriedTime: &dt}
+		if !failedTask.SaveOrUpdate() {
+			logging.RuntimeLog.Errorf("update task:%s,state:%s fail !", results[i].TaskId, ampq.RETRY)
+		}
+		count++
+	}
+	if count == 0 {
+		if err == nil {
+			err = errors.New("no failed task")
+		}
+		return 0, err
+	}
+	// 主任务已结束时，需重新置为执行中（同时清除结束时的结果及完成时间），由runner继续检查子任务的完成情况
+	if mainTask.State != ampq.STARTED && mainTask.State != ampq.PAUSED {
+		if !mainTask.Update(map[string]interface{}{"state": ampq.STARTED, "result": "", "succeeded": nil}) {
+			logging.RuntimeLog.Errorf("update maintask:%s,state:%s fail !", mainTaskId, ampq.STARTED)
+		}
+	}
+	return count, nil
+}
+
+// retryRunTask 使用失败任务的参数创建一个新的执行任务，新任务的LastRunTaskId指向失败的任务
+func retryRunTask(task *db.TaskRun, delay time.Duration) (taskId string, err error) {
+	// 参数超长时在数据库中被截断，无法还原任务参数
+	if !json.Valid([]byte(task.KwArgs)) {
+		msg := fmt.Sprintf("task:%s args truncated, can not retry", task.TaskId)
+		logging.RuntimeLog.Warning(msg)
+		return "", errors.New(msg)
+	}
+	attempt := task.Attempt
+	if attempt <= 0 {
+		attempt = 1
+	}
+	return newRunTask(&db.TaskRun{
+		TaskName:      task.TaskName,
+		KwArgs:        task.KwArgs,
+		MainTaskId:    task.MainTaskId,
+		LastRunTaskId: task.TaskId,
+		WorkflowStep:  task.WorkflowStep,
+		WorkerTag:     task.WorkerTag,
+		Attempt:       attempt + 1,
+	}, delay)
+}
@@ -0,0 +1,76 @@
+package serverapi
+
+import (
+	"github.com/hanc00l/nemo_go/pkg/conf"
+	"github.com/hanc00l/nemo_go/pkg/db"
+	"testing"
+	"time"
+)
+
+func TestRetryPolicyDelay(t *testing.T) {
+	tests := []struct {
+		policy  conf.RetryPolicy
+		attempt int
+		delay   time.Duration
+	}{
+		{conf.RetryPolicy{MaxAttempts: 3, Backoff: 0}, 1, 0},
+		{conf.RetryPolicy{MaxAttempts: 3, Backoff: 60}, 1, 60 * time.Second},
+		{conf.RetryPolicy{MaxAttempts: 3, Backoff: 60}, 2, 120 * time.Second},
+		{conf.RetryPolicy{MaxAttempts: 3, Backoff: 60}, 4, 480 * time.Second},
+		{conf.RetryPolicy{MaxAttempts: 3, Backoff: 60, MaxBackoff: 600}, 4, 480 * time.Second},
+		{conf.RetryPolicy{MaxAttempts: 3, Backoff: 60, MaxBackoff: 600}, 5, 600 * time.Second},
+		{conf.RetryPolicy{MaxAttempts: 3, Backoff: 60, MaxBackoff: 600}, 100, 600 * time.Second},
+		{conf.RetryPolicy{MaxAttempts: 3, Backoff: 900, MaxBackoff: 600}, 1, 600 * time.Second},
+	}
+	for _, tt := range tests {
+		if delay := tt.policy.Delay(tt.attempt); delay != tt.delay {
+			t.Errorf("backoff:%d,maxBackoff:%d,attempt:%d: delay %v, want %v", tt.policy.Backoff, tt.policy.MaxBackoff, tt.attempt, delay, tt.delay)
+		}
+	}
+}
+
+func TestRetryFailedTask(t *testing.T) {
+	mainTask := useStandalone(t)
+	config := conf.GlobalServerConfig()
+	retry := config.Task.Retry
+	config.Task.Retry = map[string]conf.RetryPolicy{"portscan": {MaxAttempts: 3, Backoff: 1}}
+	defer func() { config.Task.Retry = retry }()
+
+	tests := []struct {
+		name     string
+		taskName string
+		kwArgs   string
+		attempt  int
+		retry    bool
+	}{
+		{"first attempt", "portscan", `{"target":"192.168.1.1"}`, 1, true},
+		{"no attempt", "portscan", `{"target":"192.168.1.1"}`, 0, true},
+		{"last retry", "portscan", `{"target":"192.168.1.1"}`, 2, true},
+		{"attempt cap", "portscan", `{"target":"192.168.1.1"}`, 3, false},
+		{"truncated kwargs", "portscan", `{"target":"192.168.1.1...`, 1, false},
+		{"no policy", "fingerprint", `{"target":"192.168.1.1"}`, 1, false},
+	}
+	for _, tt := range tests {
+		failed := &db.TaskRun{TaskId: tt.name, TaskName: tt.taskName, KwArgs: tt.kwArgs, MainTaskId: mainTask.TaskId, Attempt: tt.attempt}
+		taskId := RetryFailedTask(failed)
+		if (taskId != "") != tt.retry {
+			t.Errorf("%s: retry task:%s, want retry %v", tt.name, taskId, tt.retry)
+			continue
+		}
+		if taskId == "" {
+			continue
+		}
+		task := db.TaskRun{TaskId: taskId}
+		if !task.GetByTaskId() {
+			t.Errorf("%s: retry task not saved", tt.name)
+			continue
+		}
+		attempt := tt.attempt
+		if attempt <= 0 {
+			attempt = 1
+		}
+		if task.Attempt != attempt+1 || task.LastRunTaskId != tt.name || task.KwArgs != tt.kwArgs {
+			t.Errorf("%s: invalid retry task:%d,%s,%s", tt.name, task.Attempt, task.LastRunTaskId, task.KwArgs)
+		}
+	}
+}
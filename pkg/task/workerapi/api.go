@@ -9,6 +9,7 @@ import (
 	"github.com/hanc00l/nemo_go/pkg/logging"
 	"github.com/hanc00l/nemo_go/pkg/task/ampq"
 	"github.com/sirupsen/logrus"
+	"sync"
 	"time"
 
 	"github.com/RichardKnop/machinery/v2/backends/result"
@@ -17,6 +18,12 @@ import (
 
 var WStatus ampq.WorkerStatus
 
+// PausedTaskRetryDelay 主任务暂停时，子任务重新放回消息队列的延迟时间
+const PausedTaskRetryDelay = 60 * time.Second
+
+// pausedTasks 因主任务暂停（或不在授权的时间窗口内）而丢弃或重新放回消息队列的任务
+var pausedTasks sync.Map
+
 // taskMaps 定义work执行的任务；在添加了对应的任务后，在ampq/api.go中指定任务对应的队列映射：taskTopicDefineMap
 var taskMaps = map[string]interface{}{
 	"portscan":          PortScan,
@@ -110,6 +117,11 @@ func StartWorker(topicName string, concurrency int) error {
 // postTaskHandler 任务完成时处理工作
 func postTaskHandler(signature *tasks.Signature) {
 	//log.INFO.Println("I am an end of task handler for:", signature.Name)
+	runningTasks.Delete(signature.UUID)
+	//暂停的任务已丢弃或重新放回消息队列，没有执行结果，保持任务状态不变
+	if _, ok := pausedTasks.LoadAndDelete(signature.UUID); ok {
+		return
+	}
 	server := ampq.GetWorkerAMPQServer(ampq.GetTopicByMQRoutingKey(signature.RoutingKey), 3)
 	r := result.NewAsyncResult(signature, server.GetBackend())
 	rr, _ := r.Get(time.Duration(0) * time.Second)
@@ -131,6 +143,7 @@ func preTaskHandler(signature *tasks.Signature) {
 	WStatus.Lock()
 	WStatus.TaskExecutedNumber++
 	WStatus.Unlock()
+	runningTasks.Store(signature.UUID, runningTask{TaskName: signature.Name, StartTime: time.Now()})
 
 	var taskStatus comm.TaskStatusArgs
 	if err := comm.CallXClient("CheckTask", &signature.UUID, &taskStatus); err != nil {
@@ -139,8 +152,8 @@ func preTaskHandler(signature *tasks.Signature) {
 	if !taskStatus.IsExist {
 		return
 	}
-	//REVOKED的任务：不要更新状态，只更新workName
-	if taskStatus.State == ampq.REVOKED {
+	//REVOKED的任务、暂停的任务：不要更新状态，只更新workName
+	if taskStatus.State == ampq.REVOKED || taskStatus.IsPaused {
 		UpdateTaskStatus(signature.UUID, "", WStatus.WorkerName, "")
 		return
 	}
@@ -161,6 +174,14 @@ func CheckTaskStatus(taskId string) (ok bool, result string, err error) {
 	if taskStatus.IsRevoked {
 		return false, RevokedTask(""), nil
 	}
+	//主任务暂停：子任务已由server保存的，丢弃当前的消息，恢复时由server重新发送；否则延迟后重新放回消息队列，恢复后再执行
+	if taskStatus.IsPaused {
+		pausedTasks.Store(taskId, struct{}{})
+		if taskStatus.State == ampq.PAUSED {
+			return false, "", nil
+		}
+		return false, "", tasks.NewErrRetryTaskLater("maintask paused", PausedTaskRetryDelay)
+	}
 	return true, "", nil
 }
 
@@ -173,7 +194,7 @@ func UpdateTaskStatus(taskId string, state string, worker string, result string)
 		Result: result,
 	}
 	var updateStatus bool
-	if err := comm.CallXClient("UpdateTask", &taskStatus, &updateStatus); err != nil {
+	if err := comm.CallXClientWithSpool("UpdateTask", &taskStatus, &updateStatus); err != nil {
 		logging.RuntimeLog.Error(err)
 		return false
 	}
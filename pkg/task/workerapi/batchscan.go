@@ -6,6 +6,7 @@ import (
 	"github.com/hanc00l/nemo_go/pkg/comm"
 	"github.com/hanc00l/nemo_go/pkg/logging"
 	"github.com/hanc00l/nemo_go/pkg/task/portscan"
+	"github.com/hanc00l/nemo_go/pkg/task/scope"
 	"strings"
 )
 
@@ -22,6 +23,14 @@ func BatchScan(taskId, mainTaskId, configJSON string) (result string, err error)
 		logging.RuntimeLog.Error(err)
 		return FailedTask(err.Error()), err
 	}
+	// 去除授权范围外的目标
+	var s *scope.Scope
+	if s, ok, result, err = checkTaskScope(taskId, config.WorkspaceId); !ok {
+		return result, err
+	}
+	if config.Target = filterScopeTarget(taskId, s, config.Target); config.Target == "" {
+		return SucceedTask("no target in scope"), nil
+	}
 	// 提取两个阶段的port
 	ports := strings.Split(config.Port, "|")
 	if len(ports) != 2 || strings.TrimSpace(ports[0]) == "" || strings.TrimSpace(ports[1]) == "" {
@@ -35,13 +44,17 @@ func BatchScan(taskId, mainTaskId, configJSON string) (result string, err error)
 		nmap := portscan.NewNmap(config)
 		nmap.Do()
 		resultPortScan = nmap.Result
+	} else if config.CmdBin == portscan.TCPScanCmdBin {
+		tcpScan := portscan.NewTCPScan(config)
+		tcpScan.Do()
+		resultPortScan.IPResult = tcpScan.Result.IPResult
 	} else {
 		mascan := portscan.NewMasscan(config)
 		mascan.Do()
 		resultPortScan = mascan.Result
 	}
-	// 详细端口扫描
-	ipSubnetList := getResultIPSubnetList(&resultPortScan)
+	// 详细端口扫描：C段可能超出授权范围
+	ipSubnetList := filterScopeTarget(taskId, s, getResultIPSubnetList(&resultPortScan))
 	if ipSubnetList != "" {
 		config.Port = ports[1]
 		config.Target = ipSubnetList
@@ -49,6 +62,10 @@ func BatchScan(taskId, mainTaskId, configJSON string) (result string, err error)
 			nmap := portscan.NewNmap(config)
 			nmap.Do()
 			resultPortScan = nmap.Result
+		} else if config.CmdBin == portscan.TCPScanCmdBin {
+			tcpScan := portscan.NewTCPScan(config)
+			tcpScan.Do()
+			resultPortScan.IPResult = tcpScan.Result.IPResult
 		} else {
 			mascan := portscan.NewMasscan(config)
 			mascan.Do()
@@ -66,7 +83,7 @@ func BatchScan(taskId, mainTaskId, configJSON string) (result string, err error)
 		IPConfig:   &config,
 		IPResult:   resultPortScan.IPResult,
 	}
-	err = comm.CallXClient("SaveScanResult", &resultArgs, &result)
+	err = comm.CallXClientWithSpool("SaveScanResult", &resultArgs, &result)
 	if err != nil {
 		logging.RuntimeLog.Error(err)
 		return FailedTask(err.Error()), err
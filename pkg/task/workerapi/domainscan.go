@@ -37,7 +37,7 @@ func DomainScan(taskId, mainTaskId, configJSON string) (result string, err error
 		DomainConfig: &config,
 		DomainResult: resultDomainScan.DomainResult,
 	}
-	err = comm.CallXClient("SaveScanResult", &resultArgs, &result)
+	err = comm.CallXClientWithSpool("SaveScanResult", &resultArgs, &result)
 	if err != nil {
 		logging.RuntimeLog.Error(err)
 		return FailedTask(err.Error()), err
@@ -162,6 +162,14 @@ func getResultIPList(resultDomainScan *domainscan.Result) (ipResult, ipSubnetRes
 			continue
 		}
 		for _, dar := range da.DomainAttrs {
+			// IPv6地址不进行C段的扩展
+			if dar.Tag == "AAAA" {
+				if cdnCheck.CheckIP(dar.Content) {
+					continue
+				}
+				ips[dar.Content] = struct{}{}
+				continue
+			}
 			if dar.Tag == "A" {
 				ipArray := strings.Split(dar.Content, ".")
 				if len(ipArray) != 4 {
@@ -207,7 +215,7 @@ func isHaveResolveRecord(domainAttrs *[]domainscan.DomainAttrResult) bool {
 		return false
 	}
 	for _, dar := range *domainAttrs {
-		if dar.Tag == "A" || dar.Tag == "CNAME" {
+		if dar.Tag == "A" || dar.Tag == "AAAA" || dar.Tag == "CNAME" {
 			return true
 		}
 	}
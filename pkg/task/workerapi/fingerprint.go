@@ -7,6 +7,7 @@ import (
 	"github.com/hanc00l/nemo_go/pkg/task/domainscan"
 	"github.com/hanc00l/nemo_go/pkg/task/fingerprint"
 	"github.com/hanc00l/nemo_go/pkg/task/portscan"
+	"github.com/hanc00l/nemo_go/pkg/task/scope"
 	"strings"
 )
 
@@ -36,6 +37,16 @@ func Fingerprint(taskId, mainTaskId, configJSON string) (result string, err erro
 		logging.RuntimeLog.Error(err)
 		return FailedTask(err.Error()), err
 	}
+	// 去除授权范围外的目标
+	var s *scope.Scope
+	if s, ok, result, err = checkTaskScope(taskId, config.WorkspaceId); !ok {
+		return result, err
+	}
+	config.IPTargetMap = filterScopeIPPort(taskId, s, config.IPTargetMap)
+	config.DomainTargetMap = filterScopeDomain(taskId, s, config.DomainTargetMap)
+	if len(config.IPTargetMap) == 0 && len(config.DomainTargetMap) == 0 {
+		return SucceedTask("no target in scope"), nil
+	}
 	//
 	_, _, result, err = doFingerPrintAndSave(taskId, mainTaskId, config)
 	if err != nil {
@@ -101,7 +112,7 @@ func doFingerPrintAndSave(taskId string, mainTaskId string, config FingerprintTa
 		resultArgs.DomainResult = resultDomainScan.DomainResult
 	}
 	// 保存结果
-	err = comm.CallXClient("SaveScanResult", &resultArgs, &result)
+	err = comm.CallXClientWithSpool("SaveScanResult", &resultArgs, &result)
 	if err != nil {
 		logging.RuntimeLog.Error(err)
 		return
@@ -168,7 +179,7 @@ func doScreenshotAndSave(workspaceId int, mainTaskId string, resultIPScan *ports
 		FileInfo:    ss.LoadResult(),
 		WorkspaceId: workspaceId,
 	}
-	err := comm.CallXClient("SaveScreenshotResult", &args, &result)
+	err := comm.CallXClientWithSpool("SaveScreenshotResult", &args, &result)
 	if err != nil {
 		logging.RuntimeLog.Error(err)
 		return err.Error()
@@ -195,7 +206,7 @@ func doIconHashAndSave(workspaceId int, resultIPScan *portscan.Result, resultDom
 		WorkspaceId:  workspaceId,
 		IconHashInfo: hash.IconHashInfoResult.Result,
 	}
-	err := comm.CallXClient("SaveIconImageResult", &args, &result)
+	err := comm.CallXClientWithSpool("SaveIconImageResult", &args, &result)
 	if err != nil {
 		logging.RuntimeLog.Error(err)
 		return err.Error()
@@ -27,6 +27,9 @@ func IPLocation(taskId, mainTaskId, configJSON string) (result string, err error
 	for _, ip := range ips {
 		lists := utils.ParseIP(ip)
 		for _, oneIp := range lists {
+			if utils.CheckIPV6Unexpanded(oneIp) {
+				continue
+			}
 			if !resultPortScan.HasIP(oneIp) {
 				resultPortScan.SetIP(oneIp)
 			}
@@ -40,7 +43,7 @@ func IPLocation(taskId, mainTaskId, configJSON string) (result string, err error
 		IPConfig:   &portscan.Config{OrgId: config.OrgId},
 		IPResult:   resultPortScan.IPResult,
 	}
-	err = comm.CallXClient("SaveScanResult", &resultArgs, &result)
+	err = comm.CallXClientWithSpool("SaveScanResult", &resultArgs, &result)
 	if err != nil {
 		logging.RuntimeLog.Error(err)
 		return FailedTask(err.Error()), err
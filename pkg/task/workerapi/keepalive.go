@@ -0,0 +1,42 @@
+package workerapi
+
+import (
+	"github.com/hanc00l/nemo_go/pkg/comm"
+	"github.com/hanc00l/nemo_go/pkg/conf"
+	"github.com/hanc00l/nemo_go/pkg/logging"
+	"github.com/shirou/gopsutil/v3/cpu"
+	"github.com/shirou/gopsutil/v3/mem"
+	"time"
+)
+
+// KeepAlive worker与server的心跳，用于在dashboard中显示worker的状态
+func KeepAlive() {
+	time.Sleep(10 * time.Second)
+	for {
+		RefreshWorkerTelemetry()
+		WStatus.Lock()
+		if !comm.DoKeepAlive(WStatus) {
+			logging.RuntimeLog.Errorf("keep alive fail")
+			logging.CLILog.Error("keep alive fail")
+		}
+		WStatus.Unlock()
+		time.Sleep(60 * time.Second)
+	}
+}
+
+// CheckWorkerPerformance 设置worker的性能模式（0:自动检测，1:高性能，2:普通）
+func CheckWorkerPerformance(workerPerformance int) {
+	switch workerPerformance {
+	case 0:
+		cpuNumber, err1 := cpu.Counts(true)
+		memInfo, err2 := mem.VirtualMemory()
+		if err1 != nil || err2 != nil {
+			break
+		}
+		if cpuNumber >= 4 && memInfo.Total >= 4*1024*1024*1024 {
+			conf.WorkerPerformanceMode = conf.HighPerformance
+		}
+	case 1:
+		conf.WorkerPerformanceMode = conf.HighPerformance
+	}
+}
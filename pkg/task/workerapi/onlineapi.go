@@ -88,7 +88,7 @@ func doOnlineAPIAndSave(taskId string, mainTaskId string, apiName string, config
 		IPResult:     ipResult.IPResult,
 		DomainResult: domainResult.DomainResult,
 	}
-	err = comm.CallXClient("SaveScanResult", &args, &result)
+	err = comm.CallXClientWithSpool("SaveScanResult", &args, &result)
 	if err != nil {
 		logging.RuntimeLog.Error(err)
 	}
@@ -110,7 +110,7 @@ func ICPQuery(taskId, mainTaskId, configJSON string) (result string, err error)
 	icp := onlineapi.NewICPQuery(config)
 	icp.Do()
 	// 保存结果
-	err = comm.CallXClient("SaveICPResult", &icp.QueriedICPInfo, &result)
+	err = comm.CallXClientWithSpool("SaveICPResult", &icp.QueriedICPInfo, &result)
 	if err != nil {
 		logging.RuntimeLog.Error(err)
 		return FailedTask(err.Error()), err
@@ -134,7 +134,7 @@ func WhoisQuery(taskId, mainTaskId, configJSON string) (result string, err error
 	whois := onlineapi.NewWhois(config)
 	whois.Do()
 	// 保存结果
-	err = comm.CallXClient("SaveWhoisResult", &whois.QueriedWhoisInfo, &result)
+	err = comm.CallXClientWithSpool("SaveWhoisResult", &whois.QueriedWhoisInfo, &result)
 	if err != nil {
 		logging.RuntimeLog.Error(err)
 		return FailedTask(err.Error()), err
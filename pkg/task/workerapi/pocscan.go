@@ -4,6 +4,7 @@ import (
 	"github.com/hanc00l/nemo_go/pkg/comm"
 	"github.com/hanc00l/nemo_go/pkg/logging"
 	"github.com/hanc00l/nemo_go/pkg/task/pocscan"
+	"github.com/hanc00l/nemo_go/pkg/task/scope"
 )
 
 // PocScan 漏洞验证任务
@@ -17,6 +18,10 @@ func PocScan(taskId, mainTaskId, configJSON string) (result string, err error) {
 		logging.RuntimeLog.Error(err)
 		return FailedTask(err.Error()), err
 	}
+	var s *scope.Scope
+	if s, ok, result, err = checkTaskScope(taskId, config.WorkspaceId); !ok {
+		return result, err
+	}
 	//读取资产开放端口
 	var resultIPPorts string
 	if config.IsLoadOpenedPort {
@@ -31,6 +36,10 @@ func PocScan(taskId, mainTaskId, configJSON string) (result string, err error) {
 			logging.RuntimeLog.Error(err)
 		}
 	}
+	// 去除授权范围外的目标
+	if config.Target = filterScopeTarget(taskId, s, config.Target); config.Target == "" {
+		return SucceedTask("no target in scope"), nil
+	}
 	var scanResult []pocscan.Result
 	if config.CmdBin == "xray" {
 		x := pocscan.NewXray(config)
@@ -55,7 +64,7 @@ func PocScan(taskId, mainTaskId, configJSON string) (result string, err error) {
 		MainTaskId:          mainTaskId,
 		VulnerabilityResult: scanResult,
 	}
-	err = comm.CallXClient("SaveVulnerabilityResult", &resultArgs, &result)
+	err = comm.CallXClientWithSpool("SaveVulnerabilityResult", &resultArgs, &result)
 	if err != nil {
 		logging.RuntimeLog.Error(err)
 		return FailedTask(err.Error()), err
@@ -6,8 +6,10 @@ import (
 	"github.com/hanc00l/nemo_go/pkg/conf"
 	"github.com/hanc00l/nemo_go/pkg/logging"
 	"github.com/hanc00l/nemo_go/pkg/task/portscan"
+	"github.com/hanc00l/nemo_go/pkg/task/scope"
 	"github.com/hanc00l/nemo_go/pkg/utils"
 	"github.com/remeh/sizedwaitgroup"
+	"net"
 	"strconv"
 	"strings"
 )
@@ -30,6 +32,14 @@ func PortScan(taskId, mainTaskId, configJSON string) (result string, err error)
 		logging.RuntimeLog.Error(err)
 		return FailedTask(err.Error()), err
 	}
+	// 去除授权范围外的目标
+	var s *scope.Scope
+	if s, ok, result, err = checkTaskScope(taskId, config.WorkspaceId); !ok {
+		return result, err
+	}
+	if config.Target = filterScopeTarget(taskId, s, config.Target); config.Target == "" {
+		return SucceedTask("no target in scope"), nil
+	}
 	var resultPortScan portscan.Result
 	resultPortScan, result, err = doPortScanAndSave(taskId, mainTaskId, config)
 	//指纹识别任务
@@ -55,6 +65,10 @@ func doPortScanAndSave(taskId string, mainTaskId string, config portscan.Config)
 			nmap := portscan.NewNmap(config)
 			nmap.Do()
 			resultPortScan = nmap.Result
+		} else if config.CmdBin == portscan.TCPScanCmdBin {
+			tcpScan := portscan.NewTCPScan(config)
+			tcpScan.Do()
+			resultPortScan.IPResult = tcpScan.Result.IPResult
 		} else {
 			masscan := portscan.NewMasscan(config)
 			masscan.Do()
@@ -74,7 +88,7 @@ func doPortScanAndSave(taskId string, mainTaskId string, config portscan.Config)
 		IPConfig:   &config,
 		IPResult:   resultPortScan.IPResult,
 	}
-	err = comm.CallXClient("SaveScanResult", &resultArgs, &result)
+	err = comm.CallXClientWithSpool("SaveScanResult", &resultArgs, &result)
 	if err != nil {
 		logging.RuntimeLog.Error(err)
 	}
@@ -89,14 +103,13 @@ func doPortScanAndSave(taskId string, mainTaskId string, config portscan.Config)
 		if err == nil && resultIPPorts != "" {
 			allTargets := strings.Split(resultIPPorts, ",")
 			for _, target := range allTargets {
-				// 必须是ip:port格式
-				dataArray := strings.Split(target, ":")
-				if len(dataArray) != 2 {
+				// 必须是ip:port格式（IPv6为[ip]:port）
+				ip, portString, err := net.SplitHostPort(target)
+				if err != nil {
 					continue
 				}
-				ip := dataArray[0]
-				port, err := strconv.Atoi(dataArray[1])
-				if utils.CheckIPV4(ip) == false || err != nil {
+				port, err := strconv.Atoi(portString)
+				if utils.CheckIP(ip) == false || err != nil {
 					continue
 				}
 				if !resultPortScan.HasIP(ip) {
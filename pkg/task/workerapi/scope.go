@@ -0,0 +1,131 @@
+package workerapi
+
+import (
+	"github.com/RichardKnop/machinery/v2/tasks"
+	"github.com/hanc00l/nemo_go/pkg/comm"
+	"github.com/hanc00l/nemo_go/pkg/logging"
+	"github.com/hanc00l/nemo_go/pkg/task/domainscan"
+	"github.com/hanc00l/nemo_go/pkg/task/portscan"
+	"github.com/hanc00l/nemo_go/pkg/task/scope"
+	"strings"
+	"time"
+)
+
+// ScopeWindowRetryDelay 不在授权的时间窗口内时，主动扫描任务重新放回消息队列的延迟时间
+const ScopeWindowRetryDelay = 5 * time.Minute
+
+// loadWorkspaceScope 从server读取工作空间的授权范围；未配置时返回nil，表示不限制
+func loadWorkspaceScope(workspaceId int) (s *scope.Scope, err error) {
+	var content string
+	if err = comm.CallXClient("LoadWorkspaceScope", &workspaceId, &content); err != nil {
+		logging.RuntimeLog.Error(err)
+		return
+	}
+	if s, err = scope.Parse(content); err != nil {
+		logging.RuntimeLog.Errorf("parse workspace:%d scope fail:%v", workspaceId, err)
+	}
+	return
+}
+
+// checkTaskScope 主动扫描任务执行前读取授权范围：不在授权的时间窗口内时，延迟后重新放回消息队列
+func checkTaskScope(taskId string, workspaceId int) (s *scope.Scope, ok bool, result string, err error) {
+	if s, err = loadWorkspaceScope(workspaceId); err != nil {
+		return nil, false, FailedTask(err.Error()), err
+	}
+	if !s.InWindow(time.Now()) {
+		logging.RuntimeLog.Infof("task:%s out of scope time window, retry later", taskId)
+		pausedTasks.Store(taskId, struct{}{})
+		return nil, false, "", tasks.NewErrRetryTaskLater("out of scope time window", ScopeWindowRetryDelay)
+	}
+	return s, true, "", nil
+}
+
+// filterScopeTarget 去除以“,”分隔的目标中授权范围外的目标
+func filterScopeTarget(taskId string, s *scope.Scope, target string) string {
+	if !s.IsTargetLimited() || target == "" {
+		return target
+	}
+	targetList := strings.Split(target, ",")
+	results := s.FilterTarget(targetList)
+	if len(results) != len(targetList) || strings.Join(results, ",") != target {
+		logging.RuntimeLog.Warningf("task:%s,target out of scope filtered:%s", taskId, target)
+	}
+	return strings.Join(results, ",")
+}
+
+// filterScopeIPPort 去除IP及端口目标中授权范围外的IP
+func filterScopeIPPort(taskId string, s *scope.Scope, ipPort map[string][]int) map[string][]int {
+	if !s.IsTargetLimited() || len(ipPort) == 0 {
+		return ipPort
+	}
+	results := make(map[string][]int)
+	for ip, ports := range ipPort {
+		if s.ContainsIP(ip) {
+			results[ip] = ports
+		} else {
+			logging.RuntimeLog.Warningf("task:%s,ip out of scope filtered:%s", taskId, ip)
+		}
+	}
+	return results
+}
+
+// filterScopeIPPortString 去除IP（可以是CIDR、IP范围）及端口目标中授权范围外的IP
+func filterScopeIPPortString(taskId string, s *scope.Scope, ipPort map[string]string) map[string]string {
+	if !s.IsTargetLimited() || len(ipPort) == 0 {
+		return ipPort
+	}
+	results := make(map[string]string)
+	for ip, ports := range ipPort {
+		for _, t := range strings.Split(filterScopeTarget(taskId, s, ip), ",") {
+			if t != "" {
+				results[t] = ports
+			}
+		}
+	}
+	return results
+}
+
+// filterScopeDomain 去除域名目标中授权范围外的域名
+func filterScopeDomain(taskId string, s *scope.Scope, domains map[string]struct{}) map[string]struct{} {
+	if !s.IsTargetLimited() || len(domains) == 0 {
+		return domains
+	}
+	results := make(map[string]struct{})
+	for domain := range domains {
+		if s.ContainsDomain(domain) {
+			results[domain] = struct{}{}
+		} else {
+			logging.RuntimeLog.Warningf("task:%s,domain out of scope filtered:%s", taskId, domain)
+		}
+	}
+	return results
+}
+
+// filterScopeXScanConfig 去除XScan任务中授权范围外的目标，返回是否还有需要执行的目标
+func filterScopeXScanConfig(taskId string, s *scope.Scope, config *XScanConfig) bool {
+	config.IPPort = filterScopeIPPort(taskId, s, config.IPPort)
+	config.IPPortString = filterScopeIPPortString(taskId, s, config.IPPortString)
+	config.Domain = filterScopeDomain(taskId, s, config.Domain)
+	return len(config.IPPort) > 0 || len(config.IPPortString) > 0 || len(config.Domain) > 0
+}
+
+// removeOutOfScopeResult 从结果中去除授权范围外的IP与域名，使其不作为后续任务的目标（结果已由server保存并标记）
+func removeOutOfScopeResult(s *scope.Scope, resultIP *portscan.Result, resultDomain *domainscan.Result) {
+	if !s.IsTargetLimited() {
+		return
+	}
+	if resultIP != nil {
+		for ip := range resultIP.IPResult {
+			if !s.ContainsIP(ip) {
+				delete(resultIP.IPResult, ip)
+			}
+		}
+	}
+	if resultDomain != nil {
+		for domain := range resultDomain.DomainResult {
+			if !s.ContainsDomain(domain) {
+				delete(resultDomain.DomainResult, domain)
+			}
+		}
+	}
+}
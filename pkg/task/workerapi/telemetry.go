@@ -0,0 +1,153 @@
+package workerapi
+
+import (
+	"context"
+	"github.com/hanc00l/nemo_go/pkg/comm"
+	"github.com/hanc00l/nemo_go/pkg/conf"
+	"github.com/hanc00l/nemo_go/pkg/filesync"
+	"github.com/hanc00l/nemo_go/pkg/logging"
+	"github.com/hanc00l/nemo_go/pkg/task/ampq"
+	"github.com/hanc00l/nemo_go/pkg/utils"
+	"github.com/shirou/gopsutil/v3/cpu"
+	"github.com/shirou/gopsutil/v3/disk"
+	"github.com/shirou/gopsutil/v3/mem"
+	"os/exec"
+	"path/filepath"
+	"regexp"
+	"runtime"
+	"sort"
+	"sync"
+	"time"
+)
+
+// toolVersionTimeout 获取第三方工具版本的超时时间
+const toolVersionTimeout = 10 * time.Second
+
+// runningTask 正在执行的任务
+type runningTask struct {
+	TaskName  string
+	StartTime time.Time
+}
+
+// runningTasks 正在执行的任务：taskId -> runningTask
+var runningTasks sync.Map
+
+var (
+	toolVersions        map[string]string
+	toolVersionRevision string
+	// 优先匹配“version”之后的版本号，否则使用输出中的第一个版本号
+	versionKeywordRegexp = regexp.MustCompile(`(?i)version[:\s]+v?(\d+(?:\.\d+)+)`)
+	versionRegexp        = regexp.MustCompile(`v?(\d+(?:\.\d+)+)`)
+)
+
+// chromeBinNames 截图使用的chrome查找顺序（与chromedp一致）
+var chromeBinNames = map[string][]string{
+	"darwin":  {"/Applications/Chromium.app/Contents/MacOS/Chromium", "/Applications/Google Chrome.app/Contents/MacOS/Google Chrome"},
+	"windows": {"chrome"},
+	"linux":   {"headless_shell", "headless-shell", "chromium", "chromium-browser", "google-chrome", "google-chrome-stable", "google-chrome-beta", "google-chrome-unstable"},
+}
+
+// RefreshWorkerTelemetry 在心跳前更新worker的负载、正在执行的任务、第三方工具版本及文件同步版本
+func RefreshWorkerTelemetry() {
+	var cpuPercent, memPercent, diskPercent float64
+	if percents, err := cpu.Percent(time.Second, false); err == nil && len(percents) > 0 {
+		cpuPercent = percents[0]
+	}
+	if memInfo, err := mem.VirtualMemory(); err == nil {
+		memPercent = memInfo.UsedPercent
+	}
+	if diskInfo, err := disk.Usage(conf.GetAbsRootPath()); err == nil {
+		diskPercent = diskInfo.UsedPercent
+	}
+	// 文件同步后第三方工具可能已更新，需重新获取版本
+	syncRevision := filesync.LoadSyncRevision()
+	if toolVersions == nil || toolVersionRevision != syncRevision.Revision {
+		toolVersions = checkToolVersions(syncRevision.Profile)
+		toolVersionRevision = syncRevision.Revision
+	}
+
+	WStatus.Lock()
+	defer WStatus.Unlock()
+	WStatus.CPUPercent = cpuPercent
+	WStatus.MemPercent = memPercent
+	WStatus.DiskPercent = diskPercent
+	WStatus.RunningTasks = getRunningTasks()
+	WStatus.ToolVersions = toolVersions
+	WStatus.FileSyncRevision = syncRevision.Revision
+	WStatus.FileSyncProfile = syncRevision.Profile
+	WStatus.FileSyncRelease = syncRevision.Release
+	WStatus.FileSyncTime = syncRevision.SyncTime
+	WStatus.SpoolDepth = comm.SpoolDepth()
+}
+
+// getRunningTasks 获取正在执行的任务，按已执行时间从长到短排序
+func getRunningTasks() (tasks []ampq.RunningTask) {
+	runningTasks.Range(func(key, value interface{}) bool {
+		t := value.(runningTask)
+		tasks = append(tasks, ampq.RunningTask{
+			TaskId:   key.(string),
+			TaskName: t.TaskName,
+			Elapsed:  int(time.Since(t.StartTime).Seconds()),
+		})
+		return true
+	})
+	sort.Slice(tasks, func(i, j int) bool {
+		return tasks[i].Elapsed > tasks[j].Elapsed
+	})
+	return
+}
+
+// checkToolVersions 获取worker使用的第三方工具的版本：不存在的工具为空，无法获取版本的为unknown；
+// 不在worker同步profile中的第三方工具不检查
+func checkToolVersions(syncProfile string) map[string]string {
+	thirdpartyPath := filepath.Join(conf.GetAbsRootPath(), "thirdparty")
+	tools := map[string][]string{
+		"nmap":          {lookPath("nmap"), "--version"},
+		"masscan":       {lookPath("masscan"), "--version"},
+		"nuclei":        {filepath.Join(thirdpartyPath, "nuclei", utils.GetThirdpartyBinNameByPlatform(utils.Nuclei)), "-version"},
+		"xray":          {filepath.Join(thirdpartyPath, "xray", utils.GetThirdpartyBinNameByPlatform(utils.Xray)), "version"},
+		"observer_ward": {filepath.Join(thirdpartyPath, "fingerprinthub", utils.GetThirdpartyBinNameByPlatform(utils.ObserverWard)), "--version"},
+		"chrome":        {lookPath(chromeBinNames[runtime.GOOS]...), "--version"},
+	}
+	versions := make(map[string]string)
+	for name, cmdArgs := range tools {
+		if relPath, err := filepath.Rel(conf.GetAbsRootPath(), cmdArgs[0]); err == nil && !filesync.CheckFileInSyncProfile(filepath.ToSlash(relPath), syncProfile) {
+			continue
+		}
+		versions[name] = getToolVersion(cmdArgs[0], cmdArgs[1:]...)
+	}
+	return versions
+}
+
+// lookPath 查找第一个存在的可执行文件
+func lookPath(names ...string) string {
+	for _, name := range names {
+		if binPath, err := exec.LookPath(name); err == nil {
+			return binPath
+		}
+	}
+	return ""
+}
+
+// getToolVersion 执行工具的版本命令，从输出中获取版本号
+func getToolVersion(binPath string, args ...string) string {
+	if binPath == "" || !utils.CheckFileExist(binPath) {
+		return ""
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), toolVersionTimeout)
+	defer cancel()
+	cmd := exec.CommandContext(ctx, binPath, args...)
+	cmd.Dir = filepath.Dir(binPath)
+	output, err := cmd.CombinedOutput()
+	if len(output) == 0 {
+		logging.RuntimeLog.Warningf("get %s version fail:%v", binPath, err)
+		return ampq.ToolVersionUnknown
+	}
+	if m := versionKeywordRegexp.FindSubmatch(output); m != nil {
+		return string(m[1])
+	}
+	if m := versionRegexp.FindSubmatch(output); m != nil {
+		return string(m[1])
+	}
+	return ampq.ToolVersionUnknown
+}
@@ -0,0 +1,205 @@
+package workerapi
+
+import (
+	"encoding/json"
+	"github.com/hanc00l/nemo_go/pkg/comm"
+	"github.com/hanc00l/nemo_go/pkg/conf"
+	"github.com/hanc00l/nemo_go/pkg/logging"
+	"github.com/hanc00l/nemo_go/pkg/task/domainscan"
+	"github.com/hanc00l/nemo_go/pkg/task/portscan"
+	"github.com/hanc00l/nemo_go/pkg/task/workflow"
+)
+
+// NewWorkflowStepConfig 生成工作流步骤的任务参数
+func NewWorkflowStepConfig(config XScanConfig, step workflow.Step) XScanConfig {
+	stepConfig := XScanConfig{
+		OrgId:        config.OrgId,
+		WorkspaceId:  config.WorkspaceId,
+		Workflow:     config.Workflow,
+		WorkflowStep: step.Id,
+	}
+	switch step.Task {
+	case workflow.TaskOnlineAPI:
+		switch step.Params.Engine {
+		case "hunter":
+			stepConfig.IsHunter = true
+		case "quake":
+			stepConfig.IsQuake = true
+		default:
+			stepConfig.IsFofa = true
+		}
+	case workflow.TaskXray:
+		stepConfig.IsXrayPoc = true
+		stepConfig.XrayPocFile = step.Params.PocFile
+	case workflow.TaskNuclei:
+		stepConfig.IsNucleiPoc = true
+		stepConfig.NucleiPocFile = step.Params.PocFile
+	case workflow.TaskGoby:
+		stepConfig.IsGobyPoc = true
+	}
+	return stepConfig
+}
+
+// WorkflowPortscanPort 工作流端口扫描步骤的端口，未指定时使用worker的默认配置
+func WorkflowPortscanPort(step workflow.Step) string {
+	if step.Params.Port != "" {
+		return step.Params.Port
+	}
+	return conf.GlobalWorkerConfig().Portscan.Port
+}
+
+// WorkflowDomainscanTasks 工作流域名步骤的任务：子域名枚举、爆破、爬虫拆分成为多个任务并行执行，都未指定时只进行域名解析
+func WorkflowDomainscanTasks(config XScanConfig, step workflow.Step) (tasks map[string]XScanConfig) {
+	tasks = make(map[string]XScanConfig)
+	if step.Params.SubFinder {
+		configRun := config
+		configRun.IsSubDomainFinder = true
+		tasks["xsubfinder"] = configRun
+	}
+	if step.Params.SubDomainBrute {
+		configRun := config
+		configRun.IsSubDomainBrute = true
+		tasks["xsubdomainbrute"] = configRun
+	}
+	if step.Params.SubDomainCrawler {
+		configRun := config
+		configRun.IsSubDomainCrawler = true
+		tasks["xsubdomaincrawler"] = configRun
+	}
+	if len(tasks) == 0 {
+		tasks["xdomainscan"] = config
+	}
+	return
+}
+
+// WorkflowTaskName 工作流步骤对应的任务名称（domainscan除外）
+func WorkflowTaskName(step workflow.Step) string {
+	switch step.Task {
+	case workflow.TaskOnlineAPI:
+		return workflow.OnlineAPITaskName(step.Params.Engine)
+	case workflow.TaskPortscan:
+		return "xportscan"
+	case workflow.TaskFingerprint:
+		return "xfingerprint"
+	case workflow.TaskXray:
+		return "xxray"
+	case workflow.TaskNuclei:
+		return "xnuclei"
+	case workflow.TaskGoby:
+		return "xgoby"
+	}
+	return ""
+}
+
+// NewWorkflowNextSteps 当前步骤完成后，按条件过滤本次任务的结果，生成后续步骤的任务
+func (x *XScan) NewWorkflowNextSteps(taskId, mainTaskId string) (result string, err error) {
+	for _, step := range x.Config.Workflow.Children(x.Config.WorkflowStep) {
+		ipResult := step.Condition.FilterIP(x.ResultIP.IPResult)
+		domainResult := step.Condition.FilterDomain(x.ResultDomain.DomainResult)
+		config := NewWorkflowStepConfig(x.Config, step)
+		switch step.Task {
+		case workflow.TaskPortscan:
+			port := WorkflowPortscanPort(step)
+			ipTarget := workflowIPTarget(ipResult, domainResult)
+			for i := 0; i < len(ipTarget); i += IPNumberPerSubTask {
+				configRun := config
+				configRun.IPPortString = make(map[string]string)
+				for _, ip := range ipTarget[i:minInt(i+IPNumberPerSubTask, len(ipTarget))] {
+					configRun.IPPortString[ip] = port
+				}
+				if result, err = sendWorkflowTask(taskId, mainTaskId, configRun, "xportscan", step); err != nil {
+					return
+				}
+			}
+		case workflow.TaskDomainscan:
+			_, domainTarget := MakeSubTaskTarget(nil, &domainscan.Result{DomainResult: domainResult})
+			for taskName, configDomain := range WorkflowDomainscanTasks(config, step) {
+				for _, t := range domainTarget {
+					configRun := configDomain
+					configRun.Domain = t
+					if result, err = sendWorkflowTask(taskId, mainTaskId, configRun, taskName, step); err != nil {
+						return
+					}
+				}
+			}
+		default:
+			ipTarget, domainTarget := MakeSubTaskTarget(&portscan.Result{IPResult: ipResult}, &domainscan.Result{DomainResult: domainResult})
+			for _, t := range ipTarget {
+				configRun := config
+				configRun.IPPort = t
+				if result, err = sendWorkflowTask(taskId, mainTaskId, configRun, WorkflowTaskName(step), step); err != nil {
+					return
+				}
+			}
+			for _, t := range domainTarget {
+				configRun := config
+				configRun.Domain = t
+				if result, err = sendWorkflowTask(taskId, mainTaskId, configRun, WorkflowTaskName(step), step); err != nil {
+					return
+				}
+			}
+		}
+	}
+	return
+}
+
+// runWorkflowNextSteps 工作流任务完成当前步骤后生成后续步骤的任务，返回任务的执行结果；不是工作流任务时isWorkflow为false
+func (x *XScan) runWorkflowNextSteps(taskId, mainTaskId, result string) (isWorkflow bool, taskResult string, err error) {
+	if x.Config.Workflow == nil {
+		return false, "", nil
+	}
+	if _, err = x.NewWorkflowNextSteps(taskId, mainTaskId); err != nil {
+		logging.RuntimeLog.Error(err)
+		return true, FailedTask(err.Error()), err
+	}
+	return true, SucceedTask(result), nil
+}
+
+// workflowIPTarget 端口扫描步骤的IP：上游结果中的IP，以及域名解析的IP
+func workflowIPTarget(ipResult map[string]*portscan.IPResult, domainResult map[string]*domainscan.DomainResult) (ipTarget []string) {
+	ips := make(map[string]struct{})
+	for ip := range ipResult {
+		ips[ip] = struct{}{}
+	}
+	for _, dr := range domainResult {
+		for _, attr := range dr.DomainAttrs {
+			if attr.Tag == "A" || attr.Tag == "AAAA" {
+				ips[attr.Content] = struct{}{}
+			}
+		}
+	}
+	for ip := range ips {
+		ipTarget = append(ipTarget, ip)
+	}
+	return
+}
+
+// sendWorkflowTask 调用api发送工作流步骤的任务
+func sendWorkflowTask(taskId string, mainTaskId string, config interface{}, taskName string, step workflow.Step) (result string, err error) {
+	configMarshal, err := json.Marshal(config)
+	if err != nil {
+		logging.RuntimeLog.Error(err)
+		return
+	}
+	newTaskArgs := comm.NewTaskArgs{
+		MainTaskID:    mainTaskId,
+		LastRunTaskId: taskId,
+		TaskName:      taskName,
+		ConfigJSON:    string(configMarshal),
+		WorkflowStep:  step.Id,
+		WorkerTag:     step.Tag,
+	}
+	err = comm.CallXClient("NewTask", &newTaskArgs, &result)
+	if err != nil {
+		logging.RuntimeLog.Errorf("start workflow step:%s task:%s fail:%v", step.Id, taskName, err)
+		logging.CLILog.Errorf("start workflow step:%s task:%s fail:%v", step.Id, taskName, err)
+	}
+	return
+}
+
+func minInt(a, b int) int {
+	if a < b {
+		return a
+	}
+	return b
+}
@@ -10,6 +10,8 @@ import (
 	"github.com/hanc00l/nemo_go/pkg/task/onlineapi"
 	"github.com/hanc00l/nemo_go/pkg/task/pocscan"
 	"github.com/hanc00l/nemo_go/pkg/task/portscan"
+	"github.com/hanc00l/nemo_go/pkg/task/scope"
+	"github.com/hanc00l/nemo_go/pkg/task/workflow"
 	"github.com/hanc00l/nemo_go/pkg/utils"
 	"github.com/remeh/sizedwaitgroup"
 	"strings"
@@ -50,6 +52,9 @@ type XScanConfig struct {
 	NucleiPocFile string `json:"nucleipocfile,omitempty"`
 	// gobypoc
 	IsGobyPoc bool `json:"gobypoc,omitempty"`
+	// workflow：工作流任务的定义及当前执行的步骤
+	Workflow     *workflow.Definition `json:"workflow,omitempty"`
+	WorkflowStep string               `json:"workflowStep,omitempty"`
 }
 
 type XScan struct {
@@ -171,6 +176,14 @@ func XOnlineAPI(taskId, mainTaskId, configJSON string) (result string, err error
 		logging.RuntimeLog.Error(err)
 		return FailedTask(err.Error()), err
 	}
+	// 授权范围外的结果不作为后续任务的目标
+	if s, err := loadWorkspaceScope(config.WorkspaceId); err == nil {
+		removeOutOfScopeResult(s, &scan.ResultIP, &scan.ResultDomain)
+	}
+	// 工作流任务：由工作流的定义生成后续步骤的任务
+	if isWorkflow, workflowResult, workflowErr := scan.runWorkflowNextSteps(taskId, mainTaskId, result); isWorkflow {
+		return workflowResult, workflowErr
+	}
 	// 执行portscan与domainscan
 	ipPortMap, domainMap := MakeSubTaskTarget(&scan.ResultIP, &scan.ResultDomain)
 	_, err = scan.NewPortScan(taskId, mainTaskId, ipPortMap, nil)
@@ -201,6 +214,14 @@ func XPortScan(taskId, mainTaskId, configJSON string) (result string, err error)
 		logging.RuntimeLog.Error(err)
 		return FailedTask(err.Error()), err
 	}
+	// 去除授权范围外的目标
+	var s *scope.Scope
+	if s, ok, result, err = checkTaskScope(taskId, config.WorkspaceId); !ok {
+		return result, err
+	}
+	if !filterScopeXScanConfig(taskId, s, &config) {
+		return SucceedTask("no target in scope"), nil
+	}
 	// 执行任务
 	scan := NewXScan(config)
 	result, err = scan.Portscan(taskId, mainTaskId)
@@ -208,6 +229,10 @@ func XPortScan(taskId, mainTaskId, configJSON string) (result string, err error)
 		logging.RuntimeLog.Error(err)
 		return FailedTask(err.Error()), err
 	}
+	// 工作流任务：由工作流的定义生成后续步骤的任务
+	if isWorkflow, workflowResult, workflowErr := scan.runWorkflowNextSteps(taskId, mainTaskId, result); isWorkflow {
+		return workflowResult, workflowErr
+	}
 	// 启动指纹识别任务：
 	if config.IsFingerprint {
 		_, err = scan.NewFingerprintScan(taskId, mainTaskId)
@@ -240,6 +265,14 @@ func XDomainscan(taskId, mainTaskId, configJSON string) (result string, err erro
 		logging.RuntimeLog.Error(err)
 		return FailedTask(err.Error()), err
 	}
+	// 授权范围外的结果不作为后续任务的目标
+	if s, err := loadWorkspaceScope(config.WorkspaceId); err == nil {
+		removeOutOfScopeResult(s, &scan.ResultIP, &scan.ResultDomain)
+	}
+	// 工作流任务：由工作流的定义生成后续步骤的任务
+	if isWorkflow, workflowResult, workflowErr := scan.runWorkflowNextSteps(taskId, mainTaskId, result); isWorkflow {
+		return workflowResult, workflowErr
+	}
 	// 启动指纹识别任务：
 	if config.IsFingerprint {
 		_, err = scan.NewFingerprintScan(taskId, mainTaskId)
@@ -264,6 +297,14 @@ func XFingerPrint(taskId, mainTaskId, configJSON string) (result string, err err
 		logging.RuntimeLog.Error(err)
 		return FailedTask(err.Error()), err
 	}
+	// 去除授权范围外的目标
+	var s *scope.Scope
+	if s, ok, result, err = checkTaskScope(taskId, config.WorkspaceId); !ok {
+		return result, err
+	}
+	if !filterScopeXScanConfig(taskId, s, &config) {
+		return SucceedTask("no target in scope"), nil
+	}
 	// 执行任务
 	scan := NewXScan(config)
 	result, err = scan.FingerPrint(taskId, mainTaskId)
@@ -271,6 +312,10 @@ func XFingerPrint(taskId, mainTaskId, configJSON string) (result string, err err
 		logging.RuntimeLog.Error(err)
 		return FailedTask(err.Error()), err
 	}
+	// 工作流任务：由工作流的定义生成后续步骤的任务
+	if isWorkflow, workflowResult, workflowErr := scan.runWorkflowNextSteps(taskId, mainTaskId, result); isWorkflow {
+		return workflowResult, workflowErr
+	}
 	// 启动XrayPoc任务
 	if config.IsXrayPoc {
 		_, err = scan.NewXrayScan(taskId, mainTaskId)
@@ -313,6 +358,14 @@ func XXray(taskId, mainTaskId, configJSON string) (result string, err error) {
 		logging.RuntimeLog.Error(err)
 		return FailedTask(err.Error()), err
 	}
+	// 去除授权范围外的目标
+	var s *scope.Scope
+	if s, ok, result, err = checkTaskScope(taskId, config.WorkspaceId); !ok {
+		return result, err
+	}
+	if !filterScopeXScanConfig(taskId, s, &config) {
+		return SucceedTask("no target in scope"), nil
+	}
 	// 执行任务
 	scan := NewXScan(config)
 	result, err = scan.XrayScan(taskId, mainTaskId)
@@ -336,6 +389,14 @@ func XNuclei(taskId, mainTaskId, configJSON string) (result string, err error) {
 		logging.RuntimeLog.Error(err)
 		return FailedTask(err.Error()), err
 	}
+	// 去除授权范围外的目标
+	var s *scope.Scope
+	if s, ok, result, err = checkTaskScope(taskId, config.WorkspaceId); !ok {
+		return result, err
+	}
+	if !filterScopeXScanConfig(taskId, s, &config) {
+		return SucceedTask("no target in scope"), nil
+	}
 	// 执行任务
 	scan := NewXScan(config)
 	result, err = scan.NucleiScan(taskId, mainTaskId)
@@ -359,6 +420,14 @@ func XGoby(taskId, mainTaskId, configJSON string) (result string, err error) {
 		logging.RuntimeLog.Error(err)
 		return FailedTask(err.Error()), err
 	}
+	// 去除授权范围外的目标
+	var s *scope.Scope
+	if s, ok, result, err = checkTaskScope(taskId, config.WorkspaceId); !ok {
+		return result, err
+	}
+	if !filterScopeXScanConfig(taskId, s, &config) {
+		return SucceedTask("no target in scope"), nil
+	}
 	// 执行任务
 	scan := NewXScan(config)
 	result, err = scan.GobyScan(taskId, mainTaskId)
@@ -424,7 +493,7 @@ func (x *XScan) Portscan(taskId string, mainTaskId string) (result string, err e
 		IPConfig:   &portscan.Config{OrgId: config.OrgId, WorkspaceId: config.WorkspaceId},
 		IPResult:   x.ResultIP.IPResult,
 	}
-	err = comm.CallXClient("SaveScanResult", &resultArgs, &result)
+	err = comm.CallXClientWithSpool("SaveScanResult", &resultArgs, &result)
 	if err != nil {
 		logging.RuntimeLog.Error(err)
 	}
@@ -442,6 +511,10 @@ func (x *XScan) doPortscan(swg *sizedwaitgroup.SizedWaitGroup, config portscan.C
 		m := portscan.NewMasscan(config)
 		m.Do()
 		result.IPResult = m.Result.IPResult
+	} else if config.CmdBin == portscan.TCPScanCmdBin {
+		m := portscan.NewTCPScan(config)
+		m.Do()
+		result.IPResult = m.Result.IPResult
 	} else {
 		m := portscan.NewNmap(config)
 		m.Do()
@@ -449,7 +522,7 @@ func (x *XScan) doPortscan(swg *sizedwaitgroup.SizedWaitGroup, config portscan.C
 	}
 
 	//增加ip归属地查询,先判断是否合规，再进行查询归属地
-	if utils.CheckIPV4Subnet(config.Target) == false {
+	if utils.CheckIPSubnet(config.Target) == false {
 		doLocation(&result)
 	}
 
@@ -590,7 +663,7 @@ func (x *XScan) Domainscan(taskId string, mainTaskId string) (result string, err
 		DomainConfig: &domainscan.Config{OrgId: config.OrgId, WorkspaceId: x.Config.WorkspaceId},
 		DomainResult: x.ResultDomain.DomainResult,
 	}
-	if err = comm.CallXClient("SaveScanResult", &resultArgs, &result); err != nil {
+	if err = comm.CallXClientWithSpool("SaveScanResult", &resultArgs, &result); err != nil {
 		logging.RuntimeLog.Error(err)
 	}
 	return
@@ -799,7 +872,7 @@ func (x *XScan) NucleiScan(taskId string, mainTaskId string) (result string, err
 		for ip, ports := range x.Config.IPPort {
 			for _, port := range ports {
 				runConfig := config
-				runConfig.Target = fmt.Sprintf("%s:%d", ip, port)
+				runConfig.Target = utils.FormatHostPort(ip, port)
 				swg.Add()
 				go x.doNucleiScan(&swg, runConfig)
 			}
@@ -820,7 +893,7 @@ func (x *XScan) NucleiScan(taskId string, mainTaskId string) (result string, err
 		MainTaskId:          mainTaskId,
 		VulnerabilityResult: x.ResultVul,
 	}
-	err = comm.CallXClient("SaveVulnerabilityResult", &resultArgs, &result)
+	err = comm.CallXClientWithSpool("SaveVulnerabilityResult", &resultArgs, &result)
 	if err != nil {
 		logging.RuntimeLog.Error(err)
 	}
@@ -837,7 +910,7 @@ func (x *XScan) GobyScan(taskId string, mainTaskId string) (result string, err e
 		var targets []string
 		for ip, ports := range x.Config.IPPort {
 			for _, port := range ports {
-				targets = append(targets, fmt.Sprintf("%s:%d", ip, port))
+				targets = append(targets, utils.FormatHostPort(ip, port))
 			}
 		}
 		runConfig := config
@@ -862,7 +935,7 @@ func (x *XScan) GobyScan(taskId string, mainTaskId string) (result string, err e
 		MainTaskId:          mainTaskId,
 		VulnerabilityResult: x.ResultVul,
 	}
-	err = comm.CallXClient("SaveVulnerabilityResult", &resultArgs, &result)
+	err = comm.CallXClientWithSpool("SaveVulnerabilityResult", &resultArgs, &result)
 	if err != nil {
 		logging.RuntimeLog.Error(err)
 	}
@@ -904,7 +977,7 @@ func (x *XScan) XrayScan(taskId string, mainTaskId string) (result string, err e
 		for ip, ports := range x.Config.IPPort {
 			for _, port := range ports {
 				runConfig := config
-				runConfig.Target = fmt.Sprintf("%s:%d", ip, port)
+				runConfig.Target = utils.FormatHostPort(ip, port)
 				swg.Add()
 				go x.doXrayscan(&swg, runConfig)
 			}
@@ -925,7 +998,7 @@ func (x *XScan) XrayScan(taskId string, mainTaskId string) (result string, err e
 		MainTaskId:          mainTaskId,
 		VulnerabilityResult: x.ResultVul,
 	}
-	err = comm.CallXClient("SaveVulnerabilityResult", &resultArgs, &result)
+	err = comm.CallXClientWithSpool("SaveVulnerabilityResult", &resultArgs, &result)
 	if err != nil {
 		logging.RuntimeLog.Error(err)
 	}
@@ -0,0 +1,117 @@
+package workflow
+
+import (
+	"github.com/hanc00l/nemo_go/pkg/task/domainscan"
+	"github.com/hanc00l/nemo_go/pkg/task/portscan"
+	"regexp"
+)
+
+// fingerprintTags 指纹条件匹配的属性
+var fingerprintTags = map[string]struct{}{
+	"fingerprint": {},
+	"server":      {},
+	"service":     {},
+	"banner":      {},
+	"midware":     {},
+}
+
+// Condition 步骤的执行条件：只有满足条件的上游结果才作为该步骤的目标；多个条件同时满足
+type Condition struct {
+	// 端口，如"80,443,8000-8100"，只对IP有效
+	Port string `json:"port,omitempty"`
+	// 指纹、title的正则表达式（忽略大小写）
+	Fingerprint string `json:"fingerprint,omitempty"`
+	Title       string `json:"title,omitempty"`
+
+	ports         map[int]struct{}
+	fingerprintRe *regexp.Regexp
+	titleRe       *regexp.Regexp
+	compiled      bool
+}
+
+// compile 解析条件中的端口与正则表达式
+func (c *Condition) compile() (err error) {
+	if c == nil || c.compiled {
+		return nil
+	}
+	if c.Port != "" {
+		if c.ports, err = ParsePorts(c.Port); err != nil {
+			return
+		}
+	}
+	if c.fingerprintRe, err = compileRegexp(c.Fingerprint); err != nil {
+		return
+	}
+	if c.titleRe, err = compileRegexp(c.Title); err != nil {
+		return
+	}
+	c.compiled = true
+	return
+}
+
+// matchAttrs 检查属性是否满足指纹与title条件
+func (c *Condition) matchAttrs(tags, contents []string) bool {
+	fingerprintMatched, titleMatched := c.fingerprintRe == nil, c.titleRe == nil
+	for i, tag := range tags {
+		if _, ok := fingerprintTags[tag]; ok && !fingerprintMatched {
+			fingerprintMatched = c.fingerprintRe.MatchString(contents[i])
+		}
+		if tag == "title" && !titleMatched {
+			titleMatched = c.titleRe.MatchString(contents[i])
+		}
+	}
+	return fingerprintMatched && titleMatched
+}
+
+// FilterIP 过滤出满足条件的IP及端口，条件为空时返回全部结果
+func (c *Condition) FilterIP(ipResult map[string]*portscan.IPResult) map[string]*portscan.IPResult {
+	if c == nil {
+		return ipResult
+	}
+	if err := c.compile(); err != nil {
+		return nil
+	}
+	result := make(map[string]*portscan.IPResult)
+	for ip, ipr := range ipResult {
+		ports := make(map[int]*portscan.PortResult)
+		for port, pr := range ipr.Ports {
+			if _, ok := c.ports[port]; len(c.ports) > 0 && !ok {
+				continue
+			}
+			var tags, contents []string
+			for _, attr := range pr.PortAttrs {
+				tags = append(tags, attr.Tag)
+				contents = append(contents, attr.Content)
+			}
+			if c.matchAttrs(tags, contents) {
+				ports[port] = pr
+			}
+		}
+		if len(ports) > 0 {
+			result[ip] = &portscan.IPResult{OrgId: ipr.OrgId, Location: ipr.Location, Status: ipr.Status, Ports: ports}
+		}
+	}
+	return result
+}
+
+// FilterDomain 过滤出满足条件的域名，端口条件对域名无效，条件为空时返回全部结果
+func (c *Condition) FilterDomain(domainResult map[string]*domainscan.DomainResult) map[string]*domainscan.DomainResult {
+	if c == nil {
+		return domainResult
+	}
+	if err := c.compile(); err != nil {
+		return nil
+	}
+	result := make(map[string]*domainscan.DomainResult)
+	for domain, dr := range domainResult {
+		var tags, contents []string
+		for _, attr := range dr.DomainAttrs {
+			tags = append(tags, attr.Tag)
+			contents = append(contents, attr.Content)
+		}
+		if c.matchAttrs(tags, contents) {
+			result[domain] = dr
+		}
+	}
+	return result
+}
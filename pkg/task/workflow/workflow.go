@@ -0,0 +1,241 @@
+package workflow
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"github.com/hanc00l/nemo_go/pkg/task/ampq"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// 工作流步骤支持的任务类型
+const (
+	TaskOnlineAPI   = "onlineapi"
+	TaskPortscan    = "portscan"
+	TaskDomainscan  = "domainscan"
+	TaskFingerprint = "fingerprint"
+	TaskXray        = "xray"
+	TaskNuclei      = "nuclei"
+	TaskGoby        = "goby"
+)
+
+// rootTasks 可以作为起始步骤（直接使用任务目标）的任务类型
+var rootTasks = map[string]struct{}{
+	TaskOnlineAPI:  {},
+	TaskPortscan:   {},
+	TaskDomainscan: {},
+}
+
+// leafTasks 漏洞验证的任务类型，不产生资产结果，因此不能有后续步骤
+var leafTasks = map[string]struct{}{
+	TaskXray:   {},
+	TaskNuclei: {},
+	TaskGoby:   {},
+}
+
+// Definition 工作流的定义：由任务步骤组成的树，每个步骤最多有一个上游步骤
+type Definition struct {
+	Steps []Step `json:"steps"`
+}
+
+// Step 工作流的一个步骤
+type Step struct {
+	Id        string     `json:"id"`
+	Task      string     `json:"task"`
+	Parent    string     `json:"parent,omitempty"`
+	Condition *Condition `json:"condition,omitempty"`
+	Params    Params     `json:"params,omitempty"`
+	// Tag 执行该步骤任务的worker标签，为空时使用主任务的worker标签
+	Tag string `json:"tag,omitempty"`
+}
+
+// Params 步骤的任务参数
+type Params struct {
+	// portscan：端口，为空时使用worker的默认配置
+	Port string `json:"port,omitempty"`
+	// onlineapi：fofa、hunter、quake，默认为fofa
+	Engine string `json:"engine,omitempty"`
+	// domainscan：子域名的获取方式，都为false时只进行域名解析
+	SubFinder        bool `json:"subfinder,omitempty"`
+	SubDomainBrute   bool `json:"subdomainBrute,omitempty"`
+	SubDomainCrawler bool `json:"subdomainCrawler,omitempty"`
+	// xray、nuclei：poc文件
+	PocFile string `json:"pocfile,omitempty"`
+}
+
+// Parse 解析并检查工作流的定义
+func Parse(content string) (*Definition, error) {
+	var d Definition
+	if err := json.Unmarshal([]byte(content), &d); err != nil {
+		return nil, fmt.Errorf("invalid workflow definition:%v", err)
+	}
+	if err := d.Validate(); err != nil {
+		return nil, err
+	}
+	return &d, nil
+}
+
+// Validate 检查步骤、上游步骤及条件的合法性，并确保步骤组成一棵树（不存在循环）
+func (d *Definition) Validate() error {
+	if len(d.Steps) == 0 {
+		return errors.New("workflow has no step")
+	}
+	steps := make(map[string]*Step)
+	for i := range d.Steps {
+		s := &d.Steps[i]
+		if s.Id = strings.TrimSpace(s.Id); s.Id == "" {
+			return fmt.Errorf("step %d has no id", i+1)
+		}
+		if _, ok := steps[s.Id]; ok {
+			return fmt.Errorf("duplicate step id:%s", s.Id)
+		}
+		switch s.Task {
+		case TaskOnlineAPI, TaskPortscan, TaskDomainscan, TaskFingerprint, TaskXray, TaskNuclei, TaskGoby:
+		default:
+			return fmt.Errorf("step %s has invalid task:%s", s.Id, s.Task)
+		}
+		if s.Task == TaskOnlineAPI && s.Params.Engine != "" && OnlineAPITaskName(s.Params.Engine) == "" {
+			return fmt.Errorf("step %s has invalid engine:%s", s.Id, s.Params.Engine)
+		}
+		if s.Tag != "" && !ampq.CheckWorkerTag(s.Tag) {
+			return fmt.Errorf("step %s has invalid tag:%s", s.Id, s.Tag)
+		}
+		if err := s.Condition.compile(); err != nil {
+			return fmt.Errorf("step %s has invalid condition:%v", s.Id, err)
+		}
+		steps[s.Id] = s
+	}
+	for _, s := range d.Steps {
+		if s.Parent == "" {
+			if _, ok := rootTasks[s.Task]; !ok {
+				return fmt.Errorf("step %s (%s) must have parent step", s.Id, s.Task)
+			}
+			if s.Condition != nil {
+				return fmt.Errorf("step %s has no parent but condition", s.Id)
+			}
+			continue
+		}
+		if s.Task == TaskOnlineAPI {
+			return fmt.Errorf("step %s (%s) can only be start step", s.Id, s.Task)
+		}
+		parent, ok := steps[s.Parent]
+		if !ok {
+			return fmt.Errorf("step %s has unknown parent:%s", s.Id, s.Parent)
+		}
+		if _, ok = leafTasks[parent.Task]; ok {
+			return fmt.Errorf("step %s can not be child of %s (%s)", s.Id, s.Parent, parent.Task)
+		}
+	}
+	if _, err := d.Levels(); err != nil {
+		return err
+	}
+	return nil
+}
+
+// Step 根据id获取步骤
+func (d *Definition) Step(id string) *Step {
+	for i := range d.Steps {
+		if d.Steps[i].Id == id {
+			return &d.Steps[i]
+		}
+	}
+	return nil
+}
+
+// Roots 获取起始步骤
+func (d *Definition) Roots() (steps []Step) {
+	for _, s := range d.Steps {
+		if s.Parent == "" {
+			steps = append(steps, s)
+		}
+	}
+	return
+}
+
+// Children 获取上游为指定步骤的后续步骤
+func (d *Definition) Children(id string) (steps []Step) {
+	for _, s := range d.Steps {
+		if s.Parent == id {
+			steps = append(steps, s)
+		}
+	}
+	return
+}
+
+// Levels 按树的深度对步骤进行分层，每个步骤位于其上游步骤的下一层
+func (d *Definition) Levels() (levels [][]Step, err error) {
+	level := make(map[string]int)
+	for len(level) < len(d.Steps) {
+		changed := false
+		for _, s := range d.Steps {
+			if _, ok := level[s.Id]; ok {
+				continue
+			}
+			l, ready := 0, true
+			if s.Parent != "" {
+				pl, ok := level[s.Parent]
+				l, ready = pl+1, ok
+			}
+			if ready {
+				level[s.Id] = l
+				changed = true
+				if l >= len(levels) {
+					levels = append(levels, nil)
+				}
+				levels[l] = append(levels[l], s)
+			}
+		}
+		if !changed {
+			return nil, errors.New("workflow has circular parent")
+		}
+	}
+	return
+}
+
+// OnlineAPITaskName 在线资产平台对应的任务名称
+func OnlineAPITaskName(engine string) string {
+	switch engine {
+	case "", "fofa":
+		return "xfofa"
+	case "hunter":
+		return "xhunter"
+	case "quake":
+		return "xquake"
+	}
+	return ""
+}
+
+// ParsePorts 解析条件中的端口，如"80,443,8000-8100"
+func ParsePorts(ports string) (map[int]struct{}, error) {
+	result := make(map[int]struct{})
+	for _, p := range strings.Split(ports, ",") {
+		if p = strings.TrimSpace(p); p == "" {
+			continue
+		}
+		begin, end, isRange := strings.Cut(p, "-")
+		start, err := strconv.Atoi(strings.TrimSpace(begin))
+		if err != nil || start <= 0 || start > 65535 {
+			return nil, fmt.Errorf("invalid port:%s", p)
+		}
+		stop := start
+		if isRange {
+			if stop, err = strconv.Atoi(strings.TrimSpace(end)); err != nil || stop < start || stop > 65535 {
+				return nil, fmt.Errorf("invalid port:%s", p)
+			}
+		}
+		for i := start; i <= stop; i++ {
+			result[i] = struct{}{}
+		}
+	}
+	return result, nil
+}
+
+// compileRegexp 编译条件中的正则表达式（忽略大小写）
+func compileRegexp(expr string) (*regexp.Regexp, error) {
+	if expr == "" {
+		return nil, nil
+	}
+	return regexp.Compile("(?i)" + expr)
+}
@@ -0,0 +1,93 @@
+package workflow
+
+import (
+	"github.com/hanc00l/nemo_go/pkg/task/domainscan"
+	"github.com/hanc00l/nemo_go/pkg/task/portscan"
+	"testing"
+)
+
+const testDefinition = `{"steps":[
+	{"id":"port","task":"portscan","params":{"port":"--top-ports 1000"}},
+	{"id":"finger","task":"fingerprint","parent":"port"},
+	{"id":"nuclei","task":"nuclei","parent":"finger","condition":{"fingerprint":"weblogic"},"params":{"pocfile":"weblogic"}},
+	{"id":"xray","task":"xray","parent":"finger","condition":{"port":"80,8000-8100"}}
+]}`
+
+func TestParse(t *testing.T) {
+	d, err := Parse(testDefinition)
+	if err != nil {
+		t.Fatal(err)
+	}
+	levels, err := d.Levels()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(levels) != 3 || levels[0][0].Id != "port" || levels[1][0].Id != "finger" || len(levels[2]) != 2 {
+		t.Errorf("invalid levels:%v", levels)
+	}
+	if roots := d.Roots(); len(roots) != 1 || roots[0].Id != "port" {
+		t.Errorf("invalid roots:%v", roots)
+	}
+	if children := d.Children("finger"); len(children) != 2 {
+		t.Errorf("invalid children:%v", children)
+	}
+}
+
+func TestParseInvalid(t *testing.T) {
+	definitions := map[string]string{
+		"empty":     `{"steps":[]}`,
+		"task":      `{"steps":[{"id":"a","task":"unknown"}]}`,
+		"duplicate": `{"steps":[{"id":"a","task":"portscan"},{"id":"a","task":"domainscan"}]}`,
+		"root":      `{"steps":[{"id":"a","task":"nuclei"}]}`,
+		"parent":    `{"steps":[{"id":"a","task":"portscan"},{"id":"b","task":"nuclei","parent":"c"}]}`,
+		"leaf":      `{"steps":[{"id":"a","task":"portscan"},{"id":"b","task":"nuclei","parent":"a"},{"id":"c","task":"goby","parent":"b"}]}`,
+		"circular":  `{"steps":[{"id":"r","task":"portscan"},{"id":"a","task":"fingerprint","parent":"b"},{"id":"b","task":"fingerprint","parent":"a"}]}`,
+		"multiple":  `{"steps":[{"id":"a","task":"portscan"},{"id":"b","task":"domainscan"},{"id":"c","task":"nuclei","parent":["a","b"]}]}`,
+		"regexp":    `{"steps":[{"id":"a","task":"portscan"},{"id":"b","task":"nuclei","parent":"a","condition":{"title":"("}}]}`,
+		"port":      `{"steps":[{"id":"a","task":"portscan"},{"id":"b","task":"nuclei","parent":"a","condition":{"port":"80-"}}]}`,
+		"engine":    `{"steps":[{"id":"a","task":"onlineapi","params":{"engine":"shodan"}}]}`,
+		"tag":       `{"steps":[{"id":"a","task":"portscan","tag":"dmz.1"}]}`,
+	}
+	for name, content := range definitions {
+		if _, err := Parse(content); err == nil {
+			t.Errorf("%s: expected error", name)
+		}
+	}
+}
+
+func TestConditionFilter(t *testing.T) {
+	ipResult := map[string]*portscan.IPResult{
+		"192.168.1.1": {Ports: map[int]*portscan.PortResult{
+			80:   {PortAttrs: []portscan.PortAttrResult{{Tag: "fingerprint", Content: "WebLogic"}, {Tag: "title", Content: "Error 404"}}},
+			443:  {PortAttrs: []portscan.PortAttrResult{{Tag: "server", Content: "nginx"}}},
+			8080: {PortAttrs: []portscan.PortAttrResult{{Tag: "fingerprint", Content: "weblogic"}}},
+		}},
+		"192.168.1.2": {Ports: map[int]*portscan.PortResult{
+			22: {PortAttrs: []portscan.PortAttrResult{{Tag: "service", Content: "ssh"}}},
+		}},
+	}
+	domainResult := map[string]*domainscan.DomainResult{
+		"a.example.com": {DomainAttrs: []domainscan.DomainAttrResult{{Tag: "title", Content: "Oracle WebLogic Server"}}},
+		"b.example.com": {DomainAttrs: []domainscan.DomainAttrResult{{Tag: "A", Content: "192.168.1.1"}}},
+	}
+
+	var c *Condition
+	if len(c.FilterIP(ipResult)) != 2 || len(c.FilterDomain(domainResult)) != 2 {
+		t.Error("nil condition should not filter")
+	}
+	c = &Condition{Fingerprint: "weblogic", Port: "80-100"}
+	ips := c.FilterIP(ipResult)
+	if len(ips) != 1 || len(ips["192.168.1.1"].Ports) != 1 || ips["192.168.1.1"].Ports[80] == nil {
+		t.Errorf("invalid filter ip result:%v", ips)
+	}
+	if len(ipResult["192.168.1.1"].Ports) != 3 {
+		t.Error("filter should not modify the source result")
+	}
+	if domains := c.FilterDomain(domainResult); len(domains) != 0 {
+		t.Errorf("invalid filter domain result:%v", domains)
+	}
+	c = &Condition{Title: "weblogic"}
+	if domains := c.FilterDomain(domainResult); len(domains) != 1 || domains["a.example.com"] == nil {
+		t.Errorf("invalid filter domain result:%v", domains)
+	}
+}
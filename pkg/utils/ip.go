@@ -1,15 +1,22 @@
 package utils
 
 import (
+	"encoding/hex"
 	"errors"
 	"fmt"
 	"math"
+	"math/big"
 	"net"
 	"regexp"
 	"strconv"
 	"strings"
 )
 
+const (
+	// IPV6MaxExpandPrefixLength IPv6子网展开的最小掩码长度（/112即65536个地址），掩码更短的子网不展开为单个IP
+	IPV6MaxExpandPrefixLength = 112
+)
+
 // IPToUInt32 将点分格式的IP地址转换为UINT32
 func IPToUInt32(ip string) uint32 {
 	bits := strings.Split(ip, ".")
@@ -46,6 +53,104 @@ func CheckIPV4Subnet(ip string) bool {
 	return r.MatchString(ip)
 }
 
+// CheckIPV6 检查是否为IPv6地址
+func CheckIPV6(ip string) bool {
+	if !strings.Contains(ip, ":") {
+		return false
+	}
+	return net.ParseIP(ip) != nil
+}
+
+// CheckIPV6Subnet 检查是否为IPv6的子网（如2001:db8::/64）
+func CheckIPV6Subnet(ip string) bool {
+	if !strings.Contains(ip, ":") || !strings.Contains(ip, "/") {
+		return false
+	}
+	_, _, err := net.ParseCIDR(ip)
+	return err == nil
+}
+
+// CheckIP 检查是否为IPv4或IPv6地址
+func CheckIP(ip string) bool {
+	return CheckIPV4(ip) || CheckIPV6(ip)
+}
+
+// CheckIPSubnet 检查是否为IPv4或IPv6的子网
+func CheckIPSubnet(ip string) bool {
+	return CheckIPV4Subnet(ip) || CheckIPV6Subnet(ip)
+}
+
+// CheckIPRange 检查是否为同一地址族的IP范围（如192.168.1.1-192.168.1.5、2001:db8::1-2001:db8::ff）
+func CheckIPRange(ip string) bool {
+	address := strings.Split(ip, "-")
+	if len(address) != 2 {
+		return false
+	}
+	return (CheckIPV4(address[0]) && CheckIPV4(address[1])) || (CheckIPV6(address[0]) && CheckIPV6(address[1]))
+}
+
+// FormatIPV6 将IPv6地址格式化为标准的压缩格式，非IPv6地址原样返回
+func FormatIPV6(ip string) string {
+	if !CheckIPV6(ip) {
+		return ip
+	}
+	return net.ParseIP(ip).String()
+}
+
+// FormatHostPort 组合主机与端口，IPv6地址使用[]包含（如[2001:db8::1]:80）
+func FormatHostPort(host string, port int) string {
+	return net.JoinHostPort(host, strconv.Itoa(port))
+}
+
+// IPToSortString 将IP地址转换为32位的十六进制字符串（IPv4转换为IPv4-mapped的IPv6格式），用于IPv4与IPv6的统一排序与范围查询
+func IPToSortString(ip string) string {
+	netIP := net.ParseIP(ip)
+	if netIP == nil {
+		return ""
+	}
+	return hex.EncodeToString(netIP.To16())
+}
+
+// IPSubnetToSortRange 获取子网起始与结束地址的排序字符串
+func IPSubnetToSortRange(subnet string) (start, end string, err error) {
+	_, ipNet, err := net.ParseCIDR(subnet)
+	if err != nil {
+		return
+	}
+	startIP := ipNet.IP.To16()
+	endIP := make(net.IP, len(startIP))
+	mask := ipNet.Mask
+	// IPv4的掩码只有4个字节，需要对齐到IPv6的后4个字节
+	offset := len(startIP) - len(mask)
+	for i := range startIP {
+		if i < offset {
+			endIP[i] = startIP[i]
+		} else {
+			endIP[i] = startIP[i] | ^mask[i-offset]
+		}
+	}
+	return hex.EncodeToString(startIP), hex.EncodeToString(endIP), nil
+}
+
+// IPTargetToSortRange 获取IP、子网或IP范围的起始与结束地址的排序字符串
+func IPTargetToSortRange(target string) (start, end string, ok bool) {
+	if CheckIP(target) {
+		start = IPToSortString(target)
+		return start, start, true
+	}
+	if CheckIPSubnet(target) {
+		var err error
+		start, end, err = IPSubnetToSortRange(target)
+		return start, end, err == nil
+	}
+	if CheckIPRange(target) {
+		address := strings.Split(target, "-")
+		start, end = IPToSortString(address[0]), IPToSortString(address[1])
+		return start, end, start <= end
+	}
+	return
+}
+
 func GetOutBoundIP() (ip string, err error) {
 	conn, err := net.Dial("udp", "8.8.8.8:53")
 	if err != nil {
@@ -102,10 +207,83 @@ func ParseIP(ip string) (ipResults []string) {
 		}
 		return
 	}
+	//2001:db8::1
+	if CheckIPV6(ip) {
+		return []string{FormatIPV6(ip)}
+	}
+	//2001:db8::/120，掩码过短的子网不展开
+	if CheckIPV6Subnet(ip) {
+		_, ipv6sub, err := net.ParseCIDR(ip)
+		if err != nil {
+			return
+		}
+		ones, bits := ipv6sub.Mask.Size()
+		if ones < IPV6MaxExpandPrefixLength {
+			return []string{ipv6sub.String()}
+		}
+		ipStart := new(big.Int).SetBytes(ipv6sub.IP.To16())
+		ipSize := 1 << (bits - ones)
+		for i := 0; i < ipSize; i++ {
+			ipResults = append(ipResults, bigIntToIPV6(new(big.Int).Add(ipStart, big.NewInt(int64(i)))))
+		}
+		return
+	}
+	//2001:db8::1-2001:db8::ff
+	if len(address) == 2 && CheckIPV6(address[0]) && CheckIPV6(address[1]) {
+		ipStart := new(big.Int).SetBytes(net.ParseIP(address[0]).To16())
+		ipEnd := new(big.Int).SetBytes(net.ParseIP(address[1]).To16())
+		//过大的范围与子网一样不展开
+		maxSize := big.NewInt(1 << (128 - IPV6MaxExpandPrefixLength))
+		if new(big.Int).Sub(ipEnd, ipStart).Cmp(maxSize) >= 0 {
+			return []string{FormatIPV6(address[0]) + "-" + FormatIPV6(address[1])}
+		}
+		for i := ipStart; i.Cmp(ipEnd) <= 0; i = new(big.Int).Add(i, big.NewInt(1)) {
+			ipResults = append(ipResults, bigIntToIPV6(i))
+		}
+		return
+	}
 
 	return
 }
 
+// CheckIPV6Unexpanded 是否为ParseIP未展开的IPv6子网或范围
+func CheckIPV6Unexpanded(ip string) bool {
+	return CheckIPV6Subnet(ip) || (CheckIPRange(ip) && strings.Contains(ip, ":"))
+}
+
+// IPV6Bounds 获取IPv6地址、子网或范围的起止地址
+func IPV6Bounds(ip string) (start, end *big.Int, ok bool) {
+	if CheckIPV6(ip) {
+		start = new(big.Int).SetBytes(net.ParseIP(ip).To16())
+		return start, start, true
+	}
+	if CheckIPV6Subnet(ip) {
+		_, ipv6sub, err := net.ParseCIDR(ip)
+		if err != nil {
+			return nil, nil, false
+		}
+		ones, bits := ipv6sub.Mask.Size()
+		start = new(big.Int).SetBytes(ipv6sub.IP.To16())
+		size := new(big.Int).Lsh(big.NewInt(1), uint(bits-ones))
+		return start, new(big.Int).Sub(new(big.Int).Add(start, size), big.NewInt(1)), true
+	}
+	if CheckIPRange(ip) && strings.Contains(ip, ":") {
+		address := strings.Split(ip, "-")
+		start = new(big.Int).SetBytes(net.ParseIP(address[0]).To16())
+		end = new(big.Int).SetBytes(net.ParseIP(address[1]).To16())
+		return start, end, start.Cmp(end) <= 0
+	}
+	return nil, nil, false
+}
+
+// bigIntToIPV6 将big.Int转换为IPv6地址
+func bigIntToIPV6(i *big.Int) string {
+	b := i.Bytes()
+	ip := make(net.IP, net.IPv6len)
+	copy(ip[net.IPv6len-len(b):], b)
+	return ip.String()
+}
+
 // CheckIPLocationInChinaMainLand 根据IP归属地判断是否是属于中国大陆的IP地区
 func CheckIPLocationInChinaMainLand(ipLocation string) bool {
 	//如果无IP定位，无法判断返回true
@@ -1,6 +1,9 @@
 package utils
 
-import "testing"
+import (
+	"math/big"
+	"testing"
+)
 
 func TestCheckIPV4(t *testing.T) {
 	t.Log(CheckIPV4("192.168.1.1"))
@@ -52,3 +55,66 @@ func TestCheckIPLocationInChinaMainLand(t *testing.T) {
 	t.Log(data7, CheckIPLocationInChinaMainLand(data7))
 
 }
+
+func TestCheckIPV6(t *testing.T) {
+	for ip, expected := range map[string]bool{
+		"2001:db8::1":    true,
+		"::1":            true,
+		"2001:db8::/64":  false,
+		"192.168.1.1":    false,
+		"2001:db8::zzzz": false,
+	} {
+		if CheckIPV6(ip) != expected {
+			t.Errorf("CheckIPV6(%s) should be %v", ip, expected)
+		}
+	}
+	if !CheckIPV6Subnet("2001:db8::/64") || CheckIPV6Subnet("192.168.1.0/24") {
+		t.Error("CheckIPV6Subnet fail")
+	}
+	if !CheckIPRange("2001:db8::1-2001:db8::5") || CheckIPRange("192.168.1.1-2001:db8::5") {
+		t.Error("CheckIPRange fail")
+	}
+}
+
+func TestParseIPV6(t *testing.T) {
+	if r := ParseIP("2001:0db8:0000::0001"); len(r) != 1 || r[0] != "2001:db8::1" {
+		t.Errorf("parse single ipv6 fail:%v", r)
+	}
+	if r := ParseIP("2001:db8::/126"); len(r) != 4 || r[3] != "2001:db8::3" {
+		t.Errorf("parse ipv6 subnet fail:%v", r)
+	}
+	if r := ParseIP("2001:db8::/64"); len(r) != 1 || r[0] != "2001:db8::/64" {
+		t.Errorf("large ipv6 subnet should not be expanded:%v", r)
+	}
+	if r := ParseIP("2001:db8::fe-2001:db8::101"); len(r) != 4 || r[0] != "2001:db8::fe" || r[3] != "2001:db8::101" {
+		t.Errorf("parse ipv6 range fail:%v", r)
+	}
+	if r := ParseIP("2001:db8::1-2001:db8::1:1"); len(r) != 1 || r[0] != "2001:db8::1-2001:db8::1:1" {
+		t.Errorf("large ipv6 range should not be expanded:%v", r)
+	}
+	if start, end, ok := IPV6Bounds("2001:db8::/112"); !ok || new(big.Int).Sub(end, start).Int64() != 65535 {
+		t.Errorf("ipv6 subnet bounds fail:%v-%v", start, end)
+	}
+}
+
+func TestIPToSortString(t *testing.T) {
+	ipv4 := IPToSortString("10.0.0.1")
+	ipv6 := IPToSortString("2001:db8::1")
+	if ipv4 != "00000000000000000000ffff0a000001" {
+		t.Errorf("ipv4 sort string error:%s", ipv4)
+	}
+	if len(ipv6) != 32 || ipv4 >= ipv6 {
+		t.Errorf("ipv6 sort string error:%s", ipv6)
+	}
+	start, end, err := IPSubnetToSortRange("2001:db8::/120")
+	if err != nil || start != "20010db8000000000000000000000000" || end != "20010db80000000000000000000000ff" {
+		t.Errorf("ipv6 subnet range error:%s-%s,%v", start, end, err)
+	}
+	start, end, _ = IPSubnetToSortRange("10.0.0.0/24")
+	if start != IPToSortString("10.0.0.0") || end != IPToSortString("10.0.0.255") {
+		t.Errorf("ipv4 subnet range error:%s-%s", start, end)
+	}
+	if FormatHostPort("2001:db8::1", 80) != "[2001:db8::1]:80" || FormatHostPort("10.0.0.1", 80) != "10.0.0.1:80" {
+		t.Error("FormatHostPort error")
+	}
+}
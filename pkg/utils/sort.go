@@ -2,6 +2,7 @@ package utils
 
 import (
 	"sort"
+	"strconv"
 	"strings"
 )
 
@@ -100,3 +101,24 @@ func MergeMapStringInt(dstMap map[string]int, srcMap map[string]int) {
 	}
 	return
 }
+
+// CompareVersion 按数字逐段比较“1.2.3”格式的版本号：a小于、等于、大于b时分别返回-1、0、1
+func CompareVersion(a, b string) int {
+	as := strings.Split(strings.TrimPrefix(a, "v"), ".")
+	bs := strings.Split(strings.TrimPrefix(b, "v"), ".")
+	for i := 0; i < len(as) || i < len(bs); i++ {
+		var x, y int
+		if i < len(as) {
+			x, _ = strconv.Atoi(as[i])
+		}
+		if i < len(bs) {
+			y, _ = strconv.Atoi(bs[i])
+		}
+		if x < y {
+			return -1
+		} else if x > y {
+			return 1
+		}
+	}
+	return 0
+}
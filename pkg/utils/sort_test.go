@@ -15,4 +15,17 @@ func TestSetToSlince(t *testing.T) {
 	t.Log(SetToSlice(test2))
 	t.Log(SetToString(test2))
 
-}
\ No newline at end of file
+}
+func TestCompareVersion(t *testing.T) {
+	versions := map[[2]string]int{
+		{"2.9.1", "2.9.10"}: -1,
+		{"v3.0", "2.9.6"}:   1,
+		{"7.93", "7.93.0"}:  0,
+		{"1.9.11", "1.9.4"}: 1,
+	}
+	for v, expected := range versions {
+		if r := CompareVersion(v[0], v[1]); r != expected {
+			t.Errorf("%s,%s:expected %d,got %d", v[0], v[1], expected, r)
+		}
+	}
+}
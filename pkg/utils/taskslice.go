@@ -3,6 +3,7 @@ package utils
 import (
 	"fmt"
 	"github.com/projectdiscovery/mapcidr"
+	"math/big"
 	"net"
 	"sort"
 	"strconv"
@@ -26,6 +27,7 @@ const (
 // TaskSlice 任务切分
 type TaskSlice struct {
 	IpTarget        []string
+	IpExclude       []string
 	DomainTarget    []string
 	Port            string
 	TaskMode        int
@@ -47,21 +49,24 @@ func (t *TaskSlice) DoIpSlice() (target []string, port []string) {
 	if t.PortSliceNumber == 0 {
 		t.PortSliceNumber = DefaultPortSliceNumber
 	}
+	if len(t.IpExclude) > 0 {
+		t.IpTarget = ExcludeIPTarget(t.IpTarget, t.IpExclude)
+	}
 	switch t.TaskMode {
 	case SliceByLine:
 		target = t.IpTarget
 		port = []string{t.Port}
 	case SliceByIP:
-		ipIntMap := parseAllIP(t.IpTarget)
-		target = sliceIP(ipIntMap, t.IpSliceNumber)
+		ipIntMap, ipv6Map := parseAllIP(t.IpTarget)
+		target = append(sliceIP(ipIntMap, t.IpSliceNumber), sliceIPV6(ipv6Map, t.IpSliceNumber)...)
 		port = []string{t.Port}
 	case SliceByPort:
 		portIntMap := parseAllPort(t.Port)
 		port = slicePort(portIntMap, t.PortSliceNumber)
 		target = t.IpTarget
 	case SliceByIPAndPort:
-		ipIntMap := parseAllIP(t.IpTarget)
-		target = sliceIP(ipIntMap, t.IpSliceNumber)
+		ipIntMap, ipv6Map := parseAllIP(t.IpTarget)
+		target = append(sliceIP(ipIntMap, t.IpSliceNumber), sliceIPV6(ipv6Map, t.IpSliceNumber)...)
 		portIntMap := parseAllPort(t.Port)
 		port = slicePort(portIntMap, t.PortSliceNumber)
 	default:
@@ -83,12 +88,104 @@ func (t *TaskSlice) DoDomainSlice() (target []string) {
 	return
 }
 
-// parseAllIP 解析所有的IP，将IP转换为uint32的map结构
-func parseAllIP(targetList []string) (ipIntMap map[int]struct{}) {
+// ExcludeIPTarget 从IP目标中去除排除的IP（IP、CIDR及IP范围）；包含排除IP的目标展开后重新进行cidr聚合
+func ExcludeIPTarget(targetList []string, excludeList []string) (results []string) {
+	excludeIPs := make(map[string]struct{})
+	var excludeStarts, excludeEnds []*big.Int
+	for _, v := range excludeList {
+		for _, ip := range ParseIP(strings.TrimSpace(v)) {
+			// 未展开的IPv6子网及范围
+			if CheckIPV6Unexpanded(ip) {
+				if start, end, ok := IPV6Bounds(ip); ok {
+					excludeStarts = append(excludeStarts, start)
+					excludeEnds = append(excludeEnds, end)
+				}
+				continue
+			}
+			excludeIPs[ip] = struct{}{}
+		}
+	}
+	if len(excludeIPs) == 0 && len(excludeStarts) == 0 {
+		return targetList
+	}
+	return FilterIPTarget(targetList, func(ip string) bool {
+		if _, ok := excludeIPs[ip]; ok {
+			return false
+		}
+		// IPv6地址在排除的子网或范围内时去除，未展开的IPv6子网及范围只在被整个排除时去除
+		start, end, ok := IPV6Bounds(ip)
+		if !ok {
+			return true
+		}
+		for i := range excludeStarts {
+			if excludeStarts[i].Cmp(start) <= 0 && end.Cmp(excludeEnds[i]) <= 0 {
+				return false
+			}
+		}
+		return true
+	})
+}
+
+// FilterIPTarget 按isKeep过滤IP目标（IP、CIDR及IP范围）：目标展开后的全部IP（或未展开的IPv6子网及范围）都保留时保持原目标，否则保留的IP重新进行cidr聚合；
+// 无法解析的目标由isKeep对目标整体进行判断
+func FilterIPTarget(targetList []string, isKeep func(ip string) bool) (results []string) {
+	for _, target := range targetList {
+		ips := ParseIP(target)
+		if len(ips) == 0 {
+			if isKeep(target) {
+				results = append(results, target)
+			}
+			continue
+		}
+		var remains, remainSubnets []string
+		var removed bool
+		for _, ip := range ips {
+			if !isKeep(ip) {
+				removed = true
+			} else if CheckIPV6Unexpanded(ip) {
+				remainSubnets = append(remainSubnets, ip)
+			} else {
+				remains = append(remains, ip)
+			}
+		}
+		if !removed {
+			results = append(results, target)
+			continue
+		}
+		results = append(results, remainSubnets...)
+		if len(remains) > 0 {
+			results = append(results, strings.Split(aggregateCIDRs(remains), ",")...)
+		}
+	}
+	return
+}
+
+// IsExcludedDomain 域名是否为排除的域名或其子域名
+func IsExcludedDomain(domain string, excludeList []string) bool {
+	domain = strings.ToLower(strings.TrimSpace(domain))
+	for _, v := range excludeList {
+		exclude := strings.ToLower(strings.TrimSpace(v))
+		if exclude == "" {
+			continue
+		}
+		if domain == exclude || strings.HasSuffix(domain, "."+exclude) {
+			return true
+		}
+	}
+	return false
+}
+
+// parseAllIP 解析所有的IP，将IPv4转换为uint32的map结构，IPv6地址（及未展开的IPv6子网和范围）单独保存
+func parseAllIP(targetList []string) (ipIntMap map[int]struct{}, ipv6Map map[string]struct{}) {
 	ipIntMap = make(map[int]struct{})
+	ipv6Map = make(map[string]struct{})
 	for _, v := range targetList {
 		ips := ParseIP(v)
 		for _, ip := range ips {
+			if strings.Contains(ip, ":") {
+				ipv6Map[ip] = struct{}{}
+				continue
+			}
 			ipInt := int(IPToUInt32(ip))
 			if _, ok := ipIntMap[ipInt]; !ok {
 				ipIntMap[ipInt] = struct{}{}
@@ -118,6 +215,30 @@ func sliceIP(ipIntMap map[int]struct{}, sliceNumber int) (ips []string) {
 	return
 }
 
+// sliceIPV6 按等量对IPv6进行切分，同时将IP进行cidr聚合；未展开的IPv6子网单独作为一个切分
+func sliceIPV6(ipv6Map map[string]struct{}, sliceNumber int) (ips []string) {
+	var ipList []string
+	for ip := range ipv6Map {
+		if CheckIPV6Unexpanded(ip) {
+			ips = append(ips, ip)
+		} else {
+			ipList = append(ipList, ip)
+		}
+	}
+	sort.Slice(ipList, func(i, j int) bool {
+		return IPToSortString(ipList[i]) < IPToSortString(ipList[j])
+	})
+	for start := 0; start < len(ipList); start += sliceNumber {
+		end := start + sliceNumber
+		if end > len(ipList) {
+			end = len(ipList)
+		}
+		ips = append(ips, aggregateCIDRs(ipList[start:end]))
+	}
+	sort.Strings(ips)
+	return
+}
+
 // splitArray 对数组分组
 func splitArray(arr []int, num int) (segments [][]int) {
 	segments = make([][]int, 0)
@@ -147,17 +268,24 @@ func aggregateCIDRs(ips []string) string {
 	var output []string
 	for _, ip := range ips {
 		cidr := fmt.Sprintf("%s/32", ip)
+		if strings.Contains(ip, ":") {
+			cidr = fmt.Sprintf("%s/128", ip)
+		}
 		_, pCidr, err := net.ParseCIDR(cidr)
 		if err != nil {
 			continue
 		}
 		allCidrs = append(allCidrs, pCidr)
 	}
-	cCidrsIPV4, _ := mapcidr.CoalesceCIDRs(allCidrs)
+	cCidrsIPV4, cCidrsIPV6 := mapcidr.CoalesceCIDRs(allCidrs)
 	for _, cidrIPV4 := range cCidrsIPV4 {
 		s := strings.ReplaceAll(cidrIPV4.String(), "/32", "")
 		output = append(output, s)
 	}
+	for _, cidrIPV6 := range cCidrsIPV6 {
+		s := strings.ReplaceAll(cidrIPV6.String(), "/128", "")
+		output = append(output, s)
+	}
 	return strings.Join(output, ",")
 }
 
@@ -176,6 +304,11 @@ func slicePort(portIntMap map[int]struct{}, sliceNumber int) (ports []string) {
 	return
 }
 
+// ParsePort 解析端口扫描任务的端口参数，返回int的端口列表
+func ParsePort(portstr string) map[int]struct{} {
+	return parseAllPort(portstr)
+}
+
 // parseAllPort 解析Port，返回int的端口列表
 func parseAllPort(portstr string) (portIntMap map[int]struct{}) {
 	portIntMap = make(map[int]struct{})
@@ -44,3 +44,49 @@ func TestNewTaskSlice3(t *testing.T) {
 		}
 	}
 }
+
+func TestNewTaskSliceIPV6(t *testing.T) {
+	ts := NewTaskSlice()
+	ts.TaskMode = SliceByIP
+	ts.Port = "80,443"
+	ts.IpTarget = []string{"1.1.1.1", "2001:db8::/126", "2001:db8::10", "2001:db8:1::/64"}
+	ts.IpSliceNumber = 2
+	target, _ := ts.DoIpSlice()
+	for _, v := range target {
+		t.Log(v)
+	}
+	if len(target) != 5 {
+		t.Errorf("ipv6 slice number error:%d", len(target))
+	}
+}
+
+func TestExcludeIPTarget(t *testing.T) {
+	targets := ExcludeIPTarget([]string{"192.168.1.0/30", "10.0.0.1", "172.16.1.1-172.16.1.2", "2001:db8::/64"}, []string{"192.168.1.1", "10.0.0.0/24", "172.16.1.2"})
+	t.Log(targets)
+	if len(targets) != 4 || targets[0] != "192.168.1.0" || targets[1] != "192.168.1.2/31" || targets[2] != "172.16.1.1" || targets[3] != "2001:db8::/64" {
+		t.Errorf("exclude ip target error:%v", targets)
+	}
+	ts := NewTaskSlice()
+	ts.TaskMode = SliceByIP
+	ts.IpTarget = []string{"192.168.1.0/24"}
+	ts.IpExclude = []string{"192.168.1.128/25"}
+	ts.IpSliceNumber = 256
+	target, _ := ts.DoIpSlice()
+	if len(target) != 1 || target[0] != "192.168.1.0/25" {
+		t.Errorf("task slice exclude error:%v", target)
+	}
+	// 未展开的IPv6范围保留原目标，只在被整个排除时去除
+	targets = ExcludeIPTarget([]string{"2001:db8::1-2001:db8::1:1", "2001:db8:1::1-2001:db8:1::1:1"}, []string{"2001:db8::/64", "2001:db8:1::5"})
+	if len(targets) != 1 || targets[0] != "2001:db8:1::1-2001:db8:1::1:1" {
+		t.Errorf("exclude ipv6 range error:%v", targets)
+	}
+}
+
+func TestIsExcludedDomain(t *testing.T) {
+	excludes := []string{"Example.com", " "}
+	for domain, excluded := range map[string]bool{"a.example.com": true, "example.com": true, "EXAMPLE.COM": true, "example.org": false, "badexample.com": false} {
+		if IsExcludedDomain(domain, excludes) != excluded {
+			t.Errorf("%s: excluded should be %v", domain, excluded)
+		}
+	}
+}
@@ -0,0 +1,249 @@
+package controllers
+
+import (
+	"github.com/hanc00l/nemo_go/pkg/db"
+	"github.com/hanc00l/nemo_go/pkg/logging"
+	"strings"
+)
+
+type AlertRuleController struct {
+	BaseController
+}
+
+type alertRuleRequestParam struct {
+	DatableRequestParam
+	RuleName string `form:"rule_name"`
+	RuleType string `form:"rule_type"`
+}
+
+type alertRuleAddRequestParam struct {
+	RuleName    string `form:"rule_name"`
+	RuleType    string `form:"rule_type"`
+	RuleContent string `form:"rule_content"`
+	Notify      string `form:"notify"`
+	State       string `form:"state"`
+}
+
+type AlertRuleListData struct {
+	Id          int    `json:"id"`
+	Index       int    `json:"index"`
+	RuleName    string `json:"rule_name"`
+	RuleType    string `json:"rule_type"`
+	RuleContent string `json:"rule_content"`
+	Notify      string `json:"notify"`
+	State       string `json:"state"`
+	CreateTime  string `json:"create_time"`
+	UpdateTime  string `json:"update_time"`
+}
+
+type AlertRuleInfo struct {
+	Id          int    `json:"id"`
+	RuleName    string `json:"rule_name"`
+	RuleType    string `json:"rule_type"`
+	RuleContent string `json:"rule_content"`
+	Notify      string `json:"notify"`
+	State       string `json:"state"`
+}
+
+// IndexAction 显示列表页面
+func (c *AlertRuleController) IndexAction() {
+	c.Layout = "base.html"
+	c.TplName = "alert-rule-list.html"
+}
+
+// ListAction 列表的数据
+func (c *AlertRuleController) ListAction() {
+	defer c.ServeJSON()
+
+	req := alertRuleRequestParam{}
+	err := c.ParseForm(&req)
+	if err != nil {
+		logging.RuntimeLog.Error(err.Error())
+	}
+	if req.Length <= 0 {
+		req.Length = 50
+	}
+	if req.Start < 0 {
+		req.Start = 0
+	}
+	c.Data["json"] = c.getListData(req)
+}
+
+// GetAction 一个记录的详细情况
+func (c *AlertRuleController) GetAction() {
+	defer c.ServeJSON()
+
+	rule, ok := c.getRuleOfCurrentWorkspace()
+	if !ok {
+		c.Data["json"] = AlertRuleInfo{}
+		return
+	}
+	c.Data["json"] = AlertRuleInfo{
+		Id:          rule.Id,
+		RuleName:    rule.RuleName,
+		RuleType:    rule.RuleType,
+		RuleContent: rule.RuleContent,
+		Notify:      rule.Notify,
+		State:       rule.State,
+	}
+}
+
+// AddSaveAction 保存新增的记录
+func (c *AlertRuleController) AddSaveAction() {
+	defer c.ServeJSON()
+	if c.CheckMultiAccessRequest([]RequestRole{SuperAdmin, Admin}, false) == false {
+		c.FailedStatus("当前用户权限不允许！")
+		return
+	}
+
+	workspaceId := c.GetCurrentWorkspace()
+	if workspaceId <= 0 {
+		c.FailedStatus("未选择当前的工作空间！")
+		return
+	}
+	req := alertRuleAddRequestParam{}
+	if err := c.ParseForm(&req); err != nil {
+		logging.RuntimeLog.Error(err.Error())
+		c.FailedStatus(err.Error())
+		return
+	}
+	if msg := validateAlertRule(&req); msg != "" {
+		c.FailedStatus(msg)
+		return
+	}
+	rule := db.AlertRule{
+		WorkspaceId: workspaceId,
+		RuleName:    req.RuleName,
+		RuleType:    req.RuleType,
+		RuleContent: req.RuleContent,
+		Notify:      req.Notify,
+		State:       req.State,
+	}
+	c.MakeStatusResponse(rule.Add())
+}
+
+// UpdateAction 更新记录
+func (c *AlertRuleController) UpdateAction() {
+	defer c.ServeJSON()
+	if c.CheckMultiAccessRequest([]RequestRole{SuperAdmin, Admin}, false) == false {
+		c.FailedStatus("当前用户权限不允许！")
+		return
+	}
+
+	rule, ok := c.getRuleOfCurrentWorkspace()
+	if !ok {
+		c.FailedStatus("告警规则不存在！")
+		return
+	}
+	req := alertRuleAddRequestParam{}
+	if err := c.ParseForm(&req); err != nil {
+		logging.RuntimeLog.Error(err.Error())
+		c.FailedStatus(err.Error())
+		return
+	}
+	if msg := validateAlertRule(&req); msg != "" {
+		c.FailedStatus(msg)
+		return
+	}
+	updateMap := make(map[string]interface{})
+	updateMap["rule_name"] = req.RuleName
+	updateMap["rule_type"] = req.RuleType
+	updateMap["rule_content"] = req.RuleContent
+	updateMap["notify"] = req.Notify
+	updateMap["state"] = req.State
+	c.MakeStatusResponse(rule.Update(updateMap))
+}
+
+// DeleteAction 删除一个记录
+func (c *AlertRuleController) DeleteAction() {
+	defer c.ServeJSON()
+	if c.CheckMultiAccessRequest([]RequestRole{SuperAdmin, Admin}, false) == false {
+		c.FailedStatus("当前用户权限不允许！")
+		return
+	}
+
+	rule, ok := c.getRuleOfCurrentWorkspace()
+	if !ok {
+		c.FailedStatus("告警规则不存在！")
+		return
+	}
+	c.MakeStatusResponse(rule.Delete())
+}
+
+// getRuleOfCurrentWorkspace 获取请求参数id指定的、属于当前工作空间的告警规则
+func (c *AlertRuleController) getRuleOfCurrentWorkspace() (rule db.AlertRule, ok bool) {
+	id, err := c.GetInt("id")
+	if err != nil {
+		logging.RuntimeLog.Error(err.Error())
+		return
+	}
+	rule.Id = id
+	if !rule.Get() || rule.WorkspaceId != c.GetCurrentWorkspace() {
+		return
+	}
+	return rule, true
+}
+
+// getListData 获取列表数据
+func (c *AlertRuleController) getListData(req alertRuleRequestParam) (resp DataTableResponseData) {
+	rule := db.AlertRule{}
+	searchMap := make(map[string]interface{})
+	searchMap["workspace_id"] = c.GetCurrentWorkspace()
+	if req.RuleName != "" {
+		searchMap["rule_name"] = req.RuleName
+	}
+	if req.RuleType != "" {
+		searchMap["rule_type"] = req.RuleType
+	}
+	startPage := req.Start/req.Length + 1
+	results, total := rule.Gets(searchMap, startPage, req.Length)
+	for i, row := range results {
+		resp.Data = append(resp.Data, AlertRuleListData{
+			Id:          row.Id,
+			Index:       req.Start + i + 1,
+			RuleName:    row.RuleName,
+			RuleType:    row.RuleType,
+			RuleContent: row.RuleContent,
+			Notify:      row.Notify,
+			State:       row.State,
+			CreateTime:  FormatDateTime(row.CreateDatetime),
+			UpdateTime:  FormatDateTime(row.UpdateDatetime),
+		})
+	}
+	resp.Draw = req.Draw
+	resp.RecordsTotal = total
+	resp.RecordsFiltered = total
+	if resp.Data == nil {
+		resp.Data = make([]interface{}, 0)
+	}
+	return
+}
+
+// validateAlertRule 校验告警规则的参数
+func validateAlertRule(req *alertRuleAddRequestParam) string {
+	req.RuleName = strings.TrimSpace(req.RuleName)
+	req.RuleContent = strings.TrimSpace(req.RuleContent)
+	if req.RuleName == "" {
+		return "规则名称不能为空！"
+	}
+	switch req.RuleType {
+	case db.AlertRulePort, db.AlertRuleDomain, db.AlertRuleTitle:
+		if req.RuleContent == "" {
+			return "规则内容不能为空！"
+		}
+	case db.AlertRuleVulnerability:
+	default:
+		return "规则类型错误！"
+	}
+	if req.State != "disable" {
+		req.State = "enable"
+	}
+	var notify []string
+	for _, n := range strings.Split(req.Notify, ",") {
+		if n = strings.TrimSpace(n); n != "" {
+			notify = append(notify, n)
+		}
+	}
+	req.Notify = strings.Join(notify, ",")
+	return ""
+}
@@ -0,0 +1,107 @@
+package controllers
+
+import (
+	"github.com/hanc00l/nemo_go/pkg/db"
+	"time"
+)
+
+const (
+	// assetHistoryInfoLimit 资产详情中显示的最近变更记录数量
+	assetHistoryInfoLimit = 100
+)
+
+// AssetHistoryInfo 资产的一条变更记录
+type AssetHistoryInfo struct {
+	AssetType  string
+	AssetName  string
+	Item       string
+	Port       int
+	Action     string
+	Field      string
+	OldValue   string
+	NewValue   string
+	MainTaskId string
+	CreateTime string
+}
+
+// TaskDiffInfo 两个任务之间的资产变更
+type TaskDiffInfo struct {
+	TaskId        string
+	TaskName      string
+	SinceTaskId   string
+	SinceTaskName string
+	StartTime     string
+	EndTime       string
+	AddCount      int
+	ChangeCount   int
+	RemoveCount   int
+	History       []AssetHistoryInfo
+}
+
+// getAssetHistory 获取一个资产的变更记录
+func getAssetHistory(workspaceId int, assetType, assetName string, limit int) (r []AssetHistoryInfo) {
+	history := db.AssetHistory{WorkspaceId: workspaceId, AssetType: assetType, AssetName: assetName}
+	for _, h := range history.GetsByAsset(limit) {
+		r = append(r, toAssetHistoryInfo(h))
+	}
+	return
+}
+
+// getTaskDiff 获取任务taskId在任务sinceTaskId完成后记录的资产变更（不包括同一时间段内其它任务的变更）
+func getTaskDiff(taskId, sinceTaskId string) (r TaskDiffInfo, ok bool) {
+	task := db.TaskMain{TaskId: taskId}
+	sinceTask := db.TaskMain{TaskId: sinceTaskId}
+	if !task.GetByTaskId() || !sinceTask.GetByTaskId() || task.WorkspaceId != sinceTask.WorkspaceId {
+		return
+	}
+	start := getTaskEndTime(&sinceTask)
+	end := time.Now()
+	if task.SucceededTime != nil {
+		end = *task.SucceededTime
+	}
+	if !start.Before(end) {
+		return
+	}
+	r.TaskId = task.TaskId
+	r.TaskName = task.TaskName
+	r.SinceTaskId = sinceTask.TaskId
+	r.SinceTaskName = sinceTask.TaskName
+	r.StartTime = FormatDateTime(start)
+	r.EndTime = FormatDateTime(end)
+	history := db.AssetHistory{WorkspaceId: task.WorkspaceId, MainTaskId: task.TaskId}
+	for _, h := range history.GetsByMainTaskAndDatetime(start, end) {
+		switch h.Action {
+		case db.HistoryActionAdd:
+			r.AddCount++
+		case db.HistoryActionChange:
+			r.ChangeCount++
+		case db.HistoryActionRemove:
+			r.RemoveCount++
+		}
+		r.History = append(r.History, toAssetHistoryInfo(h))
+	}
+	return r, true
+}
+
+// getTaskEndTime 任务的完成时间，未完成的任务以最后更新时间为准
+func getTaskEndTime(task *db.TaskMain) time.Time {
+	if task.SucceededTime != nil {
+		return *task.SucceededTime
+	}
+	return task.UpdateDatetime
+}
+
+func toAssetHistoryInfo(h db.AssetHistory) AssetHistoryInfo {
+	return AssetHistoryInfo{
+		AssetType:  h.AssetType,
+		AssetName:  h.AssetName,
+		Item:       h.Item,
+		Port:       h.Port,
+		Action:     h.Action,
+		Field:      h.Field,
+		OldValue:   h.OldValue,
+		NewValue:   h.NewValue,
+		MainTaskId: h.MainTaskId,
+		CreateTime: FormatDateTime(h.CreateDatetime),
+	}
+}
@@ -301,9 +301,13 @@ func (c *ConfigController) SaveTaskNotifyAction() {
 	if conf.GlobalServerConfig().Notify == nil {
 		conf.GlobalServerConfig().Notify = make(map[string]conf.Notify)
 	}
-	conf.GlobalServerConfig().Notify["serverchan"] = conf.Notify{Token: serverChanToken}
-	conf.GlobalServerConfig().Notify["dingtalk"] = conf.Notify{Token: dingtalkToken}
-	conf.GlobalServerConfig().Notify["feishu"] = conf.Notify{Token: feishuToken}
+	//只更新token，保留其它通知配置
+	tokens := map[string]string{"serverchan": serverChanToken, "dingtalk": dingtalkToken, "feishu": feishuToken}
+	for name, token := range tokens {
+		n := conf.GlobalServerConfig().Notify[name]
+		n.Token = token
+		conf.GlobalServerConfig().Notify[name] = n
+	}
 
 	err = conf.GlobalServerConfig().WriteConfig()
 	if err != nil {
@@ -320,8 +324,7 @@ func (c *ConfigController) TestTaskNotifyAction() {
 		return
 	}
 
-	message := "这是一个测试消息，来自Nemo的配置管理！"
-	notify.Send(message)
+	notify.Send(notify.NewEvent(notify.EventTypeTest, "这是一个测试消息，来自Nemo的配置管理！"))
 
 	c.SucceededStatus("已发送测试通知，请确认消息是否正确！")
 }
@@ -516,8 +519,8 @@ func (c *ConfigController) SavePortscanAction() {
 	}
 
 	conf.GlobalWorkerConfig().Portscan.Cmdbin = "masscan"
-	if cmdbin == "nmap" {
-		conf.GlobalWorkerConfig().Portscan.Cmdbin = "nmap"
+	if cmdbin == "nmap" || cmdbin == "tcpscan" {
+		conf.GlobalWorkerConfig().Portscan.Cmdbin = cmdbin
 	}
 	conf.GlobalWorkerConfig().Portscan.Port = port
 	conf.GlobalWorkerConfig().Portscan.Rate = rate
@@ -25,15 +25,34 @@ type DashboardStatisticData struct {
 }
 
 type WorkerStatusData struct {
-	Index                    int    `json:"index"`
-	WorkName                 string `json:"worker_name"`
-	WorkerTopic              string `json:"worker_topic"`
-	CreateTime               string `json:"create_time"`
-	UpdateTime               string `json:"update_time"`
-	TaskExecutedNumber       int    `json:"task_number"`
-	EnableManualReloadFlag   bool   `json:"enable_manual_reload_flag"`
-	EnableManualFileSyncFlag bool   `json:"enable_manual_file_sync_flag"`
-	HeartColor               string `json:"heart_color"`
+	Index                    int                     `json:"index"`
+	WorkName                 string                  `json:"worker_name"`
+	WorkerTopic              string                  `json:"worker_topic"`
+	CreateTime               string                  `json:"create_time"`
+	UpdateTime               string                  `json:"update_time"`
+	TaskExecutedNumber       int                     `json:"task_number"`
+	EnableManualReloadFlag   bool                    `json:"enable_manual_reload_flag"`
+	EnableManualFileSyncFlag bool                    `json:"enable_manual_file_sync_flag"`
+	HeartColor               string                  `json:"heart_color"`
+	CPUPercent               float64                 `json:"cpu_percent"`
+	MemPercent               float64                 `json:"mem_percent"`
+	DiskPercent              float64                 `json:"disk_percent"`
+	RunningTasks             []WorkerRunningTaskData `json:"running_tasks"`
+	ToolVersions             map[string]string       `json:"tool_versions"`
+	FileSyncRevision         string                  `json:"filesync_revision"`
+	FileSyncTime             string                  `json:"filesync_time"`
+	FileSyncProfile          string                  `json:"filesync_profile"`
+	FileSyncRelease          int                     `json:"filesync_release"`
+	SpoolDepth               int                     `json:"spool_depth"`
+	Identity                 string                  `json:"identity"`
+	Warnings                 []string                `json:"warnings"`
+}
+
+// WorkerRunningTaskData worker正在执行的任务
+type WorkerRunningTaskData struct {
+	TaskId   string `json:"task_id"`
+	TaskName string `json:"task_name"`
+	Elapsed  string `json:"elapsed"`
 }
 
 type TaskInfoData struct {
@@ -153,8 +172,10 @@ func (c *DashboardController) WorkerAliveListAction() {
 	for _, v := range comm.WorkerStatus {
 		if time.Now().Sub(v.UpdateTime).Minutes() > 5 {
 			delete(comm.WorkerStatus, v.WorkerName)
-			continue
 		}
+	}
+	warnings := comm.CheckWorkerTelemetry()
+	for _, v := range comm.WorkerStatus {
 		wsd := WorkerStatusData{
 			Index:              index,
 			WorkName:           v.WorkerName,
@@ -163,6 +184,26 @@ func (c *DashboardController) WorkerAliveListAction() {
 			UpdateTime:         fmt.Sprintf("%s前", time.Now().Sub(v.UpdateTime).Truncate(time.Second).String()),
 			TaskExecutedNumber: v.TaskExecutedNumber,
 			HeartColor:         "green",
+			CPUPercent:         v.CPUPercent,
+			MemPercent:         v.MemPercent,
+			DiskPercent:        v.DiskPercent,
+			ToolVersions:       v.ToolVersions,
+			FileSyncRevision:   v.FileSyncRevision,
+			FileSyncProfile:    v.FileSyncProfile,
+			FileSyncRelease:    v.FileSyncRelease,
+			SpoolDepth:         v.SpoolDepth,
+			Identity:           v.Identity,
+			Warnings:           warnings[v.WorkerName],
+		}
+		if !v.FileSyncTime.IsZero() {
+			wsd.FileSyncTime = FormatDateTime(v.FileSyncTime)
+		}
+		for _, t := range v.RunningTasks {
+			wsd.RunningTasks = append(wsd.RunningTasks, WorkerRunningTaskData{
+				TaskId:   t.TaskId,
+				TaskName: t.TaskName,
+				Elapsed:  (time.Duration(t.Elapsed) * time.Second).String(),
+			})
 		}
 		workerHeartDt := time.Now().Sub(v.UpdateTime).Minutes()
 		daemonHeartDt := time.Now().Sub(v.WorkerDaemonUpdateTime).Minutes()
@@ -286,18 +327,30 @@ func (c *DashboardController) getWorkerTopicDescription(taskMode string) string
 		"custom":  "自定义任务",
 	}
 	workerTopicDescription := make(map[string]struct{})
-	topicsArray := strings.Split(taskMode, ",")
-	if len(topicsArray) >= 4 {
-		return modeNameMap["default"]
-	}
-	for _, v := range topicsArray {
-		if modeName, ok := modeNameMap[v]; ok {
+	workerTags := make(map[string]struct{})
+	for _, v := range strings.Split(taskMode, ",") {
+		topic := v
+		// 带标签的队列：active.dmz
+		if !strings.HasPrefix(v, "custom") {
+			if i := strings.Index(v, "."); i > 0 {
+				topic = v[:i]
+				workerTags[v[i+1:]] = struct{}{}
+			}
+		}
+		if modeName, ok := modeNameMap[topic]; ok {
 			workerTopicDescription[modeName] = struct{}{}
-		} else if strings.HasPrefix(v, "custom") {
+		} else if strings.HasPrefix(topic, "custom") {
 			workerTopicDescription[modeNameMap["custom"]] = struct{}{}
 		} else {
 			return "未知模式"
 		}
 	}
-	return utils.SetToString(workerTopicDescription)
+	description := utils.SetToString(workerTopicDescription)
+	if len(workerTopicDescription) >= 4 {
+		description = modeNameMap["default"]
+	}
+	if len(workerTags) > 0 {
+		description = fmt.Sprintf("%s（标签：%s）", description, utils.SetToString(workerTags))
+	}
+	return description
 }
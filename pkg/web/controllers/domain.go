@@ -100,6 +100,7 @@ type DomainInfo struct {
 	WorkspaceGUID string
 	PinIndex      string
 	Source        []string
+	History       []AssetHistoryInfo
 }
 
 // DomainAttrInfo domain属性
@@ -209,6 +210,7 @@ func (c *DomainController) InfoAction() {
 				domainInfo.PortAttr[i].TableBackgroundSet = tableBackgroundSet
 			}
 		}
+		domainInfo.History = getAssetHistory(workspaceId, db.AssetTypeDomain, domain.DomainName, assetHistoryInfoLimit)
 	}
 	domainInfo.DisableFofa = disableFofa
 	c.Data["domain_info"] = domainInfo
@@ -216,6 +218,24 @@ func (c *DomainController) InfoAction() {
 	c.TplName = "domain-info.html"
 }
 
+// HistoryAction 获取一个域名的资产变更记录
+func (c *DomainController) HistoryAction() {
+	defer c.ServeJSON()
+
+	domainName := c.GetString("domain")
+	workspaceId, err := c.GetInt("workspace")
+	limit, _ := c.GetInt("limit", 0)
+	if domainName == "" || err != nil || workspaceId <= 0 {
+		c.FailedStatus("参数错误")
+		return
+	}
+	history := getAssetHistory(workspaceId, db.AssetTypeDomain, domainName, limit)
+	if history == nil {
+		history = make([]AssetHistoryInfo, 0)
+	}
+	c.Data["json"] = history
+}
+
 // DeleteDomainAction 删除一个记录
 func (c *DomainController) DeleteDomainAction() {
 	defer c.ServeJSON()
@@ -776,7 +796,7 @@ func getDomainAttrFullInfo(workspaceGUID string, id int, disableFofa, disableBan
 		if da.Source == "fofa" || da.Source == "quake" || da.Source == "hunter" || da.Source == "0zone" {
 			fofaInfo[da.Tag] = da.Content
 		}
-		if da.Tag == "A" {
+		if da.Tag == "A" || da.Tag == "AAAA" {
 			if _, ok := r.IP[da.Content]; !ok {
 				r.IP[da.Content] = struct{}{}
 			}
@@ -904,7 +924,7 @@ func (c *DomainController) getDomainStatisticsData(req domainRequestParam) Domai
 		domainAttrInfo := domainAttr.GetsByRelatedId()
 		domainIP := make(map[string]struct{})
 		for _, dai := range domainAttrInfo {
-			if dai.Tag == "A" {
+			if dai.Tag == "A" || dai.Tag == "AAAA" {
 				domainIP[dai.Content] = struct{}{}
 				if _, ok := dsi.IP[dai.Content]; !ok {
 					dsi.IP[dai.Content] = 1
@@ -916,9 +936,8 @@ func (c *DomainController) getDomainStatisticsData(req domainRequestParam) Domai
 		dsi.DomainIP[domainRow.DomainName] = utils.SetToString(domainIP)
 	}
 	for k, _ := range dsi.IP {
-		ipArray := strings.Split(k, ".")
-		if len(ipArray) == 4 {
-			subnet := fmt.Sprintf("%s.0/24", strings.Join(ipArray[:3], "."))
+		if utils.CheckIP(k) {
+			subnet := getIPSubnet(k)
 			if _, ok := dsi.IPSubnet[subnet]; !ok {
 				dsi.IPSubnet[subnet] = 1
 			} else {
@@ -1003,7 +1022,7 @@ func (c *DomainController) BlockDomainAction() {
 		domainAttr := db.DomainAttr{RelatedId: d.Id}
 		domainAttrData := domainAttr.GetsByRelatedId()
 		for _, da := range domainAttrData {
-			if da.Tag == "A" {
+			if da.Tag == "A" || da.Tag == "AAAA" {
 				if _, ok := domainRelatedIP[da.Content]; !ok {
 					domainRelatedIP[da.Content] = struct{}{}
 				}
@@ -0,0 +1,57 @@
+package controllers
+
+import (
+	beegoContext "github.com/beego/beego/v2/server/web/context"
+	"github.com/hanc00l/nemo_go/pkg/comm"
+	"net/http"
+	"strings"
+)
+
+// LoginFilterWhiteList web不需要登录验证的路径
+var LoginFilterWhiteList = []string{"/"}
+
+// APITokenFilterWhiteList API接口不需要token验证的路径
+var APITokenFilterWhiteList = []string{
+	"/v1/login/captcha",
+	"/v1/login/login",
+}
+
+// FilterLoginCheck 全局的登录验证：检查登录成功后的session
+func FilterLoginCheck(ctx *beegoContext.Context) {
+	for _, url := range LoginFilterWhiteList {
+		if ctx.Request.RequestURI == url {
+			return
+		}
+	}
+	// HTTP方式的worker由接口自己认证
+	if strings.HasPrefix(ctx.Request.URL.Path, comm.HTTPTransportPath+"/") {
+		return
+	}
+	// 检查用户是否登录（检查登录成功后的session:User、UserRole、Workspace
+	if user, ok := ctx.Input.Session("User").(string); !ok || len(user) == 0 {
+		ctx.Redirect(http.StatusFound, "/")
+	}
+	userRole, ok := ctx.Input.Session("UserRole").(string)
+	if !ok || len(userRole) == 0 {
+		ctx.Redirect(http.StatusFound, "/")
+	}
+	if workspaceId, ok := ctx.Input.Session("Workspace").(int); !ok || (userRole != "superadmin" && workspaceId <= 0) {
+		ctx.Redirect(http.StatusFound, "/")
+	}
+}
+
+// FilterAPITokenCheck 全局的API接口token验证
+func FilterAPITokenCheck(ctx *beegoContext.Context) {
+	for _, url := range APITokenFilterWhiteList {
+		if ctx.Request.RequestURI == url {
+			return
+		}
+	}
+	tokenString := ctx.Input.Header("Authorization")
+	if len(tokenString) == 0 {
+		ctx.Redirect(http.StatusFound, "/")
+	}
+	if jwtData := ValidToken(GetTokenValueFromHeader(tokenString)); jwtData == nil {
+		ctx.Redirect(http.StatusFound, "/")
+	}
+}
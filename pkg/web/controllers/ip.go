@@ -16,9 +16,11 @@ import (
 	"github.com/hanc00l/nemo_go/pkg/task/pocscan"
 	"github.com/hanc00l/nemo_go/pkg/task/portscan"
 	"github.com/hanc00l/nemo_go/pkg/utils"
+	"net"
 	"net/http"
 	"path"
 	"path/filepath"
+	"sort"
 	"strconv"
 	"strings"
 	"time"
@@ -104,6 +106,7 @@ type IPInfo struct {
 	Workspace     string
 	WorkspaceGUID string
 	PinIndex      string
+	History       []AssetHistoryInfo
 }
 
 // PortAttrInfo 每一个端口的详细数据
@@ -217,6 +220,7 @@ func (c *IPController) InfoAction() {
 				ipInfo.PortAttr[i].TableBackgroundSet = tableBackgroundSet
 			}
 		}
+		ipInfo.History = getAssetHistory(workspaceId, db.AssetTypeIP, ip.IpName, assetHistoryInfoLimit)
 	}
 	if c.IsServerAPI {
 		c.Data["json"] = ipInfo
@@ -430,6 +434,24 @@ func (c *IPController) PinTopAction() {
 	c.FailedStatus("ip not exist")
 }
 
+// HistoryAction 获取一个IP的资产变更记录
+func (c *IPController) HistoryAction() {
+	defer c.ServeJSON()
+
+	ipName := c.GetString("ip")
+	workspaceId, err := c.GetInt("workspace")
+	limit, _ := c.GetInt("limit", 0)
+	if ipName == "" || err != nil || workspaceId <= 0 {
+		c.FailedStatus("参数错误")
+		return
+	}
+	history := getAssetHistory(workspaceId, db.AssetTypeIP, utils.FormatIPV6(ipName), limit)
+	if history == nil {
+		history = make([]AssetHistoryInfo, 0)
+	}
+	c.Data["json"] = history
+}
+
 // InfoHttpAction 获取指定的http信息
 func (c *IPController) InfoHttpAction() {
 	defer c.ServeJSON()
@@ -876,14 +898,15 @@ func (c *IPController) getStatisticsData(req ipRequestParam) IPStatisticInfo {
 	ip := db.Ip{}
 	searchMap := c.getSearchMap(req)
 	ipResult, _ := ip.Gets(searchMap, -1, -1, req.OrderByDate)
+	var ipList []string
 	for _, ipRow := range ipResult {
 		// ip
 		if _, ok := r.IP[ipRow.IpName]; !ok {
-			r.IP[ipRow.IpName] = ipRow.IpInt
+			r.IP[ipRow.IpName] = 0
+			ipList = append(ipList, ipRow.IpName)
 		}
-		// C段
-		ipArray := strings.Split(ipRow.IpName, ".")
-		subnet := fmt.Sprintf("%s.%s.%s.0/24", ipArray[0], ipArray[1], ipArray[2])
+		// C段（IPv6按/64统计）
+		subnet := getIPSubnet(ipRow.IpName)
 		if _, ok := r.IPSubnet[subnet]; ok {
 			r.IPSubnet[subnet]++
 		} else {
@@ -908,10 +931,33 @@ func (c *IPController) getStatisticsData(req ipRequestParam) IPStatisticInfo {
 			}
 		}
 	}
+	// IPv4与IPv6统一按地址排序
+	sort.Slice(ipList, func(i, j int) bool {
+		return utils.IPToSortString(ipList[i]) < utils.IPToSortString(ipList[j])
+	})
+	for i, ipName := range ipList {
+		r.IP[ipName] = i
+	}
 
 	return r
 }
 
+// getIPSubnet 获取IP所在的C段，IPv6地址返回/64子网
+func getIPSubnet(ipName string) string {
+	if utils.CheckIPV6(ipName) {
+		_, ipNet, err := net.ParseCIDR(fmt.Sprintf("%s/64", ipName))
+		if err != nil {
+			return ipName
+		}
+		return ipNet.String()
+	}
+	ipArray := strings.Split(ipName, ".")
+	if len(ipArray) != 4 {
+		return ipName
+	}
+	return fmt.Sprintf("%s.%s.%s.0/24", ipArray[0], ipArray[1], ipArray[2])
+}
+
 // GetIPListData 获取备忘录数据
 func (c *IPController) getMemoData(req ipRequestParam) (r []string) {
 	ip := db.Ip{}
@@ -1094,7 +1140,7 @@ func (c *IPController) writeToCSVData(exportInfo []IPExportInfo) []byte {
 	for i, v := range exportInfo {
 		csvWriter.Write([]string{
 			strconv.Itoa(i + 1),
-			fmt.Sprintf("%s:%d", v.IP, v.Port),
+			utils.FormatHostPort(v.IP, v.Port),
 			v.IP,
 			strconv.Itoa(v.Port),
 			v.Location,
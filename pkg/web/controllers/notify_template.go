@@ -0,0 +1,201 @@
+package controllers
+
+import (
+	"github.com/hanc00l/nemo_go/pkg/db"
+	"github.com/hanc00l/nemo_go/pkg/logging"
+	"github.com/hanc00l/nemo_go/pkg/notify"
+	"strings"
+)
+
+type NotifyTemplateController struct {
+	BaseController
+}
+
+type notifyTemplateAddRequestParam struct {
+	NotifyName string `form:"notify_name"`
+	Template   string `form:"template"`
+}
+
+type NotifyTemplateListData struct {
+	Id         int    `json:"id"`
+	Index      int    `json:"index"`
+	NotifyName string `json:"notify_name"`
+	Template   string `json:"template"`
+	UpdateTime string `json:"update_time"`
+}
+
+type NotifyTemplateInfo struct {
+	Id         int    `json:"id"`
+	NotifyName string `json:"notify_name"`
+	Template   string `json:"template"`
+}
+
+// IndexAction 显示列表页面
+func (c *NotifyTemplateController) IndexAction() {
+	c.Layout = "base.html"
+	c.TplName = "notify-template-list.html"
+}
+
+// ListAction 列表的数据
+func (c *NotifyTemplateController) ListAction() {
+	defer c.ServeJSON()
+
+	req := DatableRequestParam{}
+	err := c.ParseForm(&req)
+	if err != nil {
+		logging.RuntimeLog.Error(err.Error())
+	}
+	c.Data["json"] = c.getListData(req)
+}
+
+// GetAction 一个记录的详细情况
+func (c *NotifyTemplateController) GetAction() {
+	defer c.ServeJSON()
+
+	t, ok := c.getTemplateOfCurrentWorkspace()
+	if !ok {
+		c.Data["json"] = NotifyTemplateInfo{}
+		return
+	}
+	c.Data["json"] = NotifyTemplateInfo{
+		Id:         t.Id,
+		NotifyName: t.NotifyName,
+		Template:   t.Template,
+	}
+}
+
+// AddSaveAction 保存新增的记录
+func (c *NotifyTemplateController) AddSaveAction() {
+	defer c.ServeJSON()
+	if c.CheckMultiAccessRequest([]RequestRole{SuperAdmin, Admin}, false) == false {
+		c.FailedStatus("当前用户权限不允许！")
+		return
+	}
+
+	workspaceId := c.GetCurrentWorkspace()
+	if workspaceId <= 0 {
+		c.FailedStatus("未选择当前的工作空间！")
+		return
+	}
+	req := notifyTemplateAddRequestParam{}
+	if err := c.ParseForm(&req); err != nil {
+		logging.RuntimeLog.Error(err.Error())
+		c.FailedStatus(err.Error())
+		return
+	}
+	if msg := validateNotifyTemplate(&req, c.GetCurrentWorkspace()); msg != "" {
+		c.FailedStatus(msg)
+		return
+	}
+	t := db.NotifyTemplate{WorkspaceId: workspaceId, NotifyName: req.NotifyName}
+	if t.GetByNotifyName() {
+		c.FailedStatus("该通知的模板已存在！")
+		return
+	}
+	t.Template = req.Template
+	c.MakeStatusResponse(t.Add())
+}
+
+// UpdateAction 更新记录
+func (c *NotifyTemplateController) UpdateAction() {
+	defer c.ServeJSON()
+	if c.CheckMultiAccessRequest([]RequestRole{SuperAdmin, Admin}, false) == false {
+		c.FailedStatus("当前用户权限不允许！")
+		return
+	}
+
+	t, ok := c.getTemplateOfCurrentWorkspace()
+	if !ok {
+		c.FailedStatus("通知模板不存在！")
+		return
+	}
+	req := notifyTemplateAddRequestParam{}
+	if err := c.ParseForm(&req); err != nil {
+		logging.RuntimeLog.Error(err.Error())
+		c.FailedStatus(err.Error())
+		return
+	}
+	if msg := validateNotifyTemplate(&req, c.GetCurrentWorkspace()); msg != "" {
+		c.FailedStatus(msg)
+		return
+	}
+	exist := db.NotifyTemplate{WorkspaceId: t.WorkspaceId, NotifyName: req.NotifyName}
+	if exist.GetByNotifyName() && exist.Id != t.Id {
+		c.FailedStatus("该通知的模板已存在！")
+		return
+	}
+	updateMap := make(map[string]interface{})
+	updateMap["notify_name"] = req.NotifyName
+	updateMap["template"] = req.Template
+	c.MakeStatusResponse(t.Update(updateMap))
+}
+
+// DeleteAction 删除一个记录
+func (c *NotifyTemplateController) DeleteAction() {
+	defer c.ServeJSON()
+	if c.CheckMultiAccessRequest([]RequestRole{SuperAdmin, Admin}, false) == false {
+		c.FailedStatus("当前用户权限不允许！")
+		return
+	}
+
+	t, ok := c.getTemplateOfCurrentWorkspace()
+	if !ok {
+		c.FailedStatus("通知模板不存在！")
+		return
+	}
+	c.MakeStatusResponse(t.Delete())
+}
+
+// getTemplateOfCurrentWorkspace 获取请求参数id指定的、属于当前工作空间的通知模板
+func (c *NotifyTemplateController) getTemplateOfCurrentWorkspace() (t db.NotifyTemplate, ok bool) {
+	id, err := c.GetInt("id")
+	if err != nil {
+		logging.RuntimeLog.Error(err.Error())
+		return
+	}
+	t.Id = id
+	if !t.Get() || t.WorkspaceId != c.GetCurrentWorkspace() {
+		return
+	}
+	return t, true
+}
+
+// getListData 获取列表数据
+func (c *NotifyTemplateController) getListData(req DatableRequestParam) (resp DataTableResponseData) {
+	t := db.NotifyTemplate{WorkspaceId: c.GetCurrentWorkspace()}
+	results := t.GetsByWorkspace()
+	for i, row := range results {
+		resp.Data = append(resp.Data, NotifyTemplateListData{
+			Id:         row.Id,
+			Index:      i + 1,
+			NotifyName: row.NotifyName,
+			Template:   row.Template,
+			UpdateTime: FormatDateTime(row.UpdateDatetime),
+		})
+	}
+	resp.Draw = req.Draw
+	resp.RecordsTotal = len(results)
+	resp.RecordsFiltered = len(results)
+	if resp.Data == nil {
+		resp.Data = make([]interface{}, 0)
+	}
+	return
+}
+
+// validateNotifyTemplate 校验通知模板的参数
+func validateNotifyTemplate(req *notifyTemplateAddRequestParam, workspaceId int) string {
+	req.NotifyName = strings.TrimSpace(req.NotifyName)
+	if req.NotifyName == "" {
+		return "通知名称不能为空！"
+	}
+	if _, ok := notify.GetNotifyConfig(workspaceId)[req.NotifyName]; !ok {
+		return "通知名称未在工作空间或server.yml中配置！"
+	}
+	if strings.TrimSpace(req.Template) == "" {
+		return "模板内容不能为空！"
+	}
+	if err := notify.CheckTemplate(req.Template); err != nil {
+		return "模板格式错误：" + err.Error()
+	}
+	return ""
+}
@@ -0,0 +1,297 @@
+package controllers
+
+import (
+	"fmt"
+	"github.com/hanc00l/nemo_go/pkg/db"
+	"github.com/hanc00l/nemo_go/pkg/logging"
+	"io"
+	"path"
+	"strings"
+)
+
+// targetSetFileMaxSize 上传的目标文件的最大长度
+const targetSetFileMaxSize = 20 * 1024 * 1024
+
+type TargetSetController struct {
+	BaseController
+}
+
+type targetSetAddRequestParam struct {
+	TargetSetName string `form:"targetset_name"`
+	Description   string `form:"description"`
+	Include       string `form:"include"`
+	Exclude       string `form:"exclude"`
+}
+
+type TargetSetListData struct {
+	Id            int    `json:"id"`
+	Index         int    `json:"index"`
+	TargetSetName string `json:"targetset_name"`
+	Description   string `json:"description"`
+	IncludeCount  int    `json:"include_count"`
+	ExcludeCount  int    `json:"exclude_count"`
+	UpdateTime    string `json:"update_time"`
+}
+
+type TargetSetInfo struct {
+	Id            int    `json:"id"`
+	TargetSetName string `json:"targetset_name"`
+	Description   string `json:"description"`
+	Include       string `json:"include"`
+	Exclude       string `json:"exclude"`
+	IncludeCount  int    `json:"include_count"`
+	ExcludeCount  int    `json:"exclude_count"`
+}
+
+// IndexAction 显示列表页面
+func (c *TargetSetController) IndexAction() {
+	c.Layout = "base.html"
+	c.TplName = "targetset-list.html"
+}
+
+// ListAction 列表的数据
+func (c *TargetSetController) ListAction() {
+	defer c.ServeJSON()
+
+	req := DatableRequestParam{}
+	err := c.ParseForm(&req)
+	if err != nil {
+		logging.RuntimeLog.Error(err.Error())
+	}
+	c.Data["json"] = c.getListData(req)
+}
+
+// GetAction 一个记录的详细情况，包括全部的条目
+func (c *TargetSetController) GetAction() {
+	defer c.ServeJSON()
+
+	t, ok := c.getTargetSetOfCurrentWorkspace()
+	if !ok {
+		c.Data["json"] = TargetSetInfo{}
+		return
+	}
+	c.Data["json"] = TargetSetInfo{
+		Id:            t.Id,
+		TargetSetName: t.TargetSetName,
+		Description:   t.Description,
+		Include:       strings.Join(t.GetEntries(false), "\n"),
+		Exclude:       strings.Join(t.GetEntries(true), "\n"),
+		IncludeCount:  t.IncludeCount,
+		ExcludeCount:  t.ExcludeCount,
+	}
+}
+
+// AddSaveAction 保存新增的记录；条目可以由include、exclude参数或上传的文件提供
+func (c *TargetSetController) AddSaveAction() {
+	defer c.ServeJSON()
+	if c.CheckMultiAccessRequest([]RequestRole{SuperAdmin, Admin}, false) == false {
+		c.FailedStatus("当前用户权限不允许！")
+		return
+	}
+
+	workspaceId := c.GetCurrentWorkspace()
+	if workspaceId <= 0 {
+		c.FailedStatus("未选择当前的工作空间！")
+		return
+	}
+	req := targetSetAddRequestParam{}
+	if err := c.ParseForm(&req); err != nil {
+		logging.RuntimeLog.Error(err.Error())
+		c.FailedStatus(err.Error())
+		return
+	}
+	if req.TargetSetName = strings.TrimSpace(req.TargetSetName); req.TargetSetName == "" {
+		c.FailedStatus("目标集合名称不能为空！")
+		return
+	}
+	include, exclude, msg := c.getTargetSetEntries(req)
+	if msg != "" {
+		c.FailedStatus(msg)
+		return
+	}
+	t := db.TargetSet{WorkspaceId: workspaceId, TargetSetName: req.TargetSetName}
+	if t.GetByName() {
+		c.FailedStatus("目标集合名称已存在！")
+		return
+	}
+	t.Description = req.Description
+	if !t.Add() || !t.SaveEntries(include, exclude) {
+		c.FailedStatus("save to db fail")
+		return
+	}
+	c.SucceededStatus(fmt.Sprintf("include:%d,exclude:%d", t.IncludeCount, t.ExcludeCount))
+}
+
+// UpdateAction 更新记录，并使用新的条目替换原有的全部条目
+func (c *TargetSetController) UpdateAction() {
+	defer c.ServeJSON()
+	if c.CheckMultiAccessRequest([]RequestRole{SuperAdmin, Admin}, false) == false {
+		c.FailedStatus("当前用户权限不允许！")
+		return
+	}
+
+	t, ok := c.getTargetSetOfCurrentWorkspace()
+	if !ok {
+		c.FailedStatus("目标集合不存在！")
+		return
+	}
+	req := targetSetAddRequestParam{}
+	if err := c.ParseForm(&req); err != nil {
+		logging.RuntimeLog.Error(err.Error())
+		c.FailedStatus(err.Error())
+		return
+	}
+	if req.TargetSetName = strings.TrimSpace(req.TargetSetName); req.TargetSetName == "" {
+		c.FailedStatus("目标集合名称不能为空！")
+		return
+	}
+	include, exclude, msg := c.getTargetSetEntries(req)
+	if msg != "" {
+		c.FailedStatus(msg)
+		return
+	}
+	exist := db.TargetSet{WorkspaceId: t.WorkspaceId, TargetSetName: req.TargetSetName}
+	if exist.GetByName() && exist.Id != t.Id {
+		c.FailedStatus("目标集合名称已存在！")
+		return
+	}
+	updateMap := make(map[string]interface{})
+	updateMap["target_set_name"] = req.TargetSetName
+	updateMap["description"] = req.Description
+	if !t.Update(updateMap) || !t.SaveEntries(include, exclude) {
+		c.FailedStatus("save to db fail")
+		return
+	}
+	c.SucceededStatus(fmt.Sprintf("include:%d,exclude:%d", t.IncludeCount, t.ExcludeCount))
+}
+
+// DeleteAction 删除一个记录
+func (c *TargetSetController) DeleteAction() {
+	defer c.ServeJSON()
+	if c.CheckMultiAccessRequest([]RequestRole{SuperAdmin, Admin}, false) == false {
+		c.FailedStatus("当前用户权限不允许！")
+		return
+	}
+
+	t, ok := c.getTargetSetOfCurrentWorkspace()
+	if !ok {
+		c.FailedStatus("目标集合不存在！")
+		return
+	}
+	c.MakeStatusResponse(t.Delete())
+}
+
+// getTargetSetOfCurrentWorkspace 获取请求参数id指定的、属于当前工作空间的目标集合
+func (c *TargetSetController) getTargetSetOfCurrentWorkspace() (t db.TargetSet, ok bool) {
+	id, err := c.GetInt("id")
+	if err != nil {
+		logging.RuntimeLog.Error(err.Error())
+		return
+	}
+	t.Id = id
+	if !t.Get() || t.WorkspaceId != c.GetCurrentWorkspace() {
+		return
+	}
+	return t, true
+}
+
+// getTargetSetEntries 获取请求中的包含及排除条目：
+// 上传的文件（webapi方式为file参数的内容）每行一个条目，以“!”开头的为排除条目，以“#”开头的为注释
+func (c *TargetSetController) getTargetSetEntries(req targetSetAddRequestParam) (include, exclude []string, msg string) {
+	includeText := req.Include
+	excludeText := req.Exclude
+	var fileContent string
+	if c.IsServerAPI {
+		fileContent = c.GetString("file")
+	} else if file, fileHeader, err := c.GetFile("file"); err == nil {
+		defer file.Close()
+		ext := path.Ext(fileHeader.Filename)
+		if ext != ".txt" && ext != ".csv" && ext != ".dat" {
+			return nil, nil, "只允许.txt、.csv或.dat文件"
+		}
+		if fileHeader.Size > targetSetFileMaxSize {
+			return nil, nil, "文件超过允许的大小"
+		}
+		content, err := io.ReadAll(file)
+		if err != nil {
+			return nil, nil, err.Error()
+		}
+		fileContent = string(content)
+	}
+	for _, line := range strings.Split(fileContent, "\n") {
+		line = strings.TrimSpace(line)
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		if strings.HasPrefix(line, "!") {
+			excludeText += "\n" + line[1:]
+		} else {
+			includeText += "\n" + line
+		}
+	}
+	if include, msg = parseTargetSetEntries(includeText); msg != "" {
+		return
+	}
+	if exclude, msg = parseTargetSetEntries(excludeText); msg != "" {
+		return
+	}
+	if len(include) == 0 {
+		msg = "目标集合至少需要一个包含的目标！"
+	}
+	return
+}
+
+// getListData 获取列表数据
+func (c *TargetSetController) getListData(req DatableRequestParam) (resp DataTableResponseData) {
+	t := db.TargetSet{WorkspaceId: c.GetCurrentWorkspace()}
+	results := t.GetsByWorkspace()
+	for i, row := range results {
+		resp.Data = append(resp.Data, TargetSetListData{
+			Id:            row.Id,
+			Index:         i + 1,
+			TargetSetName: row.TargetSetName,
+			Description:   row.Description,
+			IncludeCount:  row.IncludeCount,
+			ExcludeCount:  row.ExcludeCount,
+			UpdateTime:    FormatDateTime(row.UpdateDatetime),
+		})
+	}
+	resp.Draw = req.Draw
+	resp.RecordsTotal = len(results)
+	resp.RecordsFiltered = len(results)
+	if resp.Data == nil {
+		resp.Data = make([]interface{}, 0)
+	}
+	return
+}
+
+// parseTargetSetEntries 将以换行或“,”分隔的目标转换为去重的条目列表
+func parseTargetSetEntries(text string) (entries []string, msg string) {
+	entriesMap := make(map[string]struct{})
+	for _, line := range strings.Split(text, "\n") {
+		for _, t := range strings.Split(line, ",") {
+			entry := strings.TrimSpace(t)
+			if entry == "" {
+				continue
+			}
+			if len(entry) > 255 {
+				return nil, "目标长度不能超过255：" + entry[:50]
+			}
+			if _, ok := entriesMap[entry]; ok {
+				continue
+			}
+			entriesMap[entry] = struct{}{}
+			entries = append(entries, entry)
+		}
+	}
+	return
+}
+
+// checkTargetSet 检查任务引用的目标集合是否属于当前的工作空间
+func checkTargetSet(targetSetId, workspaceId int) bool {
+	if targetSetId <= 0 {
+		return true
+	}
+	t := db.TargetSet{Id: targetSetId}
+	return t.Get() && t.WorkspaceId == workspaceId
+}
@@ -91,6 +91,21 @@ type TaskInfo struct {
 	ResultFile    string
 	RunTaskInfo   []TaskListData
 	Workspace     string
+	WorkflowSteps [][]WorkflowStepInfo
+	Attempt       int
+	Attempts      []TaskAttemptInfo
+	WorkerTag     string
+}
+
+// TaskAttemptInfo 任务失败重试的每一次执行记录
+type TaskAttemptInfo struct {
+	TaskId     string
+	Attempt    int
+	State      string
+	Worker     string
+	Result     string
+	Runtime    string
+	UpdateTime string
 }
 
 type TaskCronInfo struct {
@@ -184,6 +199,28 @@ func (c *TaskController) InfoMainAction() {
 	}
 }
 
+// DiffMainAction 显示从指定任务完成后到当前Main任务完成时的资产变更
+func (c *TaskController) DiffMainAction() {
+	var diffInfo TaskDiffInfo
+
+	taskId := c.GetString("task_id")
+	sinceTaskId := c.GetString("since_task_id")
+	if taskId != "" && sinceTaskId != "" {
+		var ok bool
+		if diffInfo, ok = getTaskDiff(taskId, sinceTaskId); !ok {
+			logging.RuntimeLog.Warningf("diff task fail:%s since %s", taskId, sinceTaskId)
+		}
+	}
+	if c.IsServerAPI {
+		c.Data["json"] = diffInfo
+		c.ServeJSON()
+	} else {
+		c.Data["diff_info"] = diffInfo
+		c.Layout = "base.html"
+		c.TplName = "task-diff-main.html"
+	}
+}
+
 // InfoCronAction 显示一个任务的详情
 func (c *TaskController) InfoCronAction() {
 	var taskInfo TaskCronInfo
@@ -316,12 +353,85 @@ func (c *TaskController) StopAction() {
 	taskId := c.GetString("task_id")
 	if taskId != "" {
 		isRevoked, _ := serverapi.RevokeUnexcusedTask(taskId)
+		if isRevoked {
+			task := db.TaskRun{TaskId: taskId}
+			if task.GetByTaskId() {
+				runner.UpdateMainTaskProgress(task.MainTaskId)
+			}
+		}
 		c.MakeStatusResponse(isRevoked)
 		return
 	}
 	c.MakeStatusResponse(false)
 }
 
+// RetryFailedMainAction 重新执行一个Main任务中全部执行失败的子任务
+func (c *TaskController) RetryFailedMainAction() {
+	defer c.ServeJSON()
+	if c.CheckMultiAccessRequest([]RequestRole{SuperAdmin, Admin}, false) == false {
+		c.FailedStatus("当前用户权限不允许！")
+		return
+	}
+
+	taskId := c.GetString("task_id")
+	if taskId == "" {
+		c.FailedStatus("no task")
+		return
+	}
+	count, err := serverapi.RetryMainTaskFailed(taskId)
+	if err != nil {
+		c.FailedStatus(err.Error())
+		return
+	}
+	runner.UpdateMainTaskProgress(taskId)
+	c.SucceededStatus(fmt.Sprintf("共重试任务:%d", count))
+}
+
+// PauseMainAction 暂停一个执行中的Main任务
+func (c *TaskController) PauseMainAction() {
+	defer c.ServeJSON()
+	if c.CheckMultiAccessRequest([]RequestRole{SuperAdmin, Admin}, false) == false {
+		c.FailedStatus("当前用户权限不允许！")
+		return
+	}
+
+	taskId := c.GetString("task_id")
+	if taskId == "" {
+		c.FailedStatus("no task")
+		return
+	}
+	force, _ := c.GetBool("force", false)
+	count, err := serverapi.PauseMainTask(taskId, force)
+	if err != nil {
+		c.FailedStatus(err.Error())
+		return
+	}
+	runner.UpdateMainTaskProgress(taskId)
+	c.SucceededStatus(fmt.Sprintf("共暂停子任务:%d", count))
+}
+
+// ResumeMainAction 恢复一个暂停的Main任务
+func (c *TaskController) ResumeMainAction() {
+	defer c.ServeJSON()
+	if c.CheckMultiAccessRequest([]RequestRole{SuperAdmin, Admin}, false) == false {
+		c.FailedStatus("当前用户权限不允许！")
+		return
+	}
+
+	taskId := c.GetString("task_id")
+	if taskId == "" {
+		c.FailedStatus("no task")
+		return
+	}
+	count, err := serverapi.ResumeMainTask(taskId)
+	if err != nil {
+		c.FailedStatus(err.Error())
+		return
+	}
+	runner.UpdateMainTaskProgress(taskId)
+	c.SucceededStatus(fmt.Sprintf("共恢复子任务:%d", count))
+}
+
 // DisableCronTaskAction 禁用一个任务
 func (c *TaskController) DisableCronTaskAction() {
 	defer c.ServeJSON()
@@ -386,7 +496,7 @@ func (c *TaskController) StartPortScanTaskAction() {
 		c.FailedStatus(err.Error())
 		return
 	}
-	if req.Target == "" {
+	if req.Target == "" && req.TargetSetId <= 0 {
 		c.FailedStatus("no target")
 		return
 	}
@@ -395,6 +505,14 @@ func (c *TaskController) StartPortScanTaskAction() {
 		c.FailedStatus("请选择一个当前的工作空间！（如果是超级管理员，请在右上角进行切换）")
 		return
 	}
+	if !checkTargetSet(req.TargetSetId, workspaceId) {
+		c.FailedStatus("目标集合不存在！")
+		return
+	}
+	if !checkWorkerTag(req.WorkerTag) {
+		c.FailedStatus("worker标签只允许字母、数字、“_”及“-”！")
+		return
+	}
 	if req.Port == "" {
 		req.Port = conf.GlobalWorkerConfig().Portscan.Port
 	}
@@ -438,7 +556,7 @@ func (c *TaskController) StartBatchScanTaskAction() {
 		c.FailedStatus(err.Error())
 		return
 	}
-	if req.Target == "" {
+	if req.Target == "" && req.TargetSetId <= 0 {
 		c.FailedStatus("no target")
 		return
 	}
@@ -447,6 +565,14 @@ func (c *TaskController) StartBatchScanTaskAction() {
 		c.FailedStatus("请选择一个当前的工作空间！（如果是超级管理员，请在右上角进行切换）")
 		return
 	}
+	if !checkTargetSet(req.TargetSetId, workspaceId) {
+		c.FailedStatus("目标集合不存在！")
+		return
+	}
+	if !checkWorkerTag(req.WorkerTag) {
+		c.FailedStatus("worker标签只允许字母、数字、“_”及“-”！")
+		return
+	}
 	var kwArgs []byte
 	var taskId string
 	kwArgs, err = json.Marshal(req)
@@ -488,7 +614,7 @@ func (c *TaskController) StartDomainScanTaskAction() {
 		c.FailedStatus(err.Error())
 		return
 	}
-	if req.Target == "" {
+	if req.Target == "" && req.TargetSetId <= 0 {
 		c.FailedStatus("no target")
 		return
 	}
@@ -497,6 +623,14 @@ func (c *TaskController) StartDomainScanTaskAction() {
 		c.FailedStatus("请选择一个当前的工作空间！（如果是超级管理员，请在右上角进行切换）")
 		return
 	}
+	if !checkTargetSet(req.TargetSetId, workspaceId) {
+		c.FailedStatus("目标集合不存在！")
+		return
+	}
+	if !checkWorkerTag(req.WorkerTag) {
+		c.FailedStatus("worker标签只允许字母、数字、“_”及“-”！")
+		return
+	}
 	var kwArgs []byte
 	var taskId string
 	kwArgs, err = json.Marshal(req)
@@ -539,7 +673,7 @@ func (c *TaskController) StartPocScanTaskAction() {
 		return
 	}
 	// 格式化Target
-	if req.Target == "" {
+	if req.Target == "" && req.TargetSetId <= 0 {
 		c.FailedStatus("no target")
 		return
 	}
@@ -555,6 +689,14 @@ func (c *TaskController) StartPocScanTaskAction() {
 		c.FailedStatus("请选择一个当前的工作空间！（如果是超级管理员，请在右上角进行切换）")
 		return
 	}
+	if !checkTargetSet(req.TargetSetId, workspaceId) {
+		c.FailedStatus("目标集合不存在！")
+		return
+	}
+	if !checkWorkerTag(req.WorkerTag) {
+		c.FailedStatus("worker标签只允许字母、数字、“_”及“-”！")
+		return
+	}
 	if req.IsTaskCron {
 		taskId = runner.SaveCronTask("pocscan", string(kwArgs), req.TaskCronRule, req.TaskCronComment, workspaceId)
 		if taskId == "" {
@@ -595,24 +737,27 @@ func (c *TaskController) StartXScanTaskAction() {
 		reqByForm.NucleiPocFile = ""
 	}
 	var targets []string
-	if c.IsServerAPI {
+	if c.IsServerAPI && reqByForm.TargetSetId <= 0 {
 		// webapi方式：多个目标以“,”分隔，并且每一个目标单独生成一个任务
 		targets = strings.Split(reqByForm.Target, ",")
+	} else if c.IsServerAPI {
+		// webapi方式使用目标集合：全部目标与目标集合作为一个任务，由使用者指定任务类型
+		targets = []string{strings.Join(strings.Split(reqByForm.Target, ","), "\n")}
 	} else {
 		// 非webapi方式：将所有的目标作为一个任务的目标
 		targets = []string{reqByForm.Target}
 	}
 	var taskId string
 	for _, target := range targets {
-		if strings.TrimSpace(target) == "" && reqByForm.OrgId <= 0 {
+		if strings.TrimSpace(target) == "" && reqByForm.OrgId <= 0 && reqByForm.TargetSetId <= 0 {
 			continue
 		}
 		req := reqByForm
 		req.Target = target
 		// webapi方式：根据每个任务的目标是ip或domain自动生成相应的任务类型
-		// 非webapi则由使用者指定任务类型
-		if c.IsServerAPI {
-			if utils.CheckIPV4(target) || utils.CheckIPV4Subnet(target) {
+		// 非webapi及使用目标集合时由使用者指定任务类型
+		if c.IsServerAPI && req.TargetSetId <= 0 {
+			if utils.CheckIP(target) || utils.CheckIPSubnet(target) {
 				req.XScanType = "xportscan"
 			} else {
 				req.XScanType = "xdomainscan"
@@ -622,7 +767,7 @@ func (c *TaskController) StartXScanTaskAction() {
 		var taskName string
 		if req.XScanType == "xportscan" {
 			taskName = "xportscan"
-			if req.Target == "" {
+			if req.Target == "" && req.TargetSetId <= 0 {
 				c.FailedStatus("no target")
 				return
 			}
@@ -638,7 +783,7 @@ func (c *TaskController) StartXScanTaskAction() {
 			}
 		} else if req.XScanType == "xdomainscan" {
 			taskName = "xdomainscan"
-			if req.Target == "" {
+			if req.Target == "" && req.TargetSetId <= 0 {
 				c.FailedStatus("no target")
 				return
 			}
@@ -662,6 +807,14 @@ func (c *TaskController) StartXScanTaskAction() {
 			c.FailedStatus("请选择一个当前的工作空间！（如果是超级管理员，请在右上角进行切换）")
 			return
 		}
+		if !checkTargetSet(req.TargetSetId, workspaceId) {
+			c.FailedStatus("目标集合不存在！")
+			return
+		}
+		if !checkWorkerTag(req.WorkerTag) {
+			c.FailedStatus("worker标签只允许字母、数字、“_”及“-”！")
+			return
+		}
 		var kwArgs []byte
 		if kwArgs, err = json.Marshal(req); err != nil {
 			c.FailedStatus(err.Error())
@@ -918,6 +1071,21 @@ func (c *TaskController) getTaskInfo(taskId string) (r TaskInfo) {
 	r.Runtime = formatRuntime(&task)
 	r.CreateTime = FormatDateTime(task.CreateDatetime)
 	r.UpdateTime = FormatDateTime(task.UpdateDatetime)
+	r.Attempt = task.Attempt
+	r.WorkerTag = task.WorkerTag
+	if attempts := task.GetAttempts(); len(attempts) > 1 {
+		for _, t := range attempts {
+			r.Attempts = append(r.Attempts, TaskAttemptInfo{
+				TaskId:     t.TaskId,
+				Attempt:    t.Attempt,
+				State:      t.State,
+				Worker:     t.Worker,
+				Result:     t.Result,
+				Runtime:    formatRuntime(&t),
+				UpdateTime: FormatDateTime(t.UpdateDatetime),
+			})
+		}
+	}
 	workspace := db.Workspace{Id: task.WorkspaceId}
 	if workspace.Get() {
 		r.Workspace = workspace.WorkspaceName
@@ -985,6 +1153,10 @@ func (c *TaskController) getTaskMainInfo(taskId string) (r TaskInfo) {
 	r.CreateTime = FormatDateTime(task.CreateDatetime)
 	r.UpdateTime = FormatDateTime(task.UpdateDatetime)
 	r.RunTaskInfo = c.getRunTaskListData(taskId, nil, true, true)
+	r.WorkerTag = task.WorkerTag
+	if task.TaskName == "workflow" {
+		r.WorkflowSteps = getWorkflowSteps(task)
+	}
 	workspace := db.Workspace{Id: task.WorkspaceId}
 	if workspace.Get() {
 		r.Workspace = workspace.WorkspaceName
@@ -1067,5 +1239,13 @@ func deleteRunTaskByMainTaskId(workspaceGUID string, mainTaskId string) (total i
 			total++
 		}
 	}
+	//删除主任务的结果汇总
+	taskResult := db.TaskMainResult{MainTaskId: mainTaskId}
+	taskResult.DeleteByMainTaskId()
 	return
 }
+
+// checkWorkerTag 检查任务指定的worker标签，为空时不限制执行任务的worker
+func checkWorkerTag(workerTag string) bool {
+	return workerTag == "" || ampq.CheckWorkerTag(workerTag)
+}
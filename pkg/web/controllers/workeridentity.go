@@ -0,0 +1,141 @@
+package controllers
+
+import (
+	"github.com/hanc00l/nemo_go/pkg/comm"
+	"github.com/hanc00l/nemo_go/pkg/db"
+	"github.com/hanc00l/nemo_go/pkg/logging"
+	"strings"
+)
+
+type WorkerIdentityController struct {
+	BaseController
+}
+
+type WorkerIdentityListData struct {
+	Id          int    `json:"id"`
+	Index       int    `json:"index"`
+	WorkerName  string `json:"worker_name"`
+	Description string `json:"description"`
+	CertSerial  string `json:"cert_serial"`
+	IsRevoked   bool   `json:"is_revoked"`
+	OnlineCount int    `json:"online_count"`
+	CreateTime  string `json:"create_time"`
+	RevokedTime string `json:"revoked_time"`
+}
+
+// IndexAction 显示列表页面
+func (c *WorkerIdentityController) IndexAction() {
+	c.CheckOneAccessRequest(SuperAdmin, true)
+
+	c.Layout = "base.html"
+	c.TplName = "worker-identity-list.html"
+}
+
+// ListAction 列表的数据
+func (c *WorkerIdentityController) ListAction() {
+	defer c.ServeJSON()
+	if c.CheckMultiAccessRequest([]RequestRole{SuperAdmin}, false) == false {
+		c.Data["json"] = DataTableResponseData{Data: make([]interface{}, 0)}
+		return
+	}
+
+	req := DatableRequestParam{}
+	err := c.ParseForm(&req)
+	if err != nil {
+		logging.RuntimeLog.Error(err.Error())
+	}
+	c.Data["json"] = c.getListData(req)
+}
+
+// EnrollAction 注册worker身份，返回worker身份文件的内容
+func (c *WorkerIdentityController) EnrollAction() {
+	defer c.ServeJSON()
+	if c.CheckMultiAccessRequest([]RequestRole{SuperAdmin}, false) == false {
+		c.FailedStatus("当前用户权限不允许！")
+		return
+	}
+
+	workerName := strings.TrimSpace(c.GetString("worker_name"))
+	if workerName == "" {
+		c.FailedStatus("worker名称不能为空！")
+		return
+	}
+	content, err := comm.EnrollWorker(workerName, c.GetString("description"))
+	if err != nil {
+		c.FailedStatus(err.Error())
+		return
+	}
+	c.SucceededStatus(string(content))
+}
+
+// RevokeAction 吊销worker身份
+func (c *WorkerIdentityController) RevokeAction() {
+	defer c.ServeJSON()
+	if c.CheckMultiAccessRequest([]RequestRole{SuperAdmin}, false) == false {
+		c.FailedStatus("当前用户权限不允许！")
+		return
+	}
+
+	id, err := c.GetInt("id")
+	if err != nil {
+		logging.RuntimeLog.Error(err.Error())
+		c.FailedStatus(err.Error())
+		return
+	}
+	c.MakeStatusResponse(comm.RevokeWorker(id))
+}
+
+// DeleteAction 删除worker身份
+func (c *WorkerIdentityController) DeleteAction() {
+	defer c.ServeJSON()
+	if c.CheckMultiAccessRequest([]RequestRole{SuperAdmin}, false) == false {
+		c.FailedStatus("当前用户权限不允许！")
+		return
+	}
+
+	id, err := c.GetInt("id")
+	if err != nil {
+		logging.RuntimeLog.Error(err.Error())
+		c.FailedStatus(err.Error())
+		return
+	}
+	c.MakeStatusResponse(comm.DeleteWorkerIdentity(id))
+}
+
+// getListData 获取列表数据，同时统计每个身份在线的worker数量
+func (c *WorkerIdentityController) getListData(req DatableRequestParam) (resp DataTableResponseData) {
+	onlineCount := make(map[string]int)
+	comm.WorkerStatusMutex.Lock()
+	for _, ws := range comm.WorkerStatus {
+		if ws.Identity != "" {
+			onlineCount[ws.Identity]++
+		}
+	}
+	comm.WorkerStatusMutex.Unlock()
+
+	w := db.WorkerIdentity{}
+	results := w.Gets()
+	for i, row := range results {
+		data := WorkerIdentityListData{
+			Id:          row.Id,
+			Index:       i + 1,
+			WorkerName:  row.WorkerName,
+			Description: row.Description,
+			CertSerial:  row.CertSerial,
+			IsRevoked:   row.IsRevoked,
+			OnlineCount: onlineCount[row.WorkerName],
+			CreateTime:  FormatDateTime(row.CreateDatetime),
+		}
+		if row.RevokedDatetime != nil {
+			data.RevokedTime = FormatDateTime(*row.RevokedDatetime)
+		}
+		resp.Data = append(resp.Data, data)
+	}
+	resp.Draw = req.Draw
+	resp.RecordsTotal = len(results)
+	resp.RecordsFiltered = len(results)
+	if resp.Data == nil {
+		resp.Data = make([]interface{}, 0)
+	}
+	return
+}
@@ -0,0 +1,175 @@
+package controllers
+
+import (
+	"fmt"
+	"github.com/hanc00l/nemo_go/pkg/comm"
+	"github.com/hanc00l/nemo_go/pkg/db"
+	"github.com/hanc00l/nemo_go/pkg/logging"
+	"strings"
+)
+
+type WorkerReleaseController struct {
+	BaseController
+}
+
+type WorkerReleaseListData struct {
+	Id          int      `json:"id"`
+	Index       int      `json:"index"`
+	Version     int      `json:"version"`
+	Description string   `json:"description"`
+	FileCount   int      `json:"file_count"`
+	IsLatest    bool     `json:"is_latest"`
+	WorkerTags  []string `json:"worker_tags"` //指定使用该版本的worker组，“”为全部worker
+	CreateTime  string   `json:"create_time"`
+}
+
+// IndexAction 显示列表页面
+func (c *WorkerReleaseController) IndexAction() {
+	c.CheckOneAccessRequest(SuperAdmin, true)
+
+	if _, fingerprint, err := comm.ReleasePublicKey(); err != nil {
+		logging.RuntimeLog.Error(err)
+	} else {
+		c.Data["fingerprint"] = fingerprint
+	}
+	c.Layout = "base.html"
+	c.TplName = "worker-release-list.html"
+}
+
+// ListAction 列表的数据
+func (c *WorkerReleaseController) ListAction() {
+	defer c.ServeJSON()
+	if c.CheckMultiAccessRequest([]RequestRole{SuperAdmin}, false) == false {
+		c.Data["json"] = DataTableResponseData{Data: make([]interface{}, 0)}
+		return
+	}
+
+	req := DatableRequestParam{}
+	err := c.ParseForm(&req)
+	if err != nil {
+		logging.RuntimeLog.Error(err.Error())
+	}
+	c.Data["json"] = c.getListData(req)
+}
+
+// PublishAction 将server当前需要同步的文件发布为新的版本
+func (c *WorkerReleaseController) PublishAction() {
+	defer c.ServeJSON()
+	if c.CheckMultiAccessRequest([]RequestRole{SuperAdmin}, false) == false {
+		c.FailedStatus("当前用户权限不允许！")
+		return
+	}
+
+	version, err := comm.PublishRelease(strings.TrimSpace(c.GetString("description")))
+	if err != nil {
+		logging.RuntimeLog.Error(err)
+		c.FailedStatus(err.Error())
+		return
+	}
+	c.SucceededStatus(fmt.Sprintf("已发布版本:%d", version))
+}
+
+// PinAction 指定worker组使用的版本（回滚到之前的版本）
+func (c *WorkerReleaseController) PinAction() {
+	defer c.ServeJSON()
+	if c.CheckMultiAccessRequest([]RequestRole{SuperAdmin}, false) == false {
+		c.FailedStatus("当前用户权限不允许！")
+		return
+	}
+
+	version, err := c.GetInt("version")
+	if err != nil {
+		logging.RuntimeLog.Error(err.Error())
+		c.FailedStatus(err.Error())
+		return
+	}
+	if err = comm.SetWorkerReleasePin(strings.TrimSpace(c.GetString("worker_tag")), version); err != nil {
+		c.FailedStatus(err.Error())
+		return
+	}
+	c.SucceededStatus("已设置worker组的版本，并通知在线的worker同步！")
+}
+
+// UnpinAction 取消worker组指定的版本，恢复使用最新版本
+func (c *WorkerReleaseController) UnpinAction() {
+	defer c.ServeJSON()
+	if c.CheckMultiAccessRequest([]RequestRole{SuperAdmin}, false) == false {
+		c.FailedStatus("当前用户权限不允许！")
+		return
+	}
+
+	c.MakeStatusResponse(comm.DeleteWorkerReleasePin(strings.TrimSpace(c.GetString("worker_tag"))))
+}
+
+// DeleteAction 删除版本
+func (c *WorkerReleaseController) DeleteAction() {
+	defer c.ServeJSON()
+	if c.CheckMultiAccessRequest([]RequestRole{SuperAdmin}, false) == false {
+		c.FailedStatus("当前用户权限不允许！")
+		return
+	}
+
+	version, err := c.GetInt("version")
+	if err != nil {
+		logging.RuntimeLog.Error(err.Error())
+		c.FailedStatus(err.Error())
+		return
+	}
+	if err = comm.DeleteRelease(version); err != nil {
+		c.FailedStatus(err.Error())
+		return
+	}
+	c.SucceededStatus("success")
+}
+
+// PublicKeyAction 获取验证版本签名的公钥（release.pub）的内容
+func (c *WorkerReleaseController) PublicKeyAction() {
+	defer c.ServeJSON()
+	if c.CheckMultiAccessRequest([]RequestRole{SuperAdmin}, false) == false {
+		c.FailedStatus("当前用户权限不允许！")
+		return
+	}
+
+	publicKeyPEM, _, err := comm.ReleasePublicKey()
+	if err != nil {
+		logging.RuntimeLog.Error(err)
+		c.FailedStatus(err.Error())
+		return
+	}
+	c.SucceededStatus(string(publicKeyPEM))
+}
+
+// getListData 获取列表数据，同时获取指定使用每个版本的worker组
+func (c *WorkerReleaseController) getListData(req DatableRequestParam) (resp DataTableResponseData) {
+	pinTags := make(map[int][]string)
+	pin := db.WorkerReleasePin{}
+	for _, p := range pin.Gets() {
+		pinTags[p.Version] = append(pinTags[p.Version], p.WorkerTag)
+	}
+
+	r := db.WorkerRelease{}
+	results := r.Gets()
+	for i, row := range results {
+		data := WorkerReleaseListData{
+			Id:          row.Id,
+			Index:       i + 1,
+			Version:     row.Version,
+			Description: row.Description,
+			FileCount:   row.FileCount,
+			IsLatest:    i == 0,
+			WorkerTags:  pinTags[row.Version],
+			CreateTime:  FormatDateTime(row.CreateDatetime),
+		}
+		if data.WorkerTags == nil {
+			data.WorkerTags = make([]string, 0)
+		}
+		resp.Data = append(resp.Data, data)
+	}
+	resp.Draw = req.Draw
+	resp.RecordsTotal = len(results)
+	resp.RecordsFiltered = len(results)
+	if resp.Data == nil {
+		resp.Data = make([]interface{}, 0)
+	}
+	return
+}
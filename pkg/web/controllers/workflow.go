@@ -0,0 +1,365 @@
+package controllers
+
+import (
+	"encoding/json"
+	"fmt"
+	"github.com/hanc00l/nemo_go/pkg/db"
+	"github.com/hanc00l/nemo_go/pkg/logging"
+	"github.com/hanc00l/nemo_go/pkg/task/ampq"
+	"github.com/hanc00l/nemo_go/pkg/task/runner"
+	"github.com/hanc00l/nemo_go/pkg/task/workflow"
+	"strings"
+)
+
+type WorkflowController struct {
+	BaseController
+}
+
+type workflowAddRequestParam struct {
+	WorkflowName string `form:"workflow_name"`
+	Description  string `form:"description"`
+	Definition   string `form:"definition"`
+}
+
+type WorkflowListData struct {
+	Id           int    `json:"id"`
+	Index        int    `json:"index"`
+	WorkflowName string `json:"workflow_name"`
+	Description  string `json:"description"`
+	Steps        string `json:"steps"`
+	UpdateTime   string `json:"update_time"`
+}
+
+type WorkflowInfo struct {
+	Id           int    `json:"id"`
+	WorkflowName string `json:"workflow_name"`
+	Description  string `json:"description"`
+	Definition   string `json:"definition"`
+}
+
+// WorkflowStepInfo 主任务中工作流步骤的执行状态
+type WorkflowStepInfo struct {
+	Id        string
+	Task      string
+	Parent    string
+	Condition string
+	State     string
+	Total     int
+	Running   int
+	Success   int
+	Failure   int
+}
+
+// IndexAction 显示列表页面
+func (c *WorkflowController) IndexAction() {
+	c.Layout = "base.html"
+	c.TplName = "workflow-list.html"
+}
+
+// ListAction 列表的数据
+func (c *WorkflowController) ListAction() {
+	defer c.ServeJSON()
+
+	req := DatableRequestParam{}
+	err := c.ParseForm(&req)
+	if err != nil {
+		logging.RuntimeLog.Error(err.Error())
+	}
+	c.Data["json"] = c.getListData(req)
+}
+
+// GetAction 一个记录的详细情况
+func (c *WorkflowController) GetAction() {
+	defer c.ServeJSON()
+
+	w, ok := c.getWorkflowOfCurrentWorkspace()
+	if !ok {
+		c.Data["json"] = WorkflowInfo{}
+		return
+	}
+	c.Data["json"] = WorkflowInfo{
+		Id:           w.Id,
+		WorkflowName: w.WorkflowName,
+		Description:  w.Description,
+		Definition:   w.Definition,
+	}
+}
+
+// AddSaveAction 保存新增的记录
+func (c *WorkflowController) AddSaveAction() {
+	defer c.ServeJSON()
+	if c.CheckMultiAccessRequest([]RequestRole{SuperAdmin, Admin}, false) == false {
+		c.FailedStatus("当前用户权限不允许！")
+		return
+	}
+
+	workspaceId := c.GetCurrentWorkspace()
+	if workspaceId <= 0 {
+		c.FailedStatus("未选择当前的工作空间！")
+		return
+	}
+	req := workflowAddRequestParam{}
+	if err := c.ParseForm(&req); err != nil {
+		logging.RuntimeLog.Error(err.Error())
+		c.FailedStatus(err.Error())
+		return
+	}
+	if msg := validateWorkflow(&req); msg != "" {
+		c.FailedStatus(msg)
+		return
+	}
+	w := db.Workflow{WorkspaceId: workspaceId, WorkflowName: req.WorkflowName}
+	if w.GetByName() {
+		c.FailedStatus("工作流名称已存在！")
+		return
+	}
+	w.Description = req.Description
+	w.Definition = req.Definition
+	c.MakeStatusResponse(w.Add())
+}
+
+// UpdateAction 更新记录
+func (c *WorkflowController) UpdateAction() {
+	defer c.ServeJSON()
+	if c.CheckMultiAccessRequest([]RequestRole{SuperAdmin, Admin}, false) == false {
+		c.FailedStatus("当前用户权限不允许！")
+		return
+	}
+
+	w, ok := c.getWorkflowOfCurrentWorkspace()
+	if !ok {
+		c.FailedStatus("工作流不存在！")
+		return
+	}
+	req := workflowAddRequestParam{}
+	if err := c.ParseForm(&req); err != nil {
+		logging.RuntimeLog.Error(err.Error())
+		c.FailedStatus(err.Error())
+		return
+	}
+	if msg := validateWorkflow(&req); msg != "" {
+		c.FailedStatus(msg)
+		return
+	}
+	exist := db.Workflow{WorkspaceId: w.WorkspaceId, WorkflowName: req.WorkflowName}
+	if exist.GetByName() && exist.Id != w.Id {
+		c.FailedStatus("工作流名称已存在！")
+		return
+	}
+	updateMap := make(map[string]interface{})
+	updateMap["workflow_name"] = req.WorkflowName
+	updateMap["description"] = req.Description
+	updateMap["definition"] = req.Definition
+	c.MakeStatusResponse(w.Update(updateMap))
+}
+
+// DeleteAction 删除一个记录
+func (c *WorkflowController) DeleteAction() {
+	defer c.ServeJSON()
+	if c.CheckMultiAccessRequest([]RequestRole{SuperAdmin, Admin}, false) == false {
+		c.FailedStatus("当前用户权限不允许！")
+		return
+	}
+
+	w, ok := c.getWorkflowOfCurrentWorkspace()
+	if !ok {
+		c.FailedStatus("工作流不存在！")
+		return
+	}
+	c.MakeStatusResponse(w.Delete())
+}
+
+// RunAction 执行工作流任务，或保存为计划任务
+func (c *WorkflowController) RunAction() {
+	defer c.ServeJSON()
+	if c.CheckMultiAccessRequest([]RequestRole{SuperAdmin, Admin}, false) == false {
+		c.FailedStatus("当前用户权限不允许！")
+		return
+	}
+
+	req := runner.WorkflowRequestParam{}
+	if err := c.ParseForm(&req); err != nil {
+		logging.RuntimeLog.Error(err)
+		c.FailedStatus(err.Error())
+		return
+	}
+	workspaceId := c.GetCurrentWorkspace()
+	if workspaceId <= 0 {
+		c.FailedStatus("请选择一个当前的工作空间！（如果是超级管理员，请在右上角进行切换）")
+		return
+	}
+	if req.Target = strings.TrimSpace(req.Target); req.Target == "" && req.TargetSetId <= 0 {
+		c.FailedStatus("no target")
+		return
+	}
+	if !checkTargetSet(req.TargetSetId, workspaceId) {
+		c.FailedStatus("目标集合不存在！")
+		return
+	}
+	if !checkWorkerTag(req.WorkerTag) {
+		c.FailedStatus("worker标签只允许字母、数字、“_”及“-”！")
+		return
+	}
+	if c.IsServerAPI {
+		// webapi方式：多个目标以“,”分隔
+		req.Target = strings.Join(strings.Split(req.Target, ","), "\n")
+	}
+	w := db.Workflow{Id: req.WorkflowId}
+	if !w.Get() || w.WorkspaceId != workspaceId {
+		c.FailedStatus("工作流不存在！")
+		return
+	}
+	kwArgs, err := json.Marshal(req)
+	if err != nil {
+		c.FailedStatus(err.Error())
+		return
+	}
+	var taskId string
+	if req.IsTaskCron {
+		if taskId = runner.SaveCronTask("workflow", string(kwArgs), req.TaskCronRule, req.TaskCronComment, workspaceId); taskId == "" {
+			c.FailedStatus("save to db fail")
+			return
+		}
+	} else {
+		if taskId, err = runner.SaveMainTask("workflow", string(kwArgs), "", workspaceId); err != nil {
+			c.FailedStatus(err.Error())
+			return
+		}
+	}
+	c.SucceededStatus(taskId)
+}
+
+// getWorkflowOfCurrentWorkspace 获取请求参数id指定的、属于当前工作空间的工作流
+func (c *WorkflowController) getWorkflowOfCurrentWorkspace() (w db.Workflow, ok bool) {
+	id, err := c.GetInt("id")
+	if err != nil {
+		logging.RuntimeLog.Error(err.Error())
+		return
+	}
+	w.Id = id
+	if !w.Get() || w.WorkspaceId != c.GetCurrentWorkspace() {
+		return
+	}
+	return w, true
+}
+
+// getListData 获取列表数据
+func (c *WorkflowController) getListData(req DatableRequestParam) (resp DataTableResponseData) {
+	w := db.Workflow{WorkspaceId: c.GetCurrentWorkspace()}
+	results := w.GetsByWorkspace()
+	for i, row := range results {
+		var steps []string
+		if definition, err := workflow.Parse(row.Definition); err == nil {
+			for _, s := range definition.Steps {
+				steps = append(steps, fmt.Sprintf("%s(%s)", s.Id, s.Task))
+			}
+		}
+		resp.Data = append(resp.Data, WorkflowListData{
+			Id:           row.Id,
+			Index:        i + 1,
+			WorkflowName: row.WorkflowName,
+			Description:  row.Description,
+			Steps:        strings.Join(steps, ","),
+			UpdateTime:   FormatDateTime(row.UpdateDatetime),
+		})
+	}
+	resp.Draw = req.Draw
+	resp.RecordsTotal = len(results)
+	resp.RecordsFiltered = len(results)
+	if resp.Data == nil {
+		resp.Data = make([]interface{}, 0)
+	}
+	return
+}
+
+// validateWorkflow 校验工作流的参数
+func validateWorkflow(req *workflowAddRequestParam) string {
+	req.WorkflowName = strings.TrimSpace(req.WorkflowName)
+	if req.WorkflowName == "" {
+		return "工作流名称不能为空！"
+	}
+	if _, err := workflow.Parse(req.Definition); err != nil {
+		return "工作流定义错误：" + err.Error()
+	}
+	return ""
+}
+
+// getWorkflowSteps 获取工作流任务的步骤（按树的深度分层）及各步骤的执行状态
+func getWorkflowSteps(task db.TaskMain) (levels [][]WorkflowStepInfo) {
+	var req runner.WorkflowRequestParam
+	if err := json.Unmarshal([]byte(task.KwArgs), &req); err != nil {
+		return
+	}
+	w := db.Workflow{Id: req.WorkflowId}
+	if !w.Get() {
+		return
+	}
+	definition, err := workflow.Parse(w.Definition)
+	if err != nil {
+		return
+	}
+	stepLevels, err := definition.Levels()
+	if err != nil {
+		return
+	}
+	taskRun := db.TaskRun{MainTaskId: task.TaskId}
+	stepStates := make(map[string]map[string]int)
+	for _, s := range taskRun.CountWorkflowStepState() {
+		if _, ok := stepStates[s.WorkflowStep]; !ok {
+			stepStates[s.WorkflowStep] = make(map[string]int)
+		}
+		stepStates[s.WorkflowStep][s.State] += s.Count
+	}
+	for _, stepLevel := range stepLevels {
+		var level []WorkflowStepInfo
+		for _, step := range stepLevel {
+			info := WorkflowStepInfo{
+				Id:     step.Id,
+				Task:   step.Task,
+				Parent: step.Parent,
+			}
+			if step.Condition != nil {
+				var conditions []string
+				if step.Condition.Port != "" {
+					conditions = append(conditions, "port:"+step.Condition.Port)
+				}
+				if step.Condition.Fingerprint != "" {
+					conditions = append(conditions, "fingerprint:"+step.Condition.Fingerprint)
+				}
+				if step.Condition.Title != "" {
+					conditions = append(conditions, "title:"+step.Condition.Title)
+				}
+				info.Condition = strings.Join(conditions, " ")
+			}
+			for state, count := range stepStates[step.Id] {
+				info.Total += count
+				switch state {
+				case ampq.SUCCESS:
+					info.Success += count
+				case ampq.FAILURE:
+					info.Failure += count
+				case ampq.CREATED, ampq.STARTED, ampq.RECEIVED:
+					info.Running += count
+				}
+			}
+			info.State = workflowStepState(info)
+			level = append(level, info)
+		}
+		levels = append(levels, level)
+	}
+	return
+}
+
+// workflowStepState 根据子任务的数量得到步骤的状态
+func workflowStepState(info WorkflowStepInfo) string {
+	if info.Total == 0 {
+		return "PENDING"
+	}
+	if info.Running > 0 {
+		return ampq.STARTED
+	}
+	if info.Failure > 0 {
+		return ampq.FAILURE
+	}
+	return ampq.SUCCESS
+}
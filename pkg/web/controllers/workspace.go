@@ -5,8 +5,10 @@ import (
 	"github.com/hanc00l/nemo_go/pkg/conf"
 	"github.com/hanc00l/nemo_go/pkg/db"
 	"github.com/hanc00l/nemo_go/pkg/logging"
+	"github.com/hanc00l/nemo_go/pkg/task/scope"
 	"os"
 	"path/filepath"
+	"strings"
 )
 
 type WorkspaceController struct {
@@ -25,6 +27,8 @@ type WorkspaceData struct {
 	WorkspaceDescription string `json:"workspace_description" form:"workspace_description"`
 	State                string `json:"state" form:"state"`
 	SortOrder            int    `json:"sort_order" form:"sort_order"`
+	NotifyConfig         string `json:"notify_config" form:"notify_config"`
+	ScopeConfig          string `json:"scope_config" form:"scope_config"`
 	CreateDatetime       string `json:"create_time" form:"-"`
 	UpdateDatetime       string `json:"update_time" form:"-"`
 }
@@ -229,11 +233,21 @@ func (c *WorkspaceController) AddSaveAction() {
 		c.FailedStatus(err.Error())
 		return
 	}
+	if err = validateNotifyConfig(wData.NotifyConfig); err != nil {
+		c.FailedStatus("消息通知配置错误：" + err.Error())
+		return
+	}
+	if _, err = scope.Parse(wData.ScopeConfig); err != nil {
+		c.FailedStatus("授权范围配置错误：" + err.Error())
+		return
+	}
 	workspace := db.Workspace{}
 	workspace.WorkspaceName = wData.WorkspaceName
 	workspace.State = wData.State
 	workspace.SortOrder = wData.SortOrder
 	workspace.WorkspaceDescription = wData.WorkspaceDescription
+	workspace.NotifyConfig = wData.NotifyConfig
+	workspace.ScopeConfig = wData.ScopeConfig
 	c.MakeStatusResponse(workspace.Add())
 	logging.RuntimeLog.Infof("add workspace:%s,GUID:%s", workspace.WorkspaceName, workspace.WorkspaceGUID)
 
@@ -260,6 +274,8 @@ func (c *WorkspaceController) GetAction() {
 		wData.SortOrder = workspace.SortOrder
 		wData.WorkspaceDescription = workspace.WorkspaceDescription
 		wData.WorkspaceGUID = workspace.WorkspaceGUID
+		wData.NotifyConfig = workspace.NotifyConfig
+		wData.ScopeConfig = workspace.ScopeConfig
 		wData.UpdateDatetime = FormatDateTime(workspace.UpdateDatetime)
 		wData.CreateDatetime = FormatDateTime(workspace.CreateDatetime)
 	}
@@ -286,12 +302,22 @@ func (c *WorkspaceController) UpdateAction() {
 		c.FailedStatus(err.Error())
 		return
 	}
+	if err = validateNotifyConfig(wData.NotifyConfig); err != nil {
+		c.FailedStatus("消息通知配置错误：" + err.Error())
+		return
+	}
+	if _, err = scope.Parse(wData.ScopeConfig); err != nil {
+		c.FailedStatus("授权范围配置错误：" + err.Error())
+		return
+	}
 	workspace := db.Workspace{Id: id}
 	updateMap := make(map[string]interface{})
 	updateMap["workspace_name"] = wData.WorkspaceName
 	updateMap["sort_order"] = wData.SortOrder
 	updateMap["state"] = wData.State
 	updateMap["workspace_description"] = wData.WorkspaceDescription
+	updateMap["notify_config"] = wData.NotifyConfig
+	updateMap["scope_config"] = wData.ScopeConfig
 	c.MakeStatusResponse(workspace.Update(updateMap))
 	logging.RuntimeLog.Infof("update workspace:%s", wData.WorkspaceName)
 
@@ -319,3 +345,12 @@ func (c *WorkspaceController) DeleteAction() {
 	logging.RuntimeLog.Infof("delete workspace:%s,GUID:%s", workspace.WorkspaceName, workspace.WorkspaceGUID)
 
 }
+
+// validateNotifyConfig 校验工作空间的消息通知配置（yaml格式，为空时使用全局配置）
+func validateNotifyConfig(content string) error {
+	if strings.TrimSpace(content) == "" {
+		return nil
+	}
+	_, err := conf.ParseNotifyConfig(content)
+	return err
+}
@@ -52,6 +52,7 @@ func init() {
 	web.CtrlPost("/ip-info-http", (*controllers.IPController).InfoHttpAction)
 	web.CtrlPost("/ip-block", (*controllers.IPController).BlackIPAction)
 	web.CtrlGet("/ip-export", (*controllers.IPController).ExportIPResultAction)
+	web.CtrlPost("/ip-history", (*controllers.IPController).HistoryAction)
 
 	web.CtrlGet("/domain-list", (*controllers.DomainController).IndexAction)
 	web.CtrlPost("/domain-list", (*controllers.DomainController).ListAction)
@@ -66,6 +67,7 @@ func init() {
 	web.CtrlPost("/domain-color-tag", (*controllers.DomainController).MarkColorTagAction)
 	web.CtrlPost("/domain-pin-top", (*controllers.DomainController).PinTopAction)
 	web.CtrlPost("/domain-info-http", (*controllers.DomainController).InfoHttpAction)
+	web.CtrlPost("/domain-history", (*controllers.DomainController).HistoryAction)
 	web.CtrlPost("/domain-block", (*controllers.DomainController).BlockDomainAction)
 	web.CtrlGet("/domain-export", (*controllers.DomainController).ExportDomainResultAction)
 
@@ -97,7 +99,11 @@ func init() {
 	web.CtrlPost("/task-batch-delete", (*controllers.TaskController).DeleteBatchAction)
 	web.CtrlPost("/task-start-xscan", (*controllers.TaskController).StartXScanTaskAction)
 	web.CtrlGet("/task-info-main", (*controllers.TaskController).InfoMainAction)
+	web.CtrlGet("/task-diff-main", (*controllers.TaskController).DiffMainAction)
 	web.CtrlPost("/task-delete-main", (*controllers.TaskController).DeleteMainAction)
+	web.CtrlPost("/task-retry-main", (*controllers.TaskController).RetryFailedMainAction)
+	web.CtrlPost("/task-pause-main", (*controllers.TaskController).PauseMainAction)
+	web.CtrlPost("/task-resume-main", (*controllers.TaskController).ResumeMainAction)
 
 	web.CtrlGet("/task-cron-list", (*controllers.TaskController).IndexCronAction)
 	web.CtrlPost("/task-cron-list", (*controllers.TaskController).ListCronAction)
@@ -114,6 +120,48 @@ func init() {
 	web.CtrlPost("/key-word-get", (*controllers.KeySearchController).GetAction)
 	web.CtrlPost("/key-word-update", (*controllers.KeySearchController).UpdateAction)
 
+	web.CtrlGet("/alert-rule-list", (*controllers.AlertRuleController).IndexAction)
+	web.CtrlPost("/alert-rule-list", (*controllers.AlertRuleController).ListAction)
+	web.CtrlPost("/alert-rule-add", (*controllers.AlertRuleController).AddSaveAction)
+	web.CtrlPost("/alert-rule-get", (*controllers.AlertRuleController).GetAction)
+	web.CtrlPost("/alert-rule-update", (*controllers.AlertRuleController).UpdateAction)
+	web.CtrlPost("/alert-rule-del", (*controllers.AlertRuleController).DeleteAction)
+
+	web.CtrlGet("/notify-template-list", (*controllers.NotifyTemplateController).IndexAction)
+	web.CtrlPost("/notify-template-list", (*controllers.NotifyTemplateController).ListAction)
+	web.CtrlPost("/notify-template-add", (*controllers.NotifyTemplateController).AddSaveAction)
+	web.CtrlPost("/notify-template-get", (*controllers.NotifyTemplateController).GetAction)
+	web.CtrlPost("/notify-template-update", (*controllers.NotifyTemplateController).UpdateAction)
+	web.CtrlPost("/notify-template-del", (*controllers.NotifyTemplateController).DeleteAction)
+
+	web.CtrlGet("/workflow-list", (*controllers.WorkflowController).IndexAction)
+	web.CtrlPost("/workflow-list", (*controllers.WorkflowController).ListAction)
+	web.CtrlPost("/workflow-add", (*controllers.WorkflowController).AddSaveAction)
+	web.CtrlPost("/workflow-get", (*controllers.WorkflowController).GetAction)
+	web.CtrlPost("/workflow-update", (*controllers.WorkflowController).UpdateAction)
+	web.CtrlPost("/workflow-del", (*controllers.WorkflowController).DeleteAction)
+	web.CtrlPost("/workflow-run", (*controllers.WorkflowController).RunAction)
+
+	web.CtrlGet("/targetset-list", (*controllers.TargetSetController).IndexAction)
+	web.CtrlPost("/targetset-list", (*controllers.TargetSetController).ListAction)
+	web.CtrlPost("/targetset-add", (*controllers.TargetSetController).AddSaveAction)
+	web.CtrlPost("/targetset-get", (*controllers.TargetSetController).GetAction)
+	web.CtrlPost("/targetset-update", (*controllers.TargetSetController).UpdateAction)
+	web.CtrlPost("/targetset-del", (*controllers.TargetSetController).DeleteAction)
+
+	web.CtrlGet("/worker-identity-list", (*controllers.WorkerIdentityController).IndexAction)
+	web.CtrlPost("/worker-identity-list", (*controllers.WorkerIdentityController).ListAction)
+	web.CtrlPost("/worker-identity-enroll", (*controllers.WorkerIdentityController).EnrollAction)
+	web.CtrlPost("/worker-identity-revoke", (*controllers.WorkerIdentityController).RevokeAction)
+	web.CtrlPost("/worker-identity-del", (*controllers.WorkerIdentityController).DeleteAction)
+	web.CtrlGet("/worker-release-list", (*controllers.WorkerReleaseController).IndexAction)
+	web.CtrlPost("/worker-release-list", (*controllers.WorkerReleaseController).ListAction)
+	web.CtrlPost("/worker-release-publish", (*controllers.WorkerReleaseController).PublishAction)
+	web.CtrlPost("/worker-release-pin", (*controllers.WorkerReleaseController).PinAction)
+	web.CtrlPost("/worker-release-unpin", (*controllers.WorkerReleaseController).UnpinAction)
+	web.CtrlPost("/worker-release-del", (*controllers.WorkerReleaseController).DeleteAction)
+	web.CtrlPost("/worker-release-pubkey", (*controllers.WorkerReleaseController).PublicKeyAction)
+
 	web.CtrlPost("/workspace-user-list", (*controllers.WorkspaceController).UserWorkspaceAction)
 	web.CtrlPost("/workspace-user-change", (*controllers.WorkspaceController).ChangeWorkspaceSelectAction)
 	web.CtrlGet("/workspace-list", (*controllers.WorkspaceController).IndexAction)
@@ -0,0 +1,74 @@
+package controllers
+
+import ctrl "github.com/hanc00l/nemo_go/pkg/web/controllers"
+
+type AlertRuleController struct {
+	ctrl.AlertRuleController
+}
+
+// @Title List
+// @Description 获取当前工作空间的告警规则列表
+// @Param authorization		header string true "token"
+// @Param start 			formData int true "查询的起始行数"
+// @Param length 			formData int true "返回指定的数量"
+// @Param rule_name 		formData string false "规则名称"
+// @Param rule_type 		formData string false "规则类型（port/vulnerability/domain/title）"
+// @Success 200 {object} models.AlertRuleDataTableResponseData
+// @router /list [post]
+func (c *AlertRuleController) List() {
+	c.IsServerAPI = true
+	c.ListAction()
+}
+
+// @Title Info
+// @Description 显示一个告警规则的详情
+// @Param authorization		header string true "token"
+// @Param id 				formData int true "id"
+// @Success 200 {object} models.AlertRuleInfo
+// @router /info [post]
+func (c *AlertRuleController) Info() {
+	c.IsServerAPI = true
+	c.GetAction()
+}
+
+// @Title SaveRule
+// @Description 保存一个新增的告警规则
+// @Param authorization		header string true "token"
+// @Param rule_name 		formData string true "规则名称"
+// @Param rule_type 		formData string true "规则类型（port/vulnerability/domain/title）"
+// @Param rule_content 		formData string false "规则内容，多个以,分隔"
+// @Param notify 			formData string false "通知渠道，多个以,分隔，为空时发送到全部已配置的通知"
+// @Param state 			formData string false "状态（enable/disable）"
+// @Success 200 {object} models.StatusResponseData
+// @router /save [post]
+func (c *AlertRuleController) SaveRule() {
+	c.IsServerAPI = true
+	c.AddSaveAction()
+}
+
+// @Title UpdateRule
+// @Description 更新一个已有的告警规则
+// @Param authorization		header string true "token"
+// @Param id		 		formData int true "id"
+// @Param rule_name 		formData string true "规则名称"
+// @Param rule_type 		formData string true "规则类型（port/vulnerability/domain/title）"
+// @Param rule_content 		formData string false "规则内容，多个以,分隔"
+// @Param notify 			formData string false "通知渠道，多个以,分隔，为空时发送到全部已配置的通知"
+// @Param state 			formData string false "状态（enable/disable）"
+// @Success 200 {object} models.StatusResponseData
+// @router /update [post]
+func (c *AlertRuleController) UpdateRule() {
+	c.IsServerAPI = true
+	c.UpdateAction()
+}
+
+// @Title DeleteRule
+// @Description 删除一个告警规则
+// @Param authorization	header string true "token"
+// @Param id 			formData int true "id"
+// @Success 200 {object} models.StatusResponseData
+// @router /delete [post]
+func (c *AlertRuleController) DeleteRule() {
+	c.IsServerAPI = true
+	c.DeleteAction()
+}
@@ -80,10 +80,10 @@ func (c *ConfigController) LoadDefaultConfig() {
 // @Title SaveDefaultConfig
 // @Description 保存默认的配置
 // @Param authorization		header string true "token"
-// @Param cmdbin			formData string true "端口扫描使用的程序"
+// @Param cmdbin			formData string true "端口扫描使用的程序（masscan、nmap或tcpscan）"
 // @Param port				formData string true "默认端口（nmap支持和格式，如--top-ports 1000）"
 // @Param rate				formData int true "速率（默认1000）"
-// @Param tech				formData string true "扫描技术（nmap支持的格式，如-sS，-sT，-sV），masscan只支持-sS"
+// @Param tech				formData string true "扫描技术（nmap支持的格式，如-sS，-sT，-sV），masscan只支持-sS；tcpscan为-sV时获取端口的banner"
 // @Param ping				formData bool true "是否Ping（只支持nmap）"
 // @Param wordlist			formData string true "Brute使用的子域名字典文件（默认：subnames.txt，9万条记录；较大的字典：subnames_medium.txt，88万条记录）"
 // @Param subfinder			formData bool true "是否进行子域名枚举"
@@ -139,8 +139,8 @@ func (c *ConfigController) SaveDefaultConfig() {
 		return
 	}
 	conf.GlobalWorkerConfig().Portscan.Cmdbin = "masscan"
-	if data.CmdBin == "nmap" {
-		conf.GlobalWorkerConfig().Portscan.Cmdbin = "nmap"
+	if data.CmdBin == "nmap" || data.CmdBin == "tcpscan" {
+		conf.GlobalWorkerConfig().Portscan.Cmdbin = data.CmdBin
 	}
 	//portscan
 	conf.GlobalWorkerConfig().Portscan.Port = data.Port
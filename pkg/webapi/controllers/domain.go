@@ -44,6 +44,19 @@ func (c *DomainController) Info() {
 	c.InfoAction()
 }
 
+// @Title History
+// @Description 获取一个domain的资产变更记录
+// @Param authorization	header string true "token"
+// @Param domain 		formData string true "domain"
+// @Param workspace 	formData int true "所在的workspace id"
+// @Param limit 		formData int false "返回的记录数量，默认为全部"
+// @Success 200 {object} models.AssetHistoryInfo
+// @router /history [post]
+func (c *DomainController) History() {
+	c.IsServerAPI = true
+	c.HistoryAction()
+}
+
 // @Title DeleteDomain
 // @Description 删除一个domain
 // @Param authorization	header string true "token"
@@ -51,6 +51,19 @@ func (c *IPController) Info() {
 	c.InfoAction()
 }
 
+// @Title History
+// @Description 获取一个IP的资产变更记录
+// @Param authorization	header string true "token"
+// @Param ip 			formData string true "ip"
+// @Param workspace 	formData int true "所在的workspace id"
+// @Param limit 		formData int false "返回的记录数量，默认为全部"
+// @Success 200 {object} models.AssetHistoryInfo
+// @router /history [post]
+func (c *IPController) History() {
+	c.IsServerAPI = true
+	c.HistoryAction()
+}
+
 // @Title DeleteIP
 // @Description 删除一个IP
 // @Param authorization	header string true "token"
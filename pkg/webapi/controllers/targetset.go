@@ -0,0 +1,70 @@
+package controllers
+
+import ctrl "github.com/hanc00l/nemo_go/pkg/web/controllers"
+
+type TargetSetController struct {
+	ctrl.TargetSetController
+}
+
+// @Title List
+// @Description 获取当前工作空间的目标集合列表
+// @Param authorization		header string true "token"
+// @Success 200 {object} models.TargetSetDataTableResponseData
+// @router /list [post]
+func (c *TargetSetController) List() {
+	c.IsServerAPI = true
+	c.ListAction()
+}
+
+// @Title Info
+// @Description 显示一个目标集合的详情，包括全部的条目
+// @Param authorization		header string true "token"
+// @Param id 				formData int true "id"
+// @Success 200 {object} models.TargetSetInfo
+// @router /info [post]
+func (c *TargetSetController) Info() {
+	c.IsServerAPI = true
+	c.GetAction()
+}
+
+// @Title SaveTargetSet
+// @Description 保存一个新增的目标集合
+// @Param authorization		header string true "token"
+// @Param targetset_name 	formData string true "名称"
+// @Param description 		formData string false "描述"
+// @Param include 			formData string false "包含的目标（IP、CIDR、IP范围、域名），以换行或,分隔"
+// @Param exclude 			formData string false "排除的目标，以换行或,分隔"
+// @Param file 				formData string false "目标文件的内容：每行一个目标，以!开头的为排除的目标，以#开头的为注释"
+// @Success 200 {object} models.StatusResponseData
+// @router /save [post]
+func (c *TargetSetController) SaveTargetSet() {
+	c.IsServerAPI = true
+	c.AddSaveAction()
+}
+
+// @Title UpdateTargetSet
+// @Description 更新一个已有的目标集合，新的条目替换原有的全部条目
+// @Param authorization		header string true "token"
+// @Param id		 		formData int true "id"
+// @Param targetset_name 	formData string true "名称"
+// @Param description 		formData string false "描述"
+// @Param include 			formData string false "包含的目标（IP、CIDR、IP范围、域名），以换行或,分隔"
+// @Param exclude 			formData string false "排除的目标，以换行或,分隔"
+// @Param file 				formData string false "目标文件的内容：每行一个目标，以!开头的为排除的目标，以#开头的为注释"
+// @Success 200 {object} models.StatusResponseData
+// @router /update [post]
+func (c *TargetSetController) UpdateTargetSet() {
+	c.IsServerAPI = true
+	c.UpdateAction()
+}
+
+// @Title DeleteTargetSet
+// @Description 删除一个目标集合
+// @Param authorization	header string true "token"
+// @Param id 			formData int true "id"
+// @Success 200 {object} models.StatusResponseData
+// @router /delete [post]
+func (c *TargetSetController) DeleteTargetSet() {
+	c.IsServerAPI = true
+	c.DeleteAction()
+}
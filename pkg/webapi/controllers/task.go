@@ -54,6 +54,18 @@ func (c *TaskController) InfoMainTask() {
 	c.InfoMainAction()
 }
 
+// @Title DiffMainTask
+// @Description 获取从指定任务完成后到当前MainTask任务完成时的资产变更
+// @Param authorization		header string true "token"
+// @Param task_id 			formData string true "任务ID"
+// @Param since_task_id 	formData string true "对比的任务ID"
+// @Success 200 {object} models.TaskDiffInfo
+// @router /main/diff [post]
+func (c *TaskController) DiffMainTask() {
+	c.IsServerAPI = true
+	c.DiffMainAction()
+}
+
 // @Title InfoRunTask
 // @Description 显示一个RunTask任务的详情
 // @Param authorization		header string true "token"
@@ -100,6 +112,40 @@ func (c *TaskController) DeleteMainTask() {
 	c.DeleteMainAction()
 }
 
+// @Title PauseMainTask
+// @Description 暂停一个执行中的MainTask任务
+// @Param authorization	header string true "token"
+// @Param task_id 		formData string true "task id"
+// @Param force 		formData bool false "是否不再等待执行中的子任务（恢复后重新执行）"
+// @Success 200 {object} models.StatusResponseData
+// @router /main/pause [post]
+func (c *TaskController) PauseMainTask() {
+	c.IsServerAPI = true
+	c.PauseMainAction()
+}
+
+// @Title ResumeMainTask
+// @Description 恢复一个暂停的MainTask任务
+// @Param authorization	header string true "token"
+// @Param task_id 		formData string true "task id"
+// @Success 200 {object} models.StatusResponseData
+// @router /main/resume [post]
+func (c *TaskController) ResumeMainTask() {
+	c.IsServerAPI = true
+	c.ResumeMainAction()
+}
+
+// @Title RetryMainTask
+// @Description 重新执行一个MainTask任务中全部执行失败的子任务
+// @Param authorization	header string true "token"
+// @Param task_id 		formData string true "task id"
+// @Success 200 {object} models.StatusResponseData
+// @router /main/retry [post]
+func (c *TaskController) RetryMainTask() {
+	c.IsServerAPI = true
+	c.RetryFailedMainAction()
+}
+
 // @Title DeleteCronTask
 // @Description 删除一个CronTask任务记录
 // @Param authorization	header string true "token"
@@ -188,6 +234,9 @@ type XScanRequestParam struct {
 // @Description 执行一个XScan任务
 // @Param authorization	header string true "token"
 // @Param target 		formData string true "任务目标(ip、ip/掩码或域名），多个任务以,分开"
+// @Param targetset_id 	formData int false "目标集合的id；使用目标集合时需指定xscan_type（xportscan或xdomainscan），全部目标作为一个任务"
+// @Param worker_tag 	formData string false "执行任务的worker标签，为空时不限制worker"
+// @Param xscan_type 	formData string false "任务类型"
 // @Param port 			formData string false "ip目标扫描的端口"
 // @Param org_id 		formData int false "关联的组机构"
 // @Param onlineapi 	formData bool false "是否要执行fofa、quake、hunter等任务"
@@ -0,0 +1,51 @@
+package controllers
+
+import ctrl "github.com/hanc00l/nemo_go/pkg/web/controllers"
+
+type WorkerIdentityController struct {
+	ctrl.WorkerIdentityController
+}
+
+// @Title List
+// @Description 获取已注册的worker身份列表
+// @Param authorization		header string true "token"
+// @Success 200 {object} models.WorkerIdentityDataTableResponseData
+// @router /list [post]
+func (c *WorkerIdentityController) List() {
+	c.IsServerAPI = true
+	c.ListAction()
+}
+
+// @Title Enroll
+// @Description 注册worker身份，msg为worker身份文件（worker_identity.yml）的内容，只能在注册时获取
+// @Param authorization		header string true "token"
+// @Param worker_name 		formData string true "worker名称，只能包含字母、数字及_.@-"
+// @Param description 		formData string false "描述"
+// @Success 200 {object} models.StatusResponseData
+// @router /enroll [post]
+func (c *WorkerIdentityController) Enroll() {
+	c.IsServerAPI = true
+	c.EnrollAction()
+}
+
+// @Title Revoke
+// @Description 吊销worker身份
+// @Param authorization	header string true "token"
+// @Param id 			formData int true "id"
+// @Success 200 {object} models.StatusResponseData
+// @router /revoke [post]
+func (c *WorkerIdentityController) Revoke() {
+	c.IsServerAPI = true
+	c.RevokeAction()
+}
+
+// @Title Delete
+// @Description 删除worker身份
+// @Param authorization	header string true "token"
+// @Param id 			formData int true "id"
+// @Success 200 {object} models.StatusResponseData
+// @router /delete [post]
+func (c *WorkerIdentityController) Delete() {
+	c.IsServerAPI = true
+	c.DeleteAction()
+}
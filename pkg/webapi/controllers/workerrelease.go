@@ -0,0 +1,72 @@
+package controllers
+
+import ctrl "github.com/hanc00l/nemo_go/pkg/web/controllers"
+
+type WorkerReleaseController struct {
+	ctrl.WorkerReleaseController
+}
+
+// @Title List
+// @Description 获取worker同步文件的发布版本列表
+// @Param authorization		header string true "token"
+// @Success 200 {object} models.WorkerReleaseDataTableResponseData
+// @router /list [post]
+func (c *WorkerReleaseController) List() {
+	c.IsServerAPI = true
+	c.ListAction()
+}
+
+// @Title Publish
+// @Description 将server当前同步的文件发布为新的签名版本
+// @Param authorization		header string true "token"
+// @Param description 		formData string false "描述"
+// @Success 200 {object} models.StatusResponseData
+// @router /publish [post]
+func (c *WorkerReleaseController) Publish() {
+	c.IsServerAPI = true
+	c.PublishAction()
+}
+
+// @Title Pin
+// @Description 指定worker组使用的发布版本，用于回滚到之前的版本
+// @Param authorization	header string true "token"
+// @Param version 		formData int true "发布版本"
+// @Param worker_tag 	formData string false "worker标签，为空时为全部worker"
+// @Success 200 {object} models.StatusResponseData
+// @router /pin [post]
+func (c *WorkerReleaseController) Pin() {
+	c.IsServerAPI = true
+	c.PinAction()
+}
+
+// @Title Unpin
+// @Description 取消worker组指定的发布版本，恢复使用最新版本
+// @Param authorization	header string true "token"
+// @Param worker_tag 	formData string false "worker标签，为空时为全部worker"
+// @Success 200 {object} models.StatusResponseData
+// @router /unpin [post]
+func (c *WorkerReleaseController) Unpin() {
+	c.IsServerAPI = true
+	c.UnpinAction()
+}
+
+// @Title Delete
+// @Description 删除发布版本
+// @Param authorization	header string true "token"
+// @Param version 		formData int true "发布版本"
+// @Success 200 {object} models.StatusResponseData
+// @router /delete [post]
+func (c *WorkerReleaseController) Delete() {
+	c.IsServerAPI = true
+	c.DeleteAction()
+}
+
+// @Title PublicKey
+// @Description 获取验证发布版本签名的公钥，msg为公钥文件（release.pub）的内容
+// @Param authorization	header string true "token"
+// @Success 200 {object} models.StatusResponseData
+// @router /pubkey [post]
+func (c *WorkerReleaseController) PublicKey() {
+	c.IsServerAPI = true
+	c.PublicKeyAction()
+}
@@ -0,0 +1,84 @@
+package controllers
+
+import ctrl "github.com/hanc00l/nemo_go/pkg/web/controllers"
+
+type WorkflowController struct {
+	ctrl.WorkflowController
+}
+
+// @Title List
+// @Description 获取当前工作空间的工作流列表
+// @Param authorization		header string true "token"
+// @Success 200 {object} models.WorkflowDataTableResponseData
+// @router /list [post]
+func (c *WorkflowController) List() {
+	c.IsServerAPI = true
+	c.ListAction()
+}
+
+// @Title Info
+// @Description 显示一个工作流的详情
+// @Param authorization		header string true "token"
+// @Param id 				formData int true "id"
+// @Success 200 {object} models.WorkflowInfo
+// @router /info [post]
+func (c *WorkflowController) Info() {
+	c.IsServerAPI = true
+	c.GetAction()
+}
+
+// @Title SaveWorkflow
+// @Description 保存一个新增的工作流
+// @Param authorization		header string true "token"
+// @Param workflow_name 	formData string true "名称"
+// @Param description 		formData string false "描述"
+// @Param definition 		formData string true "工作流定义（JSON）"
+// @Success 200 {object} models.StatusResponseData
+// @router /save [post]
+func (c *WorkflowController) SaveWorkflow() {
+	c.IsServerAPI = true
+	c.AddSaveAction()
+}
+
+// @Title UpdateWorkflow
+// @Description 更新一个已有的工作流
+// @Param authorization		header string true "token"
+// @Param id		 		formData int true "id"
+// @Param workflow_name 	formData string true "名称"
+// @Param description 		formData string false "描述"
+// @Param definition 		formData string true "工作流定义（JSON）"
+// @Success 200 {object} models.StatusResponseData
+// @router /update [post]
+func (c *WorkflowController) UpdateWorkflow() {
+	c.IsServerAPI = true
+	c.UpdateAction()
+}
+
+// @Title DeleteWorkflow
+// @Description 删除一个工作流
+// @Param authorization	header string true "token"
+// @Param id 			formData int true "id"
+// @Success 200 {object} models.StatusResponseData
+// @router /delete [post]
+func (c *WorkflowController) DeleteWorkflow() {
+	c.IsServerAPI = true
+	c.DeleteAction()
+}
+
+// @Title RunWorkflow
+// @Description 执行工作流任务，或保存为计划任务
+// @Param authorization	header string true "token"
+// @Param workflow_id 	formData int true "工作流的id"
+// @Param target 		formData string true "任务目标，多个以,分隔"
+// @Param targetset_id 	formData int false "目标集合的id"
+// @Param worker_tag 	formData string false "执行任务的worker标签，为空时使用步骤定义中的标签"
+// @Param org_id 		formData int false "所属组织的id"
+// @Param taskcron 		formData bool false "是否为定时任务"
+// @Param cronrule 		formData string false "定时任务的执行规则"
+// @Param croncomment 	formData string false "定时任务的说明"
+// @Success 200 {object} models.StatusResponseData
+// @router /run [post]
+func (c *WorkflowController) RunWorkflow() {
+	c.IsServerAPI = true
+	c.RunAction()
+}
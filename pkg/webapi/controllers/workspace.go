@@ -56,6 +56,8 @@ func (c *WorkspaceController) DeleteWorkspace() {
 // @Param workspace_description 	formData string true "描述"
 // @Param state 					formData string true "状态（enable/disable）"
 // @Param sort_order 				formData int true "排序号（默认100）"
+// @Param notify_config 			formData string false "消息通知配置（yaml格式，同server.yml的notify；为空时使用全局配置）"
+// @Param scope_config 			formData string false "授权范围配置（yaml格式，包括ip、domain及window；为空时不限制）"
 // @Success 200 {object} models.StatusResponseData
 // @router /save [post]
 func (c *WorkspaceController) SaveWorkspace() {
@@ -71,6 +73,8 @@ func (c *WorkspaceController) SaveWorkspace() {
 // @Param workspace_description 	formData string true "描述"
 // @Param state 					formData string true "状态（enable/disable）"
 // @Param sort_order 				formData int true "排序号（默认100）"
+// @Param notify_config 			formData string false "消息通知配置（yaml格式，同server.yml的notify；为空时使用全局配置）"
+// @Param scope_config 			formData string false "授权范围配置（yaml格式，包括ip、domain及window；为空时不限制）"
 // @Success 200 {object} models.StatusResponseData
 // @router /update [post]
 func (c *WorkspaceController) UpdateWorkspace() {
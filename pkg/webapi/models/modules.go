@@ -114,6 +114,7 @@ type IPInfo struct {
 	Workspace     string
 	WorkspaceGUID string
 	PinIndex      string
+	History       []AssetHistoryInfo
 }
 
 // TaskListData 任务的列表显示数据
@@ -180,6 +181,34 @@ type TaskInfo struct {
 	Workspace     string
 }
 
+// AssetHistoryInfo 资产的一条变更记录
+type AssetHistoryInfo struct {
+	AssetType  string
+	AssetName  string
+	Item       string
+	Port       int
+	Action     string
+	Field      string
+	OldValue   string
+	NewValue   string
+	MainTaskId string
+	CreateTime string
+}
+
+// TaskDiffInfo 两个任务之间的资产变更
+type TaskDiffInfo struct {
+	TaskId        string
+	TaskName      string
+	SinceTaskId   string
+	SinceTaskName string
+	StartTime     string
+	EndTime       string
+	AddCount      int
+	ChangeCount   int
+	RemoveCount   int
+	History       []AssetHistoryInfo
+}
+
 type TaskCronInfo struct {
 	Id          int
 	TaskId      string
@@ -250,6 +279,7 @@ type DomainInfo struct {
 	Workspace     string
 	WorkspaceGUID string
 	PinIndex      string
+	History       []AssetHistoryInfo
 }
 
 // DomainAttrInfo domain属性
@@ -342,6 +372,8 @@ type WorkspaceData struct {
 	WorkspaceDescription string `json:"workspace_description" form:"workspace_description"`
 	State                string `json:"state" form:"state"`
 	SortOrder            int    `json:"sort_order" form:"sort_order"`
+	NotifyConfig         string `json:"notify_config" form:"notify_config"`
+	ScopeConfig          string `json:"scope_config" form:"scope_config"`
 	CreateDatetime       string `json:"create_time" form:"-"`
 	UpdateDatetime       string `json:"update_time" form:"-"`
 }
@@ -406,14 +438,32 @@ type DashboardStatisticData struct {
 }
 
 type WorkerStatusData struct {
-	Index                    int    `json:"index"`
-	WorkName                 string `json:"worker_name"`
-	CreateTime               string `json:"create_time"`
-	UpdateTime               string `json:"update_time"`
-	TaskExecutedNumber       int    `json:"task_number"`
-	EnableManualReloadFlag   bool   `json:"enable_manual_reload_flag"`
-	EnableManualFileSyncFlag bool   `json:"enable_manual_file_sync_flag"`
-	HeartColor               string `json:"heart_color"`
+	Index                    int                     `json:"index"`
+	WorkName                 string                  `json:"worker_name"`
+	CreateTime               string                  `json:"create_time"`
+	UpdateTime               string                  `json:"update_time"`
+	TaskExecutedNumber       int                     `json:"task_number"`
+	EnableManualReloadFlag   bool                    `json:"enable_manual_reload_flag"`
+	EnableManualFileSyncFlag bool                    `json:"enable_manual_file_sync_flag"`
+	HeartColor               string                  `json:"heart_color"`
+	CPUPercent               float64                 `json:"cpu_percent"`
+	MemPercent               float64                 `json:"mem_percent"`
+	DiskPercent              float64                 `json:"disk_percent"`
+	RunningTasks             []WorkerRunningTaskData `json:"running_tasks"`
+	ToolVersions             map[string]string       `json:"tool_versions"`
+	FileSyncRevision         string                  `json:"filesync_revision"`
+	FileSyncTime             string                  `json:"filesync_time"`
+	FileSyncProfile          string                  `json:"filesync_profile"`
+	FileSyncRelease          int                     `json:"filesync_release"`
+	SpoolDepth               int                     `json:"spool_depth"`
+	Identity                 string                  `json:"identity"`
+	Warnings                 []string                `json:"warnings"`
+}
+
+type WorkerRunningTaskData struct {
+	TaskId   string `json:"task_id"`
+	TaskName string `json:"task_name"`
+	Elapsed  string `json:"elapsed"`
 }
 
 type TaskInfoData struct {
@@ -435,3 +485,131 @@ type OnlineUserInfo struct {
 	UpdateTime   time.Time
 	UpdateNumber int64
 }
+
+// AlertRuleDataTableResponseData DataTable列表的返回数据
+type AlertRuleDataTableResponseData struct {
+	Draw            int                 `json:"draw"`
+	RecordsTotal    int                 `json:"recordsTotal"`
+	RecordsFiltered int                 `json:"recordsFiltered"`
+	Data            []AlertRuleListData `json:"data"`
+}
+
+// AlertRuleListData 告警规则的列表显示数据
+type AlertRuleListData struct {
+	Id          int    `json:"id"`
+	Index       int    `json:"index"`
+	RuleName    string `json:"rule_name"`
+	RuleType    string `json:"rule_type"`
+	RuleContent string `json:"rule_content"`
+	Notify      string `json:"notify"`
+	State       string `json:"state"`
+	CreateTime  string `json:"create_time"`
+	UpdateTime  string `json:"update_time"`
+}
+
+// AlertRuleInfo 告警规则详情
+type AlertRuleInfo struct {
+	Id          int    `json:"id"`
+	RuleName    string `json:"rule_name"`
+	RuleType    string `json:"rule_type"`
+	RuleContent string `json:"rule_content"`
+	Notify      string `json:"notify"`
+	State       string `json:"state"`
+}
+
+// WorkflowDataTableResponseData DataTable列表的返回数据
+type WorkflowDataTableResponseData struct {
+	Draw            int                `json:"draw"`
+	RecordsTotal    int                `json:"recordsTotal"`
+	RecordsFiltered int                `json:"recordsFiltered"`
+	Data            []WorkflowListData `json:"data"`
+}
+
+// WorkflowListData 工作流的列表显示数据
+type WorkflowListData struct {
+	Id           int    `json:"id"`
+	Index        int    `json:"index"`
+	WorkflowName string `json:"workflow_name"`
+	Description  string `json:"description"`
+	Steps        string `json:"steps"`
+	UpdateTime   string `json:"update_time"`
+}
+
+// WorkflowInfo 工作流详情
+type WorkflowInfo struct {
+	Id           int    `json:"id"`
+	WorkflowName string `json:"workflow_name"`
+	Description  string `json:"description"`
+	Definition   string `json:"definition"`
+}
+
+// TargetSetDataTableResponseData DataTable列表的返回数据
+type TargetSetDataTableResponseData struct {
+	Draw            int                 `json:"draw"`
+	RecordsTotal    int                 `json:"recordsTotal"`
+	RecordsFiltered int                 `json:"recordsFiltered"`
+	Data            []TargetSetListData `json:"data"`
+}
+
+// TargetSetListData 目标集合的列表显示数据
+type TargetSetListData struct {
+	Id            int    `json:"id"`
+	Index         int    `json:"index"`
+	TargetSetName string `json:"targetset_name"`
+	Description   string `json:"description"`
+	IncludeCount  int    `json:"include_count"`
+	ExcludeCount  int    `json:"exclude_count"`
+	UpdateTime    string `json:"update_time"`
+}
+
+// TargetSetInfo 目标集合详情
+type TargetSetInfo struct {
+	Id            int    `json:"id"`
+	TargetSetName string `json:"targetset_name"`
+	Description   string `json:"description"`
+	Include       string `json:"include"`
+	Exclude       string `json:"exclude"`
+	IncludeCount  int    `json:"include_count"`
+	ExcludeCount  int    `json:"exclude_count"`
+}
+
+// WorkerIdentityDataTableResponseData DataTable列表的返回数据
+type WorkerIdentityDataTableResponseData struct {
+	Draw            int                      `json:"draw"`
+	RecordsTotal    int                      `json:"recordsTotal"`
+	RecordsFiltered int                      `json:"recordsFiltered"`
+	Data            []WorkerIdentityListData `json:"data"`
+}
+
+// WorkerIdentityListData worker身份的列表显示数据
+type WorkerIdentityListData struct {
+	Id          int    `json:"id"`
+	Index       int    `json:"index"`
+	WorkerName  string `json:"worker_name"`
+	Description string `json:"description"`
+	CertSerial  string `json:"cert_serial"`
+	IsRevoked   bool   `json:"is_revoked"`
+	OnlineCount int    `json:"online_count"`
+	CreateTime  string `json:"create_time"`
+	RevokedTime string `json:"revoked_time"`
+}
+
+// WorkerReleaseDataTableResponseData DataTable列表的返回数据
+type WorkerReleaseDataTableResponseData struct {
+	Draw            int                     `json:"draw"`
+	RecordsTotal    int                     `json:"recordsTotal"`
+	RecordsFiltered int                     `json:"recordsFiltered"`
+	Data            []WorkerReleaseListData `json:"data"`
+}
+
+// WorkerReleaseListData 发布版本的列表显示数据
+type WorkerReleaseListData struct {
+	Id          int      `json:"id"`
+	Index       int      `json:"index"`
+	Version     int      `json:"version"`
+	Description string   `json:"description"`
+	FileCount   int      `json:"file_count"`
+	IsLatest    bool     `json:"is_latest"`
+	WorkerTags  []string `json:"worker_tags"`
+	CreateTime  string   `json:"create_time"`
+}
@@ -7,6 +7,51 @@ import (
 
 func init() {
 
+    beego.GlobalControllerRouter["github.com/hanc00l/nemo_go/pkg/webapi/controllers:AlertRuleController"] = append(beego.GlobalControllerRouter["github.com/hanc00l/nemo_go/pkg/webapi/controllers:AlertRuleController"],
+        beego.ControllerComments{
+            Method: "DeleteRule",
+            Router: `/delete`,
+            AllowHTTPMethods: []string{"post"},
+            MethodParams: param.Make(),
+            Filters: nil,
+            Params: nil})
+
+    beego.GlobalControllerRouter["github.com/hanc00l/nemo_go/pkg/webapi/controllers:AlertRuleController"] = append(beego.GlobalControllerRouter["github.com/hanc00l/nemo_go/pkg/webapi/controllers:AlertRuleController"],
+        beego.ControllerComments{
+            Method: "Info",
+            Router: `/info`,
+            AllowHTTPMethods: []string{"post"},
+            MethodParams: param.Make(),
+            Filters: nil,
+            Params: nil})
+
+    beego.GlobalControllerRouter["github.com/hanc00l/nemo_go/pkg/webapi/controllers:AlertRuleController"] = append(beego.GlobalControllerRouter["github.com/hanc00l/nemo_go/pkg/webapi/controllers:AlertRuleController"],
+        beego.ControllerComments{
+            Method: "List",
+            Router: `/list`,
+            AllowHTTPMethods: []string{"post"},
+            MethodParams: param.Make(),
+            Filters: nil,
+            Params: nil})
+
+    beego.GlobalControllerRouter["github.com/hanc00l/nemo_go/pkg/webapi/controllers:AlertRuleController"] = append(beego.GlobalControllerRouter["github.com/hanc00l/nemo_go/pkg/webapi/controllers:AlertRuleController"],
+        beego.ControllerComments{
+            Method: "SaveRule",
+            Router: `/save`,
+            AllowHTTPMethods: []string{"post"},
+            MethodParams: param.Make(),
+            Filters: nil,
+            Params: nil})
+
+    beego.GlobalControllerRouter["github.com/hanc00l/nemo_go/pkg/webapi/controllers:AlertRuleController"] = append(beego.GlobalControllerRouter["github.com/hanc00l/nemo_go/pkg/webapi/controllers:AlertRuleController"],
+        beego.ControllerComments{
+            Method: "UpdateRule",
+            Router: `/update`,
+            AllowHTTPMethods: []string{"post"},
+            MethodParams: param.Make(),
+            Filters: nil,
+            Params: nil})
+
     beego.GlobalControllerRouter["github.com/hanc00l/nemo_go/pkg/webapi/controllers:ConfigController"] = append(beego.GlobalControllerRouter["github.com/hanc00l/nemo_go/pkg/webapi/controllers:ConfigController"],
         beego.ControllerComments{
             Method: "ChangePassword",
@@ -133,6 +178,15 @@ func init() {
             Filters: nil,
             Params: nil})
 
+    beego.GlobalControllerRouter["github.com/hanc00l/nemo_go/pkg/webapi/controllers:DomainController"] = append(beego.GlobalControllerRouter["github.com/hanc00l/nemo_go/pkg/webapi/controllers:DomainController"],
+        beego.ControllerComments{
+            Method: "History",
+            Router: `/history`,
+            AllowHTTPMethods: []string{"post"},
+            MethodParams: param.Make(),
+            Filters: nil,
+            Params: nil})
+
     beego.GlobalControllerRouter["github.com/hanc00l/nemo_go/pkg/webapi/controllers:DomainController"] = append(beego.GlobalControllerRouter["github.com/hanc00l/nemo_go/pkg/webapi/controllers:DomainController"],
         beego.ControllerComments{
             Method: "Info",
@@ -205,6 +259,15 @@ func init() {
             Filters: nil,
             Params: nil})
 
+    beego.GlobalControllerRouter["github.com/hanc00l/nemo_go/pkg/webapi/controllers:IPController"] = append(beego.GlobalControllerRouter["github.com/hanc00l/nemo_go/pkg/webapi/controllers:IPController"],
+        beego.ControllerComments{
+            Method: "History",
+            Router: `/history`,
+            AllowHTTPMethods: []string{"post"},
+            MethodParams: param.Make(),
+            Filters: nil,
+            Params: nil})
+
     beego.GlobalControllerRouter["github.com/hanc00l/nemo_go/pkg/webapi/controllers:IPController"] = append(beego.GlobalControllerRouter["github.com/hanc00l/nemo_go/pkg/webapi/controllers:IPController"],
         beego.ControllerComments{
             Method: "Info",
@@ -340,6 +403,51 @@ func init() {
             Filters: nil,
             Params: nil})
 
+    beego.GlobalControllerRouter["github.com/hanc00l/nemo_go/pkg/webapi/controllers:TargetSetController"] = append(beego.GlobalControllerRouter["github.com/hanc00l/nemo_go/pkg/webapi/controllers:TargetSetController"],
+        beego.ControllerComments{
+            Method: "DeleteTargetSet",
+            Router: `/delete`,
+            AllowHTTPMethods: []string{"post"},
+            MethodParams: param.Make(),
+            Filters: nil,
+            Params: nil})
+
+    beego.GlobalControllerRouter["github.com/hanc00l/nemo_go/pkg/webapi/controllers:TargetSetController"] = append(beego.GlobalControllerRouter["github.com/hanc00l/nemo_go/pkg/webapi/controllers:TargetSetController"],
+        beego.ControllerComments{
+            Method: "Info",
+            Router: `/info`,
+            AllowHTTPMethods: []string{"post"},
+            MethodParams: param.Make(),
+            Filters: nil,
+            Params: nil})
+
+    beego.GlobalControllerRouter["github.com/hanc00l/nemo_go/pkg/webapi/controllers:TargetSetController"] = append(beego.GlobalControllerRouter["github.com/hanc00l/nemo_go/pkg/webapi/controllers:TargetSetController"],
+        beego.ControllerComments{
+            Method: "List",
+            Router: `/list`,
+            AllowHTTPMethods: []string{"post"},
+            MethodParams: param.Make(),
+            Filters: nil,
+            Params: nil})
+
+    beego.GlobalControllerRouter["github.com/hanc00l/nemo_go/pkg/webapi/controllers:TargetSetController"] = append(beego.GlobalControllerRouter["github.com/hanc00l/nemo_go/pkg/webapi/controllers:TargetSetController"],
+        beego.ControllerComments{
+            Method: "SaveTargetSet",
+            Router: `/save`,
+            AllowHTTPMethods: []string{"post"},
+            MethodParams: param.Make(),
+            Filters: nil,
+            Params: nil})
+
+    beego.GlobalControllerRouter["github.com/hanc00l/nemo_go/pkg/webapi/controllers:TargetSetController"] = append(beego.GlobalControllerRouter["github.com/hanc00l/nemo_go/pkg/webapi/controllers:TargetSetController"],
+        beego.ControllerComments{
+            Method: "UpdateTargetSet",
+            Router: `/update`,
+            AllowHTTPMethods: []string{"post"},
+            MethodParams: param.Make(),
+            Filters: nil,
+            Params: nil})
+
     beego.GlobalControllerRouter["github.com/hanc00l/nemo_go/pkg/webapi/controllers:TaskController"] = append(beego.GlobalControllerRouter["github.com/hanc00l/nemo_go/pkg/webapi/controllers:TaskController"],
         beego.ControllerComments{
             Method: "DeleteBatchTask",
@@ -412,6 +520,15 @@ func init() {
             Filters: nil,
             Params: nil})
 
+    beego.GlobalControllerRouter["github.com/hanc00l/nemo_go/pkg/webapi/controllers:TaskController"] = append(beego.GlobalControllerRouter["github.com/hanc00l/nemo_go/pkg/webapi/controllers:TaskController"],
+        beego.ControllerComments{
+            Method: "DiffMainTask",
+            Router: `/main/diff`,
+            AllowHTTPMethods: []string{"post"},
+            MethodParams: param.Make(),
+            Filters: nil,
+            Params: nil})
+
     beego.GlobalControllerRouter["github.com/hanc00l/nemo_go/pkg/webapi/controllers:TaskController"] = append(beego.GlobalControllerRouter["github.com/hanc00l/nemo_go/pkg/webapi/controllers:TaskController"],
         beego.ControllerComments{
             Method: "InfoMainTask",
@@ -430,6 +547,33 @@ func init() {
             Filters: nil,
             Params: nil})
 
+    beego.GlobalControllerRouter["github.com/hanc00l/nemo_go/pkg/webapi/controllers:TaskController"] = append(beego.GlobalControllerRouter["github.com/hanc00l/nemo_go/pkg/webapi/controllers:TaskController"],
+        beego.ControllerComments{
+            Method: "PauseMainTask",
+            Router: `/main/pause`,
+            AllowHTTPMethods: []string{"post"},
+            MethodParams: param.Make(),
+            Filters: nil,
+            Params: nil})
+
+    beego.GlobalControllerRouter["github.com/hanc00l/nemo_go/pkg/webapi/controllers:TaskController"] = append(beego.GlobalControllerRouter["github.com/hanc00l/nemo_go/pkg/webapi/controllers:TaskController"],
+        beego.ControllerComments{
+            Method: "ResumeMainTask",
+            Router: `/main/resume`,
+            AllowHTTPMethods: []string{"post"},
+            MethodParams: param.Make(),
+            Filters: nil,
+            Params: nil})
+
+    beego.GlobalControllerRouter["github.com/hanc00l/nemo_go/pkg/webapi/controllers:TaskController"] = append(beego.GlobalControllerRouter["github.com/hanc00l/nemo_go/pkg/webapi/controllers:TaskController"],
+        beego.ControllerComments{
+            Method: "RetryMainTask",
+            Router: `/main/retry`,
+            AllowHTTPMethods: []string{"post"},
+            MethodParams: param.Make(),
+            Filters: nil,
+            Params: nil})
+
     beego.GlobalControllerRouter["github.com/hanc00l/nemo_go/pkg/webapi/controllers:TaskController"] = append(beego.GlobalControllerRouter["github.com/hanc00l/nemo_go/pkg/webapi/controllers:TaskController"],
         beego.ControllerComments{
             Method: "DeleteRunTask",
@@ -583,6 +727,150 @@ func init() {
             Filters: nil,
             Params: nil})
 
+    beego.GlobalControllerRouter["github.com/hanc00l/nemo_go/pkg/webapi/controllers:WorkerIdentityController"] = append(beego.GlobalControllerRouter["github.com/hanc00l/nemo_go/pkg/webapi/controllers:WorkerIdentityController"],
+        beego.ControllerComments{
+            Method: "Delete",
+            Router: `/delete`,
+            AllowHTTPMethods: []string{"post"},
+            MethodParams: param.Make(),
+            Filters: nil,
+            Params: nil})
+
+    beego.GlobalControllerRouter["github.com/hanc00l/nemo_go/pkg/webapi/controllers:WorkerIdentityController"] = append(beego.GlobalControllerRouter["github.com/hanc00l/nemo_go/pkg/webapi/controllers:WorkerIdentityController"],
+        beego.ControllerComments{
+            Method: "Enroll",
+            Router: `/enroll`,
+            AllowHTTPMethods: []string{"post"},
+            MethodParams: param.Make(),
+            Filters: nil,
+            Params: nil})
+
+    beego.GlobalControllerRouter["github.com/hanc00l/nemo_go/pkg/webapi/controllers:WorkerIdentityController"] = append(beego.GlobalControllerRouter["github.com/hanc00l/nemo_go/pkg/webapi/controllers:WorkerIdentityController"],
+        beego.ControllerComments{
+            Method: "List",
+            Router: `/list`,
+            AllowHTTPMethods: []string{"post"},
+            MethodParams: param.Make(),
+            Filters: nil,
+            Params: nil})
+
+    beego.GlobalControllerRouter["github.com/hanc00l/nemo_go/pkg/webapi/controllers:WorkerIdentityController"] = append(beego.GlobalControllerRouter["github.com/hanc00l/nemo_go/pkg/webapi/controllers:WorkerIdentityController"],
+        beego.ControllerComments{
+            Method: "Revoke",
+            Router: `/revoke`,
+            AllowHTTPMethods: []string{"post"},
+            MethodParams: param.Make(),
+            Filters: nil,
+            Params: nil})
+
+    beego.GlobalControllerRouter["github.com/hanc00l/nemo_go/pkg/webapi/controllers:WorkerReleaseController"] = append(beego.GlobalControllerRouter["github.com/hanc00l/nemo_go/pkg/webapi/controllers:WorkerReleaseController"],
+        beego.ControllerComments{
+            Method: "Delete",
+            Router: `/delete`,
+            AllowHTTPMethods: []string{"post"},
+            MethodParams: param.Make(),
+            Filters: nil,
+            Params: nil})
+
+    beego.GlobalControllerRouter["github.com/hanc00l/nemo_go/pkg/webapi/controllers:WorkerReleaseController"] = append(beego.GlobalControllerRouter["github.com/hanc00l/nemo_go/pkg/webapi/controllers:WorkerReleaseController"],
+        beego.ControllerComments{
+            Method: "List",
+            Router: `/list`,
+            AllowHTTPMethods: []string{"post"},
+            MethodParams: param.Make(),
+            Filters: nil,
+            Params: nil})
+
+    beego.GlobalControllerRouter["github.com/hanc00l/nemo_go/pkg/webapi/controllers:WorkerReleaseController"] = append(beego.GlobalControllerRouter["github.com/hanc00l/nemo_go/pkg/webapi/controllers:WorkerReleaseController"],
+        beego.ControllerComments{
+            Method: "Pin",
+            Router: `/pin`,
+            AllowHTTPMethods: []string{"post"},
+            MethodParams: param.Make(),
+            Filters: nil,
+            Params: nil})
+
+    beego.GlobalControllerRouter["github.com/hanc00l/nemo_go/pkg/webapi/controllers:WorkerReleaseController"] = append(beego.GlobalControllerRouter["github.com/hanc00l/nemo_go/pkg/webapi/controllers:WorkerReleaseController"],
+        beego.ControllerComments{
+            Method: "PublicKey",
+            Router: `/pubkey`,
+            AllowHTTPMethods: []string{"post"},
+            MethodParams: param.Make(),
+            Filters: nil,
+            Params: nil})
+
+    beego.GlobalControllerRouter["github.com/hanc00l/nemo_go/pkg/webapi/controllers:WorkerReleaseController"] = append(beego.GlobalControllerRouter["github.com/hanc00l/nemo_go/pkg/webapi/controllers:WorkerReleaseController"],
+        beego.ControllerComments{
+            Method: "Publish",
+            Router: `/publish`,
+            AllowHTTPMethods: []string{"post"},
+            MethodParams: param.Make(),
+            Filters: nil,
+            Params: nil})
+
+    beego.GlobalControllerRouter["github.com/hanc00l/nemo_go/pkg/webapi/controllers:WorkerReleaseController"] = append(beego.GlobalControllerRouter["github.com/hanc00l/nemo_go/pkg/webapi/controllers:WorkerReleaseController"],
+        beego.ControllerComments{
+            Method: "Unpin",
+            Router: `/unpin`,
+            AllowHTTPMethods: []string{"post"},
+            MethodParams: param.Make(),
+            Filters: nil,
+            Params: nil})
+
+    beego.GlobalControllerRouter["github.com/hanc00l/nemo_go/pkg/webapi/controllers:WorkflowController"] = append(beego.GlobalControllerRouter["github.com/hanc00l/nemo_go/pkg/webapi/controllers:WorkflowController"],
+        beego.ControllerComments{
+            Method: "DeleteWorkflow",
+            Router: `/delete`,
+            AllowHTTPMethods: []string{"post"},
+            MethodParams: param.Make(),
+            Filters: nil,
+            Params: nil})
+
+    beego.GlobalControllerRouter["github.com/hanc00l/nemo_go/pkg/webapi/controllers:WorkflowController"] = append(beego.GlobalControllerRouter["github.com/hanc00l/nemo_go/pkg/webapi/controllers:WorkflowController"],
+        beego.ControllerComments{
+            Method: "Info",
+            Router: `/info`,
+            AllowHTTPMethods: []string{"post"},
+            MethodParams: param.Make(),
+            Filters: nil,
+            Params: nil})
+
+    beego.GlobalControllerRouter["github.com/hanc00l/nemo_go/pkg/webapi/controllers:WorkflowController"] = append(beego.GlobalControllerRouter["github.com/hanc00l/nemo_go/pkg/webapi/controllers:WorkflowController"],
+        beego.ControllerComments{
+            Method: "List",
+            Router: `/list`,
+            AllowHTTPMethods: []string{"post"},
+            MethodParams: param.Make(),
+            Filters: nil,
+            Params: nil})
+
+    beego.GlobalControllerRouter["github.com/hanc00l/nemo_go/pkg/webapi/controllers:WorkflowController"] = append(beego.GlobalControllerRouter["github.com/hanc00l/nemo_go/pkg/webapi/controllers:WorkflowController"],
+        beego.ControllerComments{
+            Method: "RunWorkflow",
+            Router: `/run`,
+            AllowHTTPMethods: []string{"post"},
+            MethodParams: param.Make(),
+            Filters: nil,
+            Params: nil})
+
+    beego.GlobalControllerRouter["github.com/hanc00l/nemo_go/pkg/webapi/controllers:WorkflowController"] = append(beego.GlobalControllerRouter["github.com/hanc00l/nemo_go/pkg/webapi/controllers:WorkflowController"],
+        beego.ControllerComments{
+            Method: "SaveWorkflow",
+            Router: `/save`,
+            AllowHTTPMethods: []string{"post"},
+            MethodParams: param.Make(),
+            Filters: nil,
+            Params: nil})
+
+    beego.GlobalControllerRouter["github.com/hanc00l/nemo_go/pkg/webapi/controllers:WorkflowController"] = append(beego.GlobalControllerRouter["github.com/hanc00l/nemo_go/pkg/webapi/controllers:WorkflowController"],
+        beego.ControllerComments{
+            Method: "UpdateWorkflow",
+            Router: `/update`,
+            AllowHTTPMethods: []string{"post"},
+            MethodParams: param.Make(),
+            Filters: nil,
+            Params: nil})
+
     beego.GlobalControllerRouter["github.com/hanc00l/nemo_go/pkg/webapi/controllers:WorkspaceController"] = append(beego.GlobalControllerRouter["github.com/hanc00l/nemo_go/pkg/webapi/controllers:WorkspaceController"],
         beego.ControllerComments{
             Method: "ChangeWorkspaceSelect",
@@ -64,6 +64,31 @@ func init() {
 				&controllers.DashboardController{},
 			),
 		),
+		beego.NSNamespace("/alert-rule",
+			beego.NSInclude(
+				&controllers.AlertRuleController{},
+			),
+		),
+		beego.NSNamespace("/workflow",
+			beego.NSInclude(
+				&controllers.WorkflowController{},
+			),
+		),
+		beego.NSNamespace("/targetset",
+			beego.NSInclude(
+				&controllers.TargetSetController{},
+			),
+		),
+		beego.NSNamespace("/worker-identity",
+			beego.NSInclude(
+				&controllers.WorkerIdentityController{},
+			),
+		),
+		beego.NSNamespace("/worker-release",
+			beego.NSInclude(
+				&controllers.WorkerReleaseController{},
+			),
+		),
 	)
 	beego.AddNamespace(ns)
 }
@@ -0,0 +1,160 @@
+$(function () {
+    $('#alert_rule_table').DataTable(
+        {
+            "paging": true,
+            "serverSide": true,
+            "autowidth": false,
+            "sort": false,
+            "pagingType": "full_numbers",//分页样式
+            'iDisplayLength': 50,
+            "dom": '<i><t><"bottom"lp>',
+            "ajax": {
+                "url": "/alert-rule-list",
+                "type": "post",
+                "data": function (d) {
+                    init_dataTables_defaultParam(d);
+                    return $.extend({}, d, {
+                        "rule_name": $.trim($('#rule_name').val()),
+                        "rule_type": $.trim($('#rule_type').val()),
+                    });
+                }
+            },
+            columns: [
+                {
+                    data: "index",
+                    title: "序号",
+                    width: "5%"
+                },
+                {data: "rule_name", title: "规则名称", width: "15%"},
+                {data: "rule_type", title: "规则类型", width: "8%"},
+                {
+                    data: "rule_content", title: "规则内容", width: "25%",
+                    "render": function (data, type, row) {
+                        return '<div style="width:100%;white-space:normal;word-wrap:break-word;word-break:break-all;">' + data + '</div>'
+                    }
+                },
+                {data: "notify", title: "通知渠道", width: "12%"},
+                {data: "state", title: "状态", width: "6%"},
+                {data: "update_time", title: "更新时间", width: "12%"},
+                {
+                    title: "操作",
+                    width: "8%",
+                    "render": function (data, type, row, meta) {
+                        let strButton = "<a class=\"btn btn-sm btn-primary\" href=javascript:edit_alert_rule(\"" + row["id"] + "\") role=\"button\" title=\"Edit\"><i class=\"fa fa-edit\"></i></a>";
+                        strButton += "&nbsp;<a class=\"btn btn-sm btn-danger\" href=javascript:delete_alert_rule(\"" + row["id"] + "\") role=\"button\" title=\"Delete\"><i class=\"fa fa-trash\"></i></a>";
+                        return strButton;
+                    }
+                }
+            ],
+            infoCallback: function (settings, start, end, max, total, pre) {
+                return "共<b>" + total + "</b>条记录，当前显示" + start + "到" + end + "记录";
+            },
+        }
+    );//end datatable
+    //搜索
+    $("#search").click(function () {
+        $("#alert_rule_table").DataTable().draw(true);
+    });
+});
+
+/**
+ * 移除 dataTables默认参数，并设置分页值
+ * @param param
+ */
+function init_dataTables_defaultParam(param) {
+    for (var key in param) {
+        if (key.indexOf("columns") == 0 || key.indexOf("order") == 0 || key.indexOf("search") == 0) { //以columns开头的参数删除
+            delete param[key];
+        }
+    }
+    param.pageSize = param.length;
+    param.pageNum = (param.start / param.length) + 1;
+}
+
+//新建告警规则窗口
+$("#create_alert_rule").click(function () {
+    $('#new_alert_rule').modal('toggle');
+    $('#alertRuleActionType').html("新建告警规则");
+    $('#alert_rule_id').val("0");
+    $('#add_rule_name').val("");
+    $('#add_rule_type').val("port");
+    $('#add_rule_content').val("");
+    $('#add_notify').val("");
+    $('#add_state').val("enable");
+});
+
+$("#save_alert_rule").click(function () {
+    let url;
+    let data = {
+        "rule_name": $('#add_rule_name').val(),
+        "rule_type": $('#add_rule_type').val(),
+        "rule_content": $('#add_rule_content').val(),
+        "notify": $('#add_notify').val(),
+        "state": $('#add_state').val(),
+    };
+    if ($('#alert_rule_id').val() === "0") {
+        url = "/alert-rule-add";
+    } else {
+        url = "/alert-rule-update";
+        data["id"] = $('#alert_rule_id').val();
+    }
+    $.post(url, data, function (res, e) {
+        if (e === "success" && res['status'] == "success") {
+            swal({
+                    title: "保存成功！",
+                    text: res['msg'],
+                    type: "success",
+                    confirmButtonText: "确定",
+                    confirmButtonColor: "#41b883",
+                    closeOnConfirm: true,
+                },
+                function () {
+                    $('#new_alert_rule').modal('hide');
+                    $('#alert_rule_table').DataTable().draw(false);
+                });
+        } else {
+            swal('Warning', '保存失败！' + res['msg'], 'error');
+        }
+    });
+});
+
+function edit_alert_rule(id) {
+    $('#new_alert_rule').modal('toggle');
+    $('#alertRuleActionType').html("编辑告警规则");
+    $.post("/alert-rule-get",
+        {
+            "id": id,
+        }, function (data, e) {
+            if (e === "success") {
+                $('#alert_rule_id').val(data["id"]);
+                $('#add_rule_name').val(data["rule_name"]);
+                $('#add_rule_type').val(data["rule_type"]);
+                $('#add_rule_content').val(data["rule_content"]);
+                $('#add_notify').val(data["notify"]);
+                $('#add_state').val(data["state"]);
+            }
+        });
+}
+
+function delete_alert_rule(id) {
+    swal({
+            title: "确定要删除?",
+            text: "该操作会删除当前告警规则，请确认！",
+            type: "warning",
+            showCancelButton: true,
+            confirmButtonColor: "#DD6B55",
+            confirmButtonText: "确认删除",
+            cancelButtonText: "取消",
+            closeOnConfirm: true
+        },
+        function () {
+            $.post("/alert-rule-del",
+                {
+                    "id": id,
+                }, function (data, e) {
+                    if (e === "success") {
+                        $('#alert_rule_table').DataTable().draw(false);
+                    }
+                });
+        });
+}
@@ -1,6 +1,7 @@
 $(function () {
     //$('#btnsiderbar').click();
     load_org_list();
+    load_targetset_list();
     // //获取任务的状态信息
     get_task_status();
     setInterval(function () {
@@ -107,6 +108,27 @@ function load_org_list() {
     });
 }
 
+/**
+ * 加载目标集合列表
+ */
+function load_targetset_list() {
+    if ($("#select_targetset_task").length === 0 && $("#select_targetset_task_xscan").length === 0) {
+        return;
+    }
+    $("#select_targetset_task").append("<option value=''>--无--</option>")
+    $("#select_targetset_task_xscan").append("<option value=''>--无--</option>")
+    $.post("/targetset-list", {}, function (res, e) {
+        if (e === "success") {
+            const data = res["data"];
+            for (let i = 0; i < data.length; i++) {
+                const option = "<option value='" + data[i].id + "'>" + data[i].targetset_name + "(" + data[i].include_count + ")</option>";
+                $("#select_targetset_task").append(option)
+                $("#select_targetset_task_xscan").append(option)
+            }
+        }
+    });
+}
+
 /**
  * 加载poc文件列表
  */
@@ -72,11 +72,60 @@ $(function () {
             },
             columns: [
                 {data: "index", title: "序号", width: "5%"},
-                {data: "worker_name", title: "Worker", width: "25%"},
-                {data: "worker_topic", title: "任务模式", width: "20%"},
-                {data: 'create_time', title: '启动时间', width: '15%',},
                 {
-                    data: 'update_time', title: '心跳时间', width: '10%',
+                    data: "worker_name", title: "Worker", width: "15%",
+                    render: function (data, type, row, meta) {
+                        let tools = [];
+                        for (let tool in row['tool_versions']) {
+                            tools.push(tool + ":" + (row['tool_versions'][tool] || "无"));
+                        }
+                        tools.sort();
+                        tools.unshift("身份:" + (row['identity'] || "未注册"));
+                        if (row['filesync_release'] > 0) {
+                            tools.push("发布版本:" + row['filesync_release']);
+                        }
+                        if (row['filesync_revision']) {
+                            tools.push("文件同步:" + row['filesync_revision'] + "(" + row['filesync_time'] + ")" + (row['filesync_profile'] ? "[" + row['filesync_profile'] + "]" : ""));
+                        }
+                        if (row['spool_depth'] > 0) {
+                            tools.push("待补传结果:" + row['spool_depth']);
+                        }
+                        return '<span title="' + tools.join('\n') + '">' + data + '</span>';
+                    }
+                },
+                {data: "worker_topic", title: "任务模式", width: "15%"},
+                {
+                    title: "负载", width: "10%",
+                    render: function (data, type, row, meta) {
+                        return 'CPU:' + row['cpu_percent'].toFixed(0) + '%<br>内存:' + row['mem_percent'].toFixed(0) + '%<br>磁盘:' + row['disk_percent'].toFixed(0) + '%';
+                    }
+                },
+                {
+                    title: "执行中任务", width: "10%",
+                    render: function (data, type, row, meta) {
+                        if (!row['running_tasks']) {
+                            return '0';
+                        }
+                        let tasks = [];
+                        for (let i = 0; i < row['running_tasks'].length; i++) {
+                            const t = row['running_tasks'][i];
+                            tasks.push(t['task_name'] + " " + t['task_id'] + " " + t['elapsed']);
+                        }
+                        return '<span title="' + tasks.join('\n') + '">' + row['running_tasks'].length + '</span>';
+                    }
+                },
+                {
+                    title: "状态", width: "10%",
+                    render: function (data, type, row, meta) {
+                        if (!row['warnings']) {
+                            return '<span class="text-success">正常</span>';
+                        }
+                        return '<span class="text-danger">' + row['warnings'].join('<br>') + '</span>';
+                    }
+                },
+                {data: 'create_time', title: '启动时间', width: '10%',},
+                {
+                    data: 'update_time', title: '心跳时间', width: '8%',
                     render: function (data, type, row, meta) {
                         if (row["heart_color"] === "green") {
                             return '<span class="text-primary">' + data + '</span>';
@@ -85,7 +134,7 @@ $(function () {
                         } else return '<span class="text-danger">' + data + '</span>';
                     }
                 },
-                {data: 'task_number', title: '已执行任务数', width: '10%'},
+                {data: 'task_number', title: '已执行任务数', width: '7%'},
                 {
                     title: "操作", width: '10%',
                     render: function (data, type, row, meta) {
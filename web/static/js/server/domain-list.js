@@ -54,12 +54,13 @@ $(function () {
     //启动任务 
     $("#start_task").click(function () {
         const target = $('#text_target').val();
-        if (!target) {
-            swal('Warning', '请至少输入一个Target', 'error');
+        const targetset_id = $('#select_targetset_task').val();
+        if (!target && !targetset_id) {
+            swal('Warning', '请至少输入一个Target或选择目标集合', 'error');
             return;
         }
         if (target.length > 5000) {
-            swal('Warning', '目标Targets长度不能超过5000', 'error');
+            swal('Warning', '目标Targets长度不能超过5000，大量的目标请使用目标集合', 'error');
             return;
         }
         let cron_rule = "";
@@ -74,6 +75,8 @@ $(function () {
             $.post("/task-start-domainscan",
                 {
                     "target": target,
+                    "targetset_id": targetset_id,
+                    "worker_tag": $('#text_worker_tag').val().trim(),
                     'org_id': $('#select_org_id_task').val(),
                     'subdomainbrute': $('#checkbox_subdomainbrute').is(":checked"),
                     'fld_domain': $('#checkbox_fld_domain').is(":checked"),
@@ -133,6 +136,8 @@ $(function () {
             }
             $.post("/task-start-vulnerability", {
                 "target": target,
+                "targetset_id": targetset_id,
+                "worker_tag": $('#text_worker_tag').val().trim(),
                 'xrayverify': $('#checkbox_xray').is(":checked"),
                 'xray_poc_file': $('#select_poc_type').val() + '|' + $('#input_xray_poc_file').val(),
                 'nucleiverify': $('#checkbox_nuclei').is(":checked"),
@@ -167,16 +172,19 @@ $(function () {
         const formData = new FormData();
         if (getCurrentTabIndex('#nav_tabs_xscan') === 0) {
             const target = $('#text_target_xscan').val();
-            if (!target) {
-                swal('Warning', '请至少输入一个Target', 'error');
+            const targetset_id = $('#select_targetset_task_xscan').val();
+            if (!target && !targetset_id) {
+                swal('Warning', '请至少输入一个Target或选择目标集合', 'error');
                 return;
             }
             if (target.length > 5000) {
-                swal('Warning', '目标Targets长度不能超过5000', 'error');
+                swal('Warning', '目标Targets长度不能超过5000，大量的目标请使用目标集合', 'error');
                 return;
             }
             formData.append("xscan_type", "xdomainscan");
             formData.append("target", target)
+            formData.append("targetset_id", targetset_id);
+            formData.append("worker_tag", $('#text_worker_tag_xscan').val().trim());
             formData.append("onlineapi", $('#checkbox_onlineapi_xscan').is(":checked"));
         } else if (getCurrentTabIndex('#nav_tabs_xscan') === 2) {
             const target = $('#text_target_onlineapi_xscan').val();
@@ -92,12 +92,13 @@ $(function () {
     //执行新建任务Button
     $("#start_task").click(function () {
         const target = $('#text_target').val();
-        if (!target) {
-            swal('Warning', '请至少输入一个Target', 'error');
+        const targetset_id = $('#select_targetset_task').val();
+        if (!target && !targetset_id) {
+            swal('Warning', '请至少输入一个Target或选择目标集合', 'error');
             return;
         }
         if (target.length > 5000) {
-            swal('Warning', '目标Targets长度不能超过5000', 'error');
+            swal('Warning', '目标Targets长度不能超过5000，大量的目标请使用目标集合', 'error');
             return;
         }
         let cron_rule = "";
@@ -118,6 +119,8 @@ $(function () {
             $.post("/task-start-portscan",
                 {
                     "target": target,
+                    "targetset_id": targetset_id,
+                    "worker_tag": $('#text_worker_tag').val().trim(),
                     "port": port,
                     'rate': rate,
                     'portscan': $('#checkbox_portscan').is(":checked"),
@@ -179,6 +182,8 @@ $(function () {
             $.post("/task-start-vulnerability",
                 {
                     "target": target,
+                    "targetset_id": targetset_id,
+                    "worker_tag": $('#text_worker_tag').val().trim(),
                     'xrayverify': $('#checkbox_xray').is(":checked"),
                     'xray_poc_file': $('#select_poc_type') + "|" + $('#input_xray_poc_file').val(),
                     'nucleiverify': $('#checkbox_nuclei').is(":checked"),
@@ -219,6 +224,8 @@ $(function () {
             $.post("/task-start-batchscan",
                 {
                     "target": target,
+                    "targetset_id": targetset_id,
+                    "worker_tag": $('#text_worker_tag').val().trim(),
                     "port": port1 + "|" + port2,
                     'rate': rate,
                     'portscan': true,
@@ -264,16 +271,19 @@ $(function () {
         const formData = new FormData();
         if (getCurrentTabIndex('#nav_tabs_xscan') === 0) {
             const target = $('#text_target_xscan').val();
-            if (!target) {
-                swal('Warning', '请至少输入一个Target', 'error');
+            const targetset_id = $('#select_targetset_task_xscan').val();
+            if (!target && !targetset_id) {
+                swal('Warning', '请至少输入一个Target或选择目标集合', 'error');
                 return;
             }
             if (target.length > 5000) {
-                swal('Warning', '目标Targets长度不能超过5000', 'error');
+                swal('Warning', '目标Targets长度不能超过5000，大量的目标请使用目标集合', 'error');
                 return;
             }
             formData.append("xscan_type", "xportscan");
             formData.append("target", target);
+            formData.append("targetset_id", targetset_id);
+            formData.append("worker_tag", $('#text_worker_tag_xscan').val().trim());
             formData.append("onlineapi", $('#checkbox_onlineapi_xscan').is(":checked"));
             formData.append("port", $('#input_port_xscan').val());
         } else if (getCurrentTabIndex('#nav_tabs_xscan') === 2) {
@@ -0,0 +1,123 @@
+$(function () {
+    $('#notify_template_table').DataTable(
+        {
+            "paging": false,
+            "serverSide": true,
+            "autowidth": false,
+            "sort": false,
+            "dom": '<i><t>',
+            "ajax": {
+                "url": "/notify-template-list",
+                "type": "post",
+            },
+            columns: [
+                {
+                    data: "index",
+                    title: "序号",
+                    width: "5%"
+                },
+                {data: "notify_name", title: "通知名称", width: "15%"},
+                {
+                    data: "template", title: "模板内容", width: "55%",
+                    "render": function (data, type, row) {
+                        return '<pre style="width:100%;white-space:pre-wrap;word-wrap:break-word;word-break:break-all;">' + $('<div>').text(data).html() + '</pre>'
+                    }
+                },
+                {data: "update_time", title: "更新时间", width: "12%"},
+                {
+                    title: "操作",
+                    width: "8%",
+                    "render": function (data, type, row, meta) {
+                        let strButton = "<a class=\"btn btn-sm btn-primary\" href=javascript:edit_notify_template(\"" + row["id"] + "\") role=\"button\" title=\"Edit\"><i class=\"fa fa-edit\"></i></a>";
+                        strButton += "&nbsp;<a class=\"btn btn-sm btn-danger\" href=javascript:delete_notify_template(\"" + row["id"] + "\") role=\"button\" title=\"Delete\"><i class=\"fa fa-trash\"></i></a>";
+                        return strButton;
+                    }
+                }
+            ],
+            infoCallback: function (settings, start, end, max, total, pre) {
+                return "共<b>" + total + "</b>条记录";
+            },
+        }
+    );//end datatable
+    //模板示例（页面由服务端模板渲染，示例中的{{}}不能直接写在页面中）
+    $('#add_template').attr("placeholder", "### {{.TaskName}}\n- 目标：{{.Target}}\n- 新增IP：{{join .NewIPs \",\"}}\n- [查看任务]({{.URL}})");
+});
+
+//新建通知模板窗口
+$("#create_notify_template").click(function () {
+    $('#new_notify_template').modal('toggle');
+    $('#notifyTemplateActionType').html("新建通知模板");
+    $('#notify_template_id').val("0");
+    $('#add_notify_name').val("");
+    $('#add_template').val("");
+});
+
+$("#save_notify_template").click(function () {
+    let url;
+    let data = {
+        "notify_name": $('#add_notify_name').val(),
+        "template": $('#add_template').val(),
+    };
+    if ($('#notify_template_id').val() === "0") {
+        url = "/notify-template-add";
+    } else {
+        url = "/notify-template-update";
+        data["id"] = $('#notify_template_id').val();
+    }
+    $.post(url, data, function (res, e) {
+        if (e === "success" && res['status'] == "success") {
+            swal({
+                    title: "保存成功！",
+                    text: res['msg'],
+                    type: "success",
+                    confirmButtonText: "确定",
+                    confirmButtonColor: "#41b883",
+                    closeOnConfirm: true,
+                },
+                function () {
+                    $('#new_notify_template').modal('hide');
+                    $('#notify_template_table').DataTable().draw(false);
+                });
+        } else {
+            swal('Warning', '保存失败！' + res['msg'], 'error');
+        }
+    });
+});
+
+function edit_notify_template(id) {
+    $('#new_notify_template').modal('toggle');
+    $('#notifyTemplateActionType').html("编辑通知模板");
+    $.post("/notify-template-get",
+        {
+            "id": id,
+        }, function (data, e) {
+            if (e === "success") {
+                $('#notify_template_id').val(data["id"]);
+                $('#add_notify_name').val(data["notify_name"]);
+                $('#add_template').val(data["template"]);
+            }
+        });
+}
+
+function delete_notify_template(id) {
+    swal({
+            title: "确定要删除?",
+            text: "该操作会删除当前通知模板，请确认！",
+            type: "warning",
+            showCancelButton: true,
+            confirmButtonColor: "#DD6B55",
+            confirmButtonText: "确认删除",
+            cancelButtonText: "取消",
+            closeOnConfirm: true
+        },
+        function () {
+            $.post("/notify-template-del",
+                {
+                    "id": id,
+                }, function (data, e) {
+                    if (e === "success") {
+                        $('#notify_template_table').DataTable().draw(false);
+                    }
+                });
+        });
+}
@@ -0,0 +1,137 @@
+$(function () {
+    $('#targetset_table').DataTable(
+        {
+            "paging": false,
+            "serverSide": true,
+            "autowidth": false,
+            "sort": false,
+            "dom": '<i><t>',
+            "ajax": {
+                "url": "/targetset-list",
+                "type": "post",
+            },
+            columns: [
+                {
+                    data: "index",
+                    title: "序号",
+                    width: "5%"
+                },
+                {data: "targetset_name", title: "名称", width: "20%"},
+                {data: "description", title: "描述", width: "30%"},
+                {data: "include_count", title: "包含", width: "8%"},
+                {data: "exclude_count", title: "排除", width: "8%"},
+                {data: "update_time", title: "更新时间", width: "15%"},
+                {
+                    title: "操作",
+                    width: "12%",
+                    "render": function (data, type, row, meta) {
+                        let strButton = "<a class=\"btn btn-sm btn-primary\" href=javascript:edit_targetset(\"" + row["id"] + "\") role=\"button\" title=\"Edit\"><i class=\"fa fa-edit\"></i></a>";
+                        strButton += "&nbsp;<a class=\"btn btn-sm btn-danger\" href=javascript:delete_targetset(\"" + row["id"] + "\") role=\"button\" title=\"Delete\"><i class=\"fa fa-trash\"></i></a>";
+                        return strButton;
+                    }
+                }
+            ],
+            infoCallback: function (settings, start, end, max, total, pre) {
+                return "共<b>" + total + "</b>条记录";
+            },
+        }
+    );//end datatable
+});
+
+//新建目标集合窗口
+$("#create_targetset").click(function () {
+    $('#new_targetset').modal('toggle');
+    $('#targetsetActionType').html("新建目标集合");
+    $('#targetset_id').val("0");
+    $('#add_targetset_name').val("");
+    $('#add_description').val("");
+    $('#add_include').val("");
+    $('#add_exclude').val("");
+    $('#file').val("");
+});
+
+$("#save_targetset").click(function () {
+    let url;
+    const formData = new FormData();
+    formData.append("targetset_name", $('#add_targetset_name').val());
+    formData.append("description", $('#add_description').val());
+    formData.append("include", $('#add_include').val());
+    formData.append("exclude", $('#add_exclude').val());
+    if ($('#file')[0].files.length > 0) {
+        formData.append('file', $('#file')[0].files[0]);
+    }
+    if ($('#targetset_id').val() === "0") {
+        url = "/targetset-add";
+    } else {
+        url = "/targetset-update";
+        formData.append("id", $('#targetset_id').val());
+    }
+    $.ajax({
+        url: url,
+        type: 'POST',
+        cache: false,
+        data: formData,
+        processData: false,
+        contentType: false
+    }).done(function (res) {
+        if (res['status'] == "success") {
+            swal({
+                    title: "保存成功！",
+                    text: res['msg'],
+                    type: "success",
+                    confirmButtonText: "确定",
+                    confirmButtonColor: "#41b883",
+                    closeOnConfirm: true,
+                },
+                function () {
+                    $('#new_targetset').modal('hide');
+                    $('#targetset_table').DataTable().draw(false);
+                });
+        } else {
+            swal('Warning', '保存失败！' + res['msg'], 'error');
+        }
+    }).fail(function (res) {
+        swal('Warning', '保存失败！' + res['msg'], 'error');
+    });
+});
+
+function edit_targetset(id) {
+    $('#new_targetset').modal('toggle');
+    $('#targetsetActionType').html("编辑目标集合");
+    $('#file').val("");
+    $.post("/targetset-get",
+        {
+            "id": id,
+        }, function (data, e) {
+            if (e === "success") {
+                $('#targetset_id').val(data["id"]);
+                $('#add_targetset_name').val(data["targetset_name"]);
+                $('#add_description').val(data["description"]);
+                $('#add_include').val(data["include"]);
+                $('#add_exclude').val(data["exclude"]);
+            }
+        });
+}
+
+function delete_targetset(id) {
+    swal({
+            title: "确定要删除?",
+            text: "该操作会删除当前目标集合，引用该目标集合的任务将无法执行，请确认！",
+            type: "warning",
+            showCancelButton: true,
+            confirmButtonColor: "#DD6B55",
+            confirmButtonText: "确认删除",
+            cancelButtonText: "取消",
+            closeOnConfirm: true
+        },
+        function () {
+            $.post("/targetset-del",
+                {
+                    "id": id,
+                }, function (data, e) {
+                    if (e === "success") {
+                        $('#targetset_table').DataTable().draw(false);
+                    }
+                });
+        });
+}
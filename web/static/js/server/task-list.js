@@ -59,6 +59,12 @@ $(function () {
                             strData = data;
                             strData += '<button class="btn btn-sm btn-danger" type="button" onclick="stop_task(\'' + row['task_id'] + '\')" >&nbsp;中止&nbsp;</button>';
                             return strData;
+                        } else if (row["tasktype"] === "MainTask" && data === 'STARTED') {
+                            return " <span class=\"badge badge-warning\">" + data + "</span>" +
+                                '<button class="btn btn-sm btn-warning" type="button" onclick="pause_main_task(\'' + row['task_id'] + '\')" >&nbsp;暂停&nbsp;</button>';
+                        } else if (row["tasktype"] === "MainTask" && data === 'PAUSED') {
+                            return " <span class=\"badge badge-secondary\">" + data + "</span>" +
+                                '<button class="btn btn-sm btn-success" type="button" onclick="resume_main_task(\'' + row['task_id'] + '\')" >&nbsp;恢复&nbsp;</button>';
                         } else if (data === 'STARTED') {
                             return " <span class=\"badge badge-warning\">" + data + "</span>";
                         } else return data;
@@ -196,6 +202,52 @@ function stop_task(task_id) {
         });
 }
 
+/**
+ * 暂停一个主任务
+ * @param task_id
+ */
+function pause_main_task(task_id) {
+    swal({
+            title: "确定要暂停任务?",
+            text: "未开始执行的子任务将暂停执行，正在执行的子任务会继续执行直至完成！",
+            type: "warning",
+            showCancelButton: true,
+            confirmButtonColor: "#DD6B55",
+            confirmButtonText: "确认暂停",
+            cancelButtonText: "取消",
+            closeOnConfirm: true
+        },
+        function () {
+            $.post("/task-pause-main",
+                {
+                    "task_id": task_id,
+                }, function (data, e) {
+                    if (e === "success" && data['status'] == 'success') {
+                        $('#task_table').DataTable().draw(false);
+                    } else {
+                        swal('Warning', "暂停任务失败：" + data['msg'], 'error');
+                    }
+                });
+        });
+}
+
+/**
+ * 恢复一个暂停的主任务
+ * @param task_id
+ */
+function resume_main_task(task_id) {
+    $.post("/task-resume-main",
+        {
+            "task_id": task_id,
+        }, function (data, e) {
+            if (e === "success" && data['status'] == 'success') {
+                $('#task_table').DataTable().draw(false);
+            } else {
+                swal('Warning', "恢复任务失败：" + data['msg'], 'error');
+            }
+        });
+}
+
 /**
  * 删除一个任务
  * @param id
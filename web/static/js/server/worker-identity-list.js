@@ -0,0 +1,144 @@
+$(function () {
+    $('#identity_table').DataTable(
+        {
+            "paging": false,
+            "serverSide": true,
+            "autowidth": false,
+            "sort": false,
+            "dom": '<i><t>',
+            "ajax": {
+                "url": "/worker-identity-list",
+                "type": "post",
+            },
+            columns: [
+                {
+                    data: "index",
+                    title: "序号",
+                    width: "5%"
+                },
+                {data: "worker_name", title: "名称", width: "15%"},
+                {data: "description", title: "描述", width: "20%"},
+                {data: "cert_serial", title: "证书序列号", width: "15%"},
+                {
+                    title: "状态",
+                    width: "10%",
+                    "render": function (data, type, row, meta) {
+                        if (row["is_revoked"]) {
+                            return "<span class=\"badge badge-danger\" title=\"" + row["revoked_time"] + "\">已吊销</span>";
+                        }
+                        if (row["online_count"] > 0) {
+                            return "<span class=\"badge badge-success\">在线(" + row["online_count"] + ")</span>";
+                        }
+                        return "<span class=\"badge badge-secondary\">离线</span>";
+                    }
+                },
+                {data: "create_time", title: "注册时间", width: "15%"},
+                {
+                    title: "操作",
+                    width: "10%",
+                    "render": function (data, type, row, meta) {
+                        let strButton = "";
+                        if (!row["is_revoked"]) {
+                            strButton += "<a class=\"btn btn-sm btn-warning\" href=javascript:revoke_identity(\"" + row["id"] + "\") role=\"button\" title=\"Revoke\"><i class=\"fa fa-ban\"></i></a>&nbsp;";
+                        }
+                        strButton += "<a class=\"btn btn-sm btn-danger\" href=javascript:delete_identity(\"" + row["id"] + "\") role=\"button\" title=\"Delete\"><i class=\"fa fa-trash\"></i></a>";
+                        return strButton;
+                    }
+                }
+            ],
+            infoCallback: function (settings, start, end, max, total, pre) {
+                return "共<b>" + total + "</b>条记录";
+            },
+        }
+    );//end datatable
+});
+
+//注册worker窗口
+$("#create_identity").click(function () {
+    $('#new_identity').modal('toggle');
+    $('#add_worker_name').val("");
+    $('#add_description').val("");
+});
+
+$("#save_identity").click(function () {
+    const workerName = $('#add_worker_name').val();
+    $.post("/worker-identity-enroll",
+        {
+            "worker_name": workerName,
+            "description": $('#add_description').val(),
+        }, function (res, e) {
+            if (e === "success" && res['status'] == "success") {
+                download_identity(res['msg']);
+                $('#new_identity').modal('hide');
+                $('#identity_table').DataTable().draw(false);
+                swal({
+                    title: "注册成功！",
+                    text: "已下载worker_identity.yml，请复制到worker" + workerName + "的运行目录下",
+                    type: "success",
+                    confirmButtonText: "确定",
+                    confirmButtonColor: "#41b883",
+                    closeOnConfirm: true,
+                });
+            } else {
+                swal('Warning', '注册失败！' + res['msg'], 'error');
+            }
+        });
+});
+
+//下载worker身份文件
+function download_identity(content) {
+    const blob = new Blob([content], {type: "text/yaml"});
+    const link = document.createElement("a");
+    link.href = URL.createObjectURL(blob);
+    link.download = "worker_identity.yml";
+    document.body.appendChild(link);
+    link.click();
+    document.body.removeChild(link);
+    URL.revokeObjectURL(link.href);
+}
+
+function revoke_identity(id) {
+    swal({
+            title: "确定要吊销?",
+            text: "吊销后使用该身份的worker将无法连接server，请确认！",
+            type: "warning",
+            showCancelButton: true,
+            confirmButtonColor: "#DD6B55",
+            confirmButtonText: "确认吊销",
+            cancelButtonText: "取消",
+            closeOnConfirm: true
+        },
+        function () {
+            $.post("/worker-identity-revoke",
+                {
+                    "id": id,
+                }, function (data, e) {
+                    if (e === "success") {
+                        $('#identity_table').DataTable().draw(false);
+                    }
+                });
+        });
+}
+
+function delete_identity(id) {
+    swal({
+            title: "确定要删除?",
+            text: "删除后使用该身份的worker将无法连接server，请确认！",
+            type: "warning",
+            showCancelButton: true,
+            confirmButtonColor: "#DD6B55",
+            confirmButtonText: "确认删除",
+            cancelButtonText: "取消",
+            closeOnConfirm: true
+        },
+        function () {
+            $.post("/worker-identity-del",
+                {
+                    "id": id,
+                }, function (data, e) {
+                    if (e === "success") {
+                        $('#identity_table').DataTable().draw(false);
+                    }
+                });
+        });
+}
@@ -0,0 +1,172 @@
+$(function () {
+    $('#release_table').DataTable(
+        {
+            "paging": false,
+            "serverSide": true,
+            "autowidth": false,
+            "sort": false,
+            "dom": '<i><t>',
+            "ajax": {
+                "url": "/worker-release-list",
+                "type": "post",
+            },
+            columns: [
+                {
+                    data: "index",
+                    title: "序号",
+                    width: "5%"
+                },
+                {
+                    title: "版本",
+                    width: "8%",
+                    "render": function (data, type, row, meta) {
+                        if (row["is_latest"]) {
+                            return row["version"] + "&nbsp;<span class=\"badge badge-success\">最新</span>";
+                        }
+                        return row["version"];
+                    }
+                },
+                {data: "description", title: "描述", width: "25%"},
+                {data: "file_count", title: "文件数", width: "8%"},
+                {
+                    title: "指定的worker组",
+                    width: "22%",
+                    "render": function (data, type, row, meta) {
+                        let strGroups = "";
+                        for (const tag of row["worker_tags"]) {
+                            const name = tag === "" ? "全部worker" : tag;
+                            strGroups += "<span class=\"badge badge-info\">" + name + "&nbsp;<a href=javascript:unpin_release(\"" + tag + "\") title=\"取消指定\"><i class=\"fa fa-times text-white\"></i></a></span>&nbsp;";
+                        }
+                        return strGroups;
+                    }
+                },
+                {data: "create_time", title: "发布时间", width: "15%"},
+                {
+                    title: "操作",
+                    width: "10%",
+                    "render": function (data, type, row, meta) {
+                        let strButton = "<a class=\"btn btn-sm btn-warning\" href=javascript:pin_release(\"" + row["version"] + "\") role=\"button\" title=\"Pin\"><i class=\"fa fa-history\"></i></a>&nbsp;";
+                        strButton += "<a class=\"btn btn-sm btn-danger\" href=javascript:delete_release(\"" + row["version"] + "\") role=\"button\" title=\"Delete\"><i class=\"fa fa-trash\"></i></a>";
+                        return strButton;
+                    }
+                }
+            ],
+            infoCallback: function (settings, start, end, max, total, pre) {
+                return "共<b>" + total + "</b>条记录";
+            },
+        }
+    );//end datatable
+});
+
+//发布版本窗口
+$("#create_release").click(function () {
+    $('#new_release').modal('toggle');
+    $('#add_description').val("");
+});
+
+$("#save_release").click(function () {
+    $('#save_release').attr("disabled", true);
+    $.post("/worker-release-publish",
+        {
+            "description": $('#add_description').val(),
+        }, function (res, e) {
+            $('#save_release').attr("disabled", false);
+            if (e === "success" && res['status'] == "success") {
+                $('#new_release').modal('hide');
+                $('#release_table').DataTable().draw(false);
+                swal('Success', res['msg'], 'success');
+            } else {
+                swal('Warning', '发布失败！' + res['msg'], 'error');
+            }
+        });
+});
+
+//下载验证签名的公钥
+$("#download_pubkey").click(function () {
+    $.post("/worker-release-pubkey", {}, function (res, e) {
+        if (e === "success" && res['status'] == "success") {
+            const blob = new Blob([res['msg']], {type: "application/x-pem-file"});
+            const link = document.createElement("a");
+            link.href = URL.createObjectURL(blob);
+            link.download = "release.pub";
+            document.body.appendChild(link);
+            link.click();
+            document.body.removeChild(link);
+            URL.revokeObjectURL(link.href);
+        } else {
+            swal('Warning', '获取公钥失败！' + res['msg'], 'error');
+        }
+    });
+});
+
+//指定worker组的版本窗口
+function pin_release(version) {
+    $('#pin_release').modal('toggle');
+    $('#pin_version').val(version);
+    $('#pin_worker_tag').val("");
+}
+
+$("#save_pin").click(function () {
+    $.post("/worker-release-pin",
+        {
+            "version": $('#pin_version').val(),
+            "worker_tag": $('#pin_worker_tag').val(),
+        }, function (res, e) {
+            if (e === "success" && res['status'] == "success") {
+                $('#pin_release').modal('hide');
+                $('#release_table').DataTable().draw(false);
+                swal('Success', res['msg'], 'success');
+            } else {
+                swal('Warning', '指定版本失败！' + res['msg'], 'error');
+            }
+        });
+});
+
+function unpin_release(tag) {
+    swal({
+            title: "确定要取消指定的版本?",
+            text: "取消后该组的worker将同步最新版本，请确认！",
+            type: "warning",
+            showCancelButton: true,
+            confirmButtonColor: "#DD6B55",
+            confirmButtonText: "确认",
+            cancelButtonText: "取消",
+            closeOnConfirm: true
+        },
+        function () {
+            $.post("/worker-release-unpin",
+                {
+                    "worker_tag": tag,
+                }, function (data, e) {
+                    if (e === "success") {
+                        $('#release_table').DataTable().draw(false);
+                    }
+                });
+        });
+}
+
+function delete_release(version) {
+    swal({
+            title: "确定要删除?",
+            text: "删除版本后不能再回滚到该版本，请确认！",
+            type: "warning",
+            showCancelButton: true,
+            confirmButtonColor: "#DD6B55",
+            confirmButtonText: "确认删除",
+            cancelButtonText: "取消",
+            closeOnConfirm: false
+        },
+        function () {
+            $.post("/worker-release-del",
+                {
+                    "version": version,
+                }, function (res, e) {
+                    if (e === "success" && res['status'] == "success") {
+                        swal.close();
+                        $('#release_table').DataTable().draw(false);
+                    } else {
+                        swal('Warning', '删除失败！' + res['msg'], 'error');
+                    }
+                });
+        });
+}
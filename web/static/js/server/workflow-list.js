@@ -0,0 +1,192 @@
+$(function () {
+    $('#workflow_table').DataTable(
+        {
+            "paging": false,
+            "serverSide": true,
+            "autowidth": false,
+            "sort": false,
+            "dom": '<i><t>',
+            "ajax": {
+                "url": "/workflow-list",
+                "type": "post",
+            },
+            columns: [
+                {
+                    data: "index",
+                    title: "序号",
+                    width: "5%"
+                },
+                {data: "workflow_name", title: "名称", width: "15%"},
+                {data: "description", title: "描述", width: "20%"},
+                {data: "steps", title: "步骤", width: "35%"},
+                {data: "update_time", title: "更新时间", width: "12%"},
+                {
+                    title: "操作",
+                    width: "12%",
+                    "render": function (data, type, row, meta) {
+                        let strButton = "<a class=\"btn btn-sm btn-success\" href=javascript:run_workflow(\"" + row["id"] + "\") role=\"button\" title=\"Run\"><i class=\"fa fa-play\"></i></a>";
+                        strButton += "&nbsp;<a class=\"btn btn-sm btn-primary\" href=javascript:edit_workflow(\"" + row["id"] + "\") role=\"button\" title=\"Edit\"><i class=\"fa fa-edit\"></i></a>";
+                        strButton += "&nbsp;<a class=\"btn btn-sm btn-danger\" href=javascript:delete_workflow(\"" + row["id"] + "\") role=\"button\" title=\"Delete\"><i class=\"fa fa-trash\"></i></a>";
+                        return strButton;
+                    }
+                }
+            ],
+            infoCallback: function (settings, start, end, max, total, pre) {
+                return "共<b>" + total + "</b>条记录";
+            },
+        }
+    );//end datatable
+    $('#add_definition').attr("placeholder", JSON.stringify({
+        "steps": [
+            {"id": "port", "task": "portscan", "params": {"port": "--top-ports 1000"}},
+            {"id": "finger", "task": "fingerprint", "parent": "port"},
+            {"id": "nuclei", "task": "nuclei", "parent": "finger", "condition": {"fingerprint": "weblogic"}, "params": {"pocfile": "weblogic"}}
+        ]
+    }, null, 2));
+    $('#checkbox_cron_task').click(function () {
+        const checked = $(this).is(":checked");
+        $('#input_cron_comment').prop("disabled", !checked);
+        $('#input_cron_rule').prop("disabled", !checked);
+    });
+});
+
+//新建工作流窗口
+$("#create_workflow").click(function () {
+    $('#new_workflow').modal('toggle');
+    $('#workflowActionType').html("新建工作流");
+    $('#workflow_id').val("0");
+    $('#add_workflow_name').val("");
+    $('#add_description').val("");
+    $('#add_definition').val("");
+});
+
+$("#save_workflow").click(function () {
+    let url;
+    let data = {
+        "workflow_name": $('#add_workflow_name').val(),
+        "description": $('#add_description').val(),
+        "definition": $('#add_definition').val(),
+    };
+    if ($('#workflow_id').val() === "0") {
+        url = "/workflow-add";
+    } else {
+        url = "/workflow-update";
+        data["id"] = $('#workflow_id').val();
+    }
+    $.post(url, data, function (res, e) {
+        if (e === "success" && res['status'] == "success") {
+            swal({
+                    title: "保存成功！",
+                    text: res['msg'],
+                    type: "success",
+                    confirmButtonText: "确定",
+                    confirmButtonColor: "#41b883",
+                    closeOnConfirm: true,
+                },
+                function () {
+                    $('#new_workflow').modal('hide');
+                    $('#workflow_table').DataTable().draw(false);
+                });
+        } else {
+            swal('Warning', '保存失败！' + res['msg'], 'error');
+        }
+    });
+});
+
+$("#start_workflow").click(function () {
+    let cron_rule = "";
+    if ($('#checkbox_cron_task').is(":checked")) {
+        cron_rule = $('#input_cron_rule').val();
+        if (!cron_rule) {
+            swal('Warning', '请输入定时任务规则', 'error');
+            return;
+        }
+    }
+    $.post("/workflow-run", {
+        "workflow_id": $('#run_workflow_id').val(),
+        "target": $('#run_target').val(),
+        "targetset_id": $('#run_targetset_id').val(),
+        "worker_tag": $('#run_worker_tag').val().trim(),
+        "taskcron": $('#checkbox_cron_task').is(":checked"),
+        "cronrule": cron_rule,
+        "croncomment": $('#input_cron_comment').val(),
+    }, function (res, e) {
+        if (e === "success" && res['status'] == "success") {
+            swal({
+                    title: "新建任务成功！",
+                    text: res['msg'],
+                    type: "success",
+                    confirmButtonText: "确定",
+                    confirmButtonColor: "#41b883",
+                    closeOnConfirm: true,
+                },
+                function () {
+                    $('#run_workflow').modal('hide');
+                });
+        } else {
+            swal('Warning', '新建任务失败！' + res['msg'], 'error');
+        }
+    });
+});
+
+function run_workflow(id) {
+    $('#run_workflow').modal('toggle');
+    $('#run_workflow_id').val(id);
+    $('#run_target').val("");
+    $('#run_targetset_id').empty().append("<option value=''>--无--</option>");
+    $.post("/targetset-list", {}, function (res, e) {
+        if (e === "success") {
+            const data = res["data"];
+            for (let i = 0; i < data.length; i++) {
+                $('#run_targetset_id').append("<option value='" + data[i].id + "'>" + data[i].targetset_name + "(" + data[i].include_count + ")</option>");
+            }
+        }
+    });
+    $.post("/workflow-get",
+        {
+            "id": id,
+        }, function (data, e) {
+            if (e === "success") {
+                $('#runWorkflowName').text("执行工作流：" + data["workflow_name"]);
+            }
+        });
+}
+
+function edit_workflow(id) {
+    $('#new_workflow').modal('toggle');
+    $('#workflowActionType').html("编辑工作流");
+    $.post("/workflow-get",
+        {
+            "id": id,
+        }, function (data, e) {
+            if (e === "success") {
+                $('#workflow_id').val(data["id"]);
+                $('#add_workflow_name').val(data["workflow_name"]);
+                $('#add_description').val(data["description"]);
+                $('#add_definition').val(data["definition"]);
+            }
+        });
+}
+
+function delete_workflow(id) {
+    swal({
+            title: "确定要删除?",
+            text: "该操作会删除当前工作流，请确认！",
+            type: "warning",
+            showCancelButton: true,
+            confirmButtonColor: "#DD6B55",
+            confirmButtonText: "确认删除",
+            cancelButtonText: "取消",
+            closeOnConfirm: true
+        },
+        function () {
+            $.post("/workflow-del",
+                {
+                    "id": id,
+                }, function (data, e) {
+                    if (e === "success") {
+                        $('#workflow_table').DataTable().draw(false);
+                    }
+                });
+        });
+}
@@ -4,6 +4,8 @@ $(function () {
         const state = $("#state").val();
         const workspace_description = $("#workspace_description").val();
         const sort_order = $("#sort_order").val();
+        const notify_config = $("#notify_config").val();
+        const scope_config = $("#scope_config").val();
         if (!workspace_name) {
             swal('Warning', '工作空间名称不能为空', 'error');
             return;
@@ -22,8 +24,10 @@ $(function () {
                 "sort_order": sort_order,
                 'state': state,
                 'workspace_description': workspace_description,
+                'notify_config': notify_config,
+                'scope_config': scope_config,
             }, function (data, e) {
-                if (e === "success") {
+                if (e === "success" && data['status'] == "success") {
                     swal({
                             title: "添加工作空间成功",
                             text: "",
@@ -37,7 +41,7 @@ $(function () {
                             location.href = "/workspace-list"
                         });
                 } else {
-                    swal('Warning', "添加工作空间失败!", 'error');
+                    swal('Warning', "添加工作空间失败!" + data['msg'], 'error');
                 }
             });
 
@@ -63,6 +63,8 @@ $(function () {
         const workspace_description = $("#workspace_description").val();
         const state = $("#state").val();
         const sort_order = $("#sort_order").val();
+        const notify_config = $("#notify_config").val();
+        const scope_config = $("#scope_config").val();
         if (!workspace_id) return;
         if (!workspace_name) {
             swal('Warning', '工作空间名称不能为空', 'error');
@@ -78,8 +80,10 @@ $(function () {
                 "sort_order": sort_order,
                 'state': state,
                 'workspace_description': workspace_description,
+                'notify_config': notify_config,
+                'scope_config': scope_config,
             }, function (data, e) {
-                if (e === "success") {
+                if (e === "success" && data['status'] == "success") {
                     swal({
                             title: "更新工作空间成功",
                             text: "",
@@ -94,7 +98,7 @@ $(function () {
                             $('#editworkspace').modal('hide');
                         });
                 } else {
-                    swal('Warning', "更新工作空间失败!", 'error');
+                    swal('Warning', "更新工作空间失败!" + data['msg'], 'error');
                 }
             });
     });
@@ -137,6 +141,8 @@ function edit_workspace(id) {
             $('#state').val(data.state);
             $('#workspace_id').val(id);
             $('#workspace_description').val(data.workspace_description);
+            $('#notify_config').val(data.notify_config);
+            $('#scope_config').val(data.scope_config);
         },
         error: function (xhr, type) {
         }